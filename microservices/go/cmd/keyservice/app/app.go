@@ -1,6 +1,7 @@
 package app
 
 import (
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/background"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/listeners"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/servers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
@@ -16,6 +17,7 @@ func NewApp(
 	_ servers.AppServer,
 	_ listeners.KafkaListener,
 	_ servers.GrpcServer,
+	_ background.CronRunner,
 ) *App {
 	return &App{}
 }
@@ -5,10 +5,12 @@ package app
 
 import (
 	"github.com/google/wire"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/background"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/businessrules"
 	appConf "github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/controllers"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/grpcapis"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/keyproviders"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/listeners"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/repositories"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/servers"
@@ -17,7 +19,9 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userkeypb"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/identityproviders"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/middlewares"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/scheduler"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedrepos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
@@ -40,10 +44,22 @@ func InitializeApp() *App {
 		wire.Bind(new(conf.GrpcClientConf), new(*conf.GrpcClientConfImpl)),
 		authconf.NewAuth0SecurityConfImpl,
 		wire.Bind(new(authconf.Auth0SecurityConf), new(*authconf.Auth0RouteSecurityConfImpl)),
+		authconf.NewIdentityProviderConfImpl,
+		wire.Bind(new(authconf.IdentityProviderConf), new(*authconf.IdentityProviderConfImpl)),
+		identityproviders.NewIdentityProvider,
 		conf.NewMongoConfImpl,
 		wire.Bind(new(conf.MongoConf), new(*conf.MongoConfImpl)),
 		appConf.NewKeyConfImpl,
 		wire.Bind(new(appConf.KeyConf), new(*appConf.KeyConfImpl)),
+		appConf.NewSessionBindingConfImpl,
+		wire.Bind(new(appConf.SessionBindingConf), new(*appConf.SessionBindingConfImpl)),
+		appConf.NewPasscodePolicyConfImpl,
+		wire.Bind(new(appConf.PasscodePolicyConf), new(*appConf.PasscodePolicyConfImpl)),
+		appConf.NewKdfConfImpl,
+		wire.Bind(new(appConf.KdfConf), new(*appConf.KdfConfImpl)),
+		appConf.NewMasterKeyConfImpl,
+		wire.Bind(new(appConf.MasterKeyConf), new(*appConf.MasterKeyConfImpl)),
+		keyproviders.NewKeyProvider,
 		externalservices.NewHTTPClientProviderImpl,
 		wire.Bind(new(externalservices.HttpClientProvider), new(*externalservices.HttpClientProviderImpl)),
 		externalservices.NewSysAccessTokenClientAuth0Impl,
@@ -57,28 +73,66 @@ func InitializeApp() *App {
 		dshandlers.NewMongoDBHandler,
 		wire.Bind(new(dshandlers.CrudDSHandler), new(*dshandlers.MongoDBHandler)),
 		dshandlers.NewRedisDBHandler,
+		conf.NewSchedulerConfImpl,
+		wire.Bind(new(conf.SchedulerConf), new(*conf.SchedulerConfImpl)),
+		scheduler.NewRedisLeaseManagerImpl,
+		wire.Bind(new(scheduler.LeaseManager), new(*scheduler.RedisLeaseManagerImpl)),
 		sharedrepos.NewUserRepositoryImpl,
 		wire.Bind(new(sharedrepos.UserRepository), new(*sharedrepos.UserRepositoryImpl)),
 		repositories.NewUserKeyRepositoryImpl,
 		wire.Bind(new(repositories.UserKeyGeneratorRepository), new(*repositories.UserKeyGeneratorRepositoryImpl)),
 		repositories.NewUserKeySessionRepositoryImpl,
 		wire.Bind(new(repositories.UserKeySessionRepository), new(*repositories.UserKeySessionRepositoryImpl)),
+		repositories.NewSrpChallengeRepositoryImpl,
+		wire.Bind(new(repositories.SrpChallengeRepository), new(*repositories.SrpChallengeRepositoryImpl)),
+		repositories.NewUnlockAttemptRepositoryImpl,
+		wire.Bind(new(repositories.UnlockAttemptRepository), new(*repositories.UnlockAttemptRepositoryImpl)),
+		repositories.NewSrpUnlockRepositoryImpl,
+		wire.Bind(new(repositories.SrpUnlockRepository), new(*repositories.SrpUnlockRepositoryImpl)),
+		repositories.NewRewrapGrantRepositoryImpl,
+		wire.Bind(new(repositories.RewrapGrantRepository), new(*repositories.RewrapGrantRepositoryImpl)),
+		repositories.NewUserKeyRotationOutboxRepositoryImpl,
+		wire.Bind(new(repositories.UserKeyRotationOutboxRepository), new(*repositories.UserKeyRotationOutboxRepositoryImpl)),
+		repositories.NewDecoyVaultSeedOutboxRepositoryImpl,
+		wire.Bind(new(repositories.DecoyVaultSeedOutboxRepository), new(*repositories.DecoyVaultSeedOutboxRepositoryImpl)),
+		repositories.NewKeySessionElevationRepositoryImpl,
+		wire.Bind(new(repositories.KeySessionElevationRepository), new(*repositories.KeySessionElevationRepositoryImpl)),
 		repositories.NewAppSecretRepositoryImpl,
 		wire.Bind(new(repositories.AppSecretRepository), new(*repositories.AppSecretRepositoryImpl)),
 		repositories.NewPrimaryAppSecretRefRepositoryImpl,
 		wire.Bind(new(repositories.PrimaryAppSecretRefRepository), new(*repositories.PrimaryAppSecretRefRepositoryImpl)),
+		repositories.NewAppSecretHistoryRepositoryImpl,
+		wire.Bind(new(repositories.AppSecretHistoryRepository), new(*repositories.AppSecretHistoryRepositoryImpl)),
 		sharedservices.NewErrorServiceImpl,
 		wire.Bind(new(sharedservices.ErrorService), new(*sharedservices.ErrorServiceImpl)),
 		ginservices.NewGinCtxServiceImpl,
 		wire.Bind(new(ginservices.GinCtxService), new(*ginservices.GinCtxServiceImpl)),
 		sharedservices.NewUserServiceImpl,
 		wire.Bind(new(sharedservices.UserService), new(*sharedservices.UserServiceImpl)),
+		sharedservices.NewUserDeleteAckMsgSendServiceImpl,
+		wire.Bind(new(sharedservices.UserDeleteAckMsgSendService), new(*sharedservices.UserDeleteAckMsgSendServiceImpl)),
+		sharedservices.NewUserDataExportPartMsgSendServiceImpl,
+		wire.Bind(new(sharedservices.UserDataExportPartMsgSendService), new(*sharedservices.UserDataExportPartMsgSendServiceImpl)),
 		businessrules.NewUserKeyBrImpl,
 		wire.Bind(new(businessrules.UserKeyBr), new(*businessrules.UserKeyBrImpl)),
 		services.NewUserChangeEventServiceImpl,
 		wire.Bind(new(services.UserChangeEventService), new(*services.UserChangeEventServiceImpl)),
+		services.NewUserDataExportEventServiceImpl,
+		wire.Bind(new(services.UserDataExportEventService), new(*services.UserDataExportEventServiceImpl)),
 		services.NewAppSecretServiceImpl,
 		wire.Bind(new(services.AppSecretService), new(*services.AppSecretServiceImpl)),
+		sharedservices.NewUserKeyMsgSendServiceImpl,
+		wire.Bind(new(sharedservices.UserKeyMsgSendService), new(*sharedservices.UserKeyMsgSendServiceImpl)),
+		services.NewUserKeyRotationOutboxServiceImpl,
+		wire.Bind(new(services.UserKeyRotationOutboxService), new(*services.UserKeyRotationOutboxServiceImpl)),
+		services.NewDecoyVaultSeedOutboxServiceImpl,
+		wire.Bind(new(services.DecoyVaultSeedOutboxService), new(*services.DecoyVaultSeedOutboxServiceImpl)),
+		services.NewPasscodePolicyServiceImpl,
+		wire.Bind(new(services.PasscodePolicyService), new(*services.PasscodePolicyServiceImpl)),
+		services.NewUnlockAttemptServiceImpl,
+		wire.Bind(new(services.UnlockAttemptService), new(*services.UnlockAttemptServiceImpl)),
+		services.NewTotpServiceImpl,
+		wire.Bind(new(services.TotpService), new(*services.TotpServiceImpl)),
 		services.NewUserKeyServiceImpl,
 		wire.Bind(new(services.UserKeyService), new(*services.UserKeyServiceImpl)),
 		securityservices.NewJwtValidateWebAppServiceImpl,
@@ -87,10 +141,16 @@ func InitializeApp() *App {
 		wire.Bind(new(middlewares.AuthMiddleware), new(*middlewares.AuthMiddlewareImpl)),
 		controllers.NewTestControllerImpl,
 		wire.Bind(new(controllers.UserKeyController), new(*controllers.UserKeyControllerImpl)),
+		controllers.NewAppSecretControllerImpl,
+		wire.Bind(new(controllers.AppSecretController), new(*controllers.AppSecretControllerImpl)),
 		servers.NewAppServerImpl,
 		wire.Bind(new(servers.AppServer), new(*servers.AppServerImpl)),
 		listeners.NewUserChange1ListenerImpl,
 		wire.Bind(new(listeners.UserChange1Listener), new(*listeners.UserChange1ListenerImpl)),
+		listeners.NewUserKeyRotationAckListenerImpl,
+		wire.Bind(new(listeners.UserKeyRotationAckListener), new(*listeners.UserKeyRotationAckListenerImpl)),
+		listeners.NewUserDataExportListenerImpl,
+		wire.Bind(new(listeners.UserDataExportListener), new(*listeners.UserDataExportListenerImpl)),
 		listeners.NewKafkaListenerImpl,
 		wire.Bind(new(listeners.KafkaListener), new(*listeners.KafkaListenerImpl)),
 		securityservices.NewJwtValidateGrpcServiceImpl,
@@ -103,6 +163,8 @@ func InitializeApp() *App {
 		wire.Bind(new(userkeypb.UserKeyServiceServer), new(*grpcapis.UserKeyServiceServerImpl)),
 		servers.NewGrpcServerImpl,
 		wire.Bind(new(servers.GrpcServer), new(*servers.GrpcServerImpl)),
+		background.NewCronRunnerImpl,
+		wire.Bind(new(background.CronRunner), new(*background.CronRunnerImpl)),
 		NewApp,
 	)
 	return &App{}
@@ -0,0 +1,72 @@
+package background
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/scheduler"
+	"time"
+)
+
+// CronRunner runs cron tasks in the background
+type CronRunner interface {
+	lifecycle.TaskRunner
+}
+
+type CronRunnerImpl struct {
+	appSecretService       services.AppSecretService
+	rotationOutboxService  services.UserKeyRotationOutboxService
+	decoySeedOutboxService services.DecoyVaultSeedOutboxService
+	leaseManager           scheduler.LeaseManager
+}
+
+func (c CronRunnerImpl) Run() {
+	s := scheduler.NewSchedulerImpl(c.leaseManager)
+
+	// App secrets are shared by every replica, so only one replica rotates them
+	s.Schedule(
+		scheduler.JobSettings{Name: "rotate-app-secrets", Interval: time.Minute, ClusterSingleton: true},
+		func(ctx context.Context) {
+			c.appSecretService.AppSecretRotationTask(ctx)
+		},
+	)
+
+	// Rotations are relayed by one replica so they are not published twice
+	s.Schedule(
+		scheduler.JobSettings{Name: "relay-user-key-rotations", Interval: time.Second, ClusterSingleton: true},
+		func(ctx context.Context) {
+			c.rotationOutboxService.RelayUserKeyRotationsTask(ctx)
+		},
+	)
+
+	s.Schedule(
+		scheduler.JobSettings{Name: "relay-decoy-vault-seeds", Interval: time.Second, ClusterSingleton: true},
+		func(ctx context.Context) {
+			c.decoySeedOutboxService.RelayDecoyVaultSeedsTask(ctx)
+		},
+	)
+
+	s.StartBlocking()
+}
+
+func NewCronRunnerImpl(
+	appSecretService services.AppSecretService,
+	rotationOutboxService services.UserKeyRotationOutboxService,
+	decoySeedOutboxService services.DecoyVaultSeedOutboxService,
+	leaseManager scheduler.LeaseManager,
+) *CronRunnerImpl {
+	if !environment.ActivateCronRunner() {
+		// Task runner is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+	c := &CronRunnerImpl{
+		appSecretService:       appSecretService,
+		rotationOutboxService:  rotationOutboxService,
+		decoySeedOutboxService: decoySeedOutboxService,
+		leaseManager:           leaseManager,
+	}
+	lifecycle.RegisterTaskRunner(c)
+	return c
+}
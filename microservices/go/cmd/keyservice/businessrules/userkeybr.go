@@ -2,31 +2,82 @@ package businessrules
 
 import (
 	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors/validationutils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"regexp"
+	"time"
 )
 
+var vaultIdRegexp = regexp.MustCompile("^[a-z0-9][a-z0-9-]{0,35}$")
+
 type UserKeyBr interface {
+	ValidateVaultId(vaultId string) error
+	ValidateVaultCreate(vaultId string, existingFind option.Maybe[models.UserKeyGenerator]) error
 	ValidateSessionTokenHash(session models.UserKeySession, tokenBytes []byte) error
+	ValidateSessionBinding(session models.UserKeySession, binding commondtos.ClientBindingDto) error
 	ValidateKeyFromSession(userKeyGen models.UserKeyGenerator, key []byte) error
 	ValidateKeyFromPassword(userKeyGen models.UserKeyGenerator, key []byte) error
+	ValidateKeyRotation(userKeyGen models.UserKeyGenerator) error
+	ValidateSrpEnabled(userKeyGen models.UserKeyGenerator) error
+	ValidateSrpChallenge(userId string, challengeFind option.Maybe[models.SrpChallenge]) error
+	ValidateSrpCreate(dto keydtos.SrpPasscodeCreateDto) error
+	ValidateSrpUnlock(userId string, unlockFind option.Maybe[models.SrpUnlock]) error
+	ValidateUserKey(userKeyGen models.UserKeyGenerator, key []byte) error
+	// ValidateRewrapGrant checks the grant exists, was issued to the consumer
+	// presenting it and the token is the one it was issued with
+	ValidateRewrapGrant(grantFind option.Maybe[models.RewrapGrant], consumer string, tokenBytes []byte) error
+	ValidateTotpEnrolment(userKeyGen models.UserKeyGenerator) error
+	ValidateTotpConfirmation(userKeyGen models.UserKeyGenerator) error
+	ValidateTotpEnabled(userKeyGen models.UserKeyGenerator) error
+	ValidateDuressCreate(userKeyGen models.UserKeyGenerator, dto keydtos.DuressPasscodeCreateDto) error
+	// ValidateSessionNotRevoked checks the session was created after the
+	// vault's sessions were last revoked
+	ValidateSessionNotRevoked(userKeyGen models.UserKeyGenerator, session models.UserKeySession) error
 	ValidateProxyKeyCiphersFromSession(
 		ctx context.Context,
 		proxyKey []byte,
 		userId string,
+		vaultId string,
 		keyVersion int64,
 		session models.UserKeySession,
 	) error
 }
 
 type UserKeyBrImpl struct {
-	errorService sharedservices.ErrorService
-	userService  sharedservices.UserService
+	errorService       sharedservices.ErrorService
+	userService        sharedservices.UserService
+	sessionBindingConf conf.SessionBindingConf
+}
+
+func (u UserKeyBrImpl) ValidateVaultId(vaultId string) error {
+	var ruleErrs []apperrors.RuleError
+	if !vaultIdRegexp.MatchString(vaultId) {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidVaultId))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateVaultCreate(
+	vaultId string,
+	existingFind option.Maybe[models.UserKeyGenerator],
+) error {
+	if err := u.ValidateVaultId(vaultId); err != nil {
+		return err
+	}
+	var ruleErrs []apperrors.RuleError
+	if existingFind.IsPresent() {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeResourceAlreadyCreated))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
 }
 
 func (u UserKeyBrImpl) ValidateSessionTokenHash(session models.UserKeySession, tokenBytes []byte) error {
@@ -42,6 +93,39 @@ func (u UserKeyBrImpl) ValidateSessionTokenHash(session models.UserKeySession, t
 
 }
 
+// ValidateSessionBinding checks the client presenting a session is the client
+// it was created for. Only the bindings enabled for the environment that were
+// recorded on the session are checked.
+func (u UserKeyBrImpl) ValidateSessionBinding(session models.UserKeySession, binding commondtos.ClientBindingDto) error {
+	var ruleErrs []apperrors.RuleError
+
+	if u.sessionBindingConf.BindDevice() && len(session.DeviceIdHash) > 0 {
+		verified, err := cipherutils.VerifyHashWithSaltSHA256(session.DeviceIdHash, []byte(binding.DeviceId))
+		if err != nil {
+			return err
+		}
+		if !verified || utils.StringIsBlank(binding.DeviceId) {
+			ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSession))
+		}
+	}
+	if u.sessionBindingConf.BindIpNetwork() && len(session.IpNetworkHash) > 0 {
+		ipNetwork, ok := utils.IPNetwork(
+			binding.ClientIp,
+			u.sessionBindingConf.GetIpv4PrefixLength(),
+			u.sessionBindingConf.GetIpv6PrefixLength(),
+		)
+		verified, err := cipherutils.VerifyHashWithSaltSHA256(session.IpNetworkHash, []byte(ipNetwork))
+		if err != nil {
+			return err
+		}
+		if !verified || !ok {
+			ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSession))
+		}
+	}
+
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
 func (u UserKeyBrImpl) ValidateKeyFromSession(userKeyGen models.UserKeyGenerator, key []byte) error {
 	var ruleErrs []apperrors.RuleError
 
@@ -60,6 +144,7 @@ func (u UserKeyBrImpl) ValidateProxyKeyCiphersFromSession(
 	ctx context.Context,
 	proxyKey []byte,
 	userId string,
+	vaultId string,
 	keyVersion int64,
 	session models.UserKeySession,
 ) error {
@@ -78,15 +163,30 @@ func (u UserKeyBrImpl) ValidateProxyKeyCiphersFromSession(
 		return err
 	}
 	keyInvalid := string(savedKeyVersionBytes) != utils.Int64ToStr(keyVersion)
+	savedVaultId := commondtos.DefaultVaultId
+	if len(session.VaultIdCipher) > 0 {
+		savedVaultIdBytes, err := cipherutils.DecryptAES(proxyKey, session.VaultIdCipher)
+		if err != nil {
+			logger.Log.WithContext(ctx).WithError(err).Debug()
+			return err
+		}
+		savedVaultId = string(savedVaultIdBytes)
+	}
+	vaultInvalid := savedVaultId != vaultId
 
-	if userIdInvalid || keyInvalid {
+	if userIdInvalid || keyInvalid || vaultInvalid {
 		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSession))
 	}
 
-	// Validate User Exists
-	userExists, err := u.userService.UserExistsWithId(ctx, userId)
-	if !userExists {
+	// Validate User Exists and is not suspended
+	userFind, err := u.userService.GetUserWithId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if userBo, ok := userFind.Get(); !ok {
 		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSession))
+	} else if userBo.Suspended {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeUserSuspended))
 	}
 
 	return validationutils.MergeRuleErrors(ruleErrs)
@@ -94,6 +194,14 @@ func (u UserKeyBrImpl) ValidateProxyKeyCiphersFromSession(
 
 func (u UserKeyBrImpl) ValidateKeyFromPassword(userKeyGen models.UserKeyGenerator, key []byte) error {
 	var ruleErrs []apperrors.RuleError
+	if !userKeyGen.HasKeyHash() {
+		// Generators created with SRP have no passcode key hash, so the passcode
+		// key is verified by unwrapping the user key with it
+		if _, err := cipherutils.DecryptAES(key, userKeyGen.WrappedKey); err != nil {
+			ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeIncorrectPasscode))
+		}
+		return validationutils.MergeRuleErrors(ruleErrs)
+	}
 	verified, err := cipherutils.VerifyKeyHashBcrypt(userKeyGen.KeyHash, key)
 	if err != nil {
 		return err
@@ -103,6 +211,144 @@ func (u UserKeyBrImpl) ValidateKeyFromPassword(userKeyGen models.UserKeyGenerato
 	return validationutils.MergeRuleErrors(ruleErrs)
 }
 
-func NewUserKeyBrImpl(errorService sharedservices.ErrorService, userService sharedservices.UserService) *UserKeyBrImpl {
-	return &UserKeyBrImpl{errorService: errorService, userService: userService}
+func (u UserKeyBrImpl) ValidateKeyRotation(userKeyGen models.UserKeyGenerator) error {
+	var ruleErrs []apperrors.RuleError
+	// A rotation whose consumers ran out of time is superseded rather than
+	// blocking rotations forever
+	if userKeyGen.IsRotating() && !userKeyGen.IsRotationExpired(time.Now()) {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeKeyRotationInProgress))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateSrpEnabled(userKeyGen models.UserKeyGenerator) error {
+	var ruleErrs []apperrors.RuleError
+	// SRP cannot tell a duress passcode apart from a wrong one, so vaults with a
+	// duress passcode are unlocked with their passcode instead. The error is the
+	// same as for vaults never enrolled in SRP.
+	if !userKeyGen.IsSrpEnabled() || userKeyGen.HasDuress() {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeSrpNotEnabled))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateSrpChallenge(userId string, challengeFind option.Maybe[models.SrpChallenge]) error {
+	var ruleErrs []apperrors.RuleError
+	challenge, ok := challengeFind.Get()
+	if !ok || challenge.UserId != userId {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSrpChallenge))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateSrpCreate(dto keydtos.SrpPasscodeCreateDto) error {
+	var ruleErrs []apperrors.RuleError
+	if !cipherutils.IsKeyLengthAES(dto.Key) {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidUserKey))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateSrpUnlock(userId string, unlockFind option.Maybe[models.SrpUnlock]) error {
+	var ruleErrs []apperrors.RuleError
+	unlock, ok := unlockFind.Get()
+	if !ok || unlock.UserId != userId {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSrpChallenge))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateUserKey(userKeyGen models.UserKeyGenerator, key []byte) error {
+	var ruleErrs []apperrors.RuleError
+	if !cipherutils.IsKeyLengthAES(key) {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidUserKey))
+		return validationutils.MergeRuleErrors(ruleErrs)
+	}
+	verified, err := cipherutils.VerifyKeyHashBcrypt(userKeyGen.UserKeyHash, key)
+	if err != nil {
+		return err
+	} else if !verified {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidUserKey))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateRewrapGrant(
+	grantFind option.Maybe[models.RewrapGrant],
+	consumer string,
+	tokenBytes []byte,
+) error {
+	var ruleErrs []apperrors.RuleError
+	grant, ok := grantFind.Get()
+	if !ok || grant.Consumer != consumer {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidRewrapGrant))
+		return validationutils.MergeRuleErrors(ruleErrs)
+	}
+	verified, err := cipherutils.VerifyHashWithSaltSHA256(grant.TokenHash, tokenBytes)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidRewrapGrant))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateTotpEnrolment(userKeyGen models.UserKeyGenerator) error {
+	var ruleErrs []apperrors.RuleError
+	if userKeyGen.IsTotpEnabled() {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeTotpAlreadyEnabled))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateTotpConfirmation(userKeyGen models.UserKeyGenerator) error {
+	var ruleErrs []apperrors.RuleError
+	if userKeyGen.IsTotpEnabled() {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeTotpAlreadyEnabled))
+	} else if len(userKeyGen.Totp.WrappedSecret) == 0 {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeTotpNotEnrolled))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateTotpEnabled(userKeyGen models.UserKeyGenerator) error {
+	var ruleErrs []apperrors.RuleError
+	if !userKeyGen.IsTotpEnabled() {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeTotpNotEnrolled))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateDuressCreate(
+	userKeyGen models.UserKeyGenerator,
+	dto keydtos.DuressPasscodeCreateDto,
+) error {
+	var ruleErrs []apperrors.RuleError
+	if userKeyGen.HasDuress() || userKeyGen.IsDecoy() {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeResourceAlreadyCreated))
+	}
+	if dto.DuressPasscode == dto.Passcode {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeDuressPasscodeMatchesPasscode))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserKeyBrImpl) ValidateSessionNotRevoked(
+	userKeyGen models.UserKeyGenerator,
+	session models.UserKeySession,
+) error {
+	var ruleErrs []apperrors.RuleError
+	if session.CreatedAt < userKeyGen.SessionsRevokedAt {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSession))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func NewUserKeyBrImpl(
+	errorService sharedservices.ErrorService,
+	userService sharedservices.UserService,
+	sessionBindingConf conf.SessionBindingConf,
+) *UserKeyBrImpl {
+	return &UserKeyBrImpl{errorService: errorService, userService: userService, sessionBindingConf: sessionBindingConf}
 }
@@ -0,0 +1,56 @@
+package conf
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+)
+
+type KdfConf interface {
+	// GetKDFParams returns the key derivation parameters new and upgraded user
+	// keys are derived with
+	GetKDFParams() cipherutils.KDFParams
+}
+
+type KdfConfImpl struct {
+	kdfParams cipherutils.KDFParams
+}
+
+func (k KdfConfImpl) GetKDFParams() cipherutils.KDFParams {
+	return k.kdfParams
+}
+
+func NewKdfConfImpl() *KdfConfImpl {
+	defaultArgon2id := cipherutils.DefaultArgon2idKDFParams().Argon2id
+	defaultScrypt := cipherutils.LegacyKDFParams().Scrypt
+
+	algorithm := cipherutils.KDFAlgorithm(environment.GetEnvVarOrDefault(
+		environment.EnvVarKeyKdfAlgorithm,
+		string(cipherutils.KDFAlgorithmArgon2id),
+	))
+	kdfParams := cipherutils.KDFParams{Algorithm: algorithm}
+	switch algorithm {
+	case cipherutils.KDFAlgorithmScrypt:
+		kdfParams.Scrypt = cipherutils.ScryptParams{
+			N: environment.GetEnvVarAsIntOrDefault(environment.EnvVarKeyKdfScryptN, defaultScrypt.N),
+			R: environment.GetEnvVarAsIntOrDefault(environment.EnvVarKeyKdfScryptR, defaultScrypt.R),
+			P: environment.GetEnvVarAsIntOrDefault(environment.EnvVarKeyKdfScryptP, defaultScrypt.P),
+		}
+	default:
+		kdfParams.Algorithm = cipherutils.KDFAlgorithmArgon2id
+		kdfParams.Argon2id = cipherutils.Argon2idParams{
+			Time: uint32(environment.GetEnvVarAsIntOrDefault(
+				environment.EnvVarKeyKdfArgon2idTime,
+				int(defaultArgon2id.Time),
+			)),
+			MemoryKiB: uint32(environment.GetEnvVarAsIntOrDefault(
+				environment.EnvVarKeyKdfArgon2idMemoryKiB,
+				int(defaultArgon2id.MemoryKiB),
+			)),
+			Threads: uint8(environment.GetEnvVarAsIntOrDefault(
+				environment.EnvVarKeyKdfArgon2idThreads,
+				int(defaultArgon2id.Threads),
+			)),
+		}
+	}
+	return &KdfConfImpl{kdfParams: kdfParams}
+}
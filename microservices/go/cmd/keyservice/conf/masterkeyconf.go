@@ -0,0 +1,108 @@
+package conf
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/encodingutils"
+	"os"
+	"strings"
+)
+
+const KeyProviderLocal = "local"
+const KeyProviderVault = "vault"
+
+type MasterKeyConf interface {
+	// GetKeyProvider returns which key provider wraps app secrets, either
+	// KeyProviderLocal or KeyProviderVault
+	GetKeyProvider() string
+	// GetLocalMasterKey returns the master key used by the local key provider
+	GetLocalMasterKey() []byte
+	GetVaultAddress() string
+	GetVaultToken() string
+	GetVaultTransitKeyName() string
+}
+
+type MasterKeyConfImpl struct {
+	keyProvider         string
+	localMasterKey      []byte
+	vaultAddress        string
+	vaultToken          string
+	vaultTransitKeyName string
+}
+
+func (m MasterKeyConfImpl) GetKeyProvider() string {
+	return m.keyProvider
+}
+
+func (m MasterKeyConfImpl) GetLocalMasterKey() []byte {
+	return m.localMasterKey
+}
+
+func (m MasterKeyConfImpl) GetVaultAddress() string {
+	return m.vaultAddress
+}
+
+func (m MasterKeyConfImpl) GetVaultToken() string {
+	return m.vaultToken
+}
+
+func (m MasterKeyConfImpl) GetVaultTransitKeyName() string {
+	return m.vaultTransitKeyName
+}
+
+func NewMasterKeyConfImpl() *MasterKeyConfImpl {
+	keyProvider := environment.GetEnvVarOrDefault(environment.EnvVarKeyProvider, KeyProviderLocal)
+	var localMasterKey []byte
+	if keyProvider != KeyProviderVault {
+		keyProvider = KeyProviderLocal
+		localMasterKey = loadLocalMasterKey()
+	}
+	return &MasterKeyConfImpl{
+		keyProvider:         keyProvider,
+		localMasterKey:      localMasterKey,
+		vaultAddress:        environment.GetEnvVar(environment.EnvVarVaultAddress),
+		vaultToken:          environment.GetEnvVar(environment.EnvVarVaultToken),
+		vaultTransitKeyName: environment.GetEnvVarOrDefault(environment.EnvVarVaultTransitKeyName, "keyservice"),
+	}
+}
+
+// loadLocalMasterKey reads a base64 encoded master key from the environment or
+// from the file it points to. Outside of production, a random master key is
+// generated if none is provided and ephemeral master keys are explicitly
+// allowed, as app secrets wrapped by it can't be read after a restart or by
+// other replicas.
+func loadLocalMasterKey() []byte {
+	encodedKey := environment.GetEnvVar(environment.EnvVarMasterKey)
+	if keyPath := environment.GetEnvVar(environment.EnvVarMasterKeyPath); utils.StringIsBlank(encodedKey) &&
+		utils.StringIsNotBlank(keyPath) {
+		fileBytes, err := os.ReadFile(keyPath)
+		if err != nil {
+			logger.Log.WithError(err).Fatal("Unable to read master key file")
+		}
+		encodedKey = strings.TrimSpace(string(fileBytes))
+	}
+	if utils.StringIsBlank(encodedKey) {
+		if environment.IsProduction() {
+			logger.Log.Fatal("A master key is required in production")
+		}
+		if !environment.GetEnvVarAsBoolOrDefault(environment.EnvVarMasterKeyAllowEphemeral, false) {
+			logger.Log.Fatalf("A master key is required unless %v is true", environment.EnvVarMasterKeyAllowEphemeral)
+		}
+		logger.Log.Warn("No master key provided, generating a random master key")
+		key, err := cipherutils.GenerateRandomKeyAES()
+		if err != nil {
+			logger.Log.WithError(err).Fatal()
+		}
+		return key
+	}
+	key, err := encodingutils.DecodeBase64String(encodedKey)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Master key is not valid base64")
+	}
+	if !cipherutils.IsKeyLengthAES(key) {
+		logger.Log.Fatalf("Master key must be 32 bytes, got %v bytes", len(key))
+	}
+	return key
+}
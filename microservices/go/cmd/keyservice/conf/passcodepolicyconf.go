@@ -0,0 +1,42 @@
+package conf
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/passcodepolicy"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+)
+
+type PasscodePolicyConf interface {
+	// GetPasscodePolicy returns the rules new passcodes must satisfy
+	GetPasscodePolicy() passcodepolicy.Policy
+	// GetBreachedListPath is the path of the breached passcode list file, or
+	// empty if passcodes are not checked against one
+	GetBreachedListPath() string
+}
+
+type PasscodePolicyConfImpl struct {
+	passcodePolicy   passcodepolicy.Policy
+	breachedListPath string
+}
+
+func (p PasscodePolicyConfImpl) GetPasscodePolicy() passcodepolicy.Policy {
+	return p.passcodePolicy
+}
+
+func (p PasscodePolicyConfImpl) GetBreachedListPath() string {
+	return p.breachedListPath
+}
+
+func NewPasscodePolicyConfImpl() *PasscodePolicyConfImpl {
+	return &PasscodePolicyConfImpl{
+		passcodePolicy: passcodepolicy.Policy{
+			MinLength:        environment.GetEnvVarAsIntOrDefault(environment.EnvVarPasscodeMinLength, 8),
+			MaxLength:        environment.GetEnvVarAsIntOrDefault(environment.EnvVarPasscodeMaxLength, 128),
+			RequireLowercase: environment.GetEnvVarAsBoolOrDefault(environment.EnvVarPasscodeRequireLowercase, false),
+			RequireUppercase: environment.GetEnvVarAsBoolOrDefault(environment.EnvVarPasscodeRequireUppercase, false),
+			RequireDigit:     environment.GetEnvVarAsBoolOrDefault(environment.EnvVarPasscodeRequireDigit, false),
+			RequireSymbol:    environment.GetEnvVarAsBoolOrDefault(environment.EnvVarPasscodeRequireSymbol, false),
+			MinStrengthScore: environment.GetEnvVarAsIntOrDefault(environment.EnvVarPasscodeMinStrengthScore, 2),
+		},
+		breachedListPath: environment.GetEnvVar(environment.EnvVarPasscodeBreachedListPath),
+	}
+}
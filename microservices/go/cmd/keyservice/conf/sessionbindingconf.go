@@ -0,0 +1,48 @@
+package conf
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+)
+
+type SessionBindingConf interface {
+	// BindDevice returns true if key sessions are bound to the device ID the
+	// gateway assigned to the client
+	BindDevice() bool
+	// BindIpNetwork returns true if key sessions are bound to the network of
+	// the client's IP address
+	BindIpNetwork() bool
+	GetIpv4PrefixLength() int
+	GetIpv6PrefixLength() int
+}
+
+type SessionBindingConfImpl struct {
+	bindDevice       bool
+	bindIpNetwork    bool
+	ipv4PrefixLength int
+	ipv6PrefixLength int
+}
+
+func (s SessionBindingConfImpl) BindDevice() bool {
+	return s.bindDevice
+}
+
+func (s SessionBindingConfImpl) BindIpNetwork() bool {
+	return s.bindIpNetwork
+}
+
+func (s SessionBindingConfImpl) GetIpv4PrefixLength() int {
+	return s.ipv4PrefixLength
+}
+
+func (s SessionBindingConfImpl) GetIpv6PrefixLength() int {
+	return s.ipv6PrefixLength
+}
+
+func NewSessionBindingConfImpl() *SessionBindingConfImpl {
+	return &SessionBindingConfImpl{
+		bindDevice:       environment.GetEnvVarAsBoolOrDefault(environment.EnvVarKeySessionBindDevice, false),
+		bindIpNetwork:    environment.GetEnvVarAsBoolOrDefault(environment.EnvVarKeySessionBindIpNetwork, false),
+		ipv4PrefixLength: environment.GetEnvVarAsIntOrDefault(environment.EnvVarKeySessionIpv4PrefixLength, 24),
+		ipv6PrefixLength: environment.GetEnvVarAsIntOrDefault(environment.EnvVarKeySessionIpv6PrefixLength, 64),
+	}
+}
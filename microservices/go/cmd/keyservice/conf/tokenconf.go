@@ -1,12 +1,38 @@
 package conf
 
-import "time"
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"time"
+)
 
 type KeyConf interface {
 	GetTokenSessionDuration() time.Duration
 	GetSecretDuration() time.Duration
 	GetKeyRefreshInterval() time.Duration
 	GetPrimaryAppSecretDuration() time.Duration
+	// GetPreviousAppSecretsToKeep is the number of retired primary app secrets
+	// kept until they expire. Older app secrets are deleted once the sessions
+	// still referencing them have expired.
+	GetPreviousAppSecretsToKeep() int
+	// GetKeyRotationGrantDuration is how long consumers have to re-encrypt data
+	// with a rotated user key. A rotation that is not acknowledged by then can
+	// be superseded by another rotation.
+	GetKeyRotationGrantDuration() time.Duration
+	// GetKeyRotationConsumers are the services that must acknowledge a user key
+	// rotation before the previous key version is retired
+	GetKeyRotationConsumers() []string
+	// GetSrpChallengeDuration is how long a client has to answer an SRP
+	// challenge, and then to create a key session once its proof is verified
+	GetSrpChallengeDuration() time.Duration
+	// GetSessionElevationDuration is how long a key session stays elevated after
+	// the user re-enters their passcode
+	GetSessionElevationDuration() time.Duration
+	// GetUnlockAttemptLimit is how many incorrect passcodes and TOTP codes a
+	// user can enter in an attempt window before further attempts are refused
+	GetUnlockAttemptLimit() int64
+	// GetUnlockAttemptWindow is how long failed attempts are counted from the
+	// first failure
+	GetUnlockAttemptWindow() time.Duration
 }
 
 type KeyConfImpl struct {
@@ -14,6 +40,13 @@ type KeyConfImpl struct {
 	secretDuration           time.Duration
 	keyRefreshInterval       time.Duration
 	primaryAppSecretDuration time.Duration
+	previousAppSecretsToKeep int
+	keyRotationGrantDuration time.Duration
+	keyRotationConsumers     []string
+	srpChallengeDuration     time.Duration
+	sessionElevationDuration time.Duration
+	unlockAttemptLimit       int64
+	unlockAttemptWindow      time.Duration
 }
 
 func (k KeyConfImpl) GetTokenSessionDuration() time.Duration {
@@ -32,15 +65,57 @@ func (k KeyConfImpl) GetPrimaryAppSecretDuration() time.Duration {
 	return k.primaryAppSecretDuration
 }
 
+func (k KeyConfImpl) GetPreviousAppSecretsToKeep() int {
+	return k.previousAppSecretsToKeep
+}
+
+func (k KeyConfImpl) GetKeyRotationGrantDuration() time.Duration {
+	return k.keyRotationGrantDuration
+}
+
+func (k KeyConfImpl) GetKeyRotationConsumers() []string {
+	return k.keyRotationConsumers
+}
+
+func (k KeyConfImpl) GetSrpChallengeDuration() time.Duration {
+	return k.srpChallengeDuration
+}
+
+func (k KeyConfImpl) GetSessionElevationDuration() time.Duration {
+	return k.sessionElevationDuration
+}
+
+func (k KeyConfImpl) GetUnlockAttemptLimit() int64 {
+	return k.unlockAttemptLimit
+}
+
+func (k KeyConfImpl) GetUnlockAttemptWindow() time.Duration {
+	return k.unlockAttemptWindow
+}
+
 func NewKeyConfImpl() *KeyConfImpl {
 	tokenSessionDuration := 30 * time.Minute
 	secretDuration := 6 * tokenSessionDuration
 	keyRefreshInterval := 3 * tokenSessionDuration
 	primaryAppSecretDuration := 4 * tokenSessionDuration
+	previousAppSecretsToKeep := 3
+	keyRotationGrantDuration := time.Hour
+	keyRotationConsumers := []string{keydtos.KeyRotationConsumerNoteService}
+	srpChallengeDuration := time.Minute
+	sessionElevationDuration := 5 * time.Minute
+	unlockAttemptLimit := int64(10)
+	unlockAttemptWindow := 15 * time.Minute
 	return &KeyConfImpl{
 		tokenSessionDuration:     tokenSessionDuration,
 		secretDuration:           secretDuration,
 		keyRefreshInterval:       keyRefreshInterval,
 		primaryAppSecretDuration: primaryAppSecretDuration,
+		previousAppSecretsToKeep: previousAppSecretsToKeep,
+		keyRotationGrantDuration: keyRotationGrantDuration,
+		keyRotationConsumers:     keyRotationConsumers,
+		srpChallengeDuration:     srpChallengeDuration,
+		sessionElevationDuration: sessionElevationDuration,
+		unlockAttemptLimit:       unlockAttemptLimit,
+		unlockAttemptWindow:      unlockAttemptWindow,
 	}
 }
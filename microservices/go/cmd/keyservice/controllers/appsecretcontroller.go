@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/middlewares"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/ginservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/controller"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/routing"
+	"net/http"
+)
+
+type AppSecretController interface {
+	controller.Controller
+}
+
+type AppSecretControllerImpl struct {
+	appSecretService services.AppSecretService
+	authMiddleware   middlewares.AuthMiddleware
+	ginCtxService    ginservices.GinCtxService
+}
+
+func (a AppSecretControllerImpl) AddRoutes(r *gin.Engine) {
+	appSecretGroupV1 := r.Group(routing.APIPath(1, "appSecret"), a.authMiddleware.Authentication())
+
+	appSecretGroupV1.POST("/emergencyRotation",
+		a.authMiddleware.Authorization(middlewares.AuthorizerSettings{
+			AllAuthoritiesToVerify: []string{security.AuthorityAdminKeys},
+		}),
+		func(c *gin.Context) {
+			var resBody commondtos.SuccessDto
+
+			a.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				resBody, err = a.appSecretService.EmergencyRotateAppSecrets(c)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+}
+
+func NewAppSecretControllerImpl(
+	authMiddleware middlewares.AuthMiddleware,
+	ginCtxService ginservices.GinCtxService,
+	appSecretService services.AppSecretService,
+) *AppSecretControllerImpl {
+	return &AppSecretControllerImpl{
+		authMiddleware:   authMiddleware,
+		ginCtxService:    ginCtxService,
+		appSecretService: appSecretService,
+	}
+}
@@ -22,6 +22,7 @@ type UserKeyController interface {
 type UserKeyControllerImpl struct {
 	userService    sharedservices.UserService
 	userKeyService services.UserKeyService
+	totpService    services.TotpService
 	authMiddleware middlewares.AuthMiddleware
 	ginCtxService  ginservices.GinCtxService
 }
@@ -33,13 +34,16 @@ func (u UserKeyControllerImpl) AddRoutes(r *gin.Engine) {
 		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
 		func(c *gin.Context) {
 			var userBo userbos.UserBo
+			var vaultId string
 			var resBody commondtos.ExistsDto
 
 			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
 				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
 				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
 			}).Next(func() (err error) {
-				resBody, err = u.userKeyService.UserKeyExists(c, userBo)
+				resBody, err = u.userKeyService.UserKeyExists(c, userBo, vaultId)
 				return
 			}).Next(func() (err error) {
 				c.JSON(http.StatusOK, resBody)
@@ -51,17 +55,20 @@ func (u UserKeyControllerImpl) AddRoutes(r *gin.Engine) {
 		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
 		func(c *gin.Context) {
 			var userBo userbos.UserBo
+			var vaultId string
 			var reqBody keydtos.PasscodeCreateDto
 			var resBody commondtos.SuccessDto
 
 			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
 				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
 				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
 			}).Next(func() (err error) {
 				reqBody, err = ginservices.ReadValueFromBody[keydtos.PasscodeCreateDto](u.ginCtxService, c)
 				return
 			}).Next(func() (err error) {
-				resBody, err = u.userKeyService.CreateUserKey(c, userBo, reqBody)
+				resBody, err = u.userKeyService.CreateUserKey(c, userBo, vaultId, reqBody)
 				return
 			}).Next(func() (err error) {
 				c.JSON(http.StatusOK, resBody)
@@ -73,17 +80,295 @@ func (u UserKeyControllerImpl) AddRoutes(r *gin.Engine) {
 		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
 		func(c *gin.Context) {
 			var userBo userbos.UserBo
+			var vaultId string
 			var reqBody keydtos.PasscodeDto
 			var resBody commondtos.UKeySessionDto
 
 			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
 				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
 				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
 			}).Next(func() (err error) {
 				reqBody, err = ginservices.ReadValueFromBody[keydtos.PasscodeDto](u.ginCtxService, c)
 				return
 			}).Next(func() (err error) {
-				resBody, err = u.userKeyService.NewKeySession(c, userBo, reqBody)
+				resBody, err = u.userKeyService.NewKeySession(c, userBo, vaultId, reqBody, ginservices.ClientBindingFromHeaders(c))
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.GET("/vaults",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var resBody []keydtos.VaultDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.GetVaults(c, userBo)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.GET("/srp/params",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var resBody keydtos.SrpCreateParamsDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				_, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.GetSrpCreateParams(c)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/srp/passcode",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var vaultId string
+			var reqBody keydtos.SrpPasscodeCreateDto
+			var resBody commondtos.SuccessDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[keydtos.SrpPasscodeCreateDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.CreateUserKeyWithSrp(c, userBo, vaultId, reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/srp/challenge",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var vaultId string
+			var resBody keydtos.SrpChallengeDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.NewSrpChallenge(c, userBo, vaultId)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/srp/proof",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var reqBody keydtos.SrpProofDto
+			var resBody keydtos.SrpUnlockDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[keydtos.SrpProofDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.VerifySrpProof(c, userBo, reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/srp/newSession",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var reqBody keydtos.SrpKeySessionCreateDto
+			var resBody commondtos.UKeySessionDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[keydtos.SrpKeySessionCreateDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.NewKeySessionWithSrp(c, userBo, reqBody, ginservices.ClientBindingFromHeaders(c))
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/elevateSession",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var reqBody commondtos.UKeySessionReqDto[keydtos.PasscodeDto]
+			var resBody keydtos.SessionElevationDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadSessionReqFromBody[keydtos.PasscodeDto](
+					u.ginCtxService,
+					c,
+				)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.ElevateKeySession(c, userBo, reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/rotate",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var vaultId string
+			var reqBody keydtos.PasscodeDto
+			var resBody commondtos.SuccessDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[keydtos.PasscodeDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.RotateUserKeyTxn(c, userBo, vaultId, reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/duress",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var vaultId string
+			var reqBody keydtos.DuressPasscodeCreateDto
+			var resBody commondtos.SuccessDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[keydtos.DuressPasscodeCreateDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userKeyService.RegisterDuressPasscodeTxn(c, userBo, vaultId, reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/totp/enrol",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var vaultId string
+			var reqBody keydtos.PasscodeDto
+			var resBody keydtos.TotpEnrolmentDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[keydtos.PasscodeDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.totpService.EnrolTotp(c, userBo, vaultId, reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/totp/confirm",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var vaultId string
+			var reqBody keydtos.TotpCodeDto
+			var resBody keydtos.TotpBackupCodesDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[keydtos.TotpCodeDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.totpService.ConfirmTotp(c, userBo, vaultId, reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return
+			})
+		})
+
+	userKeyGroupV1.POST("/totp/disable",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var userBo userbos.UserBo
+			var vaultId string
+			var reqBody keydtos.TotpDisableDto
+			var resBody commondtos.SuccessDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				userBo, err = u.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				return u.readVaultId(c, &vaultId)
+			}).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[keydtos.TotpDisableDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.totpService.DisableTotp(c, userBo, vaultId, reqBody)
 				return
 			}).Next(func() (err error) {
 				c.JSON(http.StatusOK, resBody)
@@ -110,16 +395,26 @@ func (u UserKeyControllerImpl) AddRoutes(r *gin.Engine) {
 		})
 }
 
+// readVaultId reads the vault a request is for from the query, defaulting to
+// the default vault
+func (u UserKeyControllerImpl) readVaultId(c *gin.Context, vaultId *string) error {
+	return u.ginCtxService.ReqQueryReader(c).
+		ReadStringOrDefault("vaultId", vaultId, commondtos.DefaultVaultId).
+		Complete()
+}
+
 func NewTestControllerImpl(
 	authMiddleware middlewares.AuthMiddleware,
 	userService sharedservices.UserService,
 	ginCtxService ginservices.GinCtxService,
 	userKeyService services.UserKeyService,
+	totpService services.TotpService,
 ) *UserKeyControllerImpl {
 	return &UserKeyControllerImpl{
 		authMiddleware: authMiddleware,
 		userService:    userService,
 		ginCtxService:  ginCtxService,
 		userKeyService: userKeyService,
+		totpService:    totpService,
 	}
 }
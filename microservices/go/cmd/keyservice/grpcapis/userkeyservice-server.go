@@ -6,7 +6,9 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/gtools"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userkeypb"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmappers/grpcmappers"
+	"io"
 )
 
 type UserKeyServiceServerImpl struct {
@@ -29,6 +31,170 @@ func (u UserKeyServiceServerImpl) GetKeyFromSession(
 	return userKey, nil
 }
 
+func (u UserKeyServiceServerImpl) GetKeySessionInfo(
+	ctx context.Context,
+	userKeySession *userkeypb.UserKeySession,
+) (*userkeypb.KeySessionInfo, error) {
+	userKeySessionDto := commondtos.UKeySessionDto{}
+	grpcmappers.UserKeySessionToUserKeySessionDto(userKeySession, &userKeySessionDto)
+	keyDto, err := u.userKeyService.GetKeyFromSession(ctx, userKeySessionDto)
+	if err != nil {
+		return nil, gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+	}
+	sessionInfoDto := keyDto.GetSessionInfo()
+	sessionInfo := &userkeypb.KeySessionInfo{}
+	grpcmappers.KeySessionInfoDtoToKeySessionInfo(&sessionInfoDto, sessionInfo)
+	return sessionInfo, nil
+}
+
+func (u UserKeyServiceServerImpl) EncryptWithSession(
+	ctx context.Context,
+	sessionPayload *userkeypb.SessionCipherPayload,
+) (*userkeypb.CipherPayload, error) {
+	userKeySessionDto := commondtos.UKeySessionDto{}
+	grpcmappers.UserKeySessionToUserKeySessionDto(sessionPayload.GetSession(), &userKeySessionDto)
+	payloadDto := keydtos.CipherPayloadDto{}
+	grpcmappers.CipherPayloadToCipherPayloadDto(sessionPayload.GetPayload(), &payloadDto)
+	cipherPayloadDtos, err := u.userKeyService.EncryptWithSession(
+		ctx,
+		userKeySessionDto,
+		[]keydtos.CipherPayloadDto{payloadDto},
+	)
+	if err != nil {
+		return nil, gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+	}
+	cipherPayload := &userkeypb.CipherPayload{}
+	grpcmappers.CipherPayloadDtoToCipherPayload(&cipherPayloadDtos[0], cipherPayload)
+	return cipherPayload, nil
+}
+
+func (u UserKeyServiceServerImpl) DecryptWithSession(
+	ctx context.Context,
+	sessionPayload *userkeypb.SessionCipherPayload,
+) (*userkeypb.CipherPayload, error) {
+	userKeySessionDto := commondtos.UKeySessionDto{}
+	grpcmappers.UserKeySessionToUserKeySessionDto(sessionPayload.GetSession(), &userKeySessionDto)
+	payloadDto := keydtos.CipherPayloadDto{}
+	grpcmappers.CipherPayloadToCipherPayloadDto(sessionPayload.GetPayload(), &payloadDto)
+	plainPayloadDtos, err := u.userKeyService.DecryptWithSession(
+		ctx,
+		userKeySessionDto,
+		[]keydtos.CipherPayloadDto{payloadDto},
+	)
+	if err != nil {
+		return nil, gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+	}
+	plainPayload := &userkeypb.CipherPayload{}
+	grpcmappers.CipherPayloadDtoToCipherPayload(&plainPayloadDtos[0], plainPayload)
+	return plainPayload, nil
+}
+
+func (u UserKeyServiceServerImpl) EncryptBatchWithSession(
+	ctx context.Context,
+	sessionPayloadBatch *userkeypb.SessionCipherPayloadBatch,
+) (*userkeypb.CipherPayloadBatch, error) {
+	userKeySessionDto := commondtos.UKeySessionDto{}
+	grpcmappers.UserKeySessionToUserKeySessionDto(sessionPayloadBatch.GetSession(), &userKeySessionDto)
+	payloadDtos := grpcmappers.CipherPayloadsToCipherPayloadDtos(sessionPayloadBatch.GetPayloads())
+	cipherPayloadDtos, err := u.userKeyService.EncryptWithSession(ctx, userKeySessionDto, payloadDtos)
+	if err != nil {
+		return nil, gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+	}
+	return &userkeypb.CipherPayloadBatch{
+		Payloads: grpcmappers.CipherPayloadDtosToCipherPayloads(cipherPayloadDtos),
+	}, nil
+}
+
+func (u UserKeyServiceServerImpl) RewrapWithGrant(
+	ctx context.Context,
+	grantPayloadBatch *userkeypb.RewrapGrantPayloadBatch,
+) (*userkeypb.CipherPayloadBatch, error) {
+	grantDto := keydtos.RewrapGrantDto{}
+	grpcmappers.RewrapGrantToRewrapGrantDto(grantPayloadBatch.GetGrant(), &grantDto)
+	payloadDtos := grpcmappers.CipherPayloadsToCipherPayloadDtos(grantPayloadBatch.GetPayloads())
+	cipherPayloadDtos, err := u.userKeyService.RewrapWithGrant(ctx, grantDto, payloadDtos)
+	if err != nil {
+		return nil, gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+	}
+	return &userkeypb.CipherPayloadBatch{
+		Payloads: grpcmappers.CipherPayloadDtosToCipherPayloads(cipherPayloadDtos),
+	}, nil
+}
+
+func (u UserKeyServiceServerImpl) DecryptBatchWithSession(
+	ctx context.Context,
+	sessionPayloadBatch *userkeypb.SessionCipherPayloadBatch,
+) (*userkeypb.CipherPayloadBatch, error) {
+	userKeySessionDto := commondtos.UKeySessionDto{}
+	grpcmappers.UserKeySessionToUserKeySessionDto(sessionPayloadBatch.GetSession(), &userKeySessionDto)
+	payloadDtos := grpcmappers.CipherPayloadsToCipherPayloadDtos(sessionPayloadBatch.GetPayloads())
+	plainPayloadDtos, err := u.userKeyService.DecryptWithSession(ctx, userKeySessionDto, payloadDtos)
+	if err != nil {
+		return nil, gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+	}
+	return &userkeypb.CipherPayloadBatch{
+		Payloads: grpcmappers.CipherPayloadDtosToCipherPayloads(plainPayloadDtos),
+	}, nil
+}
+
+func (u UserKeyServiceServerImpl) EncryptStreamWithSession(
+	stream userkeypb.UserKeyService_EncryptStreamWithSessionServer,
+) error {
+	return u.processPayloadStream(stream, u.userKeyService.EncryptWithKey)
+}
+
+func (u UserKeyServiceServerImpl) DecryptStreamWithSession(
+	stream userkeypb.UserKeyService_DecryptStreamWithSessionServer,
+) error {
+	return u.processPayloadStream(stream, u.userKeyService.DecryptWithKey)
+}
+
+// payloadStream is implemented by the encrypt and decrypt stream servers
+type payloadStream interface {
+	Context() context.Context
+	Send(*userkeypb.CipherPayload) error
+	Recv() (*userkeypb.SessionCipherPayload, error)
+}
+
+// processPayloadStream replies to each payload received with the processed
+// payload. The key is only retrieved again when the session changes.
+func (u UserKeyServiceServerImpl) processPayloadStream(
+	stream payloadStream,
+	process func(keydtos.UserKeyDto, []keydtos.CipherPayloadDto) ([]keydtos.CipherPayloadDto, error),
+) error {
+	ctx := stream.Context()
+	var currentSessionDto commondtos.UKeySessionDto
+	var keyDto keydtos.UserKeyDto
+	for {
+		sessionPayload, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		userKeySessionDto := commondtos.UKeySessionDto{}
+		grpcmappers.UserKeySessionToUserKeySessionDto(sessionPayload.GetSession(), &userKeySessionDto)
+		if userKeySessionDto != currentSessionDto {
+			keyDto, err = u.userKeyService.GetKeyFromSession(ctx, userKeySessionDto)
+			if err != nil {
+				return gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+			}
+			currentSessionDto = userKeySessionDto
+		}
+		payloadDto := keydtos.CipherPayloadDto{}
+		grpcmappers.CipherPayloadToCipherPayloadDto(sessionPayload.GetPayload(), &payloadDto)
+		processedDtos, err := process(keyDto, []keydtos.CipherPayloadDto{payloadDto})
+		if err != nil {
+			return gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+		}
+		processedPayload := &userkeypb.CipherPayload{}
+		grpcmappers.CipherPayloadDtoToCipherPayload(&processedDtos[0], processedPayload)
+		if err := stream.Send(processedPayload); err != nil {
+			return err
+		}
+	}
+}
+
 func NewUserKeyServiceServerImpl(userKeyService services.UserKeyService) *UserKeyServiceServerImpl {
 	return &UserKeyServiceServerImpl{userKeyService: userKeyService}
 }
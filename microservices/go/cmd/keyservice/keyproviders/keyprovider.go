@@ -0,0 +1,29 @@
+package keyproviders
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
+)
+
+// KeyProvider wraps and unwraps keys with a master key that never leaves the
+// provider
+type KeyProvider interface {
+	// WrapKey encrypts a key with the master key
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+	// UnwrapKey decrypts a key wrapped by WrapKey
+	UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// NewKeyProvider creates the KeyProvider configured by the MasterKeyConf
+func NewKeyProvider(
+	masterKeyConf conf.MasterKeyConf,
+	clientProvider externalservices.HttpClientProvider,
+) KeyProvider {
+	switch masterKeyConf.GetKeyProvider() {
+	case conf.KeyProviderVault:
+		return NewVaultTransitKeyProviderImpl(masterKeyConf, clientProvider)
+	default:
+		return NewLocalKeyProviderImpl(masterKeyConf)
+	}
+}
@@ -0,0 +1,123 @@
+package keyproviders_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/keyproviders"
+	pkgConf "github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/encodingutils"
+	cv "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const stubVaultToken = "stub-token"
+const stubCiphertextPrefix = "vault:v1:"
+
+// newVaultTransitStub creates a server imitating the Vault Transit encrypt and
+// decrypt endpoints for the "keyservice" key
+func newVaultTransitStub() *httptest.Server {
+	mux := http.NewServeMux()
+	respond := func(w http.ResponseWriter, status int, body map[string]any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("X-Vault-Token") != stubVaultToken {
+			respond(w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc("/v1/transit/encrypt/keyservice", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		req := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		ciphertext := stubCiphertextPrefix + req["plaintext"]
+		respond(w, http.StatusOK, map[string]any{"data": map[string]string{"ciphertext": ciphertext}})
+	})
+	mux.HandleFunc("/v1/transit/decrypt/keyservice", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		req := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		plaintext := strings.TrimPrefix(req["ciphertext"], stubCiphertextPrefix)
+		respond(w, http.StatusOK, map[string]any{"data": map[string]string{"plaintext": plaintext}})
+	})
+	return httptest.NewServer(mux)
+}
+
+func newHttpClientProvider() externalservices.HttpClientProvider {
+	return externalservices.NewHTTPClientProviderImpl(pkgConf.NewHttpClientConfImpl())
+}
+
+func TestLocalKeyProvider(t *testing.T) {
+	masterKey, err := cipherutils.GenerateRandomKeyAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(environment.EnvVarKeyProvider, conf.KeyProviderLocal)
+	t.Setenv(environment.EnvVarMasterKey, encodingutils.EncodeBase64String(masterKey))
+	keyProvider := keyproviders.NewKeyProvider(conf.NewMasterKeyConfImpl(), newHttpClientProvider())
+
+	cv.Convey("When wrapping a key with the local key provider", t, func() {
+		ctx := context.Background()
+		key, err := cipherutils.GenerateRandomKeyAES()
+		cv.So(err, cv.ShouldBeNil)
+		wrappedKey, err := keyProvider.WrapKey(ctx, key)
+		cv.So(err, cv.ShouldBeNil)
+
+		cv.Convey("Expect the wrapped key is not the key", func() {
+			cv.So(wrappedKey, cv.ShouldNotResemble, key)
+		})
+		cv.Convey("Expect the wrapped key unwraps to the key", func() {
+			unwrappedKey, err := keyProvider.UnwrapKey(ctx, wrappedKey)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(unwrappedKey, cv.ShouldResemble, key)
+		})
+	})
+}
+
+func TestVaultTransitKeyProvider(t *testing.T) {
+	stub := newVaultTransitStub()
+	defer stub.Close()
+	t.Setenv(environment.EnvVarKeyProvider, conf.KeyProviderVault)
+	t.Setenv(environment.EnvVarVaultAddress, stub.URL)
+	t.Setenv(environment.EnvVarVaultTransitKeyName, "keyservice")
+
+	cv.Convey("When wrapping a key with the Vault Transit key provider", t, func() {
+		ctx := context.Background()
+		key, err := cipherutils.GenerateRandomKeyAES()
+		cv.So(err, cv.ShouldBeNil)
+
+		cv.Convey("Expect a valid token can wrap and unwrap the key", func() {
+			t.Setenv(environment.EnvVarVaultToken, stubVaultToken)
+			keyProvider := keyproviders.NewKeyProvider(conf.NewMasterKeyConfImpl(), newHttpClientProvider())
+
+			wrappedKey, err := keyProvider.WrapKey(ctx, key)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(string(wrappedKey), cv.ShouldStartWith, stubCiphertextPrefix)
+
+			unwrappedKey, err := keyProvider.UnwrapKey(ctx, wrappedKey)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(unwrappedKey, cv.ShouldResemble, key)
+		})
+		cv.Convey("Expect an invalid token fails to wrap the key", func() {
+			t.Setenv(environment.EnvVarVaultToken, "wrong-token")
+			keyProvider := keyproviders.NewKeyProvider(conf.NewMasterKeyConfImpl(), newHttpClientProvider())
+
+			_, err := keyProvider.WrapKey(ctx, key)
+			cv.So(err, cv.ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,25 @@
+package keyproviders
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+)
+
+// LocalKeyProviderImpl wraps keys with a master key loaded from a file or the
+// environment
+type LocalKeyProviderImpl struct {
+	masterKey []byte
+}
+
+func (l LocalKeyProviderImpl) WrapKey(_ context.Context, key []byte) ([]byte, error) {
+	return cipherutils.EncryptAES(l.masterKey, key)
+}
+
+func (l LocalKeyProviderImpl) UnwrapKey(_ context.Context, wrappedKey []byte) ([]byte, error) {
+	return cipherutils.DecryptAES(l.masterKey, wrappedKey)
+}
+
+func NewLocalKeyProviderImpl(masterKeyConf conf.MasterKeyConf) *LocalKeyProviderImpl {
+	return &LocalKeyProviderImpl{masterKey: masterKeyConf.GetLocalMasterKey()}
+}
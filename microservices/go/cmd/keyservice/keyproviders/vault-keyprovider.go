@@ -0,0 +1,94 @@
+package keyproviders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/encodingutils"
+	"net/http"
+	"strings"
+)
+
+type vaultTransitEncryptReq struct {
+	Plaintext string `json:"plaintext"`
+}
+
+type vaultTransitDecryptReq struct {
+	Ciphertext string `json:"ciphertext"`
+}
+
+type vaultTransitRes struct {
+	Data struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// VaultTransitKeyProviderImpl wraps keys with the HashiCorp Vault Transit
+// secrets engine over its HTTP API
+type VaultTransitKeyProviderImpl struct {
+	masterKeyConf  conf.MasterKeyConf
+	clientProvider externalservices.HttpClientProvider
+}
+
+func (v VaultTransitKeyProviderImpl) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	reqBody := vaultTransitEncryptReq{Plaintext: encodingutils.EncodeBase64String(key)}
+	res, err := v.post(ctx, "encrypt", reqBody)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(res.Data.Ciphertext), nil
+}
+
+func (v VaultTransitKeyProviderImpl) UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	reqBody := vaultTransitDecryptReq{Ciphertext: string(wrappedKey)}
+	res, err := v.post(ctx, "decrypt", reqBody)
+	if err != nil {
+		return nil, err
+	}
+	return encodingutils.DecodeBase64String(res.Data.Plaintext)
+}
+
+func (v VaultTransitKeyProviderImpl) post(ctx context.Context, operation string, body any) (vaultTransitRes, error) {
+	url := fmt.Sprintf("%v/v1/transit/%v/%v",
+		strings.TrimSuffix(v.masterKeyConf.GetVaultAddress(), "/"),
+		operation,
+		v.masterKeyConf.GetVaultTransitKeyName(),
+	)
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return vaultTransitRes{}, err
+	}
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return vaultTransitRes{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", v.masterKeyConf.GetVaultToken())
+
+	resp, err := v.clientProvider.Client().Do(req)
+	if err != nil {
+		return vaultTransitRes{}, err
+	}
+	defer resp.Body.Close()
+
+	res := vaultTransitRes{}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&res)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return vaultTransitRes{}, fmt.Errorf("vault transit %v failed with status %v: %v",
+			operation, resp.StatusCode, strings.Join(res.Errors, ", "))
+	}
+	return res, decodeErr
+}
+
+func NewVaultTransitKeyProviderImpl(
+	masterKeyConf conf.MasterKeyConf,
+	clientProvider externalservices.HttpClientProvider,
+) *VaultTransitKeyProviderImpl {
+	return &VaultTransitKeyProviderImpl{masterKeyConf: masterKeyConf, clientProvider: clientProvider}
+}
@@ -10,24 +10,34 @@ type KafkaListener interface {
 }
 
 type KafkaListenerImpl struct {
-	userChange1Listener UserChange1Listener
+	userChange1Listener        UserChange1Listener
+	userKeyRotationAckListener UserKeyRotationAckListener
+	userDataExportListener     UserDataExportListener
 }
 
 func (k KafkaListenerImpl) Run() {
 	k.userChange1Listener.ListenUserChange()
+	k.userKeyRotationAckListener.ListenUserKeyRotationAck()
+	k.userDataExportListener.ListenUserDataExport()
 	forever := make(chan any)
 	<-forever
 }
 
 func NewKafkaListenerImpl(
 	userChange1Listener UserChange1Listener,
+	userKeyRotationAckListener UserKeyRotationAckListener,
+	userDataExportListener UserDataExportListener,
 ) *KafkaListenerImpl {
 	if !environment.ActivateKafkaListener() {
 		// Listener is deactivated, ran via the lifecycle package,
 		// and is a root-child dependency so a nil is returned
 		return nil
 	}
-	r := &KafkaListenerImpl{userChange1Listener: userChange1Listener}
+	r := &KafkaListenerImpl{
+		userChange1Listener:        userChange1Listener,
+		userKeyRotationAckListener: userKeyRotationAckListener,
+		userDataExportListener:     userDataExportListener,
+	}
 	lifecycle.RegisterTaskRunner(r)
 	return r
 }
@@ -0,0 +1,71 @@
+package listeners
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+)
+
+type UserDataExportListener interface {
+	lifecycle.Closable
+	ListenUserDataExport()
+}
+
+type UserDataExportListenerImpl struct {
+	userDataExportEventService services.UserDataExportEventService
+	exportConsumer             *kfka.RetryingConsumer[userdtos.UserDataExportRequestDto]
+}
+
+func (k UserDataExportListenerImpl) ListenUserDataExport() {
+	k.exportConsumer.Listen(
+		func(ctx context.Context, dto userdtos.UserDataExportRequestDto) error {
+			err := k.userDataExportEventService.HandleUserDataExportRequest(ctx, dto)
+			if err != nil {
+				logger.Log.WithError(err).Error("Error exporting user data")
+			}
+			return err
+		},
+		func(ctx context.Context, retry kfka.RetryDto[userdtos.UserDataExportRequestDto], cause error) error {
+			return k.userDataExportEventService.HandleUserDataExportRequestFailure(ctx, retry.Value, cause)
+		},
+	)
+	logger.Log.Info("Listening for user data export requests")
+}
+
+func (k UserDataExportListenerImpl) Close() error {
+	logger.Log.Info("Closing user data export listener")
+	err := k.exportConsumer.Close()
+	if err != nil {
+		logger.Log.WithError(err).Error("Errors closing listener")
+	}
+	logger.Log.Info("Finish closing user data export listener")
+	return err
+}
+
+func NewUserDataExportListenerImpl(
+	userDataExportEventService services.UserDataExportEventService,
+	kafkaConf conf.KafkaConf,
+) *UserDataExportListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+
+	exportConsumer := kfka.NewRetryingConsumer(
+		kafkaConf,
+		retrypolicies.NewUserDataExportKeyServicePolicy(),
+		userdtos.UserDataExportRequestDto.MessageKey,
+	)
+
+	return &UserDataExportListenerImpl{
+		userDataExportEventService: userDataExportEventService,
+		exportConsumer:             exportConsumer,
+	}
+}
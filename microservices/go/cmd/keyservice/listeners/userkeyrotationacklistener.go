@@ -0,0 +1,69 @@
+package listeners
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+)
+
+type UserKeyRotationAckListener interface {
+	lifecycle.Closable
+	ListenUserKeyRotationAck()
+}
+
+type UserKeyRotationAckListenerImpl struct {
+	userKeyService services.UserKeyService
+	ackConsumer    *kfka.RetryingConsumer[keydtos.UserKeyRotationAckDto]
+}
+
+func (k UserKeyRotationAckListenerImpl) ListenUserKeyRotationAck() {
+	k.ackConsumer.Listen(
+		func(ctx context.Context, dto keydtos.UserKeyRotationAckDto) error {
+			err := k.userKeyService.HandleKeyRotationAckTxn(ctx, dto)
+			if err != nil {
+				logger.Log.WithError(err).Error("Error handling key rotation acknowledgement")
+			}
+			return err
+		},
+		nil,
+	)
+	logger.Log.Info("Listening for user key rotation acknowledgements")
+}
+
+func (k UserKeyRotationAckListenerImpl) Close() error {
+	logger.Log.Info("Closing user key rotation acknowledgement listener")
+	err := k.ackConsumer.Close()
+	if err != nil {
+		logger.Log.WithError(err).Error("Errors closing listener")
+	}
+	logger.Log.Info("Finish closing user key rotation acknowledgement listener")
+	return err
+}
+
+func NewUserKeyRotationAckListenerImpl(
+	userKeyService services.UserKeyService,
+	kafkaConf conf.KafkaConf,
+) *UserKeyRotationAckListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+
+	ackConsumer := kfka.NewRetryingConsumer(
+		kafkaConf,
+		retrypolicies.NewUserKeyRotationAckKeyServicePolicy(),
+		keydtos.UserKeyRotationAckDto.MessageKey,
+	)
+
+	return &UserKeyRotationAckListenerImpl{
+		userKeyService: userKeyService,
+		ackConsumer:    ackConsumer,
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+// AppSecretHistory contains the previous primary app secrets, ordered from the
+// most to the least recently retired
+type AppSecretHistory struct {
+	Retired []RetiredAppSecret `json:"retired"`
+}
+
+// RetiredAppSecret is a former primary app secret and when it was retired
+type RetiredAppSecret struct {
+	Kid       string `json:"kid"`
+	RetiredAt int64  `json:"retiredAt"`
+}
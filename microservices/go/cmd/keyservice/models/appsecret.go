@@ -3,3 +3,12 @@ package models
 type AppSecret struct {
 	SecretKey []byte `json:"secretKey"`
 }
+
+// WrappedAppSecret is how an AppSecret is stored, with its secret key wrapped
+// by the master key
+type WrappedAppSecret struct {
+	WrappedSecretKey []byte `json:"wrappedSecretKey"`
+	// LegacySecretKey is set on app secrets stored before they were wrapped and
+	// is only read until they expire
+	LegacySecretKey []byte `json:"secretKey,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/kamva/mgm/v3"
+	"time"
+)
+
+// DecoyVaultSeedOutboxEvent holds the encrypted notes of a new decoy vault
+// waiting to be published to the note service. It is written in the same
+// transaction as the decoy vault.
+type DecoyVaultSeedOutboxEvent struct {
+	// DefaultModel adds _id, created_at and updated_at fields to the Model
+	mgm.DefaultModel `bson:",inline"`
+	UserId           string `bson:"userId"`
+	VaultId          string `bson:"vaultId"`
+	KeyVersion       int64  `bson:"keyVersion"`
+	// Notes are removed once the event is sent
+	Notes  []DecoyNoteCipher `bson:"notes"`
+	Sent   bool              `bson:"sent"`
+	SentAt time.Time         `bson:"sentAt"`
+}
+
+// DecoyNoteCipher is a decoy note encrypted by its own data encryption key,
+// which is wrapped by the decoy vault's user key
+type DecoyNoteCipher struct {
+	TitleCipher []byte `bson:"titleCipher"`
+	TextCipher  []byte `bson:"textCipher"`
+	WrappedDek  []byte `bson:"wrappedDek"`
+}
+
+func (d DecoyVaultSeedOutboxEvent) GetIdStr() string {
+	return d.ID.Hex()
+}
+
+func (d DecoyVaultSeedOutboxEvent) IsIdEmpty() bool {
+	return d.ID.IsZero()
+}
+
+func (d *DecoyVaultSeedOutboxEvent) CollectionName() string {
+	return "decoyVaultSeedOutbox"
+}
+
+func (d DecoyVaultSeedOutboxEvent) GetCreatedAt() time.Time {
+	return d.CreatedAt
+}
+
+func (d DecoyVaultSeedOutboxEvent) GetUpdatedAt() time.Time {
+	return d.UpdatedAt
+}
@@ -0,0 +1,9 @@
+package models
+
+// KeySessionElevation marks a key session whose user recently re-entered their
+// passcode, allowing it to perform sensitive operations until it expires
+type KeySessionElevation struct {
+	UserId string `json:"userId"`
+	// ExpiresAt is when the elevation expires in unix milliseconds
+	ExpiresAt int64 `json:"expiresAt"`
+}
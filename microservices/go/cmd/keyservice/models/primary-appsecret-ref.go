@@ -3,4 +3,6 @@ package models
 // PrimaryAppSecretRef contains the kid of the main app secret used to initialize sessions
 type PrimaryAppSecretRef struct {
 	Kid string `json:"kid"`
+	// CreatedAt is when the app secret became primary in unix milliseconds
+	CreatedAt int64 `json:"createdAt"`
 }
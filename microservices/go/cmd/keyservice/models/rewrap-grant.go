@@ -0,0 +1,13 @@
+package models
+
+// RewrapGrant lets a key rotation consumer re-encrypt the data of a vault with
+// the rotated user key without ever receiving the key. The key is encrypted by
+// the grant token, which only the consumer is sent.
+type RewrapGrant struct {
+	UserId     string `json:"userId"`
+	VaultId    string `json:"vaultId"`
+	KeyVersion int64  `json:"keyVersion"`
+	Consumer   string `json:"consumer"`
+	TokenHash  []byte `json:"tokenHash"`
+	KeyCipher  []byte `json:"keyCipher"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// SrpChallenge is the server state between the two rounds of an SRP unlock
+type SrpChallenge struct {
+	UserId  string `json:"userId"`
+	VaultId string `json:"vaultId"`
+	// PrivateValue is the server's ephemeral SRP private value. It is only
+	// valid for a single unlock attempt.
+	PrivateValue []byte `json:"privateValue"`
+}
+
+// SrpUnlock is the server state between a verified SRP proof and the key
+// session it creates
+type SrpUnlock struct {
+	UserId  string `json:"userId"`
+	VaultId string `json:"vaultId"`
+	// SessionKey is the key both parties derived from the exchange, which the
+	// client encrypts the unwrapped user key with
+	SessionKey []byte `json:"sessionKey"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"github.com/kamva/mgm/v3"
+	"time"
+)
+
+// UserKeyRotationOutboxEvent is a user key rotation waiting to be published. It
+// is written in the same transaction as the rotation so consumers are only told
+// about rotations that were saved.
+type UserKeyRotationOutboxEvent struct {
+	// DefaultModel adds _id, created_at and updated_at fields to the Model
+	mgm.DefaultModel   `bson:",inline"`
+	UserId             string `bson:"userId"`
+	VaultId            string `bson:"vaultId"`
+	KeyVersion         int64  `bson:"keyVersion"`
+	PreviousKeyVersion int64  `bson:"previousKeyVersion"`
+	// WrappedKey is the rotated user key wrapped by the master key so rewrap
+	// grants can be issued for it. It is removed once the event is sent.
+	WrappedKey []byte    `bson:"wrappedKey"`
+	Sent       bool      `bson:"sent"`
+	SentAt     time.Time `bson:"sentAt"`
+}
+
+func (u UserKeyRotationOutboxEvent) GetIdStr() string {
+	return u.ID.Hex()
+}
+
+func (u UserKeyRotationOutboxEvent) IsIdEmpty() bool {
+	return u.ID.IsZero()
+}
+
+func (u *UserKeyRotationOutboxEvent) CollectionName() string {
+	return "userKeyRotationOutbox"
+}
+
+func (u UserKeyRotationOutboxEvent) GetCreatedAt() time.Time {
+	return u.CreatedAt
+}
+
+func (u UserKeyRotationOutboxEvent) GetUpdatedAt() time.Time {
+	return u.UpdatedAt
+}
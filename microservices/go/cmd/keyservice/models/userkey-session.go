@@ -7,4 +7,13 @@ type UserKeySession struct {
 	KeyVersionCipher []byte `json:"keyVersionCipher"`
 	AppSecretKid     string `json:"appSecretKid"`
 	TokenHash        []byte `json:"tokenHash"`
+	// VaultIdCipher is empty for sessions of the default vault created before
+	// users could have more than one
+	VaultIdCipher []byte `json:"vaultIdCipher"`
+	// CreatedAt is when the session was created in unix milliseconds
+	CreatedAt int64 `json:"createdAt"`
+	// DeviceIdHash and IpNetworkHash are salted hashes of the client the
+	// session was created for. They are empty if the session is not bound.
+	DeviceIdHash  []byte `json:"deviceIdHash"`
+	IpNetworkHash []byte `json:"ipNetworkHash"`
 }
@@ -2,15 +2,140 @@ package models
 
 import (
 	"github.com/kamva/mgm/v3"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
 	"time"
 )
 
 type UserKeyGenerator struct {
 	mgm.DefaultModel  `bson:",inline"`
-	UserId            string `bson:"userId"`
-	KeyDerivationSalt []byte `bson:"keyDerivationSalt"`
-	KeyHash           []byte `bson:"keyHash"`
-	KeyVersion        int64
+	UserId            string                `bson:"userId"`
+	VaultId           string                `bson:"vaultId"`
+	VaultName         string                `bson:"vaultName"`
+	KeyDerivationSalt []byte                `bson:"keyDerivationSalt"`
+	KdfParams         cipherutils.KDFParams `bson:"kdfParams"`
+	// WrappedKey is the user key encrypted with the key derived from the
+	// passcode. It is empty for legacy generators where the derived key is the
+	// user key itself.
+	WrappedKey []byte `bson:"wrappedKey"`
+	// KeyHash is a hash of the passcode key. It is empty for generators created
+	// with SRP, as their passcode key is only known by the client.
+	KeyHash []byte `bson:"keyHash"`
+	// UserKeyHash is a hash of the user key, which verifies the key a client
+	// unwraps in an SRP unlock
+	UserKeyHash []byte `bson:"userKeyHash"`
+	KeyVersion  int64
+	// PreviousKeys are the keys of versions that are being rotated out, each
+	// wrapped by the current user key
+	PreviousKeys []PreviousUserKey `bson:"previousKeys"`
+	// RotationAcks are the consumers that finished re-encrypting data with the
+	// current key version
+	RotationAcks []string `bson:"rotationAcks"`
+	// RotationExpiresAt is when the rewrap grants of the current rotation expire
+	// in unix milliseconds. A rotation that is not acknowledged by every
+	// consumer by then can be superseded.
+	RotationExpiresAt int64 `bson:"rotationExpiresAt"`
+	// SrpSalt and SrpVerifier let a client prove it knows the passcode key
+	// without sending it. They are empty if the user never enrolled in SRP.
+	SrpSalt     []byte `bson:"srpSalt"`
+	SrpVerifier []byte `bson:"srpVerifier"`
+	// Totp is the user's TOTP second factor
+	Totp UserKeyTotp `bson:"totp"`
+	// Duress links the generator to the decoy vault its duress passcode unlocks
+	Duress UserKeyDuress `bson:"duress"`
+	// DecoyOfVaultId is the vault whose duress passcode unlocks this decoy
+	// vault. Decoy vaults are hidden from the user's vaults.
+	DecoyOfVaultId string `bson:"decoyOfVaultId"`
+	// SessionsRevokedAt is when key sessions of the vault were revoked in unix
+	// milliseconds. Sessions created earlier are invalid.
+	SessionsRevokedAt int64 `bson:"sessionsRevokedAt"`
+}
+
+type UserKeyDuress struct {
+	DecoyVaultId string `bson:"decoyVaultId"`
+	SilentAction string `bson:"silentAction"`
+}
+
+type UserKeyTotp struct {
+	// WrappedSecret is the TOTP secret wrapped by the master key
+	WrappedSecret []byte `bson:"wrappedSecret"`
+	// Confirmed is true once the user proved their authenticator app generates
+	// codes for the secret. Unconfirmed secrets are not required on unlock.
+	Confirmed bool `bson:"confirmed"`
+	// LastUsedStep is the most recent time step a code was accepted for, so a
+	// code cannot be replayed
+	LastUsedStep int64 `bson:"lastUsedStep"`
+	// BackupCodeHashes are salted hashes of the unused backup codes
+	BackupCodeHashes [][]byte `bson:"backupCodeHashes"`
+}
+
+type PreviousUserKey struct {
+	KeyVersion int64  `bson:"keyVersion"`
+	WrappedKey []byte `bson:"wrappedKey"`
+}
+
+// GetVaultId returns the ID of the vault the generator unlocks, as each of a
+// user's vaults has its own passcode and user key. Generators created before
+// users could have more than one vault unlock the default vault.
+func (k UserKeyGenerator) GetVaultId() string {
+	return commondtos.VaultIdOrDefault(k.VaultId)
+}
+
+// IsRotating returns true if previous key versions have yet to be retired
+func (k UserKeyGenerator) IsRotating() bool {
+	return len(k.PreviousKeys) > 0
+}
+
+// IsRotationExpired returns true if the rewrap grants of an unfinished
+// rotation have expired, so consumers can no longer finish it
+func (k UserKeyGenerator) IsRotationExpired(now time.Time) bool {
+	return k.IsRotating() && now.UnixMilli() >= k.RotationExpiresAt
+}
+
+// GetKdfParams returns the parameters the passcode key was derived with,
+// defaulting to the legacy parameters for generators created before they were
+// stored.
+func (k UserKeyGenerator) GetKdfParams() cipherutils.KDFParams {
+	if k.KdfParams.IsEmpty() {
+		return cipherutils.LegacyKDFParams()
+	}
+	return k.KdfParams
+}
+
+// IsSrpEnabled returns true if the passcode can be verified with SRP
+func (k UserKeyGenerator) IsSrpEnabled() bool {
+	return len(k.SrpVerifier) > 0
+}
+
+// IsTotpEnabled returns true if unlocking requires a TOTP code
+func (k UserKeyGenerator) IsTotpEnabled() bool {
+	return k.Totp.Confirmed && len(k.Totp.WrappedSecret) > 0
+}
+
+// HasDuress returns true if a duress passcode unlocks a decoy vault in place of
+// the generator's vault
+func (k UserKeyGenerator) HasDuress() bool {
+	return k.Duress.DecoyVaultId != ""
+}
+
+// IsDecoy returns true if the generator is of a decoy vault
+func (k UserKeyGenerator) IsDecoy() bool {
+	return k.DecoyOfVaultId != ""
+}
+
+// HasKeyHash returns true if the passcode key can be verified by its hash
+func (k UserKeyGenerator) HasKeyHash() bool {
+	return len(k.KeyHash) > 0
+}
+
+// HasUserKeyHash returns true if the user key can be verified by its hash
+func (k UserKeyGenerator) HasUserKeyHash() bool {
+	return len(k.UserKeyHash) > 0
+}
+
+// IsKeyWrapped returns true if the user key is wrapped by the passcode key
+func (k UserKeyGenerator) IsKeyWrapped() bool {
+	return len(k.WrappedKey) > 0
 }
 
 func (k UserKeyGenerator) GetIdStr() string {
@@ -0,0 +1,78 @@
+package passcodepolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// BreachedList is a set of passcodes exposed by data breaches. Only SHA-1
+// hashes are kept in memory.
+type BreachedList struct {
+	hashes map[string]struct{}
+}
+
+// NewBreachedList creates a breached list of plain passcodes
+func NewBreachedList(passcodes ...string) BreachedList {
+	breachedList := BreachedList{hashes: make(map[string]struct{}, len(passcodes))}
+	for _, passcode := range passcodes {
+		breachedList.hashes[breachedHash(passcode)] = struct{}{}
+	}
+	return breachedList
+}
+
+// LoadBreachedList reads a breached list file with one entry per line. An entry
+// is either a plain passcode or the hex SHA-1 hash of one, optionally followed
+// by a colon and count as in the Pwned Passwords downloads. Blank lines and
+// lines starting with # are ignored.
+func LoadBreachedList(path string) (BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return BreachedList{}, err
+	}
+	defer file.Close()
+
+	breachedList := NewBreachedList()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if hash, ok := parseSHA1Entry(line); ok {
+			breachedList.hashes[hash] = struct{}{}
+		} else {
+			breachedList.hashes[breachedHash(line)] = struct{}{}
+		}
+	}
+	return breachedList, scanner.Err()
+}
+
+// Contains returns true if the passcode is in the list
+func (b BreachedList) Contains(passcode string) bool {
+	_, ok := b.hashes[breachedHash(passcode)]
+	return ok
+}
+
+// Len returns the number of entries in the list
+func (b BreachedList) Len() int {
+	return len(b.hashes)
+}
+
+func breachedHash(passcode string) string {
+	sum := sha1.Sum([]byte(passcode))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func parseSHA1Entry(line string) (string, bool) {
+	hash, _, _ := strings.Cut(line, ":")
+	if len(hash) != sha1.Size*2 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", false
+	}
+	return strings.ToUpper(hash), true
+}
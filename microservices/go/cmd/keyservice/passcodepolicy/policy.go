@@ -0,0 +1,120 @@
+package passcodepolicy
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules a passcode can fail, reported with each violation
+const (
+	RuleMinLength = "minLength"
+	RuleMaxLength = "maxLength"
+	RuleLowercase = "lowercase"
+	RuleUppercase = "uppercase"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleStrength  = "strength"
+	RuleBreached  = "breached"
+)
+
+// Policy is the set of rules a new passcode must satisfy
+type Policy struct {
+	MinLength        int
+	MaxLength        int
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// MinStrengthScore is the lowest StrengthScore accepted, from 0 to 4
+	MinStrengthScore int
+}
+
+// Violation is a rule a passcode failed
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// Check returns the rules the passcode violates. Passcodes in the breached list
+// are rejected regardless of their strength.
+func (p Policy) Check(passcode string, breachedList BreachedList) []Violation {
+	var violations []Violation
+	length := utf8.RuneCountInString(passcode)
+	if length < p.MinLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("Must be at least %v characters", p.MinLength),
+		})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("Must be at most %v characters", p.MaxLength),
+		})
+	}
+
+	classes := charClassesOf(passcode)
+	if p.RequireLowercase && !classes.lower {
+		violations = append(violations, Violation{Rule: RuleLowercase, Message: "Must contain a lowercase letter"})
+	}
+	if p.RequireUppercase && !classes.upper {
+		violations = append(violations, Violation{Rule: RuleUppercase, Message: "Must contain an uppercase letter"})
+	}
+	if p.RequireDigit && !classes.digit {
+		violations = append(violations, Violation{Rule: RuleDigit, Message: "Must contain a digit"})
+	}
+	if p.RequireSymbol && !classes.symbol {
+		violations = append(violations, Violation{Rule: RuleSymbol, Message: "Must contain a symbol or space"})
+	}
+
+	if strength := EstimateStrength(passcode); strength.Score < p.MinStrengthScore {
+		violations = append(violations, Violation{
+			Rule: RuleStrength,
+			Message: fmt.Sprintf(
+				"Too easy to guess, scoring %v of the required %v. Use a longer passcode or avoid "+
+					"repeated characters, sequences and keyboard patterns",
+				strength.Score,
+				p.MinStrengthScore,
+			),
+		})
+	}
+	if breachedList.Contains(passcode) {
+		violations = append(violations, Violation{
+			Rule:    RuleBreached,
+			Message: "Appears in a list of passcodes exposed by data breaches",
+		})
+	}
+	return violations
+}
+
+type charClasses struct {
+	lower  bool
+	upper  bool
+	digit  bool
+	symbol bool
+	other  bool
+}
+
+func charClassesOf(passcode string) charClasses {
+	var classes charClasses
+	for _, r := range passcode {
+		switch {
+		case r >= 'a' && r <= 'z':
+			classes.lower = true
+		case r >= 'A' && r <= 'Z':
+			classes.upper = true
+		case r >= '0' && r <= '9':
+			classes.digit = true
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			classes.symbol = true
+		case unicode.IsLower(r):
+			classes.lower, classes.other = true, true
+		case unicode.IsUpper(r):
+			classes.upper, classes.other = true, true
+		default:
+			classes.other = true
+		}
+	}
+	return classes
+}
@@ -0,0 +1,94 @@
+package passcodepolicy_test
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/passcodepolicy"
+	cv "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func violatedRules(violations []passcodepolicy.Violation) []string {
+	rules := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestEstimateStrength(t *testing.T) {
+	cv.Convey("When estimating passcode strength", t, func() {
+		cv.Convey("Expect short numeric sequences to be trivial", func() {
+			cv.So(passcodepolicy.EstimateStrength("1234").Score, cv.ShouldEqual, 0)
+		})
+
+		cv.Convey("Expect repeats and keyboard rows to score lower than random characters of the same length", func() {
+			patterned := passcodepolicy.EstimateStrength("qwertyuiop")
+			random := passcodepolicy.EstimateStrength("qpwmvzkrtx")
+			cv.So(patterned.EntropyBits, cv.ShouldBeLessThan, random.EntropyBits)
+			cv.So(passcodepolicy.EstimateStrength("aaaaaaaaaa").Score, cv.ShouldEqual, 0)
+		})
+
+		cv.Convey("Expect a long passphrase with spaces to be very strong", func() {
+			cv.So(passcodepolicy.EstimateStrength("correct horse battery staple").Score, cv.ShouldEqual, 4)
+		})
+	})
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := passcodepolicy.Policy{
+		MinLength:        8,
+		MaxLength:        64,
+		RequireUppercase: true,
+		RequireDigit:     true,
+		MinStrengthScore: 2,
+	}
+	breachedList := passcodepolicy.NewBreachedList("Password123")
+
+	cv.Convey("When checking passcodes against a policy", t, func() {
+		cv.Convey("Expect every failed rule to be reported", func() {
+			rules := violatedRules(policy.Check("abc", breachedList))
+			cv.So(rules, cv.ShouldContain, passcodepolicy.RuleMinLength)
+			cv.So(rules, cv.ShouldContain, passcodepolicy.RuleUppercase)
+			cv.So(rules, cv.ShouldContain, passcodepolicy.RuleDigit)
+			cv.So(rules, cv.ShouldContain, passcodepolicy.RuleStrength)
+		})
+
+		cv.Convey("Expect a breached passcode to be rejected even if it satisfies the other rules", func() {
+			cv.So(violatedRules(policy.Check("Password123", breachedList)), cv.ShouldResemble,
+				[]string{passcodepolicy.RuleBreached})
+		})
+
+		cv.Convey("Expect a strong passcode satisfying every rule to pass", func() {
+			cv.So(policy.Check("Vivid Lantern 42 Orbit", breachedList), cv.ShouldBeEmpty)
+		})
+
+		cv.Convey("Expect control characters not to count as symbols", func() {
+			symbolPolicy := passcodepolicy.Policy{RequireSymbol: true}
+			cv.So(violatedRules(symbolPolicy.Check("abc\x01\t\x7f", breachedList)), cv.ShouldBeEmpty)
+			cv.So(violatedRules(symbolPolicy.Check("abc\x01\x7f", breachedList)), cv.ShouldResemble,
+				[]string{passcodepolicy.RuleSymbol})
+		})
+	})
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	cv.Convey("When loading a breached list file", t, func() {
+		path := filepath.Join(t.TempDir(), "breached.txt")
+		contents := "# comment\n" +
+			"letmein\n" +
+			// SHA-1 of "trustno1" with a Pwned Passwords count
+			"E68E11BE8B70E435C65AEF8BA9798FF7775C361E:3\n"
+		cv.So(os.WriteFile(path, []byte(contents), 0600), cv.ShouldBeNil)
+
+		breachedList, err := passcodepolicy.LoadBreachedList(path)
+		cv.So(err, cv.ShouldBeNil)
+
+		cv.Convey("Expect plain and hashed entries to be found", func() {
+			cv.So(breachedList.Len(), cv.ShouldEqual, 2)
+			cv.So(breachedList.Contains("letmein"), cv.ShouldBeTrue)
+			cv.So(breachedList.Contains("trustno1"), cv.ShouldBeTrue)
+			cv.So(breachedList.Contains("something else"), cv.ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package passcodepolicy
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Strength is an estimate of how hard a passcode is to guess
+type Strength struct {
+	// EntropyBits is the estimated entropy after discounting patterns
+	EntropyBits float64
+	// Score buckets the entropy from 0, trivially guessable, to 4, very strong
+	Score int
+}
+
+// Characters that follow a repeat, sequence or keyboard neighbour add only this
+// many bits, as guessers try those patterns first
+const patternCharBits = 1.0
+
+// Character pool sizes of each character class
+const (
+	lowerPoolSize  = 26
+	upperPoolSize  = 26
+	digitPoolSize  = 10
+	symbolPoolSize = 33
+	otherPoolSize  = 100
+)
+
+// Entropy thresholds of scores 1 to 4
+var scoreThresholds = []float64{28, 36, 60, 80}
+
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+// EstimateStrength estimates the entropy of a passcode from the size of its
+// character pool, discounting characters that repeat the previous character,
+// continue a sequence such as "abc" or "321", or neighbour the previous
+// character on a keyboard row
+func EstimateStrength(passcode string) Strength {
+	runes := []rune(passcode)
+	if len(runes) == 0 {
+		return Strength{}
+	}
+	bitsPerChar := math.Log2(float64(poolSize(charClassesOf(passcode))))
+
+	entropyBits := bitsPerChar
+	for i := 1; i < len(runes); i++ {
+		if isPatternPair(runes[i-1], runes[i]) {
+			entropyBits += patternCharBits
+		} else {
+			entropyBits += bitsPerChar
+		}
+	}
+
+	score := 0
+	for _, threshold := range scoreThresholds {
+		if entropyBits >= threshold {
+			score++
+		}
+	}
+	return Strength{EntropyBits: entropyBits, Score: score}
+}
+
+func poolSize(classes charClasses) int {
+	size := 0
+	if classes.lower {
+		size += lowerPoolSize
+	}
+	if classes.upper {
+		size += upperPoolSize
+	}
+	if classes.digit {
+		size += digitPoolSize
+	}
+	if classes.symbol {
+		size += symbolPoolSize
+	}
+	if classes.other {
+		size += otherPoolSize
+	}
+	return size
+}
+
+func isPatternPair(previous, current rune) bool {
+	previous, current = unicode.ToLower(previous), unicode.ToLower(current)
+	if previous == current {
+		return true
+	}
+	if diff := current - previous; (diff == 1 || diff == -1) && isSequenceRune(previous) && isSequenceRune(current) {
+		return true
+	}
+	for _, row := range keyboardRows {
+		if i := strings.IndexRune(row, previous); i >= 0 {
+			j := strings.IndexRune(row, current)
+			if j >= 0 && (j-i == 1 || i-j == 1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isSequenceRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
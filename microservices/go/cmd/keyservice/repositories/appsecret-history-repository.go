@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/kvstoreutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+)
+
+type AppSecretHistoryRepository interface {
+	Get(ctx context.Context) (option.Maybe[models.AppSecretHistory], error)
+	Set(ctx context.Context, value models.AppSecretHistory) (models.AppSecretHistory, error)
+}
+
+type AppSecretHistoryRepositoryImpl struct {
+	key      string
+	baseRepo baserepos.KeyValueTimedRepository[models.AppSecretHistory]
+}
+
+func (a AppSecretHistoryRepositoryImpl) Get(ctx context.Context) (option.Maybe[models.AppSecretHistory], error) {
+	return a.baseRepo.Get(ctx, a.key)
+}
+
+func (a AppSecretHistoryRepositoryImpl) Set(
+	ctx context.Context,
+	value models.AppSecretHistory,
+) (models.AppSecretHistory, error) {
+	// The history does not expire as it is trimmed on every rotation
+	return a.baseRepo.Set(ctx, a.key, value, 0)
+}
+
+func NewAppSecretHistoryRepositoryImpl(
+	redisDBHandler *dshandlers.RedisDBHandler,
+) *AppSecretHistoryRepositoryImpl {
+	key := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "appSecretHistory")
+	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.AppSecretHistory](redisDBHandler)
+	return &AppSecretHistoryRepositoryImpl{key: key, baseRepo: baseRepo}
+}
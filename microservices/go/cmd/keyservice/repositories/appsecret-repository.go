@@ -3,26 +3,57 @@ package repositories
 import (
 	"context"
 	"github.com/akrennmair/slice"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/keyproviders"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/kvstoreutils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"sync"
 	"time"
 )
 
+// appSecretCacheDuration is the longest an unwrapped app secret is held in
+// memory before it is unwrapped by the KeyProvider again
+const appSecretCacheDuration = 5 * time.Minute
+
 type AppSecretRepository interface {
 	baserepos.KeyValueTimedRepository[models.AppSecret]
+	// DelAllExcept deletes every stored value other than the ones under the kept
+	// keys
+	DelAllExcept(ctx context.Context, keptKeys ...string) error
+	// ExtendExpiration ensures the app secret under the key expires no sooner
+	// than minExpiration from now. Missing keys are ignored.
+	ExtendExpiration(ctx context.Context, key string, minExpiration time.Duration) error
 }
 
+// AppSecretRepositoryImpl stores app secrets wrapped by the KeyProvider and
+// caches unwrapped app secrets in memory by their wrapped secret key. App
+// secrets are always read from the store, so app secrets deleted by any
+// replica, such as by an emergency rotation, are never served from the cache.
 type AppSecretRepositoryImpl struct {
-	prefix   string
-	baseRepo baserepos.KeyValueTimedRepository[models.AppSecret]
+	prefix         string
+	baseRepo       baserepos.KeyValueTimedRepository[models.WrappedAppSecret]
+	redisDBHandler *dshandlers.RedisDBHandler
+	keyProvider    keyproviders.KeyProvider
+	cache          *appSecretCache
 }
 
 func (a AppSecretRepositoryImpl) Get(ctx context.Context, key string) (option.Maybe[models.AppSecret], error) {
-	return a.baseRepo.Get(ctx, kvstoreutils.CombineKeySections(a.prefix, key))
+	wrappedFind, err := a.baseRepo.Get(ctx, kvstoreutils.CombineKeySections(a.prefix, key))
+	if err != nil {
+		return option.None[models.AppSecret](), err
+	}
+	wrapped, ok := wrappedFind.Get()
+	if !ok {
+		return option.None[models.AppSecret](), nil
+	}
+	appSecret, err := a.unwrap(ctx, wrapped)
+	if err != nil {
+		return option.None[models.AppSecret](), err
+	}
+	return option.Perhaps(appSecret), nil
 }
 
 func (a AppSecretRepositoryImpl) Set(
@@ -31,7 +62,16 @@ func (a AppSecretRepositoryImpl) Set(
 	value models.AppSecret,
 	expiration time.Duration,
 ) (models.AppSecret, error) {
-	return a.baseRepo.Set(ctx, kvstoreutils.CombineKeySections(a.prefix, key), value, expiration)
+	wrappedSecretKey, err := a.keyProvider.WrapKey(ctx, value.SecretKey)
+	if err != nil {
+		return value, err
+	}
+	wrapped := models.WrappedAppSecret{WrappedSecretKey: wrappedSecretKey}
+	if _, err := a.baseRepo.Set(ctx, kvstoreutils.CombineKeySections(a.prefix, key), wrapped, expiration); err != nil {
+		return value, err
+	}
+	a.cache.set(string(wrappedSecretKey), value)
+	return value, nil
 }
 
 func (a AppSecretRepositoryImpl) Del(ctx context.Context, keys ...string) error {
@@ -42,8 +82,99 @@ func (a AppSecretRepositoryImpl) Del(ctx context.Context, keys ...string) error
 	return a.baseRepo.Del(ctx, combinedKeys...)
 }
 
-func NewAppSecretRepositoryImpl(redisDBHandler *dshandlers.RedisDBHandler) *AppSecretRepositoryImpl {
+func (a AppSecretRepositoryImpl) DelAllExcept(ctx context.Context, keptKeys ...string) error {
+	combinedKeptKeys := slice.Map(keptKeys, func(key string) string {
+		return kvstoreutils.CombineKeySections(a.prefix, key)
+	})
+	return a.redisDBHandler.ScanAndDelExcept(ctx, kvstoreutils.CombineKeySections(a.prefix, "*"), combinedKeptKeys...)
+}
+
+func (a AppSecretRepositoryImpl) ExtendExpiration(
+	ctx context.Context,
+	key string,
+	minExpiration time.Duration,
+) error {
+	redisClient := a.redisDBHandler.GetRedisClient()
+	combinedKey := kvstoreutils.CombineKeySections(a.prefix, key)
+	ttl, err := redisClient.TTL(ctx, combinedKey).Result()
+	if err != nil {
+		return err
+	}
+	// A negative TTL means the key is missing or never expires
+	if ttl < 0 || ttl >= minExpiration {
+		return nil
+	}
+	return redisClient.Expire(ctx, combinedKey, minExpiration).Err()
+}
+
+func (a AppSecretRepositoryImpl) unwrap(
+	ctx context.Context,
+	wrapped models.WrappedAppSecret,
+) (models.AppSecret, error) {
+	if len(wrapped.WrappedSecretKey) == 0 {
+		return models.AppSecret{SecretKey: wrapped.LegacySecretKey}, nil
+	}
+	if appSecret, ok := a.cache.get(string(wrapped.WrappedSecretKey)); ok {
+		return appSecret, nil
+	}
+	secretKey, err := a.keyProvider.UnwrapKey(ctx, wrapped.WrappedSecretKey)
+	if err != nil {
+		return models.AppSecret{}, err
+	}
+	appSecret := models.AppSecret{SecretKey: secretKey}
+	a.cache.set(string(wrapped.WrappedSecretKey), appSecret)
+	return appSecret, nil
+}
+
+func NewAppSecretRepositoryImpl(
+	redisDBHandler *dshandlers.RedisDBHandler,
+	keyProvider keyproviders.KeyProvider,
+) *AppSecretRepositoryImpl {
 	prefix := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "appSecret")
-	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.AppSecret](redisDBHandler)
-	return &AppSecretRepositoryImpl{prefix: prefix, baseRepo: baseRepo}
+	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.WrappedAppSecret](redisDBHandler)
+	return &AppSecretRepositoryImpl{
+		prefix:         prefix,
+		baseRepo:       baseRepo,
+		redisDBHandler: redisDBHandler,
+		keyProvider:    keyProvider,
+		cache:          newAppSecretCache(),
+	}
+}
+
+type appSecretCacheEntry struct {
+	appSecret models.AppSecret
+	expiresAt time.Time
+}
+
+// appSecretCache holds unwrapped app secrets in memory for a limited time,
+// keyed by their wrapped secret key
+type appSecretCache struct {
+	mu      sync.RWMutex
+	entries map[string]appSecretCacheEntry
+}
+
+func (c *appSecretCache) get(key string) (models.AppSecret, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return models.AppSecret{}, false
+	}
+	return entry.appSecret, true
+}
+
+func (c *appSecretCache) set(key string, appSecret models.AppSecret) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = appSecretCacheEntry{appSecret: appSecret, expiresAt: now.Add(appSecretCacheDuration)}
+}
+
+func newAppSecretCache() *appSecretCache {
+	return &appSecretCache{entries: make(map[string]appSecretCacheEntry)}
 }
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/operator"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type DecoyVaultSeedOutboxRepository interface {
+	Create(ctx context.Context, model models.DecoyVaultSeedOutboxEvent) (models.DecoyVaultSeedOutboxEvent, error)
+
+	// FindUnsent returns up to limit unsent events in the order they were
+	// written
+	FindUnsent(ctx context.Context, limit int64) ([]models.DecoyVaultSeedOutboxEvent, error)
+
+	// MarkSent marks an event as sent and removes its notes
+	MarkSent(ctx context.Context, id string, sentAt time.Time) error
+}
+
+type DecoyVaultSeedOutboxRepositoryImpl struct {
+	baserepos.BaseRepositoryMongo[models.DecoyVaultSeedOutboxEvent]
+}
+
+func (d DecoyVaultSeedOutboxRepositoryImpl) Create(
+	ctx context.Context,
+	model models.DecoyVaultSeedOutboxEvent,
+) (models.DecoyVaultSeedOutboxEvent, error) {
+	err := mgm.Coll(d.ModelColl).CreateWithCtx(d.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (d DecoyVaultSeedOutboxRepositoryImpl) FindUnsent(
+	ctx context.Context,
+	limit int64,
+) ([]models.DecoyVaultSeedOutboxEvent, error) {
+	findOpts := options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(limit)
+	childCtx := d.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(d.ModelColl).Find(childCtx, bson.M{"sent": false}, findOpts)
+	return mgmtools.HandleFindManyRes[models.DecoyVaultSeedOutboxEvent](childCtx, cursor, err)
+}
+
+func (d DecoyVaultSeedOutboxRepositoryImpl) MarkSent(ctx context.Context, id string, sentAt time.Time) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = mgm.Coll(d.ModelColl).UpdateByID(
+		d.MongoDBHandler.ToChildCtx(ctx),
+		objectId,
+		bson.M{
+			operator.Set:   bson.M{"sent": true, "sentAt": sentAt},
+			operator.Unset: bson.M{"notes": ""},
+		},
+	)
+	return err
+}
+
+func NewDecoyVaultSeedOutboxRepositoryImpl(
+	mongoDBHandler *dshandlers.MongoDBHandler,
+) *DecoyVaultSeedOutboxRepositoryImpl {
+	return &DecoyVaultSeedOutboxRepositoryImpl{
+		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.DecoyVaultSeedOutboxEvent](
+			models.DecoyVaultSeedOutboxEvent{},
+			mongoDBHandler,
+		),
+	}
+}
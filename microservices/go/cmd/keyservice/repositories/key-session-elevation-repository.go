@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"github.com/akrennmair/slice"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/kvstoreutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"time"
+)
+
+// KeySessionElevationRepository stores session elevations by the proxy KID of
+// the elevated key session
+type KeySessionElevationRepository interface {
+	baserepos.KeyValueTimedRepository[models.KeySessionElevation]
+}
+
+type KeySessionElevationRepositoryImpl struct {
+	prefix   string
+	baseRepo baserepos.KeyValueTimedRepository[models.KeySessionElevation]
+}
+
+func (k KeySessionElevationRepositoryImpl) Get(
+	ctx context.Context,
+	key string,
+) (option.Maybe[models.KeySessionElevation], error) {
+	return k.baseRepo.Get(ctx, kvstoreutils.CombineKeySections(k.prefix, key))
+}
+
+func (k KeySessionElevationRepositoryImpl) Set(
+	ctx context.Context,
+	key string,
+	value models.KeySessionElevation,
+	expiration time.Duration,
+) (models.KeySessionElevation, error) {
+	return k.baseRepo.Set(ctx, kvstoreutils.CombineKeySections(k.prefix, key), value, expiration)
+}
+
+func (k KeySessionElevationRepositoryImpl) Del(ctx context.Context, keys ...string) error {
+	nonEmptyKeys := slice.Filter(keys, utils.StringIsNotBlank)
+	combinedKeys := slice.Map(nonEmptyKeys, func(key string) string {
+		return kvstoreutils.CombineKeySections(k.prefix, key)
+	})
+	return k.baseRepo.Del(ctx, combinedKeys...)
+}
+
+func NewKeySessionElevationRepositoryImpl(redisDBHandler *dshandlers.RedisDBHandler) *KeySessionElevationRepositoryImpl {
+	prefix := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "keySessionElevation")
+	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.KeySessionElevation](redisDBHandler)
+	return &KeySessionElevationRepositoryImpl{prefix: prefix, baseRepo: baseRepo}
+}
@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v9"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
@@ -13,11 +15,33 @@ import (
 type PrimaryAppSecretRefRepository interface {
 	Get(ctx context.Context) (option.Maybe[models.PrimaryAppSecretRef], error)
 	Set(ctx context.Context, value models.PrimaryAppSecretRef, expr time.Duration) (models.PrimaryAppSecretRef, error)
+	// SetIfKidMatches atomically sets the ref only if it is missing or the
+	// stored ref has the expected kid. The ref stored afterwards is returned
+	// alongside whether the value provided was set.
+	SetIfKidMatches(
+		ctx context.Context,
+		expectedKid string,
+		value models.PrimaryAppSecretRef,
+		expr time.Duration,
+	) (stored models.PrimaryAppSecretRef, isSet bool, err error)
 }
 
+// setIfKidMatchesScript sets KEYS[1] to ARGV[2] with an expiration of ARGV[3]
+// milliseconds if KEYS[1] is missing or its kid equals ARGV[1], then returns
+// the stored value
+var setIfKidMatchesScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current or cjson.decode(current).kid == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return ARGV[2]
+end
+return current
+`)
+
 type PrimaryAppSecretRefRepositoryImpl struct {
-	key      string
-	baseRepo baserepos.KeyValueTimedRepository[models.PrimaryAppSecretRef]
+	key            string
+	baseRepo       baserepos.KeyValueTimedRepository[models.PrimaryAppSecretRef]
+	redisDBHandler *dshandlers.RedisDBHandler
 }
 
 func (a PrimaryAppSecretRefRepositoryImpl) Get(ctx context.Context) (option.Maybe[models.PrimaryAppSecretRef], error) {
@@ -32,10 +56,38 @@ func (a PrimaryAppSecretRefRepositoryImpl) Set(
 	return a.baseRepo.Set(ctx, a.key, value, expiration)
 }
 
+func (a PrimaryAppSecretRefRepositoryImpl) SetIfKidMatches(
+	ctx context.Context,
+	expectedKid string,
+	value models.PrimaryAppSecretRef,
+	expiration time.Duration,
+) (models.PrimaryAppSecretRef, bool, error) {
+	valJson, err := json.Marshal(value)
+	if err != nil {
+		return models.PrimaryAppSecretRef{}, false, err
+	}
+	storedJson, err := setIfKidMatchesScript.Run(
+		ctx,
+		a.redisDBHandler.GetRedisClient(),
+		[]string{a.key},
+		expectedKid,
+		string(valJson),
+		expiration.Milliseconds(),
+	).Text()
+	if err != nil {
+		return models.PrimaryAppSecretRef{}, false, err
+	}
+	var stored models.PrimaryAppSecretRef
+	if err := json.Unmarshal([]byte(storedJson), &stored); err != nil {
+		return models.PrimaryAppSecretRef{}, false, err
+	}
+	return stored, stored.Kid == value.Kid, nil
+}
+
 func NewPrimaryAppSecretRefRepositoryImpl(
 	redisDBHandler *dshandlers.RedisDBHandler,
 ) *PrimaryAppSecretRefRepositoryImpl {
 	key := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "mainAppSecretRef")
 	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.PrimaryAppSecretRef](redisDBHandler)
-	return &PrimaryAppSecretRefRepositoryImpl{key: key, baseRepo: baseRepo}
+	return &PrimaryAppSecretRefRepositoryImpl{key: key, baseRepo: baseRepo, redisDBHandler: redisDBHandler}
 }
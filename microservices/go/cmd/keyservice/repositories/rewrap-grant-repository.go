@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"github.com/akrennmair/slice"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/kvstoreutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"time"
+)
+
+// RewrapGrantRepository stores rewrap grants by grant ID
+type RewrapGrantRepository interface {
+	baserepos.KeyValueTimedRepository[models.RewrapGrant]
+}
+
+type RewrapGrantRepositoryImpl struct {
+	prefix   string
+	baseRepo baserepos.KeyValueTimedRepository[models.RewrapGrant]
+}
+
+func (r RewrapGrantRepositoryImpl) Get(ctx context.Context, key string) (option.Maybe[models.RewrapGrant], error) {
+	return r.baseRepo.Get(ctx, kvstoreutils.CombineKeySections(r.prefix, key))
+}
+
+func (r RewrapGrantRepositoryImpl) Set(
+	ctx context.Context,
+	key string,
+	value models.RewrapGrant,
+	expiration time.Duration,
+) (models.RewrapGrant, error) {
+	return r.baseRepo.Set(ctx, kvstoreutils.CombineKeySections(r.prefix, key), value, expiration)
+}
+
+func (r RewrapGrantRepositoryImpl) Del(ctx context.Context, keys ...string) error {
+	nonEmptyKeys := slice.Filter(keys, utils.StringIsNotBlank)
+	combinedKeys := slice.Map(nonEmptyKeys, func(key string) string {
+		return kvstoreutils.CombineKeySections(r.prefix, key)
+	})
+	return r.baseRepo.Del(ctx, combinedKeys...)
+}
+
+func NewRewrapGrantRepositoryImpl(redisDBHandler *dshandlers.RedisDBHandler) *RewrapGrantRepositoryImpl {
+	prefix := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "rewrapGrant")
+	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.RewrapGrant](redisDBHandler)
+	return &RewrapGrantRepositoryImpl{prefix: prefix, baseRepo: baseRepo}
+}
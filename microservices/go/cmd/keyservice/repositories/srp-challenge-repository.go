@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v9"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/kvstoreutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"time"
+)
+
+type SrpChallengeRepository interface {
+	Set(ctx context.Context, key string, value models.SrpChallenge, expiration time.Duration) (models.SrpChallenge, error)
+	// GetAndDel atomically gets and deletes a challenge so it can only be
+	// answered once
+	GetAndDel(ctx context.Context, key string) (option.Maybe[models.SrpChallenge], error)
+}
+
+type SrpChallengeRepositoryImpl struct {
+	prefix         string
+	baseRepo       baserepos.KeyValueTimedRepository[models.SrpChallenge]
+	redisDBHandler *dshandlers.RedisDBHandler
+}
+
+func (s SrpChallengeRepositoryImpl) Set(
+	ctx context.Context,
+	key string,
+	value models.SrpChallenge,
+	expiration time.Duration,
+) (models.SrpChallenge, error) {
+	return s.baseRepo.Set(ctx, kvstoreutils.CombineKeySections(s.prefix, key), value, expiration)
+}
+
+func (s SrpChallengeRepositoryImpl) GetAndDel(
+	ctx context.Context,
+	key string,
+) (option.Maybe[models.SrpChallenge], error) {
+	valJson, err := s.redisDBHandler.GetRedisClient().
+		GetDel(ctx, kvstoreutils.CombineKeySections(s.prefix, key)).
+		Result()
+	if err == redis.Nil {
+		return option.None[models.SrpChallenge](), nil
+	} else if err != nil {
+		return option.None[models.SrpChallenge](), err
+	}
+	var challenge models.SrpChallenge
+	if err := json.Unmarshal([]byte(valJson), &challenge); err != nil {
+		return option.None[models.SrpChallenge](), err
+	}
+	return option.Perhaps(challenge), nil
+}
+
+func NewSrpChallengeRepositoryImpl(redisDBHandler *dshandlers.RedisDBHandler) *SrpChallengeRepositoryImpl {
+	prefix := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "srpChallenge")
+	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.SrpChallenge](redisDBHandler)
+	return &SrpChallengeRepositoryImpl{prefix: prefix, baseRepo: baseRepo, redisDBHandler: redisDBHandler}
+}
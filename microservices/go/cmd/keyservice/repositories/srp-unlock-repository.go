@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v9"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/kvstoreutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"time"
+)
+
+type SrpUnlockRepository interface {
+	Set(ctx context.Context, key string, value models.SrpUnlock, expiration time.Duration) (models.SrpUnlock, error)
+	// GetAndDel atomically gets and deletes an unlock so it creates at most one
+	// key session
+	GetAndDel(ctx context.Context, key string) (option.Maybe[models.SrpUnlock], error)
+}
+
+type SrpUnlockRepositoryImpl struct {
+	prefix         string
+	baseRepo       baserepos.KeyValueTimedRepository[models.SrpUnlock]
+	redisDBHandler *dshandlers.RedisDBHandler
+}
+
+func (s SrpUnlockRepositoryImpl) Set(
+	ctx context.Context,
+	key string,
+	value models.SrpUnlock,
+	expiration time.Duration,
+) (models.SrpUnlock, error) {
+	return s.baseRepo.Set(ctx, kvstoreutils.CombineKeySections(s.prefix, key), value, expiration)
+}
+
+func (s SrpUnlockRepositoryImpl) GetAndDel(
+	ctx context.Context,
+	key string,
+) (option.Maybe[models.SrpUnlock], error) {
+	valJson, err := s.redisDBHandler.GetRedisClient().
+		GetDel(ctx, kvstoreutils.CombineKeySections(s.prefix, key)).
+		Result()
+	if err == redis.Nil {
+		return option.None[models.SrpUnlock](), nil
+	} else if err != nil {
+		return option.None[models.SrpUnlock](), err
+	}
+	var unlock models.SrpUnlock
+	if err := json.Unmarshal([]byte(valJson), &unlock); err != nil {
+		return option.None[models.SrpUnlock](), err
+	}
+	return option.Perhaps(unlock), nil
+}
+
+func NewSrpUnlockRepositoryImpl(redisDBHandler *dshandlers.RedisDBHandler) *SrpUnlockRepositoryImpl {
+	prefix := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "srpUnlock")
+	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.SrpUnlock](redisDBHandler)
+	return &SrpUnlockRepositoryImpl{prefix: prefix, baseRepo: baseRepo, redisDBHandler: redisDBHandler}
+}
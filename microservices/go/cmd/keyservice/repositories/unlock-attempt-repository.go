@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"github.com/go-redis/redis/v9"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/kvstoreutils"
+	"time"
+)
+
+// UnlockAttemptRepository counts the passcode and TOTP attempts of users
+type UnlockAttemptRepository interface {
+	// ReserveAttempt counts an attempt of a user, starting a new window of the
+	// given length if none is open. It returns false without counting the
+	// attempt if the user already made limit attempts in the window.
+	ReserveAttempt(ctx context.Context, userId string, limit int64, window time.Duration) (bool, error)
+	// ReleaseAttempt no longer counts a reserved attempt of a user
+	ReleaseAttempt(ctx context.Context, userId string) error
+}
+
+// reserveAttemptScript increments KEYS[1] unless it is at least ARGV[1],
+// expiring it in ARGV[2] milliseconds when it is created, and returns 1 if it
+// was incremented
+var reserveAttemptScript = redis.NewScript(`
+local count = tonumber(redis.call("GET", KEYS[1]) or "0")
+if count >= tonumber(ARGV[1]) then
+	return 0
+end
+if redis.call("INCR", KEYS[1]) == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// releaseAttemptScript decrements KEYS[1] if it is positive, keeping its
+// expiration
+var releaseAttemptScript = redis.NewScript(`
+local count = tonumber(redis.call("GET", KEYS[1]) or "0")
+if count > 0 then
+	redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+
+type UnlockAttemptRepositoryImpl struct {
+	prefix         string
+	redisDBHandler *dshandlers.RedisDBHandler
+}
+
+func (u UnlockAttemptRepositoryImpl) ReserveAttempt(
+	ctx context.Context,
+	userId string,
+	limit int64,
+	window time.Duration,
+) (bool, error) {
+	reserved, err := reserveAttemptScript.Run(
+		ctx,
+		u.redisDBHandler.GetRedisClient(),
+		[]string{kvstoreutils.CombineKeySections(u.prefix, userId)},
+		limit,
+		window.Milliseconds(),
+	).Int64()
+	return reserved == 1, err
+}
+
+func (u UnlockAttemptRepositoryImpl) ReleaseAttempt(ctx context.Context, userId string) error {
+	err := releaseAttemptScript.Run(
+		ctx,
+		u.redisDBHandler.GetRedisClient(),
+		[]string{kvstoreutils.CombineKeySections(u.prefix, userId)},
+	).Err()
+	if err == redis.Nil {
+		return nil
+	}
+	return err
+}
+
+func NewUnlockAttemptRepositoryImpl(redisDBHandler *dshandlers.RedisDBHandler) *UnlockAttemptRepositoryImpl {
+	prefix := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "unlockAttempt")
+	return &UnlockAttemptRepositoryImpl{prefix: prefix, redisDBHandler: redisDBHandler}
+}
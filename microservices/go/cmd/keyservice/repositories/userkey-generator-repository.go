@@ -6,14 +6,71 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserKeyGeneratorRepository interface {
 	baserepos.CRUDRepository[models.UserKeyGenerator, string]
-	FindOneByUserId(ctx context.Context, userId string) (option.Maybe[models.UserKeyGenerator], error)
+	FindOneByUserIdAndVaultId(
+		ctx context.Context,
+		userId string,
+		vaultId string,
+	) (option.Maybe[models.UserKeyGenerator], error)
+	FindByUserId(ctx context.Context, userId string) ([]models.UserKeyGenerator, error)
 	DeleteByUserIdAndGetCount(ctx context.Context, userId string) (int64, error)
+	// UseTotpStep records the TOTP time step as used, returning false if the
+	// step or a later one was already used
+	UseTotpStep(ctx context.Context, userId string, vaultId string, step int64) (bool, error)
+	// UseTotpBackupCode removes a backup code hash, returning false if it was
+	// already removed
+	UseTotpBackupCode(ctx context.Context, userId string, vaultId string, backupCodeHash []byte) (bool, error)
+	// EnrolTotp replaces the unconfirmed TOTP secret of a generator, returning
+	// false if TOTP is already enabled
+	EnrolTotp(ctx context.Context, userId string, vaultId string, wrappedSecret []byte) (bool, error)
+	// ConfirmTotp enables the enrolled TOTP secret, returning false if it was
+	// replaced or already confirmed
+	ConfirmTotp(
+		ctx context.Context,
+		userId string,
+		vaultId string,
+		wrappedSecret []byte,
+		step int64,
+		backupCodeHashes [][]byte,
+	) (bool, error)
+	DisableTotp(ctx context.Context, userId string, vaultId string) error
+	// SetUserKeyHashIfUnset sets the user key hash of a generator that has none
+	SetUserKeyHashIfUnset(ctx context.Context, userId string, vaultId string, userKeyHash []byte) error
+	// UpgradeKdf sets the KDF parameters, salt, hashes and wrapped key of an
+	// upgraded generator, returning false if the generator's salt or KDF
+	// parameters changed since they were read
+	UpgradeKdf(
+		ctx context.Context,
+		userKeyGen models.UserKeyGenerator,
+		oldKeyDerivationSalt []byte,
+		oldKdfParams cipherutils.KDFParams,
+	) (bool, error)
+	// AddRotationAck records a consumer finished re-encrypting data with the key
+	// version, if the generator is still rotating to it
+	AddRotationAck(ctx context.Context, id string, keyVersion int64, consumer string) error
+	// RetirePreviousKeys removes the previous keys of a rotation to the key
+	// version once every consumer acknowledged it, returning false if the
+	// rotation is not in progress or has consumers yet to acknowledge it
+	RetirePreviousKeys(ctx context.Context, id string, keyVersion int64, consumers []string) (bool, error)
+	// RotateKey sets the key version, previous keys, rotation fields and the
+	// fields derived from the passcode key of a rotated generator, returning
+	// false if the generator was rotated since it was read
+	RotateKey(ctx context.Context, userKeyGen models.UserKeyGenerator, oldKeyVersion int64) (bool, error)
+	// SetDuressIfUnset links a generator to a decoy vault, returning false if a
+	// duress passcode is already registered
+	SetDuressIfUnset(ctx context.Context, id string, duress models.UserKeyDuress) (bool, error)
+	// RevokeSessions revokes the key sessions of a generator created before
+	// revokedAt in unix milliseconds
+	RevokeSessions(ctx context.Context, id string, revokedAt int64) error
 }
 
 type UserKeyGeneratorRepositoryImpl struct {
@@ -55,18 +112,29 @@ func (u UserKeyGeneratorRepositoryImpl) FindById(
 	})
 }
 
-func (u UserKeyGeneratorRepositoryImpl) FindOneByUserId(
+func (u UserKeyGeneratorRepositoryImpl) FindOneByUserIdAndVaultId(
 	ctx context.Context,
 	userId string,
+	vaultId string,
 ) (option.Maybe[models.UserKeyGenerator], error) {
 	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.UserKeyGenerator, error) {
 		user := models.UserKeyGenerator{}
 		err := mgm.Coll(u.ModelColl).
-			FirstWithCtx(u.MongoDBHandler.ToChildCtx(ctx), bson.M{"userId": userId}, &user)
+			FirstWithCtx(u.MongoDBHandler.ToChildCtx(ctx), bson.M{"userId": userId, "vaultId": vaultId}, &user)
 		return user, err
 	})
 }
 
+func (u UserKeyGeneratorRepositoryImpl) FindByUserId(
+	ctx context.Context,
+	userId string,
+) ([]models.UserKeyGenerator, error) {
+	findOpts := options.Find().SetSort(bson.M{"created_at": 1})
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, bson.M{"userId": userId}, findOpts)
+	return mgmtools.HandleFindManyRes[models.UserKeyGenerator](childCtx, cursor, err)
+}
+
 func (u UserKeyGeneratorRepositoryImpl) DeleteByUserIdAndGetCount(
 	ctx context.Context,
 	userId string,
@@ -79,6 +147,248 @@ func (u UserKeyGeneratorRepositoryImpl) DeleteByUserIdAndGetCount(
 
 }
 
+func (u UserKeyGeneratorRepositoryImpl) UseTotpStep(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+	step int64,
+) (bool, error) {
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"userId": userId, "vaultId": vaultId, "totp.lastUsedStep": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"totp.lastUsedStep": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (u UserKeyGeneratorRepositoryImpl) UseTotpBackupCode(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+	backupCodeHash []byte,
+) (bool, error) {
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"userId": userId, "vaultId": vaultId, "totp.backupCodeHashes": backupCodeHash},
+		bson.M{"$pull": bson.M{"totp.backupCodeHashes": backupCodeHash}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (u UserKeyGeneratorRepositoryImpl) EnrolTotp(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+	wrappedSecret []byte,
+) (bool, error) {
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"userId": userId, "vaultId": vaultId, "totp.confirmed": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"totp": models.UserKeyTotp{WrappedSecret: wrappedSecret}}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (u UserKeyGeneratorRepositoryImpl) ConfirmTotp(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+	wrappedSecret []byte,
+	step int64,
+	backupCodeHashes [][]byte,
+) (bool, error) {
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{
+			"userId":             userId,
+			"vaultId":            vaultId,
+			"totp.wrappedSecret": wrappedSecret,
+			"totp.confirmed":     false,
+		},
+		bson.M{"$set": bson.M{
+			"totp.confirmed":        true,
+			"totp.lastUsedStep":     step,
+			"totp.backupCodeHashes": backupCodeHashes,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (u UserKeyGeneratorRepositoryImpl) DisableTotp(ctx context.Context, userId string, vaultId string) error {
+	_, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"userId": userId, "vaultId": vaultId},
+		bson.M{"$set": bson.M{"totp": models.UserKeyTotp{}}},
+	)
+	return err
+}
+
+func (u UserKeyGeneratorRepositoryImpl) SetUserKeyHashIfUnset(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+	userKeyHash []byte,
+) error {
+	_, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"userId": userId, "vaultId": vaultId, "userKeyHash": nil},
+		bson.M{"$set": bson.M{"userKeyHash": userKeyHash}},
+	)
+	return err
+}
+
+func (u UserKeyGeneratorRepositoryImpl) UpgradeKdf(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+	oldKeyDerivationSalt []byte,
+	oldKdfParams cipherutils.KDFParams,
+) (bool, error) {
+	filter := bson.M{
+		"_id":               userKeyGen.ID,
+		"keyDerivationSalt": oldKeyDerivationSalt,
+		"kdfParams":         oldKdfParams,
+	}
+	if oldKdfParams.IsEmpty() {
+		// Generators created before KDF parameters were stored have none
+		filter["kdfParams"] = bson.M{"$in": bson.A{nil, oldKdfParams}}
+	}
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		filter,
+		bson.M{"$set": bson.M{
+			"keyDerivationSalt": userKeyGen.KeyDerivationSalt,
+			"kdfParams":         userKeyGen.KdfParams,
+			"keyHash":           userKeyGen.KeyHash,
+			"userKeyHash":       userKeyGen.UserKeyHash,
+			"wrappedKey":        userKeyGen.WrappedKey,
+			"srpVerifier":       userKeyGen.SrpVerifier,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (u UserKeyGeneratorRepositoryImpl) AddRotationAck(
+	ctx context.Context,
+	id string,
+	keyVersion int64,
+	consumer string,
+) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"_id": objId, "keyversion": keyVersion, "previousKeys.0": bson.M{"$exists": true}},
+		bson.M{"$addToSet": bson.M{"rotationAcks": consumer}},
+	)
+	return err
+}
+
+func (u UserKeyGeneratorRepositoryImpl) RetirePreviousKeys(
+	ctx context.Context,
+	id string,
+	keyVersion int64,
+	consumers []string,
+) (bool, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+	filter := bson.M{"_id": objId, "keyversion": keyVersion, "previousKeys.0": bson.M{"$exists": true}}
+	if len(consumers) > 0 {
+		filter["rotationAcks"] = bson.M{"$all": consumers}
+	}
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		filter,
+		bson.M{"$set": bson.M{
+			"previousKeys":      []models.PreviousUserKey{},
+			"rotationAcks":      []string{},
+			"rotationExpiresAt": 0,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (u UserKeyGeneratorRepositoryImpl) RotateKey(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+	oldKeyVersion int64,
+) (bool, error) {
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"_id": userKeyGen.ID, "keyversion": oldKeyVersion},
+		bson.M{"$set": bson.M{
+			"keyversion":        userKeyGen.KeyVersion,
+			"previousKeys":      userKeyGen.PreviousKeys,
+			"rotationAcks":      userKeyGen.RotationAcks,
+			"rotationExpiresAt": userKeyGen.RotationExpiresAt,
+			"keyDerivationSalt": userKeyGen.KeyDerivationSalt,
+			"kdfParams":         userKeyGen.KdfParams,
+			"keyHash":           userKeyGen.KeyHash,
+			"userKeyHash":       userKeyGen.UserKeyHash,
+			"wrappedKey":        userKeyGen.WrappedKey,
+			"srpVerifier":       userKeyGen.SrpVerifier,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (u UserKeyGeneratorRepositoryImpl) SetDuressIfUnset(
+	ctx context.Context,
+	id string,
+	duress models.UserKeyDuress,
+) (bool, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"_id": objId, "duress.decoyVaultId": bson.M{"$in": bson.A{nil, ""}}},
+		bson.M{"$set": bson.M{"duress": duress}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (u UserKeyGeneratorRepositoryImpl) RevokeSessions(ctx context.Context, id string, revokedAt int64) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"_id": objId},
+		bson.M{"$max": bson.M{"sessionsRevokedAt": revokedAt}},
+	)
+	return err
+}
+
 func NewUserKeyRepositoryImpl(mongoDBHandler *dshandlers.MongoDBHandler) *UserKeyGeneratorRepositoryImpl {
 	return &UserKeyGeneratorRepositoryImpl{
 		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.UserKeyGenerator](
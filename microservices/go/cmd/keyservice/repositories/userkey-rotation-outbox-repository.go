@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/operator"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type UserKeyRotationOutboxRepository interface {
+	Create(ctx context.Context, model models.UserKeyRotationOutboxEvent) (models.UserKeyRotationOutboxEvent, error)
+
+	// FindUnsent returns up to limit unsent events in the order they were
+	// written
+	FindUnsent(ctx context.Context, limit int64) ([]models.UserKeyRotationOutboxEvent, error)
+
+	// MarkSent marks an event as sent and removes its wrapped key
+	MarkSent(ctx context.Context, id string, sentAt time.Time) error
+}
+
+type UserKeyRotationOutboxRepositoryImpl struct {
+	baserepos.BaseRepositoryMongo[models.UserKeyRotationOutboxEvent]
+}
+
+func (u UserKeyRotationOutboxRepositoryImpl) Create(
+	ctx context.Context,
+	model models.UserKeyRotationOutboxEvent,
+) (models.UserKeyRotationOutboxEvent, error) {
+	err := mgm.Coll(u.ModelColl).CreateWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (u UserKeyRotationOutboxRepositoryImpl) FindUnsent(
+	ctx context.Context,
+	limit int64,
+) ([]models.UserKeyRotationOutboxEvent, error) {
+	findOpts := options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(limit)
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, bson.M{"sent": false}, findOpts)
+	return mgmtools.HandleFindManyRes[models.UserKeyRotationOutboxEvent](childCtx, cursor, err)
+}
+
+func (u UserKeyRotationOutboxRepositoryImpl) MarkSent(ctx context.Context, id string, sentAt time.Time) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = mgm.Coll(u.ModelColl).UpdateByID(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		objectId,
+		bson.M{
+			operator.Set:   bson.M{"sent": true, "sentAt": sentAt},
+			operator.Unset: bson.M{"wrappedKey": ""},
+		},
+	)
+	return err
+}
+
+func NewUserKeyRotationOutboxRepositoryImpl(
+	mongoDBHandler *dshandlers.MongoDBHandler,
+) *UserKeyRotationOutboxRepositoryImpl {
+	return &UserKeyRotationOutboxRepositoryImpl{
+		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.UserKeyRotationOutboxEvent](
+			models.UserKeyRotationOutboxEvent{},
+			mongoDBHandler,
+		),
+	}
+}
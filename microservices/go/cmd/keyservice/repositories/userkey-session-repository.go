@@ -14,11 +14,14 @@ import (
 
 type UserKeySessionRepository interface {
 	baserepos.KeyValueTimedRepository[models.UserKeySession]
+	// DelAll deletes every stored value
+	DelAll(ctx context.Context) error
 }
 
 type UserKeySessionRepositoryImpl struct {
-	prefix   string
-	baseRepo baserepos.KeyValueTimedRepository[models.UserKeySession]
+	prefix         string
+	baseRepo       baserepos.KeyValueTimedRepository[models.UserKeySession]
+	redisDBHandler *dshandlers.RedisDBHandler
 }
 
 func (u UserKeySessionRepositoryImpl) Get(
@@ -45,8 +48,12 @@ func (u UserKeySessionRepositoryImpl) Del(ctx context.Context, keys ...string) e
 	return u.baseRepo.Del(ctx, combinedKeys...)
 }
 
+func (u UserKeySessionRepositoryImpl) DelAll(ctx context.Context) error {
+	return u.redisDBHandler.ScanAndDel(ctx, kvstoreutils.CombineKeySections(u.prefix, "*"))
+}
+
 func NewUserKeySessionRepositoryImpl(redisDBHandler *dshandlers.RedisDBHandler) *UserKeySessionRepositoryImpl {
 	prefix := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "userKeySession")
 	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.UserKeySession](redisDBHandler)
-	return &UserKeySessionRepositoryImpl{prefix: prefix, baseRepo: baseRepo}
+	return &UserKeySessionRepositoryImpl{prefix: prefix, baseRepo: baseRepo, redisDBHandler: redisDBHandler}
 }
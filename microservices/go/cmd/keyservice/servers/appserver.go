@@ -20,13 +20,19 @@ func NewAppServerImpl(
 	serverConf conf.ServerConf,
 	tlsConf conf.TLSConf,
 	userKeyController controllers.UserKeyController,
+	appSecretController controllers.AppSecretController,
 ) *AppServerImpl {
 	if !environment.ActivateAppServer() {
 		// App server is deactivated, ran via the lifecycle package,
 		// and is a root-child dependency so a nil is returned
 		return nil
 	}
-	coreAppServer := commonservers.NewCoreAppServerImpl(serverConf, tlsConf, userKeyController)
+	coreAppServer := commonservers.NewCoreAppServerImpl(
+		serverConf,
+		tlsConf,
+		userKeyController,
+		appSecretController,
+	)
 	a := &AppServerImpl{CoreAppServer: coreAppServer}
 	lifecycle.RegisterTaskRunner(a)
 	return a
@@ -36,6 +36,7 @@ func NewGrpcServerImpl(
 		grpcOpts = append(
 			grpcOpts,
 			authInterceptorCreator.CreateUnaryInterceptor(),
+			authInterceptorCreator.CreateStreamInterceptor(),
 			credentialsOptionCreator.CreateCredentialsOption(),
 		)
 	}
@@ -3,16 +3,20 @@ package services
 import (
 	"context"
 	"errors"
+	"github.com/akrennmair/slice"
 	"github.com/google/uuid"
 	bos "github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/businessobjects"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/repositories"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"time"
 )
 
 type AppSecretService interface {
@@ -20,13 +24,22 @@ type AppSecretService interface {
 	GetAppSecret(ctx context.Context, kid string) (bos.AppSecretBo, error)
 	// GetPrimaryAppSecret gets the primary app secret
 	GetPrimaryAppSecret(ctx context.Context) (bos.AppSecretBo, error)
-	// GeneratePrimaryAppSecret generates a new primary app secret
+	// GeneratePrimaryAppSecret generates a new primary app secret, retiring the
+	// existing primary app secret
 	GeneratePrimaryAppSecret(ctx context.Context) (bos.AppSecretBo, error)
+	// AppSecretRotationTask generates a new primary app secret if the existing
+	// one is due for rotation
+	AppSecretRotationTask(ctx context.Context)
+	// EmergencyRotateAppSecrets generates a new primary app secret, then deletes
+	// every other app secret and every user key session
+	EmergencyRotateAppSecrets(ctx context.Context) (commondtos.SuccessDto, error)
 }
 
 type AppSecretServiceImpl struct {
 	primaryAppSecretRefRepository repositories.PrimaryAppSecretRefRepository
 	appSecretRepository           repositories.AppSecretRepository
+	appSecretHistoryRepository    repositories.AppSecretHistoryRepository
+	userKeySessionRepository      repositories.UserKeySessionRepository
 	keyConf                       conf.KeyConf
 	errorService                  sharedservices.ErrorService
 }
@@ -55,29 +68,156 @@ func (a AppSecretServiceImpl) GetAppSecret(ctx context.Context, kid string) (bos
 }
 
 func (a AppSecretServiceImpl) GeneratePrimaryAppSecret(ctx context.Context) (bos.AppSecretBo, error) {
-	kidGuid, err := uuid.NewRandom()
+	refFind, err := a.primaryAppSecretRefRepository.Get(ctx)
 	if err != nil {
 		return bos.AppSecretBo{}, err
 	}
+	retiredKid := option.Map(refFind, func(ref models.PrimaryAppSecretRef) string { return ref.Kid }).OrElse("")
+	newAppSecretBo, isPrimary, err := a.createPrimaryAppSecret(ctx, retiredKid)
+	if err != nil || !isPrimary {
+		// Another generator set the primary app secret first and is responsible
+		// for retiring the previous one
+		return newAppSecretBo, err
+	}
+	if utils.StringIsNotBlank(retiredKid) && retiredKid != newAppSecretBo.Kid {
+		if err := a.retireAppSecret(ctx, retiredKid); err != nil {
+			return bos.AppSecretBo{}, err
+		}
+	}
+	return newAppSecretBo, nil
+}
+
+func (a AppSecretServiceImpl) AppSecretRotationTask(ctx context.Context) {
+	refFind, err := a.primaryAppSecretRefRepository.Get(ctx)
+	if err != nil {
+		logger.Log.WithContext(ctx).WithError(err).Error("Failed to read the primary app secret ref")
+		return
+	}
+	if ref, ok := refFind.Get(); ok {
+		primarySince := time.Since(time.UnixMilli(ref.CreatedAt))
+		if primarySince < a.keyConf.GetKeyRefreshInterval() {
+			return
+		}
+	}
+	appSecretBo, err := a.GeneratePrimaryAppSecret(ctx)
+	if err != nil {
+		logger.Log.WithContext(ctx).WithError(err).Error("Failed to rotate the primary app secret")
+		return
+	}
+	logger.Log.WithContext(ctx).WithField("kid", appSecretBo.Kid).Info("Rotated the primary app secret")
+}
+
+func (a AppSecretServiceImpl) EmergencyRotateAppSecrets(ctx context.Context) (commondtos.SuccessDto, error) {
+	logger.Log.WithContext(ctx).Warn("Emergency app secret rotation, all user key sessions will be invalidated")
+	refFind, err := a.primaryAppSecretRefRepository.Get(ctx)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	// The new primary app secret is created first so there is always a primary
+	// app secret to look up while the compromised ones are deleted
+	compromisedKid := option.Map(refFind, func(ref models.PrimaryAppSecretRef) string { return ref.Kid }).OrElse("")
+	primaryAppSecretBo, _, err := a.createPrimaryAppSecret(ctx, compromisedKid)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if _, err := a.appSecretHistoryRepository.Set(ctx, models.AppSecretHistory{}); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if err := a.appSecretRepository.DelAllExcept(ctx, primaryAppSecretBo.Kid); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if err := a.userKeySessionRepository.DelAll(ctx); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	return commondtos.NewSuccessTrue(), nil
+}
+
+// createPrimaryAppSecret generates and stores a new app secret then
+// atomically marks it as the primary app secret if the primary app secret ref
+// is missing or still references expectedKid. If another generator won, the
+// new app secret is discarded and the winner's app secret is returned with
+// isPrimary set to false.
+func (a AppSecretServiceImpl) createPrimaryAppSecret(
+	ctx context.Context,
+	expectedKid string,
+) (appSecretBo bos.AppSecretBo, isPrimary bool, err error) {
+	kidGuid, err := uuid.NewRandom()
+	if err != nil {
+		return bos.AppSecretBo{}, false, err
+	}
 	kid := kidGuid.String()
 	if utils.StringIsBlank(kid) {
-		return bos.AppSecretBo{}, errors.New("generated KID is blank")
+		return bos.AppSecretBo{}, false, errors.New("generated KID is blank")
 	}
 	key, err := cipherutils.GenerateRandomKeyAES()
 	if err != nil {
-		return bos.AppSecretBo{}, err
+		return bos.AppSecretBo{}, false, err
 	}
 
-	ref := models.PrimaryAppSecretRef{Kid: kid}
+	ref := models.PrimaryAppSecretRef{Kid: kid, CreatedAt: time.Now().UnixMilli()}
 	appSecret := models.AppSecret{SecretKey: key}
 
+	// The app secret is stored before the ref so the primary ref never points
+	// to a missing app secret
 	if _, err := a.appSecretRepository.Set(ctx, ref.Kid, appSecret, a.keyConf.GetSecretDuration()); err != nil {
-		return bos.AppSecretBo{}, err
+		return bos.AppSecretBo{}, false, err
 	}
-	if _, err := a.primaryAppSecretRefRepository.Set(ctx, ref, a.keyConf.GetPrimaryAppSecretDuration()); err != nil {
-		return bos.AppSecretBo{}, err
+	storedRef, isSet, err := a.primaryAppSecretRefRepository.SetIfKidMatches(
+		ctx,
+		expectedKid,
+		ref,
+		a.keyConf.GetPrimaryAppSecretDuration(),
+	)
+	if err != nil {
+		return bos.AppSecretBo{}, false, err
+	}
+	if !isSet {
+		logger.Log.WithContext(ctx).WithField("kid", storedRef.Kid).Debug("Adopting primary app secret")
+		if err := a.appSecretRepository.Del(ctx, ref.Kid); err != nil {
+			return bos.AppSecretBo{}, false, err
+		}
+		winnerBo, err := a.GetAppSecret(ctx, storedRef.Kid)
+		return winnerBo, false, err
+	}
+	return bos.NewAppSecretBo(ref.Kid, appSecret.SecretKey), true, nil
+}
+
+// retireAppSecret adds a former primary app secret to the history, keeping it
+// valid until the last session it could have started expires. App secrets
+// beyond the number of previous app secrets to keep are deleted once their
+// sessions have expired.
+func (a AppSecretServiceImpl) retireAppSecret(ctx context.Context, kid string) error {
+	sessionDuration := a.keyConf.GetTokenSessionDuration()
+	if err := a.appSecretRepository.ExtendExpiration(ctx, kid, sessionDuration); err != nil {
+		return err
+	}
+	historyFind, err := a.appSecretHistoryRepository.Get(ctx)
+	if err != nil {
+		return err
+	}
+	history := historyFind.OrElse(models.AppSecretHistory{})
+	now := time.Now()
+	retired := append(
+		[]models.RetiredAppSecret{{Kid: kid, RetiredAt: now.UnixMilli()}},
+		slice.Filter(history.Retired, func(r models.RetiredAppSecret) bool { return r.Kid != kid })...,
+	)
+	var kept []models.RetiredAppSecret
+	var expiredKids []string
+	for i, r := range retired {
+		sessionsExpired := now.Sub(time.UnixMilli(r.RetiredAt)) >= sessionDuration
+		if i >= a.keyConf.GetPreviousAppSecretsToKeep() && sessionsExpired {
+			expiredKids = append(expiredKids, r.Kid)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	if len(expiredKids) > 0 {
+		if err := a.appSecretRepository.Del(ctx, expiredKids...); err != nil {
+			return err
+		}
 	}
-	return bos.NewAppSecretBo(ref.Kid, appSecret.SecretKey), nil
+	_, err = a.appSecretHistoryRepository.Set(ctx, models.AppSecretHistory{Retired: kept})
+	return err
 }
 
 func appSecretServiceReadMaybeModel[T any](a AppSecretServiceImpl, maybe option.Maybe[T]) (T, error) {
@@ -93,12 +233,16 @@ func appSecretServiceReadMaybeModel[T any](a AppSecretServiceImpl, maybe option.
 func NewAppSecretServiceImpl(
 	primaryAppSecretRefRepository repositories.PrimaryAppSecretRefRepository,
 	appSecretRepository repositories.AppSecretRepository,
+	appSecretHistoryRepository repositories.AppSecretHistoryRepository,
+	userKeySessionRepository repositories.UserKeySessionRepository,
 	keyConf conf.KeyConf,
 	errorService sharedservices.ErrorService,
 ) *AppSecretServiceImpl {
 	return &AppSecretServiceImpl{
 		primaryAppSecretRefRepository: primaryAppSecretRefRepository,
 		appSecretRepository:           appSecretRepository,
+		appSecretHistoryRepository:    appSecretHistoryRepository,
+		userKeySessionRepository:      userKeySessionRepository,
 		keyConf:                       keyConf,
 		errorService:                  errorService,
 	}
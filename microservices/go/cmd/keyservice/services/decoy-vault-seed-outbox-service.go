@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"github.com/akrennmair/slice"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	"time"
+)
+
+const relayDecoyVaultSeedsBatchSize = 50
+
+type DecoyVaultSeedOutboxService interface {
+	// AddDecoyVaultSeed encrypts the notes of a new decoy vault the way the note
+	// service encrypts notes and writes them to the outbox. It should run in the
+	// transaction that created the decoy vault.
+	AddDecoyVaultSeed(
+		ctx context.Context,
+		decoyKeyGen models.UserKeyGenerator,
+		decoyKey []byte,
+		notes []keydtos.DecoyNoteDto,
+	) error
+
+	// RelayDecoyVaultSeedsTask publishes the unsent decoy vault seeds in the
+	// outbox
+	RelayDecoyVaultSeedsTask(ctx context.Context)
+}
+
+type DecoyVaultSeedOutboxServiceImpl struct {
+	decoyVaultSeedOutboxRepository repositories.DecoyVaultSeedOutboxRepository
+	userKeyMsgSendService          sharedservices.UserKeyMsgSendService
+}
+
+func (d DecoyVaultSeedOutboxServiceImpl) AddDecoyVaultSeed(
+	ctx context.Context,
+	decoyKeyGen models.UserKeyGenerator,
+	decoyKey []byte,
+	notes []keydtos.DecoyNoteDto,
+) error {
+	noteCiphers := make([]models.DecoyNoteCipher, 0, len(notes))
+	for _, note := range notes {
+		noteCipher, err := encryptDecoyNote(decoyKey, note)
+		if err != nil {
+			return err
+		}
+		noteCiphers = append(noteCiphers, noteCipher)
+	}
+	outboxEvent := models.DecoyVaultSeedOutboxEvent{
+		UserId:     decoyKeyGen.UserId,
+		VaultId:    decoyKeyGen.GetVaultId(),
+		KeyVersion: decoyKeyGen.KeyVersion,
+		Notes:      noteCiphers,
+	}
+	_, err := d.decoyVaultSeedOutboxRepository.Create(ctx, outboxEvent)
+	return err
+}
+
+func (d DecoyVaultSeedOutboxServiceImpl) RelayDecoyVaultSeedsTask(ctx context.Context) {
+	outboxEvents, err := d.decoyVaultSeedOutboxRepository.FindUnsent(ctx, relayDecoyVaultSeedsBatchSize)
+	if err != nil {
+		logger.Log.WithContext(ctx).Error(err)
+		return
+	}
+	for _, outboxEvent := range outboxEvents {
+		if err := d.relayDecoyVaultSeed(ctx, outboxEvent); err != nil {
+			logger.Log.WithContext(ctx).Error(err)
+		}
+	}
+}
+
+// relayDecoyVaultSeed publishes a seed and marks it as sent. If marking fails
+// the seed is published again, which the note service ignores once the decoy
+// vault has notes.
+func (d DecoyVaultSeedOutboxServiceImpl) relayDecoyVaultSeed(
+	ctx context.Context,
+	outboxEvent models.DecoyVaultSeedOutboxEvent,
+) error {
+	seedEvent := keydtos.DecoyVaultSeedEventDto{
+		UserId:     outboxEvent.UserId,
+		VaultId:    outboxEvent.VaultId,
+		KeyVersion: outboxEvent.KeyVersion,
+		Notes: slice.Map(outboxEvent.Notes, func(note models.DecoyNoteCipher) keydtos.DecoyNoteCipherDto {
+			return keydtos.DecoyNoteCipherDto{
+				TitleCipher: note.TitleCipher,
+				TextCipher:  note.TextCipher,
+				WrappedDek:  note.WrappedDek,
+			}
+		}),
+	}
+	if err := d.userKeyMsgSendService.SendDecoyVaultSeed(ctx, seedEvent); err != nil {
+		return err
+	}
+	return d.decoyVaultSeedOutboxRepository.MarkSent(ctx, outboxEvent.GetIdStr(), time.Now())
+}
+
+// encryptDecoyNote encrypts the title and text of a note with a new data
+// encryption key wrapped by the decoy vault's user key
+func encryptDecoyNote(decoyKey []byte, note keydtos.DecoyNoteDto) (models.DecoyNoteCipher, error) {
+	dek, err := cipherutils.GenerateRandomKeyAES()
+	if err != nil {
+		return models.DecoyNoteCipher{}, err
+	}
+	titleCipher, err := cipherutils.EncryptAES(dek, []byte(note.Title))
+	if err != nil {
+		return models.DecoyNoteCipher{}, err
+	}
+	textCipher, err := cipherutils.EncryptAES(dek, []byte(note.Text))
+	if err != nil {
+		return models.DecoyNoteCipher{}, err
+	}
+	wrappedDek, err := cipherutils.EncryptAES(decoyKey, dek)
+	if err != nil {
+		return models.DecoyNoteCipher{}, err
+	}
+	return models.DecoyNoteCipher{TitleCipher: titleCipher, TextCipher: textCipher, WrappedDek: wrappedDek}, nil
+}
+
+func NewDecoyVaultSeedOutboxServiceImpl(
+	decoyVaultSeedOutboxRepository repositories.DecoyVaultSeedOutboxRepository,
+	userKeyMsgSendService sharedservices.UserKeyMsgSendService,
+) *DecoyVaultSeedOutboxServiceImpl {
+	return &DecoyVaultSeedOutboxServiceImpl{
+		decoyVaultSeedOutboxRepository: decoyVaultSeedOutboxRepository,
+		userKeyMsgSendService:          userKeyMsgSendService,
+	}
+}
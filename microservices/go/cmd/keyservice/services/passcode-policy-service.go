@@ -0,0 +1,73 @@
+package services
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/passcodepolicy"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+)
+
+const passcodeField = "passcode"
+
+type PasscodePolicyService interface {
+	// ValidatePasscode checks a new passcode against the passcode policy,
+	// returning a bad request error with a validation error for each rule it
+	// fails
+	ValidatePasscode(passcode string) error
+
+	// GetPasscodePolicy returns the rules of the passcode policy for clients
+	// that derive keys from passcodes the service never sees
+	GetPasscodePolicy() keydtos.PasscodePolicyDto
+}
+
+type PasscodePolicyServiceImpl struct {
+	passcodePolicy passcodepolicy.Policy
+	breachedList   passcodepolicy.BreachedList
+}
+
+func (p PasscodePolicyServiceImpl) ValidatePasscode(passcode string) error {
+	violations := p.passcodePolicy.Check(passcode, p.breachedList)
+	if len(violations) == 0 {
+		return nil
+	}
+	validationErrs := make([]apperrors.ValidationError, 0, len(violations))
+	for _, violation := range violations {
+		validationErrs = append(validationErrs, apperrors.ValidationError{
+			Field:   passcodeField,
+			Rule:    violation.Rule,
+			Message: violation.Message,
+		})
+	}
+	return apperrors.NewBadReqErrorFromValidationErrors(validationErrs)
+}
+
+func (p PasscodePolicyServiceImpl) GetPasscodePolicy() keydtos.PasscodePolicyDto {
+	return keydtos.PasscodePolicyDto{
+		MinLength:        p.passcodePolicy.MinLength,
+		MaxLength:        p.passcodePolicy.MaxLength,
+		RequireLowercase: p.passcodePolicy.RequireLowercase,
+		RequireUppercase: p.passcodePolicy.RequireUppercase,
+		RequireDigit:     p.passcodePolicy.RequireDigit,
+		RequireSymbol:    p.passcodePolicy.RequireSymbol,
+		MinStrengthScore: p.passcodePolicy.MinStrengthScore,
+	}
+}
+
+func NewPasscodePolicyServiceImpl(passcodePolicyConf conf.PasscodePolicyConf) *PasscodePolicyServiceImpl {
+	breachedList := passcodepolicy.NewBreachedList()
+	if path := passcodePolicyConf.GetBreachedListPath(); utils.StringIsNotBlank(path) {
+		// Passcodes are still checked by the other rules if the list is missing
+		if loaded, err := passcodepolicy.LoadBreachedList(path); err != nil {
+			logger.Log.WithError(err).Errorf("Failed to load the breached passcode list %v, skipping the check", path)
+		} else {
+			breachedList = loaded
+			logger.Log.Infof("Loaded %v breached passcodes", breachedList.Len())
+		}
+	}
+	return &PasscodePolicyServiceImpl{
+		passcodePolicy: passcodePolicyConf.GetPasscodePolicy(),
+		breachedList:   breachedList,
+	}
+}
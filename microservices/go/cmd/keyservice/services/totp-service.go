@@ -0,0 +1,332 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/businessrules"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/keyproviders"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/businessobjects/userbos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	"time"
+)
+
+const (
+	totpIssuer          = "CypherLog"
+	totpSkewSteps       = 1
+	totpBackupCodeCount = 10
+)
+
+type TotpService interface {
+	// EnrolTotp generates a TOTP secret that is required on unlock once it is
+	// confirmed
+	EnrolTotp(
+		ctx context.Context,
+		userBo userbos.UserBo,
+		vaultId string,
+		dto keydtos.PasscodeDto,
+	) (keydtos.TotpEnrolmentDto, error)
+
+	// ConfirmTotp enables TOTP with the first code from the user's
+	// authenticator app and returns the backup codes
+	ConfirmTotp(
+		ctx context.Context,
+		userBo userbos.UserBo,
+		vaultId string,
+		dto keydtos.TotpCodeDto,
+	) (keydtos.TotpBackupCodesDto, error)
+
+	DisableTotp(
+		ctx context.Context,
+		userBo userbos.UserBo,
+		vaultId string,
+		dto keydtos.TotpDisableDto,
+	) (commondtos.SuccessDto, error)
+
+	// VerifyUnlockCode verifies the TOTP or backup code of an unlock if the
+	// generator has TOTP enabled. The code is marked as used so it cannot be
+	// replayed.
+	VerifyUnlockCode(ctx context.Context, userKeyGen *models.UserKeyGenerator, code string) error
+}
+
+type TotpServiceImpl struct {
+	userKeyGeneratorRepository repositories.UserKeyGeneratorRepository
+	userKeyBr                  businessrules.UserKeyBr
+	unlockAttemptService       UnlockAttemptService
+	keyProvider                keyproviders.KeyProvider
+	errorService               sharedservices.ErrorService
+}
+
+func (t TotpServiceImpl) EnrolTotp(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+	dto keydtos.PasscodeDto,
+) (keydtos.TotpEnrolmentDto, error) {
+	userKeyGen, err := t.getUserKeyGenerator(ctx, userBo.Id, vaultId)
+	if err != nil {
+		return keydtos.TotpEnrolmentDto{}, err
+	}
+	if err := t.verifyPasscode(ctx, userKeyGen, dto.Passcode); err != nil {
+		return keydtos.TotpEnrolmentDto{}, err
+	}
+	if err := t.userKeyBr.ValidateTotpEnrolment(userKeyGen); err != nil {
+		return keydtos.TotpEnrolmentDto{}, err
+	}
+
+	secret, err := cipherutils.GenerateTOTPSecret()
+	if err != nil {
+		return keydtos.TotpEnrolmentDto{}, err
+	}
+	wrappedSecret, err := t.keyProvider.WrapKey(ctx, secret)
+	if err != nil {
+		return keydtos.TotpEnrolmentDto{}, err
+	}
+	enrolled, err := t.userKeyGeneratorRepository.EnrolTotp(ctx, userKeyGen.UserId, userKeyGen.GetVaultId(), wrappedSecret)
+	if err != nil {
+		return keydtos.TotpEnrolmentDto{}, err
+	}
+	if !enrolled {
+		ruleErr := t.errorService.RuleErrorFromCode(apperrors.ErrCodeTotpAlreadyEnabled)
+		return keydtos.TotpEnrolmentDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	return keydtos.TotpEnrolmentDto{
+		Secret: cipherutils.EncodeTOTPSecret(secret),
+		KeyUri: cipherutils.TOTPKeyURI(totpIssuer, userBo.UserName, secret),
+	}, nil
+}
+
+func (t TotpServiceImpl) ConfirmTotp(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+	dto keydtos.TotpCodeDto,
+) (keydtos.TotpBackupCodesDto, error) {
+	userKeyGen, err := t.getUserKeyGenerator(ctx, userBo.Id, vaultId)
+	if err != nil {
+		return keydtos.TotpBackupCodesDto{}, err
+	}
+	if err := t.userKeyBr.ValidateTotpConfirmation(userKeyGen); err != nil {
+		return keydtos.TotpBackupCodesDto{}, err
+	}
+	var step int64
+	if err := t.unlockAttemptService.Attempt(ctx, userKeyGen.UserId, func() (err error) {
+		step, err = t.verifyTotpCode(ctx, userKeyGen, dto.Code)
+		return
+	}); err != nil {
+		return keydtos.TotpBackupCodesDto{}, err
+	}
+
+	backupCodes, err := cipherutils.GenerateBackupCodes(totpBackupCodeCount)
+	if err != nil {
+		return keydtos.TotpBackupCodesDto{}, err
+	}
+	backupCodeHashes := make([][]byte, 0, len(backupCodes))
+	for _, backupCode := range backupCodes {
+		backupCodeHash, err := cipherutils.HashWithSaltSHA256([]byte(backupCode))
+		if err != nil {
+			return keydtos.TotpBackupCodesDto{}, err
+		}
+		backupCodeHashes = append(backupCodeHashes, backupCodeHash)
+	}
+
+	confirmed, err := t.userKeyGeneratorRepository.ConfirmTotp(
+		ctx,
+		userKeyGen.UserId,
+		userKeyGen.GetVaultId(),
+		userKeyGen.Totp.WrappedSecret,
+		step,
+		backupCodeHashes,
+	)
+	if err != nil {
+		return keydtos.TotpBackupCodesDto{}, err
+	}
+	if !confirmed {
+		// The secret was confirmed or replaced by a concurrent request
+		ruleErr := t.errorService.RuleErrorFromCode(apperrors.ErrCodeTotpNotEnrolled)
+		return keydtos.TotpBackupCodesDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	return keydtos.TotpBackupCodesDto{BackupCodes: backupCodes}, nil
+}
+
+func (t TotpServiceImpl) DisableTotp(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+	dto keydtos.TotpDisableDto,
+) (commondtos.SuccessDto, error) {
+	userKeyGen, err := t.getUserKeyGenerator(ctx, userBo.Id, vaultId)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if err := t.userKeyBr.ValidateTotpEnabled(userKeyGen); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	// The code is verified first so a wrong code does not reveal whether the
+	// passcode was correct
+	if err := t.unlockAttemptService.Attempt(ctx, userKeyGen.UserId, func() error {
+		if err := t.VerifyUnlockCode(ctx, &userKeyGen, dto.Code); err != nil {
+			return err
+		}
+		return t.verifyPasscodeKey(userKeyGen, dto.Passcode)
+	}); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+
+	logger.Log.WithContext(ctx).Debugf("Disabling TOTP")
+	if err := t.userKeyGeneratorRepository.DisableTotp(ctx, userKeyGen.UserId, userKeyGen.GetVaultId()); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	return commondtos.NewSuccessTrue(), nil
+}
+
+func (t TotpServiceImpl) VerifyUnlockCode(
+	ctx context.Context,
+	userKeyGen *models.UserKeyGenerator,
+	code string,
+) error {
+	if !userKeyGen.IsTotpEnabled() {
+		return nil
+	}
+	if utils.StringIsBlank(code) {
+		ruleErr := t.errorService.RuleErrorFromCode(apperrors.ErrCodeTotpRequired)
+		return apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+
+	if backupCodeHash, ok := t.findBackupCodeHash(*userKeyGen, code); ok {
+		used, err := t.userKeyGeneratorRepository.UseTotpBackupCode(
+			ctx,
+			userKeyGen.UserId,
+			userKeyGen.GetVaultId(),
+			backupCodeHash,
+		)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return t.incorrectTotpCodeError()
+		}
+		logger.Log.WithContext(ctx).Infof("TOTP backup code used, %v remaining", len(userKeyGen.Totp.BackupCodeHashes)-1)
+		userKeyGen.Totp.BackupCodeHashes = removeHash(userKeyGen.Totp.BackupCodeHashes, backupCodeHash)
+		return nil
+	}
+
+	step, err := t.verifyTotpCode(ctx, *userKeyGen, code)
+	if err != nil {
+		return err
+	}
+	// The step is only recorded if no later step was used, so concurrent
+	// unlocks cannot both accept the same code
+	used, err := t.userKeyGeneratorRepository.UseTotpStep(ctx, userKeyGen.UserId, userKeyGen.GetVaultId(), step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return t.incorrectTotpCodeError()
+	}
+	userKeyGen.Totp.LastUsedStep = step
+	return nil
+}
+
+// verifyTotpCode returns the time step of a valid code that is later than the
+// last used step
+func (t TotpServiceImpl) verifyTotpCode(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+	code string,
+) (int64, error) {
+	secret, err := t.keyProvider.UnwrapKey(ctx, userKeyGen.Totp.WrappedSecret)
+	if err != nil {
+		return 0, err
+	}
+	step, valid := cipherutils.VerifyTOTPCode(secret, code, time.Now(), totpSkewSteps)
+	if !valid || step <= userKeyGen.Totp.LastUsedStep {
+		return 0, t.incorrectTotpCodeError()
+	}
+	return step, nil
+}
+
+func (t TotpServiceImpl) findBackupCodeHash(userKeyGen models.UserKeyGenerator, code string) ([]byte, bool) {
+	normalizedCode := []byte(cipherutils.NormalizeBackupCode(code))
+	for _, backupCodeHash := range userKeyGen.Totp.BackupCodeHashes {
+		if verified, err := cipherutils.VerifyHashWithSaltSHA256(backupCodeHash, normalizedCode); err == nil && verified {
+			return backupCodeHash, true
+		}
+	}
+	return nil, false
+}
+
+func (t TotpServiceImpl) verifyPasscode(ctx context.Context, userKeyGen models.UserKeyGenerator, passcode string) error {
+	return t.unlockAttemptService.Attempt(ctx, userKeyGen.UserId, func() error {
+		return t.verifyPasscodeKey(userKeyGen, passcode)
+	})
+}
+
+func (t TotpServiceImpl) verifyPasscodeKey(userKeyGen models.UserKeyGenerator, passcode string) error {
+	passcodeKey, _, err := cipherutils.DeriveAESKeyWithKDF(
+		[]byte(passcode),
+		userKeyGen.KeyDerivationSalt,
+		userKeyGen.GetKdfParams(),
+	)
+	if err != nil {
+		return err
+	}
+	return t.userKeyBr.ValidateKeyFromPassword(userKeyGen, passcodeKey)
+}
+
+func (t TotpServiceImpl) incorrectTotpCodeError() error {
+	ruleErr := t.errorService.RuleErrorFromCode(apperrors.ErrCodeIncorrectTotpCode)
+	return apperrors.NewBadReqErrorFromRuleError(ruleErr)
+}
+
+func (t TotpServiceImpl) getUserKeyGenerator(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+) (models.UserKeyGenerator, error) {
+	if err := t.userKeyBr.ValidateVaultId(vaultId); err != nil {
+		return models.UserKeyGenerator{}, err
+	}
+	userKeyFind, err := t.userKeyGeneratorRepository.FindOneByUserIdAndVaultId(ctx, userId, vaultId)
+	if err != nil {
+		return models.UserKeyGenerator{}, err
+	}
+	if userKeyGen, ok := userKeyFind.Get(); ok {
+		return userKeyGen, nil
+	}
+	ruleErr := t.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+	return models.UserKeyGenerator{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+}
+
+func removeHash(hashes [][]byte, hash []byte) [][]byte {
+	remaining := make([][]byte, 0, len(hashes))
+	for _, h := range hashes {
+		if !bytes.Equal(h, hash) {
+			remaining = append(remaining, h)
+		}
+	}
+	return remaining
+}
+
+func NewTotpServiceImpl(
+	userKeyGeneratorRepository repositories.UserKeyGeneratorRepository,
+	userKeyBr businessrules.UserKeyBr,
+	unlockAttemptService UnlockAttemptService,
+	keyProvider keyproviders.KeyProvider,
+	errorService sharedservices.ErrorService,
+) *TotpServiceImpl {
+	return &TotpServiceImpl{
+		userKeyGeneratorRepository: userKeyGeneratorRepository,
+		userKeyBr:                  userKeyBr,
+		unlockAttemptService:       unlockAttemptService,
+		keyProvider:                keyProvider,
+		errorService:               errorService,
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+)
+
+type UnlockAttemptService interface {
+	// Attempt runs verify, which checks a passcode or TOTP code of a user,
+	// unless the user has made too many failed attempts. An attempt is counted
+	// before verify runs, so concurrent attempts cannot exceed the limit, and is
+	// only no longer counted if verify succeeds.
+	Attempt(ctx context.Context, userId string, verify func() error) error
+}
+
+type UnlockAttemptServiceImpl struct {
+	unlockAttemptRepository repositories.UnlockAttemptRepository
+	errorService            sharedservices.ErrorService
+	keyConf                 conf.KeyConf
+}
+
+func (u UnlockAttemptServiceImpl) Attempt(ctx context.Context, userId string, verify func() error) error {
+	reserved, err := u.unlockAttemptRepository.ReserveAttempt(
+		ctx,
+		userId,
+		u.keyConf.GetUnlockAttemptLimit(),
+		u.keyConf.GetUnlockAttemptWindow(),
+	)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeTooManyUnlockAttempts)
+		return apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	verifyErr := verify()
+	if verifyErr == nil {
+		if err := u.unlockAttemptRepository.ReleaseAttempt(ctx, userId); err != nil {
+			logger.Log.WithContext(ctx).WithError(err).Error("Failed to release an unlock attempt")
+		}
+	}
+	return verifyErr
+}
+
+func NewUnlockAttemptServiceImpl(
+	unlockAttemptRepository repositories.UnlockAttemptRepository,
+	errorService sharedservices.ErrorService,
+	keyConf conf.KeyConf,
+) *UnlockAttemptServiceImpl {
+	return &UnlockAttemptServiceImpl{
+		unlockAttemptRepository: unlockAttemptRepository,
+		errorService:            errorService,
+		keyConf:                 keyConf,
+	}
+}
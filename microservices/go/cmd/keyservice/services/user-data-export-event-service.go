@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmappers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+)
+
+type UserDataExportEventService interface {
+	// HandleUserDataExportRequest sends the user service the metadata of the
+	// user's vaults. Secrets such as keys, salts and TOTP secrets, and decoy
+	// vaults, are left out.
+	HandleUserDataExportRequest(ctx context.Context, requestDto userdtos.UserDataExportRequestDto) error
+
+	// HandleUserDataExportRequestFailure replies to the user service that the
+	// export failed once it will no longer be retried
+	HandleUserDataExportRequestFailure(
+		ctx context.Context,
+		requestDto userdtos.UserDataExportRequestDto,
+		cause error,
+	) error
+}
+
+type UserDataExportEventServiceImpl struct {
+	userKeyGeneratorRepository       repositories.UserKeyGeneratorRepository
+	userDataExportPartMsgSendService sharedservices.UserDataExportPartMsgSendService
+}
+
+func (u UserDataExportEventServiceImpl) HandleUserDataExportRequest(
+	ctx context.Context,
+	requestDto userdtos.UserDataExportRequestDto,
+) error {
+	userKeyGens, err := u.userKeyGeneratorRepository.FindByUserId(ctx, requestDto.UserId)
+	if err != nil {
+		return err
+	}
+	exportDto := keydtos.UserKeyDataExportDto{Vaults: make([]keydtos.VaultExportDto, 0, len(userKeyGens))}
+	for _, userKeyGen := range userKeyGens {
+		// Exports may be requested under duress, so decoy vaults stay hidden
+		if userKeyGen.IsDecoy() {
+			continue
+		}
+		vaultExportDto := keydtos.VaultExportDto{
+			VaultDto:          keydtos.VaultDto{VaultId: userKeyGen.GetVaultId(), Name: userKeyGen.VaultName},
+			KeyVersion:        userKeyGen.KeyVersion,
+			SrpEnabled:        userKeyGen.IsSrpEnabled(),
+			TotpEnabled:       userKeyGen.IsTotpEnabled(),
+			SessionsRevokedAt: userKeyGen.SessionsRevokedAt,
+		}
+		sharedmappers.MapMongoModelToBaseTimestamp(&userKeyGen, &vaultExportDto.BaseTimestamp)
+		exportDto.Vaults = append(exportDto.Vaults, vaultExportDto)
+	}
+	data, err := json.Marshal(exportDto)
+	if err != nil {
+		return err
+	}
+	partDto := userdtos.UserDataExportPartDto{
+		ExportId:    requestDto.ExportId,
+		UserId:      requestDto.UserId,
+		Participant: userdtos.UserDataExportParticipantKeyService,
+		Succeeded:   true,
+		Data:        data,
+	}
+	return u.userDataExportPartMsgSendService.SendUserDataExportPart(ctx, partDto)
+}
+
+func (u UserDataExportEventServiceImpl) HandleUserDataExportRequestFailure(
+	ctx context.Context,
+	requestDto userdtos.UserDataExportRequestDto,
+	cause error,
+) error {
+	partDto := userdtos.UserDataExportPartDto{
+		ExportId:    requestDto.ExportId,
+		UserId:      requestDto.UserId,
+		Participant: userdtos.UserDataExportParticipantKeyService,
+		Succeeded:   false,
+		Error:       cause.Error(),
+	}
+	return u.userDataExportPartMsgSendService.SendUserDataExportPart(ctx, partDto)
+}
+
+func NewUserDataExportEventServiceImpl(
+	userKeyGeneratorRepository repositories.UserKeyGeneratorRepository,
+	userDataExportPartMsgSendService sharedservices.UserDataExportPartMsgSendService,
+) *UserDataExportEventServiceImpl {
+	return &UserDataExportEventServiceImpl{
+		userKeyGeneratorRepository:       userKeyGeneratorRepository,
+		userDataExportPartMsgSendService: userDataExportPartMsgSendService,
+	}
+}
@@ -12,23 +12,55 @@ type UserChangeEventService interface {
 		ctx context.Context,
 		userEventDto userdtos.UserChangeEventDto,
 	) (userdtos.UserChangeEventResponseDto, error)
+
+	// HandleUserChangeEventFailure replies to the user service that a user
+	// deletion failed once it will no longer be retried
+	HandleUserChangeEventFailure(ctx context.Context, userEventDto userdtos.UserChangeEventDto, cause error) error
 }
 
 type UserChangeEventServiceImpl struct {
-	userService    sharedservices.UserService
-	userKeyService UserKeyService
-	crudDSHandler  dshandlers.CrudDSHandler
+	userService                 sharedservices.UserService
+	userKeyService              UserKeyService
+	userDeleteAckMsgSendService sharedservices.UserDeleteAckMsgSendService
+	crudDSHandler               dshandlers.CrudDSHandler
 }
 
 func (u UserChangeEventServiceImpl) HandleUserChangeEventTxn(
 	ctx context.Context,
 	userEventDto userdtos.UserChangeEventDto,
 ) (userdtos.UserChangeEventResponseDto, error) {
-	return dshandlers.Txn(ctx, u.crudDSHandler,
+	res, err := dshandlers.Txn(ctx, u.crudDSHandler,
 		func(session dshandlers.Session, ctx context.Context) (userdtos.UserChangeEventResponseDto, error) {
 			return u.handleUserChangeEvent(ctx, userEventDto)
 		},
 	)
+	if err != nil || userEventDto.Action != userdtos.UserDelete {
+		return res, err
+	}
+	// The deletion is only acknowledged once it is committed
+	ackDto := userdtos.UserDeleteAckDto{
+		UserId:      userEventDto.Id,
+		Participant: userdtos.UserDeleteParticipantKeyService,
+		Succeeded:   true,
+	}
+	return res, u.userDeleteAckMsgSendService.SendUserDeleteAck(ctx, ackDto)
+}
+
+func (u UserChangeEventServiceImpl) HandleUserChangeEventFailure(
+	ctx context.Context,
+	userEventDto userdtos.UserChangeEventDto,
+	cause error,
+) error {
+	if userEventDto.Action != userdtos.UserDelete {
+		return nil
+	}
+	ackDto := userdtos.UserDeleteAckDto{
+		UserId:      userEventDto.Id,
+		Participant: userdtos.UserDeleteParticipantKeyService,
+		Succeeded:   false,
+		Error:       cause.Error(),
+	}
+	return u.userDeleteAckMsgSendService.SendUserDeleteAck(ctx, ackDto)
 }
 
 func (u UserChangeEventServiceImpl) handleUserChangeEvent(
@@ -38,6 +70,12 @@ func (u UserChangeEventServiceImpl) handleUserChangeEvent(
 	switch userEventDto.Action {
 	case userdtos.UserSave:
 		_, err := u.userService.SaveUser(ctx, userEventDto)
+		if err != nil || !userEventDto.Suspended {
+			return userdtos.UserChangeEventResponseDto{Discarded: false}, err
+		}
+		// Suspended users cannot create key sessions, so revoking them again when
+		// a suspended user is saved again does nothing more
+		err = u.userKeyService.RevokeKeySessions(ctx, userEventDto.Id)
 		return userdtos.UserChangeEventResponseDto{Discarded: false}, err
 	case userdtos.UserDelete:
 		_, err := u.userService.DeleteUser(ctx, userEventDto)
@@ -54,11 +92,13 @@ func (u UserChangeEventServiceImpl) handleUserChangeEvent(
 func NewUserChangeEventServiceImpl(
 	userService sharedservices.UserService,
 	userKeyService UserKeyService,
+	userDeleteAckMsgSendService sharedservices.UserDeleteAckMsgSendService,
 	crudDSHandler dshandlers.CrudDSHandler,
 ) *UserChangeEventServiceImpl {
 	return &UserChangeEventServiceImpl{
-		userService:    userService,
-		userKeyService: userKeyService,
-		crudDSHandler:  crudDSHandler,
+		userService:                 userService,
+		userKeyService:              userKeyService,
+		userDeleteAckMsgSendService: userDeleteAckMsgSendService,
+		crudDSHandler:               crudDSHandler,
 	}
 }
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/keyproviders"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/encodingutils"
+	"time"
+)
+
+const relayUserKeyRotationBatchSize = 50
+
+type UserKeyRotationOutboxService interface {
+	// AddUserKeyRotation writes the rotation of a generator to the outbox. It
+	// should run in the transaction that rotated the user key.
+	AddUserKeyRotation(
+		ctx context.Context,
+		userKeyGen models.UserKeyGenerator,
+		previousKeyVersion int64,
+		key []byte,
+	) error
+
+	// RelayUserKeyRotationsTask issues rewrap grants for the unsent rotations in
+	// the outbox and publishes them
+	RelayUserKeyRotationsTask(ctx context.Context)
+}
+
+type UserKeyRotationOutboxServiceImpl struct {
+	userKeyRotationOutboxRepository repositories.UserKeyRotationOutboxRepository
+	rewrapGrantRepository           repositories.RewrapGrantRepository
+	userKeyMsgSendService           sharedservices.UserKeyMsgSendService
+	keyProvider                     keyproviders.KeyProvider
+	keyConf                         conf.KeyConf
+}
+
+func (u UserKeyRotationOutboxServiceImpl) AddUserKeyRotation(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+	previousKeyVersion int64,
+	key []byte,
+) error {
+	wrappedKey, err := u.keyProvider.WrapKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	outboxEvent := models.UserKeyRotationOutboxEvent{
+		UserId:             userKeyGen.UserId,
+		VaultId:            userKeyGen.GetVaultId(),
+		KeyVersion:         userKeyGen.KeyVersion,
+		PreviousKeyVersion: previousKeyVersion,
+		WrappedKey:         wrappedKey,
+	}
+	_, err = u.userKeyRotationOutboxRepository.Create(ctx, outboxEvent)
+	return err
+}
+
+func (u UserKeyRotationOutboxServiceImpl) RelayUserKeyRotationsTask(ctx context.Context) {
+	outboxEvents, err := u.userKeyRotationOutboxRepository.FindUnsent(ctx, relayUserKeyRotationBatchSize)
+	if err != nil {
+		logger.Log.WithContext(ctx).Error(err)
+		return
+	}
+	for _, outboxEvent := range outboxEvents {
+		// The context is done once this replica is no longer the relay
+		if ctx.Err() != nil {
+			return
+		}
+		if err := u.relayUserKeyRotation(ctx, outboxEvent); err != nil {
+			logger.Log.WithContext(ctx).Error(err)
+		}
+	}
+}
+
+// relayUserKeyRotation issues a rewrap grant to each consumer, publishes the
+// rotation with them and marks it as sent. If marking fails the rotation is
+// published again with new grants, which consumers tolerate as rewrapping
+// data already of the current key version does nothing.
+func (u UserKeyRotationOutboxServiceImpl) relayUserKeyRotation(
+	ctx context.Context,
+	outboxEvent models.UserKeyRotationOutboxEvent,
+) error {
+	key, err := u.keyProvider.UnwrapKey(ctx, outboxEvent.WrappedKey)
+	if err != nil {
+		return err
+	}
+	consumers := u.keyConf.GetKeyRotationConsumers()
+	grants := make([]keydtos.RewrapGrantDto, 0, len(consumers))
+	for _, consumer := range consumers {
+		grant, err := u.createRewrapGrant(ctx, outboxEvent, consumer, key)
+		if err != nil {
+			return err
+		}
+		grants = append(grants, grant)
+	}
+	rotationEvent := keydtos.UserKeyRotationEventDto{
+		UserId:             outboxEvent.UserId,
+		VaultId:            outboxEvent.VaultId,
+		KeyVersion:         outboxEvent.KeyVersion,
+		PreviousKeyVersion: outboxEvent.PreviousKeyVersion,
+		Grants:             grants,
+	}
+	if err := u.userKeyMsgSendService.SendUserKeyRotation(ctx, rotationEvent); err != nil {
+		return err
+	}
+	if err := u.userKeyRotationOutboxRepository.MarkSent(ctx, outboxEvent.GetIdStr(), time.Now()); err != nil {
+		return err
+	}
+	logger.Log.WithContext(ctx).Debugf(
+		"Sent user key rotation to version %v of user %v",
+		outboxEvent.KeyVersion,
+		outboxEvent.UserId,
+	)
+	return nil
+}
+
+// createRewrapGrant stores the user key encrypted by a newly generated grant
+// token, which is returned to be sent to the consumer
+func (u UserKeyRotationOutboxServiceImpl) createRewrapGrant(
+	ctx context.Context,
+	outboxEvent models.UserKeyRotationOutboxEvent,
+	consumer string,
+	key []byte,
+) (keydtos.RewrapGrantDto, error) {
+	tokenBytes, err := cipherutils.GenerateRandomKeyAES()
+	if err != nil {
+		return keydtos.RewrapGrantDto{}, err
+	}
+	tokenHash, err := cipherutils.HashWithSaltSHA256(tokenBytes)
+	if err != nil {
+		return keydtos.RewrapGrantDto{}, err
+	}
+	keyCipher, err := cipherutils.EncryptAES(tokenBytes, key)
+	if err != nil {
+		return keydtos.RewrapGrantDto{}, err
+	}
+	grantId, err := uuid.NewRandom()
+	if err != nil {
+		return keydtos.RewrapGrantDto{}, err
+	}
+	grant := models.RewrapGrant{
+		UserId:     outboxEvent.UserId,
+		VaultId:    outboxEvent.VaultId,
+		KeyVersion: outboxEvent.KeyVersion,
+		Consumer:   consumer,
+		TokenHash:  tokenHash,
+		KeyCipher:  keyCipher,
+	}
+	if _, err := u.rewrapGrantRepository.Set(
+		ctx,
+		grantId.String(),
+		grant,
+		u.keyConf.GetKeyRotationGrantDuration(),
+	); err != nil {
+		return keydtos.RewrapGrantDto{}, err
+	}
+	return keydtos.RewrapGrantDto{
+		Consumer: consumer,
+		GrantId:  grantId.String(),
+		Token:    encodingutils.EncodeBase64String(tokenBytes),
+	}, nil
+}
+
+func NewUserKeyRotationOutboxServiceImpl(
+	userKeyRotationOutboxRepository repositories.UserKeyRotationOutboxRepository,
+	rewrapGrantRepository repositories.RewrapGrantRepository,
+	userKeyMsgSendService sharedservices.UserKeyMsgSendService,
+	keyProvider keyproviders.KeyProvider,
+	keyConf conf.KeyConf,
+) *UserKeyRotationOutboxServiceImpl {
+	return &UserKeyRotationOutboxServiceImpl{
+		userKeyRotationOutboxRepository: userKeyRotationOutboxRepository,
+		rewrapGrantRepository:           rewrapGrantRepository,
+		userKeyMsgSendService:           userKeyMsgSendService,
+		keyProvider:                     keyProvider,
+		keyConf:                         keyConf,
+	}
+}
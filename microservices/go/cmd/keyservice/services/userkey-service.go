@@ -9,6 +9,7 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/models"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/keyservice/repositories"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/businessobjects/userbos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
@@ -21,61 +22,202 @@ import (
 )
 
 type UserKeyService interface {
-	UserKeyExists(ctx context.Context, userBo userbos.UserBo) (commondtos.ExistsDto, error)
+	UserKeyExists(ctx context.Context, userBo userbos.UserBo, vaultId string) (commondtos.ExistsDto, error)
+
+	// GetVaults returns the vaults the user created a user key for
+	GetVaults(ctx context.Context, userBo userbos.UserBo) ([]keydtos.VaultDto, error)
 
 	CreateUserKey(
 		ctx context.Context,
 		userBo userbos.UserBo,
+		vaultId string,
 		passwordDto keydtos.PasscodeCreateDto,
 	) (commondtos.SuccessDto, error)
 
+	// NewKeySession creates a key session bound to the client if session
+	// binding is enabled
 	NewKeySession(
 		ctx context.Context,
 		userBo userbos.UserBo,
+		vaultId string,
 		dto keydtos.PasscodeDto,
+		binding commondtos.ClientBindingDto,
 	) (commondtos.UKeySessionDto, error)
 
 	GetKeyFromSession(ctx context.Context, sessionDto commondtos.UKeySessionDto) (keydtos.UserKeyDto, error)
 
+	// RegisterDuressPasscodeTxn creates a decoy vault unlocked by a duress
+	// passcode and filled with the decoy notes. Creating a key session for the
+	// vault with the duress passcode succeeds like an ordinary unlock, but the
+	// session is of the decoy vault. SRP unlocks of the vault are turned off as
+	// SRP cannot check the duress passcode.
+	RegisterDuressPasscodeTxn(
+		ctx context.Context,
+		userBo userbos.UserBo,
+		vaultId string,
+		dto keydtos.DuressPasscodeCreateDto,
+	) (commondtos.SuccessDto, error)
+
+	// ElevateKeySession verifies the passcode of the session's vault again and
+	// marks the session as elevated for a short time, so it can perform
+	// sensitive operations
+	ElevateKeySession(
+		ctx context.Context,
+		userBo userbos.UserBo,
+		sessReqDto commondtos.UKeySessionReqDto[keydtos.PasscodeDto],
+	) (keydtos.SessionElevationDto, error)
+
+	EncryptWithSession(
+		ctx context.Context,
+		sessionDto commondtos.UKeySessionDto,
+		payloads []keydtos.CipherPayloadDto,
+	) ([]keydtos.CipherPayloadDto, error)
+
+	DecryptWithSession(
+		ctx context.Context,
+		sessionDto commondtos.UKeySessionDto,
+		payloads []keydtos.CipherPayloadDto,
+	) ([]keydtos.CipherPayloadDto, error)
+
+	// EncryptWithKey encrypts payloads with a key already retrieved from a
+	// session so a stream of payloads only validates its session once
+	EncryptWithKey(keyDto keydtos.UserKeyDto, payloads []keydtos.CipherPayloadDto) ([]keydtos.CipherPayloadDto, error)
+
+	// DecryptWithKey decrypts payloads with a key already retrieved from a
+	// session so a stream of payloads only validates its session once
+	DecryptWithKey(keyDto keydtos.UserKeyDto, payloads []keydtos.CipherPayloadDto) ([]keydtos.CipherPayloadDto, error)
+
+	RotateUserKeyTxn(
+		ctx context.Context,
+		userBo userbos.UserBo,
+		vaultId string,
+		dto keydtos.PasscodeDto,
+	) (commondtos.SuccessDto, error)
+
+	HandleKeyRotationAckTxn(ctx context.Context, ackDto keydtos.UserKeyRotationAckDto) error
+
+	// RewrapWithGrant re-encrypts payloads of previous key versions with the
+	// current user key of the vault a rewrap grant was issued for
+	RewrapWithGrant(
+		ctx context.Context,
+		grantDto keydtos.RewrapGrantDto,
+		payloads []keydtos.CipherPayloadDto,
+	) ([]keydtos.CipherPayloadDto, error)
+
+	// GetSrpCreateParams returns fresh salts, the KDF parameters and the
+	// passcode policy a client creates an SRP user key with
+	GetSrpCreateParams(ctx context.Context) (keydtos.SrpCreateParamsDto, error)
+
+	// CreateUserKeyWithSrp stores a user key wrapped by the client with a
+	// passcode key derived on the client, along with the client's SRP verifier.
+	// The passcode is never sent, so the passcode policy cannot be enforced here
+	// and is left to the client.
+	CreateUserKeyWithSrp(
+		ctx context.Context,
+		userBo userbos.UserBo,
+		vaultId string,
+		dto keydtos.SrpPasscodeCreateDto,
+	) (commondtos.SuccessDto, error)
+
+	// NewSrpChallenge starts an SRP unlock of a vault
+	NewSrpChallenge(ctx context.Context, userBo userbos.UserBo, vaultId string) (keydtos.SrpChallengeDto, error)
+
+	// VerifySrpProof verifies the answer to an SRP challenge and returns the
+	// wrapped user key of the challenged vault for the client to unwrap
+	VerifySrpProof(ctx context.Context, userBo userbos.UserBo, dto keydtos.SrpProofDto) (keydtos.SrpUnlockDto, error)
+
+	// NewKeySessionWithSrp creates a key session with the user key a client
+	// unwrapped after its SRP proof was verified
+	NewKeySessionWithSrp(
+		ctx context.Context,
+		userBo userbos.UserBo,
+		dto keydtos.SrpKeySessionCreateDto,
+		binding commondtos.ClientBindingDto,
+	) (commondtos.UKeySessionDto, error)
+
 	DeleteByUserIdAndGetCount(ctx context.Context, userId string) (int64, error)
+
+	// RevokeKeySessions revokes the key sessions of every vault of a user,
+	// including decoy vaults
+	RevokeKeySessions(ctx context.Context, userId string) error
 }
 
 type UserKeyServiceImpl struct {
 	userKeyGeneratorRepository repositories.UserKeyGeneratorRepository
 	userKeySessionRepository   repositories.UserKeySessionRepository
+	srpChallengeRepository     repositories.SrpChallengeRepository
+	srpUnlockRepository        repositories.SrpUnlockRepository
+	elevationRepository        repositories.KeySessionElevationRepository
+	rewrapGrantRepository      repositories.RewrapGrantRepository
 	userKeyBr                  businessrules.UserKeyBr
 	appSecretService           AppSecretService
+	rotationOutboxService      UserKeyRotationOutboxService
+	decoySeedOutboxService     DecoyVaultSeedOutboxService
+	totpService                TotpService
+	unlockAttemptService       UnlockAttemptService
+	passcodePolicyService      PasscodePolicyService
+	crudDSHandler              dshandlers.CrudDSHandler
 	errorService               sharedservices.ErrorService
 	keyConf                    conf.KeyConf
+	kdfConf                    conf.KdfConf
+	sessionBindingConf         conf.SessionBindingConf
 }
 
-func (u UserKeyServiceImpl) UserKeyExists(ctx context.Context, userBo userbos.UserBo) (commondtos.ExistsDto, error) {
-	userFind, err := u.userKeyGeneratorRepository.FindOneByUserId(ctx, userBo.Id)
+func (u UserKeyServiceImpl) UserKeyExists(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+) (commondtos.ExistsDto, error) {
+	if err := u.userKeyBr.ValidateVaultId(vaultId); err != nil {
+		return commondtos.ExistsDto{}, err
+	}
+	userFind, err := u.userKeyGeneratorRepository.FindOneByUserIdAndVaultId(ctx, userBo.Id, vaultId)
 	if err != nil {
 		return commondtos.ExistsDto{}, err
 	}
 	return commondtos.NewExistsDto(userFind.IsPresent()), nil
 }
 
+func (u UserKeyServiceImpl) GetVaults(ctx context.Context, userBo userbos.UserBo) ([]keydtos.VaultDto, error) {
+	userKeyGens, err := u.userKeyGeneratorRepository.FindByUserId(ctx, userBo.Id)
+	if err != nil {
+		return nil, err
+	}
+	vaultDtos := make([]keydtos.VaultDto, 0, len(userKeyGens))
+	for _, userKeyGen := range userKeyGens {
+		if userKeyGen.IsDecoy() {
+			continue
+		}
+		vaultDtos = append(vaultDtos, keydtos.VaultDto{VaultId: userKeyGen.GetVaultId(), Name: userKeyGen.VaultName})
+	}
+	return vaultDtos, nil
+}
+
 func (u UserKeyServiceImpl) CreateUserKey(
 	ctx context.Context,
 	userBo userbos.UserBo,
+	vaultId string,
 	passcodeDto keydtos.PasscodeCreateDto,
 ) (commondtos.SuccessDto, error) {
-	key, keyDerivationSalt, err := cipherutils.DeriveAESKeyFromPassword([]byte(passcodeDto.Passcode), nil)
-	if err != nil {
+	if err := u.validateVaultCreate(ctx, userBo, vaultId); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if err := u.passcodePolicyService.ValidatePasscode(passcodeDto.Passcode); err != nil {
 		return commondtos.SuccessDto{}, err
 	}
-	keyHash, err := cipherutils.HashKeyBcrypt(key)
+	key, err := cipherutils.GenerateRandomKeyAES()
 	if err != nil {
 		return commondtos.SuccessDto{}, err
 	}
 
 	newUserKeyGen := models.UserKeyGenerator{
-		UserId:            userBo.Id,
-		KeyDerivationSalt: keyDerivationSalt,
-		KeyHash:           keyHash,
-		KeyVersion:        0,
+		UserId:     userBo.Id,
+		VaultId:    vaultId,
+		VaultName:  passcodeDto.VaultName,
+		KeyVersion: 0,
+	}
+	if err := u.wrapUserKey(&newUserKeyGen, []byte(passcodeDto.Passcode), key); err != nil {
+		return commondtos.SuccessDto{}, err
 	}
 
 	if _, err := u.userKeyGeneratorRepository.Create(ctx, newUserKeyGen); err != nil {
@@ -87,23 +229,879 @@ func (u UserKeyServiceImpl) CreateUserKey(
 func (u UserKeyServiceImpl) NewKeySession(
 	ctx context.Context,
 	userBo userbos.UserBo,
+	vaultId string,
 	dto keydtos.PasscodeDto,
+	binding commondtos.ClientBindingDto,
 ) (commondtos.UKeySessionDto, error) {
-	userKeyGen, err := u.getUserKeyGenerator(ctx, userBo)
+	userKeyGen, err := u.getUserKeyGenerator(ctx, userBo, vaultId)
 	if err != nil {
 		return commondtos.UKeySessionDto{}, err
 	}
 
-	logger.Log.WithContext(ctx).Debugf("Generating key from password")
-	key, _, err := cipherutils.DeriveAESKeyFromPassword([]byte(dto.Passcode), userKeyGen.KeyDerivationSalt)
+	// The TOTP code is verified first so a wrong code does not reveal whether
+	// the passcode was correct
+	var unlockedKeyGen models.UserKeyGenerator
+	var key []byte
+	if err := u.unlockAttemptService.Attempt(ctx, userBo.Id, func() (err error) {
+		if err = u.totpService.VerifyUnlockCode(ctx, &userKeyGen, dto.TotpCode); err != nil {
+			return
+		}
+		unlockedKeyGen, key, err = u.unlockUserKeyOrDecoy(ctx, userKeyGen, dto)
+		return
+	}); err != nil {
+		return commondtos.UKeySessionDto{}, err
+	}
+	if unlockedKeyGen.IsDecoy() {
+		if err := u.runDuressSilentAction(ctx, userKeyGen); err != nil {
+			return commondtos.UKeySessionDto{}, err
+		}
+	}
+
+	return u.createKeySession(
+		ctx,
+		userBo.Id,
+		unlockedKeyGen.GetVaultId(),
+		key,
+		unlockedKeyGen.KeyVersion,
+		u.keySessionDuration(userBo),
+		binding,
+	)
+}
+
+func (u UserKeyServiceImpl) GetKeyFromSession(
+	ctx context.Context,
+	sessionDto commondtos.UKeySessionDto,
+) (keydtos.UserKeyDto, error) {
+	findStoredSession, err := u.userKeySessionRepository.Get(ctx, sessionDto.ProxyKid)
+	if err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	session, sessionPresent := findStoredSession.Get()
+	if !sessionPresent {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSession)
+		return keydtos.UserKeyDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	tokenBytes, err := encodingutils.DecodeBase64String(sessionDto.Token)
+	if err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	if err := u.userKeyBr.ValidateSessionTokenHash(session, tokenBytes); err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	if err := u.userKeyBr.ValidateSessionBinding(session, sessionDto.Binding); err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	appSecret, err := u.appSecretService.GetAppSecret(ctx, session.AppSecretKid)
+	if err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	proxyKey, err := cipherutils.DecryptAES(appSecret.Key, tokenBytes)
+	if err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	if err := u.userKeyBr.ValidateProxyKeyCiphersFromSession(
+		ctx,
+		proxyKey,
+		sessionDto.UserId,
+		sessionDto.GetVaultId(),
+		sessionDto.KeyVersion,
+		session,
+	); err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	// Sessions of a rotated key version are no longer valid
+	userKeyGen, err := u.getUserKeyGeneratorByUserId(ctx, sessionDto.UserId, sessionDto.GetVaultId())
+	if err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	if userKeyGen.KeyVersion != sessionDto.KeyVersion {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSession)
+		return keydtos.UserKeyDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	if err := u.userKeyBr.ValidateSessionNotRevoked(userKeyGen, session); err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	keyBytes, err := cipherutils.DecryptAES(proxyKey, session.KeyCipher)
+	if err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	userKeyDto, err := u.newUserKeyDto(userKeyGen, keyBytes)
+	if err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	elevated, err := u.isKeySessionElevated(ctx, sessionDto)
+	if err != nil {
+		return keydtos.UserKeyDto{}, err
+	}
+	userKeyDto.Elevated = elevated
+	return userKeyDto, nil
+}
+
+func (u UserKeyServiceImpl) RegisterDuressPasscodeTxn(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+	dto keydtos.DuressPasscodeCreateDto,
+) (commondtos.SuccessDto, error) {
+	return dshandlers.Txn(ctx, u.crudDSHandler,
+		func(_ dshandlers.Session, ctx context.Context) (commondtos.SuccessDto, error) {
+			return u.registerDuressPasscode(ctx, userBo, vaultId, dto)
+		})
+}
+
+func (u UserKeyServiceImpl) registerDuressPasscode(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+	dto keydtos.DuressPasscodeCreateDto,
+) (commondtos.SuccessDto, error) {
+	userKeyGen, err := u.getUserKeyGenerator(ctx, userBo, vaultId)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	passcodeDto := keydtos.PasscodeDto{Passcode: dto.Passcode, TotpCode: dto.TotpCode}
+	if err := u.unlockAttemptService.Attempt(ctx, userBo.Id, func() error {
+		if err := u.totpService.VerifyUnlockCode(ctx, &userKeyGen, dto.TotpCode); err != nil {
+			return err
+		}
+		_, err := u.unlockUserKey(ctx, userKeyGen, passcodeDto)
+		return err
+	}); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	// Whether a duress passcode is registered is only revealed once the passcode
+	// is verified
+	if err := u.userKeyBr.ValidateDuressCreate(userKeyGen, dto); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if err := u.passcodePolicyService.ValidatePasscode(dto.DuressPasscode); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+
+	// The decoy vault ID is random so it cannot be guessed from the vault it
+	// stands in for
+	decoyKey, err := cipherutils.GenerateRandomKeyAES()
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	decoyVaultUUID, err := uuid.NewRandom()
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	decoyVaultId := decoyVaultUUID.String()
+	decoyKeyGen := models.UserKeyGenerator{
+		UserId:         userBo.Id,
+		VaultId:        decoyVaultId,
+		VaultName:      userKeyGen.VaultName,
+		KeyVersion:     0,
+		DecoyOfVaultId: userKeyGen.GetVaultId(),
+	}
+	if err := u.wrapUserKey(&decoyKeyGen, []byte(dto.DuressPasscode), decoyKey); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if _, err := u.userKeyGeneratorRepository.Create(ctx, decoyKeyGen); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if err := u.decoySeedOutboxService.AddDecoyVaultSeed(ctx, decoyKeyGen, decoyKey, dto.DecoyNotes); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+
+	silentAction := dto.SilentAction
+	if utils.StringIsBlank(silentAction) {
+		silentAction = keydtos.DuressSilentActionNone
+	}
+	duress := models.UserKeyDuress{DecoyVaultId: decoyVaultId, SilentAction: silentAction}
+	isSet, err := u.userKeyGeneratorRepository.SetDuressIfUnset(ctx, userKeyGen.GetIdStr(), duress)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if !isSet {
+		// Another request registered a duress passcode first
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeDataRace)
+		return commondtos.SuccessDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	return commondtos.NewSuccessTrue(), nil
+}
+
+func (u UserKeyServiceImpl) ElevateKeySession(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	sessReqDto commondtos.UKeySessionReqDto[keydtos.PasscodeDto],
+) (keydtos.SessionElevationDto, error) {
+	sessionDto, passcodeDto := sessReqDto.SetUserIdAndUnwrap(userBo.Id)
+	if _, err := u.GetKeyFromSession(ctx, sessionDto); err != nil {
+		return keydtos.SessionElevationDto{}, err
+	}
+	userKeyGen, err := u.getUserKeyGenerator(ctx, userBo, sessionDto.GetVaultId())
+	if err != nil {
+		return keydtos.SessionElevationDto{}, err
+	}
+	totpKeyGen, err := u.getTotpUserKeyGenerator(ctx, userKeyGen)
+	if err != nil {
+		return keydtos.SessionElevationDto{}, err
+	}
+	if err := u.unlockAttemptService.Attempt(ctx, userBo.Id, func() error {
+		if err := u.totpService.VerifyUnlockCode(ctx, &totpKeyGen, passcodeDto.TotpCode); err != nil {
+			return err
+		}
+		_, err := u.unlockUserKey(ctx, userKeyGen, passcodeDto)
+		return err
+	}); err != nil {
+		return keydtos.SessionElevationDto{}, err
+	}
+
+	// The elevation is only checked for sessions that are still stored, so it
+	// cannot outlive the session
+	elevationDuration := u.keyConf.GetSessionElevationDuration()
+	elevation := models.KeySessionElevation{
+		UserId:    userBo.Id,
+		ExpiresAt: time.Now().Add(elevationDuration).UnixMilli(),
+	}
+	if _, err := u.elevationRepository.Set(ctx, sessionDto.ProxyKid, elevation, elevationDuration); err != nil {
+		return keydtos.SessionElevationDto{}, err
+	}
+	return keydtos.SessionElevationDto{ExpiresAt: elevation.ExpiresAt}, nil
+}
+
+// isKeySessionElevated returns true if the user of a validated session
+// recently re-entered their passcode
+func (u UserKeyServiceImpl) isKeySessionElevated(ctx context.Context, sessionDto commondtos.UKeySessionDto) (bool, error) {
+	elevationFind, err := u.elevationRepository.Get(ctx, sessionDto.ProxyKid)
+	if err != nil {
+		return false, err
+	}
+	elevation, ok := elevationFind.Get()
+	return ok && elevation.UserId == sessionDto.UserId, nil
+}
+
+func (u UserKeyServiceImpl) EncryptWithSession(
+	ctx context.Context,
+	sessionDto commondtos.UKeySessionDto,
+	payloads []keydtos.CipherPayloadDto,
+) ([]keydtos.CipherPayloadDto, error) {
+	keyDto, err := u.GetKeyFromSession(ctx, sessionDto)
 	if err != nil {
+		return nil, err
+	}
+	return u.EncryptWithKey(keyDto, payloads)
+}
+
+func (u UserKeyServiceImpl) DecryptWithSession(
+	ctx context.Context,
+	sessionDto commondtos.UKeySessionDto,
+	payloads []keydtos.CipherPayloadDto,
+) ([]keydtos.CipherPayloadDto, error) {
+	keyDto, err := u.GetKeyFromSession(ctx, sessionDto)
+	if err != nil {
+		return nil, err
+	}
+	return u.DecryptWithKey(keyDto, payloads)
+}
+
+func (u UserKeyServiceImpl) EncryptWithKey(
+	keyDto keydtos.UserKeyDto,
+	payloads []keydtos.CipherPayloadDto,
+) ([]keydtos.CipherPayloadDto, error) {
+	return keyDto.EncryptPayloads(payloads)
+}
+
+func (u UserKeyServiceImpl) DecryptWithKey(
+	keyDto keydtos.UserKeyDto,
+	payloads []keydtos.CipherPayloadDto,
+) ([]keydtos.CipherPayloadDto, error) {
+	plainPayloads, err := keyDto.DecryptPayloads(payloads)
+	if errors.Is(err, keydtos.ErrKeyVersionNotFound) {
+		// The payload is of a retired key version that is being re-encrypted
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeDataRace)
+		return nil, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	return plainPayloads, err
+}
+
+func (u UserKeyServiceImpl) RotateUserKeyTxn(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+	dto keydtos.PasscodeDto,
+) (commondtos.SuccessDto, error) {
+	return dshandlers.Txn(ctx, u.crudDSHandler,
+		func(_ dshandlers.Session, ctx context.Context) (commondtos.SuccessDto, error) {
+			return u.rotateUserKey(ctx, userBo, vaultId, dto)
+		})
+}
+
+func (u UserKeyServiceImpl) rotateUserKey(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+	dto keydtos.PasscodeDto,
+) (commondtos.SuccessDto, error) {
+	realKeyGen, err := u.getUserKeyGenerator(ctx, userBo, vaultId)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	// Unlocking with the duress passcode rotates the decoy vault's key
+	var userKeyGen models.UserKeyGenerator
+	var previousKey []byte
+	if err := u.unlockAttemptService.Attempt(ctx, userBo.Id, func() (err error) {
+		if err = u.totpService.VerifyUnlockCode(ctx, &realKeyGen, dto.TotpCode); err != nil {
+			return
+		}
+		userKeyGen, previousKey, err = u.unlockUserKeyOrDecoy(ctx, realKeyGen, dto)
+		return
+	}); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if !userKeyGen.IsDecoy() {
+		userKeyGen = realKeyGen
+	}
+	if err := u.userKeyBr.ValidateKeyRotation(userKeyGen); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+
+	logger.Log.WithContext(ctx).Debugf("Rotating user key")
+	key, err := cipherutils.GenerateRandomKeyAES()
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	// Keys of an expired rotation are kept as consumers may not have
+	// re-encrypted all their data with them yet
+	previousKeys, err := u.rewrapPreviousKeys(userKeyGen, previousKey, key)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	wrappedPreviousKey, err := cipherutils.EncryptAES(key, previousKey)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	previousKeyVersion := userKeyGen.KeyVersion
+	userKeyGen.KeyVersion = previousKeyVersion + 1
+	userKeyGen.PreviousKeys = append(
+		previousKeys,
+		models.PreviousUserKey{KeyVersion: previousKeyVersion, WrappedKey: wrappedPreviousKey},
+	)
+	userKeyGen.RotationAcks = []string{}
+	userKeyGen.RotationExpiresAt = time.Now().Add(u.keyConf.GetKeyRotationGrantDuration()).UnixMilli()
+	if err := u.wrapUserKey(&userKeyGen, []byte(dto.Passcode), key); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+
+	rotated, err := u.userKeyGeneratorRepository.RotateKey(ctx, userKeyGen, previousKeyVersion)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if !rotated {
+		// Another request rotated the key first
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeDataRace)
+		return commondtos.SuccessDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	// Consumers are sent the rotation once it is committed
+	if err := u.rotationOutboxService.AddUserKeyRotation(ctx, userKeyGen, previousKeyVersion, key); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	return commondtos.NewSuccessTrue(), nil
+}
+
+// newUserKeyDto returns the current user key of a generator with its previous
+// keys unwrapped
+func (u UserKeyServiceImpl) newUserKeyDto(
+	userKeyGen models.UserKeyGenerator,
+	keyBytes []byte,
+) (keydtos.UserKeyDto, error) {
+	userKeyDto := keydtos.NewUserKeyDto(keyBytes, userKeyGen.KeyVersion)
+	userKeyDto.PreviousKeys = make([]keydtos.PreviousUserKeyDto, 0, len(userKeyGen.PreviousKeys))
+	for _, previousKey := range userKeyGen.PreviousKeys {
+		previousKeyBytes, err := cipherutils.DecryptAES(keyBytes, previousKey.WrappedKey)
+		if err != nil {
+			return keydtos.UserKeyDto{}, err
+		}
+		userKeyDto.PreviousKeys = append(
+			userKeyDto.PreviousKeys,
+			keydtos.NewPreviousUserKeyDto(previousKeyBytes, previousKey.KeyVersion),
+		)
+	}
+	return userKeyDto, nil
+}
+
+// rewrapPreviousKeys returns the previous keys of a generator, which are
+// wrapped by its current key, wrapped by a new key instead
+func (u UserKeyServiceImpl) rewrapPreviousKeys(
+	userKeyGen models.UserKeyGenerator,
+	currentKey, newKey []byte,
+) ([]models.PreviousUserKey, error) {
+	previousKeys := make([]models.PreviousUserKey, 0, len(userKeyGen.PreviousKeys)+1)
+	for _, previousKey := range userKeyGen.PreviousKeys {
+		previousKeyBytes, err := cipherutils.DecryptAES(currentKey, previousKey.WrappedKey)
+		if err != nil {
+			return nil, err
+		}
+		wrappedKey, err := cipherutils.EncryptAES(newKey, previousKeyBytes)
+		if err != nil {
+			return nil, err
+		}
+		previousKeys = append(previousKeys, models.PreviousUserKey{
+			KeyVersion: previousKey.KeyVersion,
+			WrappedKey: wrappedKey,
+		})
+	}
+	return previousKeys, nil
+}
+
+func (u UserKeyServiceImpl) HandleKeyRotationAckTxn(ctx context.Context, ackDto keydtos.UserKeyRotationAckDto) error {
+	_, err := dshandlers.Txn(ctx, u.crudDSHandler,
+		func(_ dshandlers.Session, ctx context.Context) (bool, error) {
+			return true, u.handleKeyRotationAck(ctx, ackDto)
+		})
+	return err
+}
+
+func (u UserKeyServiceImpl) handleKeyRotationAck(ctx context.Context, ackDto keydtos.UserKeyRotationAckDto) error {
+	userKeyFind, err := u.userKeyGeneratorRepository.FindOneByUserIdAndVaultId(
+		ctx,
+		ackDto.UserId,
+		commondtos.VaultIdOrDefault(ackDto.VaultId),
+	)
+	if err != nil {
+		return err
+	}
+	userKeyGen, ok := userKeyFind.Get()
+	if !ok || !userKeyGen.IsRotating() || userKeyGen.KeyVersion != ackDto.KeyVersion {
+		logger.Log.WithContext(ctx).Infof(
+			"Discarding key rotation acknowledgement from %v for a rotation that is not in progress",
+			ackDto.Consumer,
+		)
+		return nil
+	}
+
+	// The consumer is done with its grant
+	if err := u.rewrapGrantRepository.Del(ctx, ackDto.GrantId); err != nil {
+		return err
+	}
+	if err := u.userKeyGeneratorRepository.AddRotationAck(
+		ctx,
+		userKeyGen.GetIdStr(),
+		ackDto.KeyVersion,
+		ackDto.Consumer,
+	); err != nil {
+		return err
+	}
+
+	// Once every consumer re-encrypted its data, the previous versions are
+	// retired
+	retired, err := u.userKeyGeneratorRepository.RetirePreviousKeys(
+		ctx,
+		userKeyGen.GetIdStr(),
+		ackDto.KeyVersion,
+		u.keyConf.GetKeyRotationConsumers(),
+	)
+	if retired {
+		logger.Log.WithContext(ctx).Debugf("Retired previous user key versions")
+	}
+	return err
+}
+
+func (u UserKeyServiceImpl) RewrapWithGrant(
+	ctx context.Context,
+	grantDto keydtos.RewrapGrantDto,
+	payloads []keydtos.CipherPayloadDto,
+) ([]keydtos.CipherPayloadDto, error) {
+	grantFind, err := u.rewrapGrantRepository.Get(ctx, grantDto.GrantId)
+	if err != nil {
+		return nil, err
+	}
+	tokenBytes, err := encodingutils.DecodeBase64String(grantDto.Token)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.userKeyBr.ValidateRewrapGrant(grantFind, grantDto.Consumer, tokenBytes); err != nil {
+		return nil, err
+	}
+	grant, _ := grantFind.Get()
+	keyBytes, err := cipherutils.DecryptAES(tokenBytes, grant.KeyCipher)
+	if err != nil {
+		return nil, err
+	}
+	// Grants of a superseded rotation are no longer valid
+	userKeyGen, err := u.getUserKeyGeneratorByUserId(ctx, grant.UserId, grant.VaultId)
+	if err != nil {
+		return nil, err
+	}
+	if userKeyGen.KeyVersion != grant.KeyVersion {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidRewrapGrant)
+		return nil, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	userKeyDto, err := u.newUserKeyDto(userKeyGen, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	plainPayloads, err := u.DecryptWithKey(userKeyDto, payloads)
+	if err != nil {
+		return nil, err
+	}
+	return u.EncryptWithKey(userKeyDto, plainPayloads)
+}
+
+func (u UserKeyServiceImpl) GetSrpCreateParams(_ context.Context) (keydtos.SrpCreateParamsDto, error) {
+	kdfSalt, err := cipherutils.GenerateKDFSalt()
+	if err != nil {
+		return keydtos.SrpCreateParamsDto{}, err
+	}
+	srpSalt, err := cipherutils.GenerateSRPSalt()
+	if err != nil {
+		return keydtos.SrpCreateParamsDto{}, err
+	}
+	return keydtos.SrpCreateParamsDto{
+		KdfSalt:        kdfSalt,
+		KdfParams:      u.kdfConf.GetKDFParams(),
+		SrpSalt:        srpSalt,
+		PasscodePolicy: u.passcodePolicyService.GetPasscodePolicy(),
+	}, nil
+}
+
+func (u UserKeyServiceImpl) CreateUserKeyWithSrp(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+	dto keydtos.SrpPasscodeCreateDto,
+) (commondtos.SuccessDto, error) {
+	if err := u.validateVaultCreate(ctx, userBo, vaultId); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	if err := u.userKeyBr.ValidateSrpCreate(dto); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	userKeyHash, err := cipherutils.HashKeyBcrypt(dto.Key)
+	if err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+
+	// The passcode key is only known by the client, so there is no passcode key
+	// hash and the wrapped key cannot be checked against the user key here
+	newUserKeyGen := models.UserKeyGenerator{
+		UserId:            userBo.Id,
+		VaultId:           vaultId,
+		VaultName:         dto.VaultName,
+		KeyDerivationSalt: dto.KdfSalt,
+		KdfParams:         u.kdfConf.GetKDFParams(),
+		WrappedKey:        dto.WrappedKey,
+		UserKeyHash:       userKeyHash,
+		KeyVersion:        0,
+		SrpSalt:           dto.SrpSalt,
+		SrpVerifier:       dto.Verifier,
+	}
+
+	if _, err := u.userKeyGeneratorRepository.Create(ctx, newUserKeyGen); err != nil {
+		return commondtos.SuccessDto{}, err
+	}
+	return commondtos.NewSuccessTrue(), nil
+}
+
+func (u UserKeyServiceImpl) NewSrpChallenge(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	vaultId string,
+) (keydtos.SrpChallengeDto, error) {
+	userKeyGen, err := u.getUserKeyGenerator(ctx, userBo, vaultId)
+	if err != nil {
+		return keydtos.SrpChallengeDto{}, err
+	}
+	if err := u.userKeyBr.ValidateSrpEnabled(userKeyGen); err != nil {
+		return keydtos.SrpChallengeDto{}, err
+	}
+	srpServer, err := cipherutils.NewSRPServer(userKeyGen.SrpVerifier)
+	if err != nil {
+		return keydtos.SrpChallengeDto{}, err
+	}
+	challengeId, err := uuid.NewRandom()
+	if err != nil {
+		return keydtos.SrpChallengeDto{}, err
+	}
+	challenge := models.SrpChallenge{
+		UserId:       userBo.Id,
+		VaultId:      userKeyGen.GetVaultId(),
+		PrivateValue: srpServer.PrivateValue(),
+	}
+	if _, err := u.srpChallengeRepository.Set(
+		ctx,
+		challengeId.String(),
+		challenge,
+		u.keyConf.GetSrpChallengeDuration(),
+	); err != nil {
+		return keydtos.SrpChallengeDto{}, err
+	}
+	return keydtos.SrpChallengeDto{
+		ChallengeId:  challengeId.String(),
+		KdfSalt:      userKeyGen.KeyDerivationSalt,
+		KdfParams:    userKeyGen.GetKdfParams(),
+		SrpSalt:      userKeyGen.SrpSalt,
+		ServerPublic: srpServer.PublicValue(),
+	}, nil
+}
+
+func (u UserKeyServiceImpl) VerifySrpProof(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	dto keydtos.SrpProofDto,
+) (keydtos.SrpUnlockDto, error) {
+	challengeFind, err := u.srpChallengeRepository.GetAndDel(ctx, dto.ChallengeId)
+	if err != nil {
+		return keydtos.SrpUnlockDto{}, err
+	}
+	if err := u.userKeyBr.ValidateSrpChallenge(userBo.Id, challengeFind); err != nil {
+		return keydtos.SrpUnlockDto{}, err
+	}
+	challenge, _ := challengeFind.Get()
+
+	userKeyGen, err := u.getUserKeyGenerator(ctx, userBo, commondtos.VaultIdOrDefault(challenge.VaultId))
+	if err != nil {
+		return keydtos.SrpUnlockDto{}, err
+	}
+	if err := u.userKeyBr.ValidateSrpEnabled(userKeyGen); err != nil {
+		return keydtos.SrpUnlockDto{}, err
+	}
+	srpServer, err := cipherutils.RestoreSRPServer(userKeyGen.SrpVerifier, challenge.PrivateValue)
+	if err != nil {
+		return keydtos.SrpUnlockDto{}, err
+	}
+	// The TOTP code is verified first so a wrong code does not reveal whether
+	// the proof was correct
+	var sessionKey, serverProof []byte
+	if err := u.unlockAttemptService.Attempt(ctx, userBo.Id, func() (err error) {
+		if err = u.totpService.VerifyUnlockCode(ctx, &userKeyGen, dto.TotpCode); err != nil {
+			return
+		}
+		sessionKey, serverProof, err = srpServer.VerifyClient(dto.ClientPublic, dto.ClientProof)
+		if errors.Is(err, cipherutils.ErrSRPProofMismatch) || errors.Is(err, cipherutils.ErrSRPInvalidPublicValue) {
+			ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeIncorrectPasscode)
+			return apperrors.NewBadReqErrorFromRuleError(ruleErr)
+		}
+		return
+	}); err != nil {
+		return keydtos.SrpUnlockDto{}, err
+	}
+
+	var wrappedKeyCipher []byte
+	if userKeyGen.IsKeyWrapped() {
+		if wrappedKeyCipher, err = cipherutils.EncryptAES(sessionKey, userKeyGen.WrappedKey); err != nil {
+			return keydtos.SrpUnlockDto{}, err
+		}
+	}
+	unlock := models.SrpUnlock{UserId: userBo.Id, VaultId: userKeyGen.GetVaultId(), SessionKey: sessionKey}
+	if _, err := u.srpUnlockRepository.Set(
+		ctx,
+		dto.ChallengeId,
+		unlock,
+		u.keyConf.GetSrpChallengeDuration(),
+	); err != nil {
+		return keydtos.SrpUnlockDto{}, err
+	}
+	return keydtos.SrpUnlockDto{ServerProof: serverProof, WrappedKeyCipher: wrappedKeyCipher}, nil
+}
+
+func (u UserKeyServiceImpl) NewKeySessionWithSrp(
+	ctx context.Context,
+	userBo userbos.UserBo,
+	dto keydtos.SrpKeySessionCreateDto,
+	binding commondtos.ClientBindingDto,
+) (commondtos.UKeySessionDto, error) {
+	unlockFind, err := u.srpUnlockRepository.GetAndDel(ctx, dto.ChallengeId)
+	if err != nil {
+		return commondtos.UKeySessionDto{}, err
+	}
+	if err := u.userKeyBr.ValidateSrpUnlock(userBo.Id, unlockFind); err != nil {
 		return commondtos.UKeySessionDto{}, err
 	}
+	unlock, _ := unlockFind.Get()
 
-	if err := u.userKeyBr.ValidateKeyFromPassword(userKeyGen, key); err != nil {
+	userKeyGen, err := u.getUserKeyGenerator(ctx, userBo, unlock.VaultId)
+	if err != nil {
 		return commondtos.UKeySessionDto{}, err
 	}
+	key, err := cipherutils.DecryptAES(unlock.SessionKey, dto.KeyCipher)
+	if err != nil {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidUserKey)
+		return commondtos.UKeySessionDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	if err := u.verifySrpUserKey(ctx, userKeyGen, key); err != nil {
+		return commondtos.UKeySessionDto{}, err
+	}
+
+	return u.createKeySession(
+		ctx,
+		userBo.Id,
+		userKeyGen.GetVaultId(),
+		key,
+		userKeyGen.KeyVersion,
+		u.keySessionDuration(userBo),
+		binding,
+	)
+}
 
+// verifySrpUserKey checks the user key a client unwrapped is the key of the
+// generator. Generators enrolled in SRP before user key hashes were stored
+// record the hash of the first key unwrapped, as the client has already proven
+// it knows the passcode.
+func (u UserKeyServiceImpl) verifySrpUserKey(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+	key []byte,
+) error {
+	if userKeyGen.HasUserKeyHash() {
+		return u.userKeyBr.ValidateUserKey(userKeyGen, key)
+	}
+	if !cipherutils.IsKeyLengthAES(key) {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidUserKey)
+		return apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	userKeyHash, err := cipherutils.HashKeyBcrypt(key)
+	if err != nil {
+		return err
+	}
+	return u.userKeyGeneratorRepository.SetUserKeyHashIfUnset(ctx, userKeyGen.UserId, userKeyGen.VaultId, userKeyHash)
+}
+
+// unlockUserKey verifies the passcode and returns the user key. If the
+// generator uses outdated KDF parameters, it is upgraded.
+func (u UserKeyServiceImpl) unlockUserKey(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+	dto keydtos.PasscodeDto,
+) ([]byte, error) {
+	logger.Log.WithContext(ctx).Debugf("Generating key from password")
+	kdfParams := userKeyGen.GetKdfParams()
+	passcodeKey, _, err := cipherutils.DeriveAESKeyWithKDF(
+		[]byte(dto.Passcode),
+		userKeyGen.KeyDerivationSalt,
+		kdfParams,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userKeyBr.ValidateKeyFromPassword(userKeyGen, passcodeKey); err != nil {
+		return nil, err
+	}
+
+	key, err := u.unwrapUserKey(userKeyGen, passcodeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !kdfParams.Equals(u.kdfConf.GetKDFParams()) {
+		// The user key itself does not change, so notes encrypted with it remain
+		// readable. Failing to upgrade should not prevent the user from unlocking.
+		if err := u.upgradeUserKeyGenerator(ctx, userKeyGen, []byte(dto.Passcode), key); err != nil {
+			logger.Log.WithContext(ctx).WithError(err).Warn("Failed to upgrade user key derivation parameters")
+		}
+	}
+	return key, nil
+}
+
+// unlockUserKeyOrDecoy unlocks the user key of a generator, or the key of its
+// decoy vault if the passcode is the generator's duress passcode. Both keys are
+// always derived when a duress passcode is registered, so the time taken does
+// not reveal which passcode was used.
+func (u UserKeyServiceImpl) unlockUserKeyOrDecoy(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+	dto keydtos.PasscodeDto,
+) (models.UserKeyGenerator, []byte, error) {
+	if !userKeyGen.HasDuress() {
+		key, err := u.unlockUserKey(ctx, userKeyGen, dto)
+		return userKeyGen, key, err
+	}
+	decoyKeyGen, err := u.getUserKeyGeneratorByUserId(ctx, userKeyGen.UserId, userKeyGen.Duress.DecoyVaultId)
+	if err != nil {
+		return models.UserKeyGenerator{}, nil, err
+	}
+	key, unlockErr := u.unlockUserKey(ctx, userKeyGen, dto)
+	decoyKey, decoyUnlockErr := u.unlockUserKey(ctx, decoyKeyGen, dto)
+	switch {
+	case unlockErr == nil:
+		return userKeyGen, key, nil
+	case decoyUnlockErr == nil:
+		return decoyKeyGen, decoyKey, nil
+	default:
+		return models.UserKeyGenerator{}, nil, unlockErr
+	}
+}
+
+// runDuressSilentAction runs the action a user chose to run when their duress
+// passcode is used. If the action fails, the unlock fails like any other unlock
+// hitting an internal error so the decoy vault is never opened while the real
+// vaults are still unlocked.
+func (u UserKeyServiceImpl) runDuressSilentAction(ctx context.Context, userKeyGen models.UserKeyGenerator) error {
+	if userKeyGen.Duress.SilentAction != keydtos.DuressSilentActionRevokeSessions {
+		return nil
+	}
+	// The session of the decoy vault being unlocked is kept
+	err := u.revokeKeySessions(ctx, userKeyGen.UserId, func(revokedKeyGen models.UserKeyGenerator) bool {
+		return !revokedKeyGen.IsDecoy()
+	})
+	if err != nil {
+		logger.Log.WithContext(ctx).WithError(err).Error("Failed to revoke key sessions on duress")
+	}
+	return err
+}
+
+// revokeKeySessions revokes the key sessions of the vaults of a user that
+// shouldRevoke accepts
+func (u UserKeyServiceImpl) revokeKeySessions(
+	ctx context.Context,
+	userId string,
+	shouldRevoke func(userKeyGen models.UserKeyGenerator) bool,
+) error {
+	userKeyGens, err := u.userKeyGeneratorRepository.FindByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	revokedAt := time.Now().UnixMilli()
+	for _, revokedKeyGen := range userKeyGens {
+		if !shouldRevoke(revokedKeyGen) {
+			continue
+		}
+		if err := u.userKeyGeneratorRepository.RevokeSessions(ctx, revokedKeyGen.GetIdStr(), revokedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getTotpUserKeyGenerator returns the generator holding the TOTP second factor
+// of a vault. Decoy vaults share the TOTP of the vault they stand in for.
+func (u UserKeyServiceImpl) getTotpUserKeyGenerator(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+) (models.UserKeyGenerator, error) {
+	if !userKeyGen.IsDecoy() {
+		return userKeyGen, nil
+	}
+	return u.getUserKeyGeneratorByUserId(ctx, userKeyGen.UserId, userKeyGen.DecoyOfVaultId)
+}
+
+// keySessionDuration returns the configured key session duration, or the
+// user's preferred duration if it is shorter
+func (u UserKeyServiceImpl) keySessionDuration(userBo userbos.UserBo) time.Duration {
+	sessionDuration := u.keyConf.GetTokenSessionDuration()
+	preferredDuration := time.Duration(userBo.Preferences.KeySessionMinutes) * time.Minute
+	if preferredDuration > 0 && preferredDuration < sessionDuration {
+		return preferredDuration
+	}
+	return sessionDuration
+}
+
+// createKeySession stores the user key encrypted by a newly generated proxy
+// key. The proxy key is encrypted by the primary app secret and returned as
+// the session token.
+func (u UserKeyServiceImpl) createKeySession(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+	key []byte,
+	keyVersion int64,
+	sessionDuration time.Duration,
+	binding commondtos.ClientBindingDto,
+) (commondtos.UKeySessionDto, error) {
 	proxyKey, err := cipherutils.GenerateRandomKeyAES()
 	if err != nil {
 		return commondtos.UKeySessionDto{}, err
@@ -134,11 +1132,15 @@ func (u UserKeyServiceImpl) NewKeySession(
 	if err != nil {
 		return commondtos.UKeySessionDto{}, err
 	}
-	userIdCipher, err := cipherutils.EncryptAES(proxyKey, []byte(userBo.Id))
+	userIdCipher, err := cipherutils.EncryptAES(proxyKey, []byte(userId))
 	if err != nil {
 		return commondtos.UKeySessionDto{}, err
 	}
-	keyVersionCipher, err := cipherutils.EncryptAES(proxyKey, []byte(utils.Int64ToStr(userKeyGen.KeyVersion)))
+	keyVersionCipher, err := cipherutils.EncryptAES(proxyKey, []byte(utils.Int64ToStr(keyVersion)))
+	if err != nil {
+		return commondtos.UKeySessionDto{}, err
+	}
+	vaultIdCipher, err := cipherutils.EncryptAES(proxyKey, []byte(vaultId))
 	if err != nil {
 		return commondtos.UKeySessionDto{}, err
 	}
@@ -149,10 +1151,14 @@ func (u UserKeyServiceImpl) NewKeySession(
 		AppSecretKid:     appSecret.Kid,
 		UserIdCipher:     userIdCipher,
 		KeyVersionCipher: keyVersionCipher,
+		VaultIdCipher:    vaultIdCipher,
+		CreatedAt:        time.Now().UnixMilli(),
+	}
+	if err := u.bindKeySession(&keySessionModel, binding); err != nil {
+		return commondtos.UKeySessionDto{}, err
 	}
 
 	startTime := time.Now().UnixMilli()
-	sessionDuration := u.keyConf.GetTokenSessionDuration()
 
 	if _, err := u.userKeySessionRepository.Set(ctx, proxyKid, keySessionModel, sessionDuration); err != nil {
 		return commondtos.UKeySessionDto{}, err
@@ -161,63 +1167,145 @@ func (u UserKeyServiceImpl) NewKeySession(
 	sessionDto := commondtos.UKeySessionDto{
 		Token:         token,
 		ProxyKid:      proxyKid,
-		UserId:        userBo.Id,
-		KeyVersion:    userKeyGen.KeyVersion,
+		UserId:        userId,
+		VaultId:       vaultId,
+		KeyVersion:    keyVersion,
 		StartTime:     startTime,
 		DurationMilli: sessionDuration.Milliseconds(),
 	}
 	return sessionDto, nil
 }
 
-func (u UserKeyServiceImpl) GetKeyFromSession(
-	ctx context.Context,
-	sessionDto commondtos.UKeySessionDto,
-) (keydtos.UserKeyDto, error) {
-	findStoredSession, err := u.userKeySessionRepository.Get(ctx, sessionDto.ProxyKid)
-	if err != nil {
-		return keydtos.UserKeyDto{}, err
+// bindKeySession records hashes of the client binding values enabled for the
+// environment onto the session. Values the client did not provide are left
+// unbound.
+func (u UserKeyServiceImpl) bindKeySession(
+	keySessionModel *models.UserKeySession,
+	binding commondtos.ClientBindingDto,
+) error {
+	if u.sessionBindingConf.BindDevice() && utils.StringIsNotBlank(binding.DeviceId) {
+		deviceIdHash, err := cipherutils.HashWithSaltSHA256([]byte(binding.DeviceId))
+		if err != nil {
+			return err
+		}
+		keySessionModel.DeviceIdHash = deviceIdHash
 	}
-	session, sessionPresent := findStoredSession.Get()
-	if !sessionPresent {
-		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSession)
-		return keydtos.UserKeyDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	if u.sessionBindingConf.BindIpNetwork() {
+		ipNetwork, ok := utils.IPNetwork(
+			binding.ClientIp,
+			u.sessionBindingConf.GetIpv4PrefixLength(),
+			u.sessionBindingConf.GetIpv6PrefixLength(),
+		)
+		if ok {
+			ipNetworkHash, err := cipherutils.HashWithSaltSHA256([]byte(ipNetwork))
+			if err != nil {
+				return err
+			}
+			keySessionModel.IpNetworkHash = ipNetworkHash
+		}
 	}
-	tokenBytes, err := encodingutils.DecodeBase64String(sessionDto.Token)
+	return nil
+}
+
+// wrapUserKey derives a passcode key with the configured KDF parameters, then
+// wraps the user key with it.
+func (u UserKeyServiceImpl) wrapUserKey(userKeyGen *models.UserKeyGenerator, passcode, key []byte) error {
+	kdfParams := u.kdfConf.GetKDFParams()
+	passcodeKey, keyDerivationSalt, err := cipherutils.DeriveAESKeyWithKDF(passcode, nil, kdfParams)
 	if err != nil {
-		return keydtos.UserKeyDto{}, err
+		return err
 	}
-	if err := u.userKeyBr.ValidateSessionTokenHash(session, tokenBytes); err != nil {
-		return keydtos.UserKeyDto{}, err
+	return u.wrapUserKeyWithPasscodeKey(userKeyGen, passcodeKey, keyDerivationSalt, kdfParams, key)
+}
+
+// wrapUserKeyWithPasscodeKey sets the salt, parameters, passcode key hash, user
+// key hash, and the user key wrapped by the passcode key onto the generator.
+// The SRP verifier is recomputed as it is derived from the passcode key.
+func (u UserKeyServiceImpl) wrapUserKeyWithPasscodeKey(
+	userKeyGen *models.UserKeyGenerator,
+	passcodeKey, keyDerivationSalt []byte,
+	kdfParams cipherutils.KDFParams,
+	key []byte,
+) error {
+	keyHash, err := cipherutils.HashKeyBcrypt(passcodeKey)
+	if err != nil {
+		return err
 	}
-	appSecret, err := u.appSecretService.GetAppSecret(ctx, session.AppSecretKid)
+	userKeyHash, err := cipherutils.HashKeyBcrypt(key)
 	if err != nil {
-		return keydtos.UserKeyDto{}, err
+		return err
 	}
-	proxyKey, err := cipherutils.DecryptAES(appSecret.Key, tokenBytes)
+	wrappedKey, err := cipherutils.EncryptAES(passcodeKey, key)
 	if err != nil {
-		return keydtos.UserKeyDto{}, err
+		return err
 	}
-	if err := u.userKeyBr.ValidateProxyKeyCiphersFromSession(
-		ctx,
-		proxyKey,
-		sessionDto.UserId,
-		sessionDto.KeyVersion,
-		session,
-	); err != nil {
-		return keydtos.UserKeyDto{}, err
+	userKeyGen.KeyDerivationSalt = keyDerivationSalt
+	userKeyGen.KdfParams = kdfParams
+	userKeyGen.KeyHash = keyHash
+	userKeyGen.UserKeyHash = userKeyHash
+	userKeyGen.WrappedKey = wrappedKey
+	if len(userKeyGen.SrpSalt) > 0 {
+		userKeyGen.SrpVerifier = cipherutils.SRPComputeVerifier(userKeyGen.SrpSalt, passcodeKey)
 	}
-	keyBytes, err := cipherutils.DecryptAES(proxyKey, session.KeyCipher)
+	return nil
+}
+
+// unwrapUserKey returns the user key from a verified passcode key. Legacy
+// generators have no wrapped key as the passcode key is the user key.
+func (u UserKeyServiceImpl) unwrapUserKey(userKeyGen models.UserKeyGenerator, passcodeKey []byte) ([]byte, error) {
+	if !userKeyGen.IsKeyWrapped() {
+		return passcodeKey, nil
+	}
+	return cipherutils.DecryptAES(passcodeKey, userKeyGen.WrappedKey)
+}
+
+// upgradeUserKeyGenerator re-wraps the user key with a passcode key derived
+// from the configured KDF parameters. Only the fields derived from the passcode
+// key are saved, and only if no other unlock upgraded the generator first.
+func (u UserKeyServiceImpl) upgradeUserKeyGenerator(
+	ctx context.Context,
+	userKeyGen models.UserKeyGenerator,
+	passcode, key []byte,
+) error {
+	logger.Log.WithContext(ctx).Debugf("Upgrading user key derivation parameters")
+	oldKeyDerivationSalt, oldKdfParams := userKeyGen.KeyDerivationSalt, userKeyGen.KdfParams
+	if err := u.wrapUserKey(&userKeyGen, passcode, key); err != nil {
+		return err
+	}
+	upgraded, err := u.userKeyGeneratorRepository.UpgradeKdf(ctx, userKeyGen, oldKeyDerivationSalt, oldKdfParams)
+	if err == nil && !upgraded {
+		logger.Log.WithContext(ctx).Debugf("User key derivation parameters were already upgraded")
+	}
+	return err
+}
+
+// validateVaultCreate checks the vault ID is valid and the user has not
+// already created a user key for it
+func (u UserKeyServiceImpl) validateVaultCreate(ctx context.Context, userBo userbos.UserBo, vaultId string) error {
+	existingFind, err := u.userKeyGeneratorRepository.FindOneByUserIdAndVaultId(ctx, userBo.Id, vaultId)
 	if err != nil {
-		return keydtos.UserKeyDto{}, err
+		return err
 	}
-	return keydtos.NewUserKeyDto(keyBytes, sessionDto.KeyVersion), nil
+	return u.userKeyBr.ValidateVaultCreate(vaultId, existingFind)
 }
 
 func (u UserKeyServiceImpl) getUserKeyGenerator(
 	ctx context.Context,
 	userBo userbos.UserBo,
+	vaultId string,
+) (models.UserKeyGenerator, error) {
+	if err := u.userKeyBr.ValidateVaultId(vaultId); err != nil {
+		return models.UserKeyGenerator{}, err
+	}
+	return u.getUserKeyGeneratorByUserId(ctx, userBo.Id, vaultId)
+}
+
+func (u UserKeyServiceImpl) getUserKeyGeneratorByUserId(
+	ctx context.Context,
+	userId string,
+	vaultId string,
 ) (models.UserKeyGenerator, error) {
-	userKeyFind, err := u.userKeyGeneratorRepository.FindOneByUserId(ctx, userBo.Id)
+	userKeyFind, err := u.userKeyGeneratorRepository.FindOneByUserIdAndVaultId(ctx, userId, vaultId)
 	if err != nil {
 		return models.UserKeyGenerator{}, err
 	}
@@ -233,20 +1321,48 @@ func (u UserKeyServiceImpl) DeleteByUserIdAndGetCount(ctx context.Context, userI
 	return u.userKeyGeneratorRepository.DeleteByUserIdAndGetCount(ctx, userId)
 }
 
+func (u UserKeyServiceImpl) RevokeKeySessions(ctx context.Context, userId string) error {
+	return u.revokeKeySessions(ctx, userId, func(models.UserKeyGenerator) bool { return true })
+}
+
 func NewUserKeyServiceImpl(
 	userKeyGeneratorRepository repositories.UserKeyGeneratorRepository,
 	appSecretService AppSecretService,
 	errorService sharedservices.ErrorService,
 	userKeySessionRepository repositories.UserKeySessionRepository,
+	srpChallengeRepository repositories.SrpChallengeRepository,
+	srpUnlockRepository repositories.SrpUnlockRepository,
+	elevationRepository repositories.KeySessionElevationRepository,
+	rewrapGrantRepository repositories.RewrapGrantRepository,
 	userKeyBr businessrules.UserKeyBr,
 	keyConf conf.KeyConf,
+	kdfConf conf.KdfConf,
+	sessionBindingConf conf.SessionBindingConf,
+	rotationOutboxService UserKeyRotationOutboxService,
+	decoySeedOutboxService DecoyVaultSeedOutboxService,
+	totpService TotpService,
+	unlockAttemptService UnlockAttemptService,
+	passcodePolicyService PasscodePolicyService,
+	crudDSHandler dshandlers.CrudDSHandler,
 ) *UserKeyServiceImpl {
 	return &UserKeyServiceImpl{
 		userKeyGeneratorRepository: userKeyGeneratorRepository,
 		appSecretService:           appSecretService,
 		errorService:               errorService,
 		userKeySessionRepository:   userKeySessionRepository,
+		srpChallengeRepository:     srpChallengeRepository,
+		srpUnlockRepository:        srpUnlockRepository,
+		elevationRepository:        elevationRepository,
+		rewrapGrantRepository:      rewrapGrantRepository,
 		userKeyBr:                  userKeyBr,
 		keyConf:                    keyConf,
+		kdfConf:                    kdfConf,
+		sessionBindingConf:         sessionBindingConf,
+		rotationOutboxService:      rotationOutboxService,
+		decoySeedOutboxService:     decoySeedOutboxService,
+		totpService:                totpService,
+		unlockAttemptService:       unlockAttemptService,
+		passcodePolicyService:      passcodePolicyService,
+		crudDSHandler:              crudDSHandler,
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/identityproviders"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/middlewares"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedrepos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
@@ -34,6 +35,9 @@ func InitializeApp() *App {
 		wire.Bind(new(conf.MongoConf), new(*conf.MongoConfImpl)),
 		authconf.NewAuth0SecurityConfImpl,
 		wire.Bind(new(authconf.Auth0SecurityConf), new(*authconf.Auth0RouteSecurityConfImpl)),
+		authconf.NewIdentityProviderConfImpl,
+		wire.Bind(new(authconf.IdentityProviderConf), new(*authconf.IdentityProviderConfImpl)),
+		identityproviders.NewIdentityProvider,
 		conf.NewGrpcClientConfImpl,
 		wire.Bind(new(conf.GrpcClientConf), new(*conf.GrpcClientConfImpl)),
 		conf.NewHttpClientConfImpl,
@@ -56,6 +60,10 @@ func InitializeApp() *App {
 		wire.Bind(new(ginservices.GinCtxService), new(*ginservices.GinCtxServiceImpl)),
 		sharedservices.NewUserServiceImpl,
 		wire.Bind(new(sharedservices.UserService), new(*sharedservices.UserServiceImpl)),
+		sharedservices.NewUserDeleteAckMsgSendServiceImpl,
+		wire.Bind(new(sharedservices.UserDeleteAckMsgSendService), new(*sharedservices.UserDeleteAckMsgSendServiceImpl)),
+		sharedservices.NewUserDataExportPartMsgSendServiceImpl,
+		wire.Bind(new(sharedservices.UserDataExportPartMsgSendService), new(*sharedservices.UserDataExportPartMsgSendServiceImpl)),
 		sharedservices.NewErrorServiceImpl,
 		wire.Bind(new(sharedservices.ErrorService), new(*sharedservices.ErrorServiceImpl)),
 		repositories.NewNoteRepositoryImpl,
@@ -64,6 +72,16 @@ func InitializeApp() *App {
 		wire.Bind(new(businessrules.NoteBr), new(*businessrules.NoteBrImpl)),
 		services.NewUserChangeEventServiceImpl,
 		wire.Bind(new(services.UserChangeEventService), new(*services.UserChangeEventServiceImpl)),
+		services.NewUserDataExportEventServiceImpl,
+		wire.Bind(new(services.UserDataExportEventService), new(*services.UserDataExportEventServiceImpl)),
+		services.NewNoteCipherServiceImpl,
+		wire.Bind(new(services.NoteCipherService), new(*services.NoteCipherServiceImpl)),
+		sharedservices.NewUserKeyMsgSendServiceImpl,
+		wire.Bind(new(sharedservices.UserKeyMsgSendService), new(*sharedservices.UserKeyMsgSendServiceImpl)),
+		services.NewUserKeyRotationServiceImpl,
+		wire.Bind(new(services.UserKeyRotationService), new(*services.UserKeyRotationServiceImpl)),
+		services.NewDecoyVaultSeedServiceImpl,
+		wire.Bind(new(services.DecoyVaultSeedService), new(*services.DecoyVaultSeedServiceImpl)),
 		services.NewNoteServiceImpl,
 		wire.Bind(new(services.NoteService), new(*services.NoteServiceImpl)),
 		securityservices.NewJwtValidateWebAppServiceImpl,
@@ -76,6 +94,12 @@ func InitializeApp() *App {
 		wire.Bind(new(servers.AppServer), new(*servers.AppServerImpl)),
 		listeners.NewUserChange1ListenerImpl,
 		wire.Bind(new(listeners.UserChange1Listener), new(*listeners.UserChange1ListenerImpl)),
+		listeners.NewUserKeyRotationListenerImpl,
+		wire.Bind(new(listeners.UserKeyRotationListener), new(*listeners.UserKeyRotationListenerImpl)),
+		listeners.NewDecoyVaultSeedListenerImpl,
+		wire.Bind(new(listeners.DecoyVaultSeedListener), new(*listeners.DecoyVaultSeedListenerImpl)),
+		listeners.NewUserDataExportListenerImpl,
+		wire.Bind(new(listeners.UserDataExportListener), new(*listeners.UserDataExportListenerImpl)),
 		listeners.NewKafkaListenerImpl,
 		wire.Bind(new(listeners.KafkaListener), new(*listeners.KafkaListenerImpl)),
 		NewApp)
@@ -11,9 +11,27 @@ import (
 )
 
 type NoteBr interface {
-	ValidateNoteUpdate(userBo userbos.UserBo, keyDto keydtos.UserKeyDto, existing models.Note) error
-	ValidateNoteRead(userBo userbos.UserBo, keyDto keydtos.UserKeyDto, existing models.Note) error
-	ValidateNoteDelete(userBo userbos.UserBo, existing models.Note) error
+	// ValidateNoteUpdate, ValidateNoteRead and ValidateNoteDelete also check the
+	// note is in the vault the key session unlocks. ValidateNoteDelete requires
+	// the key session to be elevated.
+	ValidateNoteUpdate(
+		userBo userbos.UserBo,
+		vaultId string,
+		sessionInfoDto keydtos.KeySessionInfoDto,
+		existing models.Note,
+	) error
+	ValidateNoteRead(
+		userBo userbos.UserBo,
+		vaultId string,
+		sessionInfoDto keydtos.KeySessionInfoDto,
+		existing models.Note,
+	) error
+	ValidateNoteDelete(
+		userBo userbos.UserBo,
+		vaultId string,
+		sessionInfoDto keydtos.KeySessionInfoDto,
+		existing models.Note,
+	) error
 	ValidateGetNotes(pageRequest pagination.PageRequest) error
 }
 
@@ -32,34 +50,67 @@ func (n NoteBrImpl) ValidateGetNotes(pageRequest pagination.PageRequest) error {
 	return validationutils.MergeRuleErrors(ruleErrors)
 }
 
-func (n NoteBrImpl) ValidateNoteRead(userBo userbos.UserBo, keyDto keydtos.UserKeyDto, existing models.Note) error {
-	ruleErrs := append(n.validateKeyVersion(keyDto, existing), n.validateNoteOwnership(userBo, existing)...)
+func (n NoteBrImpl) ValidateNoteRead(
+	userBo userbos.UserBo,
+	vaultId string,
+	sessionInfoDto keydtos.KeySessionInfoDto,
+	existing models.Note,
+) error {
+	ruleErrs := append(
+		n.validateKeyVersion(sessionInfoDto, existing),
+		n.validateNoteOwnershipInVault(userBo, vaultId, existing)...,
+	)
 	return validationutils.MergeRuleErrors(ruleErrs)
 }
 
-func (n NoteBrImpl) ValidateNoteUpdate(userBo userbos.UserBo, keyDto keydtos.UserKeyDto, existing models.Note) error {
-	ruleErrs := append(n.validateKeyVersion(keyDto, existing), n.validateNoteOwnership(userBo, existing)...)
+func (n NoteBrImpl) ValidateNoteUpdate(
+	userBo userbos.UserBo,
+	vaultId string,
+	sessionInfoDto keydtos.KeySessionInfoDto,
+	existing models.Note,
+) error {
+	ruleErrs := append(
+		n.validateKeyVersion(sessionInfoDto, existing),
+		n.validateNoteOwnershipInVault(userBo, vaultId, existing)...,
+	)
 	return validationutils.MergeRuleErrors(ruleErrs)
 }
 
-func (n NoteBrImpl) ValidateNoteDelete(userBo userbos.UserBo, existing models.Note) error {
-	return validationutils.MergeRuleErrors(n.validateNoteOwnership(userBo, existing))
+func (n NoteBrImpl) ValidateNoteDelete(
+	userBo userbos.UserBo,
+	vaultId string,
+	sessionInfoDto keydtos.KeySessionInfoDto,
+	existing models.Note,
+) error {
+	ruleErrs := n.validateNoteOwnershipInVault(userBo, vaultId, existing)
+	if !sessionInfoDto.Elevated {
+		ruleErrs = append(ruleErrs, n.errorService.RuleErrorFromCode(apperrors.ErrCodeSessionNotElevated))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
 }
 
-func (n NoteBrImpl) validateKeyVersion(keyDto keydtos.UserKeyDto, existing models.Note) []apperrors.RuleError {
+func (n NoteBrImpl) validateKeyVersion(
+	sessionInfoDto keydtos.KeySessionInfoDto,
+	existing models.Note,
+) []apperrors.RuleError {
 	var ruleErrs []apperrors.RuleError
-	if keyDto.KeyVersion != existing.KeyVersion {
+	if !sessionInfoDto.HasKeyVersion(existing.KeyVersion) {
 		ruleErrs = append(ruleErrs, n.errorService.RuleErrorFromCode(apperrors.ErrCodeDataRace))
 	}
 	return ruleErrs
 }
 
-func (n NoteBrImpl) validateNoteOwnership(userBo userbos.UserBo, existing models.Note) []apperrors.RuleError {
-	var ruleErrs []apperrors.RuleError
-	if userBo.Id != existing.UserId {
-		ruleErrs = append(ruleErrs, n.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound))
+// validateNoteOwnershipInVault reports a note in another vault as not found,
+// the same as a note of another user
+func (n NoteBrImpl) validateNoteOwnershipInVault(
+	userBo userbos.UserBo,
+	vaultId string,
+	existing models.Note,
+) []apperrors.RuleError {
+	if userBo.Id != existing.UserId || vaultId != existing.GetVaultId() {
+		return []apperrors.RuleError{n.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)}
 	}
-	return ruleErrs
+	return nil
 }
 
 func NewNoteBrImpl(errorService sharedservices.ErrorService) *NoteBrImpl {
@@ -41,7 +41,7 @@ func (n NoteControllerImpl) AddRoutes(r *gin.Engine) {
 				userBo, err = n.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
 				return
 			}).Next(func() (err error) {
-				reqBody, err = ginservices.ReadValueFromBody[cDTOs.UKeySessionReqDto[nDTOs.NoteCreateDto]](
+				reqBody, err = ginservices.ReadSessionReqFromBody[nDTOs.NoteCreateDto](
 					n.ginCtxService, c)
 				return
 			}).Next(func() (err error) {
@@ -63,7 +63,7 @@ func (n NoteControllerImpl) AddRoutes(r *gin.Engine) {
 				userBo, err = n.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
 				return
 			}).Next(func() (err error) {
-				reqBody, err = ginservices.ReadValueFromBody[cDTOs.UKeySessionReqDto[nDTOs.NoteUpdateDto]](
+				reqBody, err = ginservices.ReadSessionReqFromBody[nDTOs.NoteUpdateDto](
 					n.ginCtxService, c)
 				return
 			}).Next(func() (err error) {
@@ -78,14 +78,17 @@ func (n NoteControllerImpl) AddRoutes(r *gin.Engine) {
 		n.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
 		func(c *gin.Context) {
 			var userBo userbos.UserBo
-			var reqBody nDTOs.NoteIdDto
+			var reqBody cDTOs.UKeySessionReqDto[nDTOs.NoteIdDto]
 			var resBody cDTOs.SuccessDto
 
 			n.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
 				userBo, err = n.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
 				return
 			}).Next(func() (err error) {
-				reqBody, err = ginservices.ReadValueFromBody[nDTOs.NoteIdDto](n.ginCtxService, c)
+				reqBody, err = ginservices.ReadSessionReqFromBody[nDTOs.NoteIdDto](
+					n.ginCtxService,
+					c,
+				)
 				return
 			}).Next(func() (err error) {
 				resBody, err = n.noteService.DeleteNoteTxn(c, userBo, reqBody)
@@ -106,7 +109,7 @@ func (n NoteControllerImpl) AddRoutes(r *gin.Engine) {
 				userBo, err = n.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
 				return
 			}).Next(func() (err error) {
-				reqBody, err = ginservices.ReadValueFromBody[cDTOs.UKeySessionReqDto[nDTOs.NoteIdDto]](
+				reqBody, err = ginservices.ReadSessionReqFromBody[nDTOs.NoteIdDto](
 					n.ginCtxService, c)
 				return
 			}).Next(func() (err error) {
@@ -128,7 +131,7 @@ func (n NoteControllerImpl) AddRoutes(r *gin.Engine) {
 				userBo, err = n.userService.RequireUser(c, security.GetIdentityFromGinContext(c))
 				return
 			}).Next(func() (err error) {
-				reqBody, err = ginservices.ReadValueFromBody[cDTOs.UKeySessionReqDto[pagination.PageRequest]](
+				reqBody, err = ginservices.ReadSessionReqFromBody[pagination.PageRequest](
 					n.ginCtxService, c)
 				return
 			}).Next(func() (err error) {
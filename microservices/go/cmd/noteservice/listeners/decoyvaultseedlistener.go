@@ -0,0 +1,69 @@
+package listeners
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/noteservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+)
+
+type DecoyVaultSeedListener interface {
+	lifecycle.Closable
+	ListenDecoyVaultSeed()
+}
+
+type DecoyVaultSeedListenerImpl struct {
+	decoyVaultSeedService services.DecoyVaultSeedService
+	seedConsumer          *kfka.RetryingConsumer[keydtos.DecoyVaultSeedEventDto]
+}
+
+func (d DecoyVaultSeedListenerImpl) ListenDecoyVaultSeed() {
+	d.seedConsumer.Listen(
+		func(ctx context.Context, dto keydtos.DecoyVaultSeedEventDto) error {
+			err := d.decoyVaultSeedService.HandleDecoyVaultSeedTxn(ctx, dto)
+			if err != nil {
+				logger.Log.WithError(err).Error("Error seeding a decoy vault")
+			}
+			return err
+		},
+		nil,
+	)
+	logger.Log.Info("Listening for decoy vault seeds")
+}
+
+func (d DecoyVaultSeedListenerImpl) Close() error {
+	logger.Log.Info("Closing decoy vault seed listener")
+	err := d.seedConsumer.Close()
+	if err != nil {
+		logger.Log.WithError(err).Error("Errors closing listener")
+	}
+	logger.Log.Info("Finish closing decoy vault seed listener")
+	return err
+}
+
+func NewDecoyVaultSeedListenerImpl(
+	decoyVaultSeedService services.DecoyVaultSeedService,
+	kafkaConf conf.KafkaConf,
+) *DecoyVaultSeedListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+
+	seedConsumer := kfka.NewRetryingConsumer(
+		kafkaConf,
+		retrypolicies.NewDecoyVaultSeedNoteServicePolicy(),
+		keydtos.DecoyVaultSeedEventDto.MessageKey,
+	)
+
+	return &DecoyVaultSeedListenerImpl{
+		decoyVaultSeedService: decoyVaultSeedService,
+		seedConsumer:          seedConsumer,
+	}
+}
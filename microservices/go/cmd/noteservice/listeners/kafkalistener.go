@@ -10,24 +10,38 @@ type KafkaListener interface {
 }
 
 type KafkaListenerImpl struct {
-	userChange1Listener UserChange1Listener
+	userChange1Listener     UserChange1Listener
+	userKeyRotationListener UserKeyRotationListener
+	userDataExportListener  UserDataExportListener
+	decoyVaultSeedListener  DecoyVaultSeedListener
 }
 
 func (k KafkaListenerImpl) Run() {
 	k.userChange1Listener.ListenUserChange()
+	k.userKeyRotationListener.ListenUserKeyRotation()
+	k.userDataExportListener.ListenUserDataExport()
+	k.decoyVaultSeedListener.ListenDecoyVaultSeed()
 	forever := make(chan any)
 	<-forever
 }
 
 func NewKafkaListenerImpl(
 	userChange1Listener UserChange1Listener,
+	userKeyRotationListener UserKeyRotationListener,
+	userDataExportListener UserDataExportListener,
+	decoyVaultSeedListener DecoyVaultSeedListener,
 ) *KafkaListenerImpl {
 	if !environment.ActivateKafkaListener() {
 		// Listener is deactivated, ran via the lifecycle package,
 		// and is a root-child dependency so a nil is returned
 		return nil
 	}
-	r := &KafkaListenerImpl{userChange1Listener: userChange1Listener}
+	r := &KafkaListenerImpl{
+		userChange1Listener:     userChange1Listener,
+		userKeyRotationListener: userKeyRotationListener,
+		userDataExportListener:  userDataExportListener,
+		decoyVaultSeedListener:  decoyVaultSeedListener,
+	}
 	lifecycle.RegisterTaskRunner(r)
 	return r
 }
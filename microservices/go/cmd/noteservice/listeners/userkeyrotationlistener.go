@@ -0,0 +1,69 @@
+package listeners
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/noteservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+)
+
+type UserKeyRotationListener interface {
+	lifecycle.Closable
+	ListenUserKeyRotation()
+}
+
+type UserKeyRotationListenerImpl struct {
+	userKeyRotationService services.UserKeyRotationService
+	rotationConsumer       *kfka.RetryingConsumer[keydtos.UserKeyRotationEventDto]
+}
+
+func (k UserKeyRotationListenerImpl) ListenUserKeyRotation() {
+	k.rotationConsumer.Listen(
+		func(ctx context.Context, dto keydtos.UserKeyRotationEventDto) error {
+			err := k.userKeyRotationService.HandleUserKeyRotation(ctx, dto)
+			if err != nil {
+				logger.Log.WithError(err).Error("Error rewrapping notes for a user key rotation")
+			}
+			return err
+		},
+		nil,
+	)
+	logger.Log.Info("Listening for user key rotations")
+}
+
+func (k UserKeyRotationListenerImpl) Close() error {
+	logger.Log.Info("Closing user key rotation listener")
+	err := k.rotationConsumer.Close()
+	if err != nil {
+		logger.Log.WithError(err).Error("Errors closing listener")
+	}
+	logger.Log.Info("Finish closing user key rotation listener")
+	return err
+}
+
+func NewUserKeyRotationListenerImpl(
+	userKeyRotationService services.UserKeyRotationService,
+	kafkaConf conf.KafkaConf,
+) *UserKeyRotationListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+
+	rotationConsumer := kfka.NewRetryingConsumer(
+		kafkaConf,
+		retrypolicies.NewUserKeyRotationNoteServicePolicy(),
+		keydtos.UserKeyRotationEventDto.MessageKey,
+	)
+
+	return &UserKeyRotationListenerImpl{
+		userKeyRotationService: userKeyRotationService,
+		rotationConsumer:       rotationConsumer,
+	}
+}
@@ -25,3 +25,9 @@ func MapTextPreviewAndCoreNoteAndNoteToNotePreviewDto(
 	notePreviewDto.CoreNoteDto = *coreNoteDto
 	notePreviewDto.TextPreview = textPreview
 }
+
+func MapNoteToNoteExportDto(note *models.Note, noteExportDto *nDTOs.NoteExportDto) {
+	sharedmappers.MapMongoModelToBaseCrudObject(note, &(noteExportDto.BaseCRUDObject))
+	noteExportDto.VaultId = note.GetVaultId()
+	noteExportDto.KeyVersion = note.KeyVersion
+}
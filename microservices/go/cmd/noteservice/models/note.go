@@ -2,15 +2,35 @@ package models
 
 import (
 	"github.com/kamva/mgm/v3"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
 	"time"
 )
 
 type Note struct {
 	mgm.DefaultModel `bson:",inline"`
 	UserId           string `bson:"userId"`
+	VaultId          string `bson:"vaultId"`
 	TitleCipher      []byte `bson:"titleCipher"`
 	TextCipher       []byte `bson:"cipherText"`
-	KeyVersion       int64
+	// WrappedDek is the note's data encryption key, which encrypts the title and
+	// text, wrapped by the user key. It is empty for legacy notes where the
+	// title and text are encrypted by the user key itself.
+	WrappedDek []byte `bson:"wrappedDek"`
+	// KeyVersion is the version of the user key wrapping the data encryption
+	// key, or encrypting the title and text of legacy notes
+	KeyVersion int64
+}
+
+// GetVaultId returns the ID of the vault whose user key encrypts the note.
+// Notes created before users could have more than one vault are in the
+// default vault.
+func (k Note) GetVaultId() string {
+	return commondtos.VaultIdOrDefault(k.VaultId)
+}
+
+// HasDek returns true if the note is encrypted by its own data encryption key
+func (k Note) HasDek() bool {
+	return len(k.WrappedDek) > 0
 }
 
 func (k Note) GetIdStr() string {
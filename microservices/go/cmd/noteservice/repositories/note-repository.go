@@ -10,17 +10,41 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type NoteRepository interface {
 	baserepos.CRUDRepository[models.Note, string]
-	GetPaginatedByUserId(
+	GetPaginatedByUserIdAndVaultId(
 		ctx context.Context,
 		userId string,
+		vaultId string,
 		pageReq pagination.PageRequest,
 	) ([]models.Note, error)
-	CountByUserId(ctx context.Context, userId string) (int64, error)
+	CountByUserIdAndVaultId(ctx context.Context, userId string, vaultId string) (int64, error)
+	// GetByUserIdAndVaultIdBeforeKeyVersion returns notes of a vault encrypted
+	// with a key version older than the given one
+	GetByUserIdAndVaultIdBeforeKeyVersion(
+		ctx context.Context,
+		userId string,
+		vaultId string,
+		keyVersion int64,
+		limit int64,
+	) ([]models.Note, error)
+	// ExistsByUserIdAndVaultIdAfterKeyVersion returns true if a note of a vault
+	// is encrypted with a key version newer than the given one
+	ExistsByUserIdAndVaultIdAfterKeyVersion(
+		ctx context.Context,
+		userId string,
+		vaultId string,
+		keyVersion int64,
+	) (bool, error)
+	// GetMetadataByUserId returns the user's notes without their ciphers
+	GetMetadataByUserId(ctx context.Context, userId string) ([]models.Note, error)
 	DeleteByUserIdAndGetCount(ctx context.Context, userId string) (int64, error)
+	// DeleteInVault deletes a note only if it belongs to its user's vault,
+	// returning false if nothing was deleted
+	DeleteInVault(ctx context.Context, model models.Note, vaultId string) (bool, error)
 }
 
 type NoteRepositoryImpl struct {
@@ -50,23 +74,72 @@ func (u NoteRepositoryImpl) FindById(ctx context.Context, id string) (option.May
 	})
 }
 
-func (u NoteRepositoryImpl) GetPaginatedByUserId(
+func (u NoteRepositoryImpl) GetPaginatedByUserIdAndVaultId(
 	ctx context.Context,
 	userId string,
+	vaultId string,
 	pageReq pagination.PageRequest,
 ) ([]models.Note, error) {
 	findOpts := mgmtools.CreatePaginatedFindOpts(pageReq)
-	filter := bson.D{{"userId", userId}}
+	filter := bson.M{"userId": userId, "vaultId": vaultId}
 	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
 	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, filter, findOpts)
 	return mgmtools.HandleFindManyRes[models.Note](childCtx, cursor, err)
 }
 
-func (u NoteRepositoryImpl) CountByUserId(ctx context.Context, userId string) (int64, error) {
-	filter := bson.D{{"userId", userId}}
+func (u NoteRepositoryImpl) CountByUserIdAndVaultId(ctx context.Context, userId string, vaultId string) (int64, error) {
+	filter := bson.M{"userId": userId, "vaultId": vaultId}
 	return mgm.Coll(u.ModelColl).CountDocuments(u.MongoDBHandler.ToChildCtx(ctx), filter)
 }
 
+func (u NoteRepositoryImpl) GetByUserIdAndVaultIdBeforeKeyVersion(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+	keyVersion int64,
+	limit int64,
+) ([]models.Note, error) {
+	findOpts := options.Find().SetLimit(limit)
+	// Notes saved before key versions were stored have none
+	filter := bson.M{"userId": userId, "vaultId": vaultId, "keyversion": bson.M{"$not": bson.M{"$gte": keyVersion}}}
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, filter, findOpts)
+	return mgmtools.HandleFindManyRes[models.Note](childCtx, cursor, err)
+}
+
+func (u NoteRepositoryImpl) ExistsByUserIdAndVaultIdAfterKeyVersion(
+	ctx context.Context,
+	userId string,
+	vaultId string,
+	keyVersion int64,
+) (bool, error) {
+	filter := bson.M{"userId": userId, "vaultId": vaultId, "keyversion": bson.M{"$gt": keyVersion}}
+	count, err := mgm.Coll(u.ModelColl).CountDocuments(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		filter,
+		options.Count().SetLimit(1),
+	)
+	return count > 0, err
+}
+
+func (u NoteRepositoryImpl) GetMetadataByUserId(ctx context.Context, userId string) ([]models.Note, error) {
+	findOpts := options.Find().
+		SetSort(bson.M{"created_at": 1}).
+		SetProjection(bson.M{"titleCipher": 0, "cipherText": 0, "wrappedDek": 0})
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, bson.M{"userId": userId}, findOpts)
+	return mgmtools.HandleFindManyRes[models.Note](childCtx, cursor, err)
+}
+
+func (u NoteRepositoryImpl) DeleteInVault(ctx context.Context, model models.Note, vaultId string) (bool, error) {
+	filter := bson.M{"_id": model.ID, "userId": model.UserId, "vaultId": vaultId}
+	res, err := mgm.Coll(u.ModelColl).DeleteOne(u.MongoDBHandler.ToChildCtx(ctx), filter)
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount == 1, nil
+}
+
 func (u NoteRepositoryImpl) DeleteByUserIdAndGetCount(ctx context.Context, userId string) (int64, error) {
 	res, err := mgm.Coll(u.ModelColl).DeleteMany(u.MongoDBHandler.ToChildCtx(ctx), bson.M{"userId": userId})
 	if res != nil {
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/noteservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/noteservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+)
+
+type DecoyVaultSeedService interface {
+	// HandleDecoyVaultSeedTxn saves the encrypted notes of a new decoy vault. A
+	// seed is ignored if the vault already has notes, as it was already saved.
+	HandleDecoyVaultSeedTxn(ctx context.Context, seedDto keydtos.DecoyVaultSeedEventDto) error
+}
+
+type DecoyVaultSeedServiceImpl struct {
+	noteRepository repositories.NoteRepository
+	crudDSHandler  dshandlers.CrudDSHandler
+}
+
+func (d DecoyVaultSeedServiceImpl) HandleDecoyVaultSeedTxn(
+	ctx context.Context,
+	seedDto keydtos.DecoyVaultSeedEventDto,
+) error {
+	_, err := dshandlers.Txn(ctx, d.crudDSHandler,
+		func(_ dshandlers.Session, ctx context.Context) (bool, error) {
+			return true, d.handleDecoyVaultSeed(ctx, seedDto)
+		})
+	return err
+}
+
+func (d DecoyVaultSeedServiceImpl) handleDecoyVaultSeed(
+	ctx context.Context,
+	seedDto keydtos.DecoyVaultSeedEventDto,
+) error {
+	count, err := d.noteRepository.CountByUserIdAndVaultId(ctx, seedDto.UserId, seedDto.VaultId)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		logger.Log.WithContext(ctx).Debugf("Decoy vault of user %v is already seeded", seedDto.UserId)
+		return nil
+	}
+	for _, noteCipher := range seedDto.Notes {
+		note := models.Note{
+			UserId:      seedDto.UserId,
+			VaultId:     seedDto.VaultId,
+			TitleCipher: noteCipher.TitleCipher,
+			TextCipher:  noteCipher.TextCipher,
+			WrappedDek:  noteCipher.WrappedDek,
+			KeyVersion:  seedDto.KeyVersion,
+		}
+		if _, err := d.noteRepository.Create(ctx, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewDecoyVaultSeedServiceImpl(
+	noteRepository repositories.NoteRepository,
+	crudDSHandler dshandlers.CrudDSHandler,
+) *DecoyVaultSeedServiceImpl {
+	return &DecoyVaultSeedServiceImpl{noteRepository: noteRepository, crudDSHandler: crudDSHandler}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/noteservice/models"
+	cDTOs "github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
+	kDTOs "github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	nDTOs "github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/notedtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+)
+
+// NoteCipherService encrypts notes with their own data encryption key (DEK)
+// that is wrapped by the user key, so rotating the user key only rewraps DEKs.
+type NoteCipherService interface {
+	// EncryptNote encrypts the note details with a new DEK and sets the
+	// ciphers, wrapped DEK and user key version onto the note
+	EncryptNote(
+		ctx context.Context,
+		sessDto cDTOs.UKeySessionDto,
+		note *models.Note,
+		details nDTOs.CoreNoteDetailsDto,
+	) error
+
+	// DecryptNotes returns the details of each note in the order provided
+	DecryptNotes(
+		ctx context.Context,
+		sessDto cDTOs.UKeySessionDto,
+		notes []models.Note,
+	) ([]nDTOs.CoreNoteDetailsDto, error)
+
+	// RewrapNotes re-encrypts the DEK of each note, or the title and text of
+	// legacy notes without a DEK, with the user key a rewrap grant was issued for
+	RewrapNotes(ctx context.Context, grantDto kDTOs.RewrapGrantDto, notes []models.Note) ([]models.Note, error)
+}
+
+type NoteCipherServiceImpl struct {
+	userKeyService externalservices.ExtUserKeyService
+}
+
+func (n NoteCipherServiceImpl) EncryptNote(
+	ctx context.Context,
+	sessDto cDTOs.UKeySessionDto,
+	note *models.Note,
+	details nDTOs.CoreNoteDetailsDto,
+) error {
+	dek, err := cipherutils.GenerateRandomKeyAES()
+	if err != nil {
+		return err
+	}
+	wrappedDeks, err := n.userKeyService.EncryptWithSession(ctx, sessDto, []kDTOs.CipherPayloadDto{{Data: dek}})
+	if err != nil {
+		return err
+	}
+	if err := encryptNoteDetailsWithDek(note, dek, details); err != nil {
+		return err
+	}
+	note.WrappedDek = wrappedDeks[0].Data
+	note.KeyVersion = wrappedDeks[0].KeyVersion
+	return nil
+}
+
+func (n NoteCipherServiceImpl) DecryptNotes(
+	ctx context.Context,
+	sessDto cDTOs.UKeySessionDto,
+	notes []models.Note,
+) ([]nDTOs.CoreNoteDetailsDto, error) {
+	// DEK notes need their DEK unwrapped while legacy notes need their title and
+	// text decrypted by the user key, which is done with a single request
+	cipherPayloads := make([]kDTOs.CipherPayloadDto, 0, 2*len(notes))
+	for _, note := range notes {
+		cipherPayloads = append(cipherPayloads, noteKeyPayloads(note)...)
+	}
+	plainPayloads, err := n.userKeyService.DecryptWithSession(ctx, sessDto, cipherPayloads)
+	if err != nil {
+		return nil, err
+	}
+
+	detailsList := make([]nDTOs.CoreNoteDetailsDto, 0, len(notes))
+	payloadIndex := 0
+	for _, note := range notes {
+		if !note.HasDek() {
+			title, text := plainPayloads[payloadIndex].Data, plainPayloads[payloadIndex+1].Data
+			payloadIndex += 2
+			detailsList = append(detailsList, nDTOs.NewCoreNoteDetailsDto(string(title), string(text)))
+			continue
+		}
+		dek := plainPayloads[payloadIndex].Data
+		payloadIndex++
+		details, err := decryptNoteDetailsWithDek(note, dek)
+		if err != nil {
+			return nil, err
+		}
+		detailsList = append(detailsList, details)
+	}
+	return detailsList, nil
+}
+
+func (n NoteCipherServiceImpl) RewrapNotes(
+	ctx context.Context,
+	grantDto kDTOs.RewrapGrantDto,
+	notes []models.Note,
+) ([]models.Note, error) {
+	cipherPayloads := make([]kDTOs.CipherPayloadDto, 0, 2*len(notes))
+	for _, note := range notes {
+		cipherPayloads = append(cipherPayloads, noteKeyPayloads(note)...)
+	}
+	rewrappedPayloads, err := n.userKeyService.RewrapWithGrant(ctx, grantDto, cipherPayloads)
+	if err != nil {
+		return nil, err
+	}
+
+	rewrappedNotes := make([]models.Note, 0, len(notes))
+	payloadIndex := 0
+	for _, note := range notes {
+		if note.HasDek() {
+			note.WrappedDek = rewrappedPayloads[payloadIndex].Data
+			note.KeyVersion = rewrappedPayloads[payloadIndex].KeyVersion
+			payloadIndex++
+		} else {
+			note.TitleCipher = rewrappedPayloads[payloadIndex].Data
+			note.TextCipher = rewrappedPayloads[payloadIndex+1].Data
+			note.KeyVersion = rewrappedPayloads[payloadIndex].KeyVersion
+			payloadIndex += 2
+		}
+		rewrappedNotes = append(rewrappedNotes, note)
+	}
+	return rewrappedNotes, nil
+}
+
+// noteKeyPayloads returns the payloads of a note decrypted by the user key,
+// which is the wrapped DEK, or the title and text, in that order, for legacy
+// notes
+func noteKeyPayloads(note models.Note) []kDTOs.CipherPayloadDto {
+	if note.HasDek() {
+		return []kDTOs.CipherPayloadDto{kDTOs.NewCipherPayloadDto(note.WrappedDek, note.KeyVersion)}
+	}
+	return []kDTOs.CipherPayloadDto{
+		kDTOs.NewCipherPayloadDto(note.TitleCipher, note.KeyVersion),
+		kDTOs.NewCipherPayloadDto(note.TextCipher, note.KeyVersion),
+	}
+}
+
+func encryptNoteDetailsWithDek(note *models.Note, dek []byte, details nDTOs.CoreNoteDetailsDto) error {
+	titleCipher, err := cipherutils.EncryptAES(dek, []byte(details.Title))
+	if err != nil {
+		return err
+	}
+	textCipher, err := cipherutils.EncryptAES(dek, []byte(details.Text))
+	if err != nil {
+		return err
+	}
+	note.TitleCipher = titleCipher
+	note.TextCipher = textCipher
+	return nil
+}
+
+func decryptNoteDetailsWithDek(note models.Note, dek []byte) (nDTOs.CoreNoteDetailsDto, error) {
+	titleBytes, err := cipherutils.DecryptAES(dek, note.TitleCipher)
+	if err != nil {
+		return nDTOs.CoreNoteDetailsDto{}, err
+	}
+	textBytes, err := cipherutils.DecryptAES(dek, note.TextCipher)
+	if err != nil {
+		return nDTOs.CoreNoteDetailsDto{}, err
+	}
+	return nDTOs.NewCoreNoteDetailsDto(string(titleBytes), string(textBytes)), nil
+}
+
+func NewNoteCipherServiceImpl(userKeyService externalservices.ExtUserKeyService) *NoteCipherServiceImpl {
+	return &NoteCipherServiceImpl{userKeyService: userKeyService}
+}
@@ -11,12 +11,10 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/businessobjects/userbos"
 	cDTOs "github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
-	kDTOs "github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
 	nDTOs "github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/notedtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
-	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
 )
 
 type NoteService interface {
@@ -30,10 +28,11 @@ type NoteService interface {
 		userBo userbos.UserBo,
 		dto cDTOs.UKeySessionReqDto[nDTOs.NoteUpdateDto],
 	) (cDTOs.SuccessDto, error)
+	// DeleteNoteTxn deletes a note if the key session is elevated
 	DeleteNoteTxn(
 		ctx context.Context,
 		userBo userbos.UserBo,
-		noteIdDto nDTOs.NoteIdDto,
+		sessReqDto cDTOs.UKeySessionReqDto[nDTOs.NoteIdDto],
 	) (cDTOs.SuccessDto, error)
 	GetNoteById(
 		ctx context.Context,
@@ -49,11 +48,12 @@ type NoteService interface {
 }
 
 type NoteServiceImpl struct {
-	noteRepository repositories.NoteRepository
-	userKeyService externalservices.ExtUserKeyService
-	crudDSHandler  dshandlers.CrudDSHandler
-	errorService   sharedservices.ErrorService
-	noteBr         businessrules.NoteBr
+	noteRepository    repositories.NoteRepository
+	noteCipherService NoteCipherService
+	extUserKeyService externalservices.ExtUserKeyService
+	crudDSHandler     dshandlers.CrudDSHandler
+	errorService      sharedservices.ErrorService
+	noteBr            businessrules.NoteBr
 }
 
 func (n NoteServiceImpl) AddNoteTxn(
@@ -63,7 +63,7 @@ func (n NoteServiceImpl) AddNoteTxn(
 ) (cDTOs.SuccessDto, error) {
 	return dshandlers.Txn(ctx, n.crudDSHandler,
 		func(_ dshandlers.Session, ctx context.Context) (cDTOs.SuccessDto, error) {
-			return n.AddNoteTxn(ctx, userBo, sessReqDto)
+			return n.addNote(ctx, userBo, sessReqDto)
 		})
 }
 
@@ -73,28 +73,10 @@ func (n NoteServiceImpl) addNote(
 	sessReqDto cDTOs.UKeySessionReqDto[nDTOs.NoteCreateDto],
 ) (cDTOs.SuccessDto, error) {
 	sessDto, noteCreateDto := sessReqDto.SetUserIdAndUnwrap(userBo.Id)
-	keyDto, err := n.userKeyService.GetKeyFromSession(ctx, sessDto)
-	if err != nil {
-		return cDTOs.SuccessDto{}, err
-	}
-	key, err := kDTOs.UserKeyDto.GetKey(keyDto)
-	if err != nil {
+	note := models.Note{UserId: userBo.Id, VaultId: sessDto.GetVaultId()}
+	if err := n.noteCipherService.EncryptNote(ctx, sessDto, &note, noteCreateDto.CoreNoteDetailsDto); err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
-	titleCipher, err := cipherutils.EncryptAES(key, []byte(noteCreateDto.Title))
-	if err != nil {
-		return cDTOs.SuccessDto{}, err
-	}
-	textCipher, err := cipherutils.EncryptAES(key, []byte(noteCreateDto.Text))
-	if err != nil {
-		return cDTOs.SuccessDto{}, err
-	}
-	note := models.Note{
-		UserId:      userBo.Id,
-		TextCipher:  textCipher,
-		TitleCipher: titleCipher,
-		KeyVersion:  keyDto.KeyVersion,
-	}
 	if _, err := n.noteRepository.Create(ctx, note); err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
@@ -122,28 +104,17 @@ func (n NoteServiceImpl) updateNote(
 	if err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
-	keyDto, err := n.userKeyService.GetKeyFromSession(ctx, sessDto)
+	sessionInfoDto, err := n.extUserKeyService.GetKeySessionInfo(ctx, sessDto)
 	if err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
-	if err := n.noteBr.ValidateNoteUpdate(userBo, keyDto, existingNote); err != nil {
-		return cDTOs.SuccessDto{}, err
-	}
-	key, err := keyDto.GetKey()
-	if err != nil {
+	if err := n.noteBr.ValidateNoteUpdate(userBo, sessDto.GetVaultId(), sessionInfoDto, existingNote); err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
-	titleCipher, err := cipherutils.EncryptAES(key, []byte(noteUpdateDto.Title))
+	err = n.noteCipherService.EncryptNote(ctx, sessDto, &existingNote, noteUpdateDto.CoreNoteDetailsDto)
 	if err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
-	textCipher, err := cipherutils.EncryptAES(key, []byte(noteUpdateDto.Text))
-	if err != nil {
-		return cDTOs.SuccessDto{}, err
-	}
-	existingNote.TitleCipher = titleCipher
-	existingNote.TextCipher = textCipher
-	existingNote.KeyVersion = keyDto.KeyVersion
 	if _, err := n.noteRepository.Update(ctx, existingNote); err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
@@ -153,29 +124,39 @@ func (n NoteServiceImpl) updateNote(
 func (n NoteServiceImpl) DeleteNoteTxn(
 	ctx context.Context,
 	userBo userbos.UserBo,
-	noteIdDto nDTOs.NoteIdDto,
+	sessReqDto cDTOs.UKeySessionReqDto[nDTOs.NoteIdDto],
 ) (cDTOs.SuccessDto, error) {
 	return dshandlers.Txn(ctx, n.crudDSHandler,
 		func(_ dshandlers.Session, ctx context.Context) (cDTOs.SuccessDto, error) {
-			return n.deleteNote(ctx, userBo, noteIdDto)
+			return n.deleteNote(ctx, userBo, sessReqDto)
 		})
 }
 
 func (n NoteServiceImpl) deleteNote(
 	ctx context.Context,
 	userBo userbos.UserBo,
-	noteIdDto nDTOs.NoteIdDto,
+	sessReqDto cDTOs.UKeySessionReqDto[nDTOs.NoteIdDto],
 ) (cDTOs.SuccessDto, error) {
+	sessDto, noteIdDto := sessReqDto.SetUserIdAndUnwrap(userBo.Id)
 	existingNote, err := n.getExistingNote(ctx, noteIdDto.Id)
 	if err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
-	if err := n.noteBr.ValidateNoteDelete(userBo, existingNote); err != nil {
+	sessionInfoDto, err := n.extUserKeyService.GetKeySessionInfo(ctx, sessDto)
+	if err != nil {
+		return cDTOs.SuccessDto{}, err
+	}
+	if err := n.noteBr.ValidateNoteDelete(userBo, sessDto.GetVaultId(), sessionInfoDto, existingNote); err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
-	if _, err := n.noteRepository.Delete(ctx, existingNote); err != nil {
+	deleted, err := n.noteRepository.DeleteInVault(ctx, existingNote, sessDto.GetVaultId())
+	if err != nil {
 		return cDTOs.SuccessDto{}, err
 	}
+	if !deleted {
+		ruleErr := n.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+		return cDTOs.SuccessDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
 	return cDTOs.NewSuccessTrue(), nil
 }
 
@@ -189,29 +170,18 @@ func (n NoteServiceImpl) GetNoteById(
 	if err != nil {
 		return nDTOs.NoteReadDto{}, err
 	}
-	keyDto, err := n.userKeyService.GetKeyFromSession(ctx, sessDto)
+	sessionInfoDto, err := n.extUserKeyService.GetKeySessionInfo(ctx, sessDto)
 	if err != nil {
 		return nDTOs.NoteReadDto{}, err
 	}
-	if err := n.noteBr.ValidateNoteRead(userBo, keyDto, existingNote); err != nil {
+	if err := n.noteBr.ValidateNoteRead(userBo, sessDto.GetVaultId(), sessionInfoDto, existingNote); err != nil {
 		return nDTOs.NoteReadDto{}, err
 	}
-	key, err := kDTOs.UserKeyDto.GetKey(keyDto)
+	detailsList, err := n.noteCipherService.DecryptNotes(ctx, sessDto, []models.Note{existingNote})
 	if err != nil {
 		return nDTOs.NoteReadDto{}, err
 	}
-	textBytes, err := cipherutils.DecryptAES(key, existingNote.TextCipher)
-	if err != nil {
-		return nDTOs.NoteReadDto{}, err
-	}
-	text := string(textBytes)
-	titleBytes, err := cipherutils.DecryptAES(key, existingNote.TitleCipher)
-	if err != nil {
-		return nDTOs.NoteReadDto{}, err
-	}
-	title := string(titleBytes)
-
-	coreNoteDetails := nDTOs.NewCoreNoteDetailsDto(title, text)
+	coreNoteDetails := detailsList[0]
 	noteDetailsDto := nDTOs.NoteReadDto{}
 	mappers.MapCoreNoteDetailsAndNoteToNoteReadDto(&coreNoteDetails, &existingNote, &noteDetailsDto)
 	return noteDetailsDto, nil
@@ -223,47 +193,38 @@ func (n NoteServiceImpl) GetNotesPage(
 	sessReqDto cDTOs.UKeySessionReqDto[pagination.PageRequest],
 ) (pagination.Page[nDTOs.NotePreviewDto], error) {
 	sessionDto, pageRequest := sessReqDto.SetUserIdAndUnwrap(userBo.Id)
+	if len(pageRequest.Sort) == 0 && userBo.Preferences.DefaultNoteSort.IsSet() {
+		pageRequest.Sort = []pagination.SortField{userBo.Preferences.DefaultNoteSort.ToSortField()}
+	}
 
 	if err := n.noteBr.ValidateGetNotes(pageRequest); err != nil {
 		return pagination.Page[nDTOs.NotePreviewDto]{}, err
 	}
 
-	keyDto, err := n.userKeyService.GetKeyFromSession(ctx, sessionDto)
-	if err != nil {
-		return pagination.Page[nDTOs.NotePreviewDto]{}, err
-	}
-	key, err := kDTOs.UserKeyDto.GetKey(keyDto)
+	count, err := n.noteRepository.CountByUserIdAndVaultId(ctx, userBo.Id, sessionDto.GetVaultId())
 	if err != nil {
 		return pagination.Page[nDTOs.NotePreviewDto]{}, err
 	}
 
-	count, err := n.noteRepository.CountByUserId(ctx, userBo.Id)
+	notes, err := n.noteRepository.GetPaginatedByUserIdAndVaultId(
+		ctx,
+		userBo.Id,
+		sessionDto.GetVaultId(),
+		pageRequest,
+	)
 	if err != nil {
 		return pagination.Page[nDTOs.NotePreviewDto]{}, err
 	}
 
-	notes, err := n.noteRepository.GetPaginatedByUserId(ctx, userBo.Id, pageRequest)
+	detailsList, err := n.noteCipherService.DecryptNotes(ctx, sessionDto, notes)
 	if err != nil {
 		return pagination.Page[nDTOs.NotePreviewDto]{}, err
 	}
 
 	noteDTOs := make([]nDTOs.NotePreviewDto, 0, len(notes))
-	for _, note := range notes {
-		if note.KeyVersion != keyDto.KeyVersion {
-			ruleErr := n.errorService.RuleErrorFromCode(apperrors.ErrCodeDataRace)
-			return pagination.Page[nDTOs.NotePreviewDto]{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
-		}
-		txtBytes, err := cipherutils.DecryptAES(key, note.TextCipher)
-		if err != nil {
-			return pagination.Page[nDTOs.NotePreviewDto]{}, err
-		}
-		titleBytes, err := cipherutils.DecryptAES(key, note.TitleCipher)
-		if err != nil {
-			return pagination.Page[nDTOs.NotePreviewDto]{}, err
-		}
-		txt, title := string(txtBytes), string(titleBytes)
-		textPreview := utils.StringFirstNChars(txt, 60)
-		coreNoteDto := nDTOs.NewCoreNoteDto(title)
+	for i, note := range notes {
+		textPreview := utils.StringFirstNChars(detailsList[i].Text, 60)
+		coreNoteDto := detailsList[i].CoreNoteDto
 		noteReadDto := nDTOs.NotePreviewDto{}
 		mappers.MapTextPreviewAndCoreNoteAndNoteToNotePreviewDto(textPreview, &coreNoteDto, &note, &noteReadDto)
 		noteDTOs = append(noteDTOs, noteReadDto)
@@ -290,16 +251,18 @@ func (n NoteServiceImpl) getExistingNote(ctx context.Context, id string) (models
 
 func NewNoteServiceImpl(
 	noteRepository repositories.NoteRepository,
-	userKeyService externalservices.ExtUserKeyService,
+	noteCipherService NoteCipherService,
+	extUserKeyService externalservices.ExtUserKeyService,
 	crudDSHandler dshandlers.CrudDSHandler,
 	errorService sharedservices.ErrorService,
 	noteBr businessrules.NoteBr,
 ) *NoteServiceImpl {
 	return &NoteServiceImpl{
-		noteRepository: noteRepository,
-		userKeyService: userKeyService,
-		crudDSHandler:  crudDSHandler,
-		errorService:   errorService,
-		noteBr:         noteBr,
+		noteRepository:    noteRepository,
+		noteCipherService: noteCipherService,
+		extUserKeyService: extUserKeyService,
+		crudDSHandler:     crudDSHandler,
+		errorService:      errorService,
+		noteBr:            noteBr,
 	}
 }
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/noteservice/mappers"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/noteservice/repositories"
+	nDTOs "github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/notedtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+)
+
+type UserDataExportEventService interface {
+	// HandleUserDataExportRequest sends the user service the metadata of the
+	// user's notes. The titles and text are encrypted by the user's key so they
+	// are left out.
+	HandleUserDataExportRequest(ctx context.Context, requestDto userdtos.UserDataExportRequestDto) error
+
+	// HandleUserDataExportRequestFailure replies to the user service that the
+	// export failed once it will no longer be retried
+	HandleUserDataExportRequestFailure(
+		ctx context.Context,
+		requestDto userdtos.UserDataExportRequestDto,
+		cause error,
+	) error
+}
+
+type UserDataExportEventServiceImpl struct {
+	noteRepository                   repositories.NoteRepository
+	userDataExportPartMsgSendService sharedservices.UserDataExportPartMsgSendService
+}
+
+func (u UserDataExportEventServiceImpl) HandleUserDataExportRequest(
+	ctx context.Context,
+	requestDto userdtos.UserDataExportRequestDto,
+) error {
+	notes, err := u.noteRepository.GetMetadataByUserId(ctx, requestDto.UserId)
+	if err != nil {
+		return err
+	}
+	exportDto := nDTOs.NoteDataExportDto{Notes: make([]nDTOs.NoteExportDto, 0, len(notes))}
+	for i := range notes {
+		noteExportDto := nDTOs.NoteExportDto{}
+		mappers.MapNoteToNoteExportDto(&notes[i], &noteExportDto)
+		exportDto.Notes = append(exportDto.Notes, noteExportDto)
+	}
+	data, err := json.Marshal(exportDto)
+	if err != nil {
+		return err
+	}
+	partDto := userdtos.UserDataExportPartDto{
+		ExportId:    requestDto.ExportId,
+		UserId:      requestDto.UserId,
+		Participant: userdtos.UserDataExportParticipantNoteService,
+		Succeeded:   true,
+		Data:        data,
+	}
+	return u.userDataExportPartMsgSendService.SendUserDataExportPart(ctx, partDto)
+}
+
+func (u UserDataExportEventServiceImpl) HandleUserDataExportRequestFailure(
+	ctx context.Context,
+	requestDto userdtos.UserDataExportRequestDto,
+	cause error,
+) error {
+	partDto := userdtos.UserDataExportPartDto{
+		ExportId:    requestDto.ExportId,
+		UserId:      requestDto.UserId,
+		Participant: userdtos.UserDataExportParticipantNoteService,
+		Succeeded:   false,
+		Error:       cause.Error(),
+	}
+	return u.userDataExportPartMsgSendService.SendUserDataExportPart(ctx, partDto)
+}
+
+func NewUserDataExportEventServiceImpl(
+	noteRepository repositories.NoteRepository,
+	userDataExportPartMsgSendService sharedservices.UserDataExportPartMsgSendService,
+) *UserDataExportEventServiceImpl {
+	return &UserDataExportEventServiceImpl{
+		noteRepository:                   noteRepository,
+		userDataExportPartMsgSendService: userDataExportPartMsgSendService,
+	}
+}
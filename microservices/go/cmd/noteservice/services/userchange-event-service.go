@@ -12,23 +12,55 @@ type UserChangeEventService interface {
 		ctx context.Context,
 		userEventDto userdtos.UserChangeEventDto,
 	) (userdtos.UserChangeEventResponseDto, error)
+
+	// HandleUserChangeEventFailure replies to the user service that a user
+	// deletion failed once it will no longer be retried
+	HandleUserChangeEventFailure(ctx context.Context, userEventDto userdtos.UserChangeEventDto, cause error) error
 }
 
 type UserChangeEventServiceImpl struct {
-	userService   sharedservices.UserService
-	crudDSHandler dshandlers.CrudDSHandler
-	noteService   NoteService
+	userService                 sharedservices.UserService
+	userDeleteAckMsgSendService sharedservices.UserDeleteAckMsgSendService
+	crudDSHandler               dshandlers.CrudDSHandler
+	noteService                 NoteService
 }
 
 func (u UserChangeEventServiceImpl) HandleUserChangeEventTxn(
 	ctx context.Context,
 	userEventDto userdtos.UserChangeEventDto,
 ) (userdtos.UserChangeEventResponseDto, error) {
-	return dshandlers.Txn(ctx, u.crudDSHandler,
+	res, err := dshandlers.Txn(ctx, u.crudDSHandler,
 		func(session dshandlers.Session, ctx context.Context) (userdtos.UserChangeEventResponseDto, error) {
 			return u.HandleUserChangeEvent(ctx, userEventDto)
 		},
 	)
+	if err != nil || userEventDto.Action != userdtos.UserDelete {
+		return res, err
+	}
+	// The deletion is only acknowledged once it is committed
+	ackDto := userdtos.UserDeleteAckDto{
+		UserId:      userEventDto.Id,
+		Participant: userdtos.UserDeleteParticipantNoteService,
+		Succeeded:   true,
+	}
+	return res, u.userDeleteAckMsgSendService.SendUserDeleteAck(ctx, ackDto)
+}
+
+func (u UserChangeEventServiceImpl) HandleUserChangeEventFailure(
+	ctx context.Context,
+	userEventDto userdtos.UserChangeEventDto,
+	cause error,
+) error {
+	if userEventDto.Action != userdtos.UserDelete {
+		return nil
+	}
+	ackDto := userdtos.UserDeleteAckDto{
+		UserId:      userEventDto.Id,
+		Participant: userdtos.UserDeleteParticipantNoteService,
+		Succeeded:   false,
+		Error:       cause.Error(),
+	}
+	return u.userDeleteAckMsgSendService.SendUserDeleteAck(ctx, ackDto)
 }
 
 func (u UserChangeEventServiceImpl) HandleUserChangeEvent(
@@ -53,12 +85,14 @@ func (u UserChangeEventServiceImpl) HandleUserChangeEvent(
 
 func NewUserChangeEventServiceImpl(
 	userService sharedservices.UserService,
+	userDeleteAckMsgSendService sharedservices.UserDeleteAckMsgSendService,
 	crudDSHandler dshandlers.CrudDSHandler,
 	noteService NoteService,
 ) *UserChangeEventServiceImpl {
 	return &UserChangeEventServiceImpl{
-		userService:   userService,
-		crudDSHandler: crudDSHandler,
-		noteService:   noteService,
+		userService:                 userService,
+		userDeleteAckMsgSendService: userDeleteAckMsgSendService,
+		crudDSHandler:               crudDSHandler,
+		noteService:                 noteService,
 	}
 }
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/noteservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+)
+
+const rewrapBatchSize = 100
+
+type UserKeyRotationService interface {
+	// HandleUserKeyRotation rewraps the notes of the rotated vault with the
+	// note service's rewrap grant and acknowledges the rotation once every note is rewrapped
+	HandleUserKeyRotation(ctx context.Context, rotationDto keydtos.UserKeyRotationEventDto) error
+}
+
+type UserKeyRotationServiceImpl struct {
+	noteRepository        repositories.NoteRepository
+	noteCipherService     NoteCipherService
+	userKeyMsgSendService sharedservices.UserKeyMsgSendService
+	crudDSHandler         dshandlers.CrudDSHandler
+}
+
+func (u UserKeyRotationServiceImpl) HandleUserKeyRotation(
+	ctx context.Context,
+	rotationDto keydtos.UserKeyRotationEventDto,
+) error {
+	grantDto, ok := rotationDto.GetGrant(keydtos.KeyRotationConsumerNoteService)
+	if !ok {
+		logger.Log.WithContext(ctx).Warnf("Key rotation %v has no rewrap grant for the note service", rotationDto.KeyVersion)
+		return nil
+	}
+	// Notes rewrapped by a newer rotation must not be rewrapped with an older
+	// key, so a stale or redelivered rotation is already done
+	superseded, err := u.noteRepository.ExistsByUserIdAndVaultIdAfterKeyVersion(
+		ctx,
+		rotationDto.UserId,
+		rotationDto.VaultId,
+		rotationDto.KeyVersion,
+	)
+	if err != nil {
+		return err
+	}
+	if superseded {
+		logger.Log.WithContext(ctx).Infof("Key rotation %v was superseded by a newer rotation", rotationDto.KeyVersion)
+		return nil
+	}
+	for {
+		rewrappedCount, err := dshandlers.Txn(ctx, u.crudDSHandler,
+			func(_ dshandlers.Session, ctx context.Context) (int, error) {
+				return u.rewrapNotesBatch(ctx, rotationDto, grantDto)
+			})
+		if err != nil {
+			return err
+		}
+		if rewrappedCount == 0 {
+			break
+		}
+	}
+
+	logger.Log.WithContext(ctx).Debugf("Rewrapped notes for key version %v", rotationDto.KeyVersion)
+	return u.userKeyMsgSendService.SendUserKeyRotationAck(ctx, keydtos.UserKeyRotationAckDto{
+		UserId:     rotationDto.UserId,
+		VaultId:    rotationDto.VaultId,
+		KeyVersion: rotationDto.KeyVersion,
+		Consumer:   keydtos.KeyRotationConsumerNoteService,
+		GrantId:    grantDto.GrantId,
+	})
+}
+
+func (u UserKeyRotationServiceImpl) rewrapNotesBatch(
+	ctx context.Context,
+	rotationDto keydtos.UserKeyRotationEventDto,
+	grantDto keydtos.RewrapGrantDto,
+) (int, error) {
+	notes, err := u.noteRepository.GetByUserIdAndVaultIdBeforeKeyVersion(
+		ctx,
+		rotationDto.UserId,
+		rotationDto.VaultId,
+		rotationDto.KeyVersion,
+		rewrapBatchSize,
+	)
+	if err != nil || len(notes) == 0 {
+		return 0, err
+	}
+
+	rewrappedNotes, err := u.noteCipherService.RewrapNotes(ctx, grantDto, notes)
+	if err != nil {
+		return 0, err
+	}
+	for _, note := range rewrappedNotes {
+		if note.KeyVersion != rotationDto.KeyVersion {
+			return 0, fmt.Errorf(
+				"rewrap grant has key version %v instead of %v",
+				note.KeyVersion,
+				rotationDto.KeyVersion,
+			)
+		}
+		if _, err := u.noteRepository.Update(ctx, note); err != nil {
+			return 0, err
+		}
+	}
+	return len(notes), nil
+}
+
+func NewUserKeyRotationServiceImpl(
+	noteRepository repositories.NoteRepository,
+	noteCipherService NoteCipherService,
+	userKeyMsgSendService sharedservices.UserKeyMsgSendService,
+	crudDSHandler dshandlers.CrudDSHandler,
+) *UserKeyRotationServiceImpl {
+	return &UserKeyRotationServiceImpl{
+		noteRepository:        noteRepository,
+		noteCipherService:     noteCipherService,
+		userKeyMsgSendService: userKeyMsgSendService,
+		crudDSHandler:         crudDSHandler,
+	}
+}
@@ -15,4 +15,15 @@ export function createUserKey(payload: PasscodeCreateDto): Promise<AxiosResponse
 export function newKeySession(payload: PasscodeDto): Promise<AxiosResponse<SuccessDto>> {
   return axios.post<SuccessDto, AxiosResponse<SuccessDto>, PasscodeDto>(
     `${prefix}/v1/userKey/newSession`, payload)
-}
\ No newline at end of file
+}
+// Re-entering the passcode elevates the current key session for a few minutes,
+// which sensitive operations such as deleting notes require
+export function elevateKeySession(payload: PasscodeDto): Promise<AxiosResponse<SessionElevationDto>> {
+  return axios.post<SessionElevationDto, AxiosResponse<SessionElevationDto>, PasscodeDto>(
+    `${prefix}/v1/userKey/elevateSession?passUserKeySession=true`, payload)
+}
+
+export function registerDuressPasscode(payload: DuressPasscodeCreateDto): Promise<AxiosResponse<SuccessDto>> {
+  return axios.post<SuccessDto, AxiosResponse<SuccessDto>, DuressPasscodeCreateDto>(
+    `${prefix}/v1/userKey/duress`, payload)
+}
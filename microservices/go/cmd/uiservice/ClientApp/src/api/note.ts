@@ -1,5 +1,6 @@
 import axios from '../axios'
-import {AxiosResponse} from "axios";
+import {AxiosError, AxiosResponse} from "axios";
+import {elevateKeySession} from "./key";
 
 const prefix = "/api/noteservice"
 
@@ -11,7 +12,31 @@ export function updateNote(payload: NoteUpdateDto): Promise<AxiosResponse<Succes
   return axios.put<SuccessDto, AxiosResponse<SuccessDto>, NoteUpdateDto>(`${prefix}/v1/notes`, payload)
 }
 export function deleteNote(payload: NoteIdDto): Promise<AxiosResponse<SuccessDto>> {
-  return axios.delete<SuccessDto, AxiosResponse<SuccessDto>, NoteIdDto>(`${prefix}/v1/notes`, {data: payload})
+  return axios.delete<SuccessDto, AxiosResponse<SuccessDto>, NoteIdDto>(`${prefix}/v1/notes?passUserKeySession=true`,
+    {data: payload})
+}
+
+// Deleting a note requires an elevated key session. If the session is not
+// elevated, the user is asked for their passcode to elevate it and the delete
+// is retried once.
+export async function deleteNoteWithElevation(
+  payload: NoteIdDto,
+  promptPasscode: () => Promise<PasscodeDto>,
+): Promise<AxiosResponse<SuccessDto>> {
+  try {
+    return await deleteNote(payload)
+  } catch (e) {
+    if (!isSessionNotElevated(e)) {
+      throw e
+    }
+    await elevateKeySession(await promptPasscode())
+    return await deleteNote(payload)
+  }
+}
+
+function isSessionNotElevated(e: unknown): boolean {
+  const data = (e as AxiosError<BadRequestErrorDto>)?.response?.data
+  return !!data?.ruleErrors?.some(ruleErr => ruleErr.code === "SessionNotElevated")
 }
 
 export function getNoteById(payload: NoteIdDto): Promise<AxiosResponse<NoteReadDto>> {
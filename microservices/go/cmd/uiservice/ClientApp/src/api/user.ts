@@ -11,10 +11,46 @@ export function updateUser(payload: UserSaveDto): Promise<AxiosResponse<UserRead
   return axios.put<UserReadDto, AxiosResponse<UserReadDto>, UserSaveDto>(`${prefix}/v1/user`, payload)
 }
 
+export function updateUserProfile(payload: UserProfileSaveDto): Promise<AxiosResponse<UserReadDto>> {
+  return axios.put<UserReadDto, AxiosResponse<UserReadDto>, UserProfileSaveDto>(`${prefix}/v1/user/profile`, payload)
+}
+
+export function updateUserPreferences(payload: UserPreferencesSaveDto): Promise<AxiosResponse<UserReadDto>> {
+  return axios.put<UserReadDto, AxiosResponse<UserReadDto>, UserPreferencesSaveDto>(
+    `${prefix}/v1/user/preferences`, payload)
+}
+
+export function setUserAvatar(avatar: Blob): Promise<AxiosResponse<UserReadDto>> {
+  const form = new FormData()
+  form.append("avatar", avatar)
+  return axios.put<UserReadDto, AxiosResponse<UserReadDto>, FormData>(`${prefix}/v1/user/avatar`, form)
+}
+
+export function removeUserAvatar(): Promise<AxiosResponse<UserReadDto>> {
+  return axios.delete<UserReadDto>(`${prefix}/v1/user/avatar`)
+}
+
+export function userAvatarUrl(avatarId: string): string {
+  return `${prefix}/v1/user/avatar/${avatarId}`
+}
+
 export function deleteUser(): Promise<AxiosResponse<UserReadDto>> {
   return axios.delete<UserReadDto>(`${prefix}/v1/user`)
 }
 
 export function getIdentity(): Promise<AxiosResponse<UserIdentityDto>> {
   return axios.get<UserIdentityDto>(`${prefix}/v1/user/me`)
-}
\ No newline at end of file
+}
+
+export function requestDataExport(): Promise<AxiosResponse<UserDataExportDto>> {
+  return axios.post<UserDataExportDto>(`${prefix}/v1/dataExport`)
+}
+
+// Poll until the export is completed, then download it
+export function getDataExport(): Promise<AxiosResponse<UserDataExportDto>> {
+  return axios.get<UserDataExportDto>(`${prefix}/v1/dataExport`)
+}
+
+export function downloadDataExport(): Promise<AxiosResponse<Blob>> {
+  return axios.get<Blob>(`${prefix}/v1/dataExport/download`, { responseType: 'blob' })
+}
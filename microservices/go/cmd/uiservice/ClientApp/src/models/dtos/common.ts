@@ -5,3 +5,13 @@ interface SuccessDto {
 interface ExistsDto {
   exists: boolean
 }
+
+interface RuleErrorDto {
+  code: string
+  message: string
+}
+
+interface BadRequestErrorDto {
+  ruleErrors: RuleErrorDto[]
+  validationErrors: { field: string, rule?: string, message: string }[]
+}
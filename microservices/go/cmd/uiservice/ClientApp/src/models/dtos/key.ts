@@ -1,7 +1,31 @@
 interface PasscodeCreateDto {
   passcode: string
+  vaultName?: string
 }
 
 interface PasscodeDto {
   passcode: string
-}
\ No newline at end of file
+  totpCode?: string
+}
+
+interface DuressPasscodeCreateDto {
+  passcode: string
+  totpCode?: string
+  duressPasscode: string
+  silentAction?: "none" | "revokeSessions"
+  decoyNotes: DecoyNoteDto[]
+}
+
+interface DecoyNoteDto {
+  title: string
+  text: string
+}
+
+interface VaultDto {
+  vaultId: string
+  name: string
+}
+
+interface SessionElevationDto {
+  expiresAt: number
+}
@@ -5,4 +5,22 @@ interface UserIdentityDto extends UserReadDto, BaseUserAuthId {
 interface UserReadDto extends BaseUserPublicDto, ExistsDto {
 }
 
-interface UserSaveDto extends BaseUserCommon {}
\ No newline at end of file
+interface UserSaveDto extends BaseUserCommon {}
+
+interface UserProfileSaveDto extends BaseUserProfile {}
+
+interface UserPreferencesSaveDto extends UserPreferences {}
+
+interface UserDataExportParticipantDto {
+  participant: string,
+  status: "pending" | "failed" | "completed",
+  error: string,
+  updatedAt: number
+}
+
+interface UserDataExportDto extends BaseTimestamp {
+  id: string,
+  status: "pending" | "failed" | "completed",
+  participants: UserDataExportParticipantDto[],
+  expiresAt: number
+}
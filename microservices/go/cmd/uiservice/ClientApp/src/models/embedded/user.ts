@@ -7,4 +7,24 @@ interface BaseUserAuthId {
   authId: string,
 }
 
-interface BaseUserPublicDto extends BaseUserCommon, BaseCRUDObject {}
\ No newline at end of file
+interface BaseUserProfile {
+  timezone: string,
+  locale: string,
+  bio: string
+}
+
+interface NoteSortPreference {
+  field: "" | "createdAt" | "updatedAt",
+  direction: "" | "asc" | "desc"
+}
+
+interface UserPreferences {
+  defaultNoteSort: NoteSortPreference,
+  keySessionMinutes: number,
+  theme: "" | "system" | "light" | "dark"
+}
+
+interface BaseUserPublicDto extends BaseUserCommon, BaseUserProfile, BaseCRUDObject {
+  preferences: UserPreferences,
+  avatarId: string
+}
\ No newline at end of file
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/listeners"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/servers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
 )
@@ -11,6 +12,6 @@ func (a App) Start() {
 	lifecycle.RunApp()
 }
 
-func NewApp(_ servers.AppServer) *App {
+func NewApp(_ servers.AppServer, _ listeners.KafkaListener) *App {
 	return &App{}
 }
@@ -6,6 +6,7 @@ package app
 import (
 	"github.com/google/wire"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/controllers"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/listeners"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/middlewares"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/repositories"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/servers"
@@ -19,6 +20,8 @@ import (
 
 func InitializeApp() *App {
 	wire.Build(
+		conf.NewKafkaConfImpl,
+		wire.Bind(new(conf.KafkaConf), new(*conf.KafkaConfImpl)),
 		conf.NewRedisConfImpl,
 		wire.Bind(new(conf.RedisConf), new(*conf.RedisConfImpl)),
 		conf.NewExternalAppServerConfImpl,
@@ -44,12 +47,20 @@ func InitializeApp() *App {
 		wire.Bind(new(services.AuthenticatorService), new(*services.AuthenticatorServiceImpl)),
 		services.NewAccessTokenStoreServiceImpl,
 		wire.Bind(new(services.AccessTokenStoreService), new(*services.AccessTokenStoreServiceImpl)),
+		services.NewUserChangeEventServiceImpl,
+		wire.Bind(new(services.UserChangeEventService), new(*services.UserChangeEventServiceImpl)),
+		sharedservices.NewUserDataExportPartMsgSendServiceImpl,
+		wire.Bind(new(sharedservices.UserDataExportPartMsgSendService), new(*sharedservices.UserDataExportPartMsgSendServiceImpl)),
+		services.NewUserDataExportEventServiceImpl,
+		wire.Bind(new(services.UserDataExportEventService), new(*services.UserDataExportEventServiceImpl)),
 		middlewares.NewSessionMiddlewareImpl,
 		wire.Bind(new(middlewares.SessionMiddleware), new(*middlewares.SessionMiddlewareImpl)),
 		middlewares.NewBearerAuthMiddlewareImpl,
 		wire.Bind(new(middlewares.BearerAuthMiddleware), new(*middlewares.BearerAuthMiddlewareImpl)),
 		middlewares.NewUiProviderMiddlewareImpl,
 		wire.Bind(new(middlewares.UiProviderMiddleware), new(*middlewares.UiProviderMiddlewareImpl)),
+		middlewares.NewClientBindingMiddlewareImpl,
+		wire.Bind(new(middlewares.ClientBindingMiddleware), new(*middlewares.ClientBindingMiddlewareImpl)),
 		middlewares.NewUserKeyMiddlewareImpl,
 		wire.Bind(new(middlewares.UserKeyMiddleware), new(*middlewares.UserKeyMiddlewareImpl)),
 		controllers.NewAuthControllerImpl,
@@ -60,6 +71,12 @@ func InitializeApp() *App {
 		wire.Bind(new(controllers.GatewayController), new(*controllers.GatewayControllerImpl)),
 		servers.NewAppServerImpl,
 		wire.Bind(new(servers.AppServer), new(*servers.AppServerImpl)),
+		listeners.NewUserChange1ListenerImpl,
+		wire.Bind(new(listeners.UserChange1Listener), new(*listeners.UserChange1ListenerImpl)),
+		listeners.NewUserDataExportListenerImpl,
+		wire.Bind(new(listeners.UserDataExportListener), new(*listeners.UserDataExportListenerImpl)),
+		listeners.NewKafkaListenerImpl,
+		wire.Bind(new(listeners.KafkaListener), new(*listeners.KafkaListenerImpl)),
 		NewApp)
 	return &App{}
 }
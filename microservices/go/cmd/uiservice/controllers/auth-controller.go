@@ -3,7 +3,6 @@ package controllers
 import (
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/security"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/services"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
@@ -14,7 +13,6 @@ import (
 	"golang.org/x/oauth2"
 	"net/http"
 	"net/url"
-	"strings"
 )
 
 type AuthController interface {
@@ -68,14 +66,12 @@ func (a AuthControllerImpl) AddRoutes(r *gin.Engine) {
 			return
 		}
 
-		randomUUID, err := uuid.NewRandom()
+		tokenId, err := a.accessTokenStoreService.NewTokenId(idToken.Subject)
 		if err != nil {
 			a.sendInternalServerError(c, err)
 			return
 		}
 
-		tokenId := strings.Join([]string{idToken.Subject, randomUUID.String()}, "/")
-
 		session.Set(security.TokenIdSessionKey, tokenId)
 		err = a.accessTokenStoreService.StoreToken(c, tokenId, token.AccessToken)
 		if err != nil {
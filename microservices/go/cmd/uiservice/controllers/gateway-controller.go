@@ -26,15 +26,17 @@ type GatewayController interface {
 }
 
 type GatewayControllerImpl struct {
-	externalAppServerConf conf.ExternalAppServerConf
-	bearerAuthMiddleware  middlewares.BearerAuthMiddleware
-	userKeyMiddleware     middlewares.UserKeyMiddleware
-	tlsConf               conf.TLSConf
+	externalAppServerConf   conf.ExternalAppServerConf
+	bearerAuthMiddleware    middlewares.BearerAuthMiddleware
+	userKeyMiddleware       middlewares.UserKeyMiddleware
+	clientBindingMiddleware middlewares.ClientBindingMiddleware
+	tlsConf                 conf.TLSConf
 }
 
 func (g GatewayControllerImpl) AddRoutes(r *gin.Engine) {
 	apiGroup := r.Group("/api",
 		g.bearerAuthMiddleware.PassBearerTokenFromSession(),
+		g.clientBindingMiddleware.PassClientBinding(),
 		g.userKeyMiddleware.UserKeySession(),
 	)
 
@@ -44,40 +46,18 @@ func (g GatewayControllerImpl) AddRoutes(r *gin.Engine) {
 	apiGroup.Any("/keyservice/*proxyPath",
 		g.proxyHandlerWithModifyResp("proxyPath", g.externalAppServerConf.GetKeyServiceAddress(),
 			func(res *http.Response, destPath string, c *gin.Context) error {
-				// Only apply to paths starting with "v1/userKey/newSession"
-				if !strings.HasPrefix(destPath, "v1/userKey/newSession") {
+				switch {
+				case strings.HasPrefix(destPath, "v1/userKey/newSession"),
+					strings.HasPrefix(destPath, "v1/userKey/srp/newSession"):
+					return g.storeKeySessionFromResp(res, c,
+						func(originalBytes []byte) (commondtos.UKeySessionDto, any, error) {
+							sessionDto := commondtos.UKeySessionDto{}
+							err := json.Unmarshal(originalBytes, &sessionDto)
+							return sessionDto, commondtos.NewSuccessTrue(), err
+						})
+				default:
 					return nil
 				}
-				// original bytes to session dto
-				originalBytes, err := ioutil.ReadAll(res.Body) //Read html
-				if err != nil {
-					return err
-				}
-				if err = res.Body.Close(); err != nil {
-					return err
-				}
-				sessionDto := commondtos.UKeySessionDto{}
-				if err = json.Unmarshal(originalBytes, &sessionDto); err != nil {
-					return err
-				}
-
-				// write session dto to session
-				session := sessions.Default(c)
-				session.Set(security.UKeySessionKey, sessionDto)
-				if err := session.Save(); err != nil {
-					return err
-				}
-
-				// replace body with a success dto
-				newBytes, err := json.Marshal(commondtos.NewSuccessTrue())
-				if err != nil {
-					return err
-				}
-				body := ioutil.NopCloser(bytes.NewReader(newBytes))
-				res.Body = body
-				res.ContentLength = int64(len(newBytes))
-				res.Header.Set("Content-Length", strconv.Itoa(len(newBytes)))
-				return nil
 			},
 		))
 
@@ -85,6 +65,49 @@ func (g GatewayControllerImpl) AddRoutes(r *gin.Engine) {
 		g.proxyHandler("proxyPath", g.externalAppServerConf.GetNoteServiceAddress()))
 }
 
+// storeKeySessionFromResp writes the key session read from a successful
+// keyservice response to the web session, then replaces the response body so
+// the session token never reaches the browser.
+func (g GatewayControllerImpl) storeKeySessionFromResp(
+	res *http.Response,
+	c *gin.Context,
+	readSession func(originalBytes []byte) (sessionDto commondtos.UKeySessionDto, newBody any, err error),
+) error {
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+	// original bytes to session dto
+	originalBytes, err := ioutil.ReadAll(res.Body) //Read html
+	if err != nil {
+		return err
+	}
+	if err = res.Body.Close(); err != nil {
+		return err
+	}
+	sessionDto, newBody, err := readSession(originalBytes)
+	if err != nil {
+		return err
+	}
+
+	// write session dto to session
+	session := sessions.Default(c)
+	session.Set(security.UKeySessionKey, sessionDto)
+	if err := session.Save(); err != nil {
+		return err
+	}
+
+	// replace body with the new body
+	newBytes, err := json.Marshal(newBody)
+	if err != nil {
+		return err
+	}
+	body := ioutil.NopCloser(bytes.NewReader(newBytes))
+	res.Body = body
+	res.ContentLength = int64(len(newBytes))
+	res.Header.Set("Content-Length", strconv.Itoa(len(newBytes)))
+	return nil
+}
+
 func (g GatewayControllerImpl) proxyHandler(destPathUrlParam string, destAddr string) gin.HandlerFunc {
 	return g.proxyHandlerWithModifyResp(destPathUrlParam, destAddr, nil)
 }
@@ -147,12 +170,14 @@ func NewGatewayControllerImpl(
 	externalAppServerConf conf.ExternalAppServerConf,
 	bearerAuthMiddleware middlewares.BearerAuthMiddleware,
 	userKeyMiddleware middlewares.UserKeyMiddleware,
+	clientBindingMiddleware middlewares.ClientBindingMiddleware,
 	tlsConf conf.TLSConf,
 ) *GatewayControllerImpl {
 	return &GatewayControllerImpl{
-		bearerAuthMiddleware:  bearerAuthMiddleware,
-		userKeyMiddleware:     userKeyMiddleware,
-		externalAppServerConf: externalAppServerConf,
-		tlsConf:               tlsConf,
+		bearerAuthMiddleware:    bearerAuthMiddleware,
+		userKeyMiddleware:       userKeyMiddleware,
+		clientBindingMiddleware: clientBindingMiddleware,
+		externalAppServerConf:   externalAppServerConf,
+		tlsConf:                 tlsConf,
 	}
 }
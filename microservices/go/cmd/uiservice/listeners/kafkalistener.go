@@ -0,0 +1,39 @@
+package listeners
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+)
+
+type KafkaListener interface {
+	lifecycle.TaskRunner
+}
+
+type KafkaListenerImpl struct {
+	userChange1Listener    UserChange1Listener
+	userDataExportListener UserDataExportListener
+}
+
+func (k KafkaListenerImpl) Run() {
+	k.userChange1Listener.ListenUserChange()
+	k.userDataExportListener.ListenUserDataExport()
+	forever := make(chan any)
+	<-forever
+}
+
+func NewKafkaListenerImpl(
+	userChange1Listener UserChange1Listener,
+	userDataExportListener UserDataExportListener,
+) *KafkaListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+	r := &KafkaListenerImpl{
+		userChange1Listener:    userChange1Listener,
+		userDataExportListener: userDataExportListener,
+	}
+	lifecycle.RegisterTaskRunner(r)
+	return r
+}
@@ -0,0 +1,69 @@
+package listeners
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+)
+
+type UserChange1Listener interface {
+	lifecycle.Closable
+	ListenUserChange()
+}
+
+type UserChange1ListenerImpl struct {
+	userChangeEventService services.UserChangeEventService
+	user1Consumer          *kfka.RetryingConsumer[userdtos.UserChangeEventDto]
+}
+
+func (k UserChange1ListenerImpl) ListenUserChange() {
+	k.user1Consumer.Listen(
+		func(ctx context.Context, dto userdtos.UserChangeEventDto) error {
+			err := k.userChangeEventService.HandleUserChangeEvent(ctx, dto)
+			if err != nil {
+				logger.Log.WithError(err).Error("Error handling user change")
+			}
+			return err
+		},
+		nil,
+	)
+	logger.Log.Info("Listening for user changes")
+}
+
+func (k UserChange1ListenerImpl) Close() error {
+	logger.Log.Info("Closing user listener")
+	err := k.user1Consumer.Close()
+	if err != nil {
+		logger.Log.WithError(err).Error("Errors closing listener")
+	}
+	logger.Log.Info("Finish closing user listener")
+	return err
+}
+
+func NewUserChange1ListenerImpl(
+	userChangeEventService services.UserChangeEventService,
+	kafkaConf conf.KafkaConf,
+) *UserChange1ListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+
+	user1Consumer := kfka.NewRetryingConsumer(
+		kafkaConf,
+		retrypolicies.NewUserChange1UiServicePolicy(),
+		userdtos.UserChangeEventDto.MessageKey,
+	)
+
+	return &UserChange1ListenerImpl{
+		userChangeEventService: userChangeEventService,
+		user1Consumer:          user1Consumer,
+	}
+}
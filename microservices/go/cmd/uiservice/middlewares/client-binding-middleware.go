@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/routing"
+	"net/http"
+	"time"
+)
+
+// deviceIdCookieMaxAge is how long a browser keeps its device ID, which
+// outlives the web sessions bound to it
+const deviceIdCookieMaxAge = 365 * 24 * time.Hour
+
+type ClientBindingMiddleware interface {
+	// PassClientBinding sets the device ID and IP address of the client as
+	// request headers so key sessions can be bound to the client. The IP address
+	// is the one the server observes, and the device ID is read from a cookie
+	// kept apart from the web session so stealing a web session cookie does not
+	// also steal the device ID. A device ID is assigned if the client has none.
+	PassClientBinding() gin.HandlerFunc
+}
+
+type ClientBindingMiddlewareImpl struct {
+}
+
+func (cb ClientBindingMiddlewareImpl) PassClientBinding() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deviceId, err := c.Cookie(security.DeviceIdCookieName)
+		if err != nil || utils.StringIsBlank(deviceId) {
+			deviceId = cb.assignDeviceId(c)
+		}
+
+		// Overwrite any values sent by the client
+		if utils.StringIsBlank(deviceId) {
+			c.Request.Header.Del(routing.HeaderClientDeviceId)
+		} else {
+			c.Request.Header.Set(routing.HeaderClientDeviceId, deviceId)
+		}
+		c.Request.Header.Set(routing.HeaderClientIp, c.ClientIP())
+		c.Next()
+	}
+}
+
+// assignDeviceId sets a new device ID cookie. A blank ID is returned if one
+// cannot be generated, in which case the request continues unbound.
+func (cb ClientBindingMiddlewareImpl) assignDeviceId(c *gin.Context) string {
+	deviceIdUUID, err := uuid.NewRandom()
+	if err != nil {
+		logger.Log.WithContext(c).WithError(err).Warn("Continuing the request without a device ID")
+		return ""
+	}
+	deviceId := deviceIdUUID.String()
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(
+		security.DeviceIdCookieName,
+		deviceId,
+		int(deviceIdCookieMaxAge.Seconds()),
+		"/",
+		"",
+		true,
+		true,
+	)
+	return deviceId
+}
+
+func NewClientBindingMiddlewareImpl() *ClientBindingMiddlewareImpl {
+	return &ClientBindingMiddlewareImpl{}
+}
@@ -14,11 +14,18 @@ import (
 
 type AccessTokenHolderRepository interface {
 	baserepos.KeyValueTimedRepository[models.AccessTokenHolder]
+	// DelAllWithKeyPrefix deletes every value with a key starting with the
+	// prefix
+	DelAllWithKeyPrefix(ctx context.Context, keyPrefix string) error
+	// GetTTLsWithKeyPrefix returns the time to live of every value with a key
+	// starting with the prefix
+	GetTTLsWithKeyPrefix(ctx context.Context, keyPrefix string) ([]time.Duration, error)
 }
 
 type AccessTokenHolderRepositoryImpl struct {
-	prefix   string
-	baseRepo baserepos.KeyValueTimedRepository[models.AccessTokenHolder]
+	prefix         string
+	baseRepo       baserepos.KeyValueTimedRepository[models.AccessTokenHolder]
+	redisDBHandler *dshandlers.RedisDBHandler
 }
 
 func (a AccessTokenHolderRepositoryImpl) Get(
@@ -45,8 +52,29 @@ func (a AccessTokenHolderRepositoryImpl) Del(ctx context.Context, keys ...string
 	return a.baseRepo.Del(ctx, combinedKeys...)
 }
 
+func (a AccessTokenHolderRepositoryImpl) DelAllWithKeyPrefix(ctx context.Context, keyPrefix string) error {
+	pattern := kvstoreutils.CombineKeySections(a.prefix, kvstoreutils.EscapePattern(keyPrefix)) + "*"
+	return a.redisDBHandler.ScanAndDel(ctx, pattern)
+}
+
+func (a AccessTokenHolderRepositoryImpl) GetTTLsWithKeyPrefix(
+	ctx context.Context,
+	keyPrefix string,
+) ([]time.Duration, error) {
+	pattern := kvstoreutils.CombineKeySections(a.prefix, kvstoreutils.EscapePattern(keyPrefix)) + "*"
+	ttlsByKey, err := a.redisDBHandler.ScanTTLs(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	ttls := make([]time.Duration, 0, len(ttlsByKey))
+	for _, ttl := range ttlsByKey {
+		ttls = append(ttls, ttl)
+	}
+	return ttls, nil
+}
+
 func NewAccessTokenHolderRepositoryImpl(redisDBHandler *dshandlers.RedisDBHandler) *AccessTokenHolderRepositoryImpl {
 	prefix := kvstoreutils.CombineKeySections(kvStoreKeyPrefix, "accessTokenHolder")
 	baseRepo := baserepos.NewKeyValueTimedRepositoryRedis[models.AccessTokenHolder](redisDBHandler)
-	return &AccessTokenHolderRepositoryImpl{prefix: prefix, baseRepo: baseRepo}
+	return &AccessTokenHolderRepositoryImpl{prefix: prefix, baseRepo: baseRepo, redisDBHandler: redisDBHandler}
 }
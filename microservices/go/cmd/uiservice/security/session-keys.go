@@ -3,3 +3,7 @@ package security
 const StateSessionKey = "state"
 const TokenIdSessionKey = "token_id"
 const UKeySessionKey = "u-key-sess"
+
+// DeviceIdCookieName is the cookie the device ID is kept in, apart from the
+// web session
+const DeviceIdCookieName = "device_id"
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/models"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/uiservice/repositories"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
@@ -9,10 +10,17 @@ import (
 )
 
 type AccessTokenStoreService interface {
+	// NewTokenId creates the ID a new access token of a user is stored under
+	NewTokenId(authId string) (string, error)
 	StoreToken(ctx context.Context, tokenId string, accessToken string) error
 	// GetToken gets a token if it exists or returns an empty string if there is no token
 	GetToken(ctx context.Context, tokenId string) (string, error)
 	DeleteToken(ctx context.Context, tokenId string) error
+	// DeleteUserTokens deletes every access token of a user, ending the user's
+	// web sessions
+	DeleteUserTokens(ctx context.Context, authId string) error
+	// GetUserTokenExpiries returns when each access token of a user expires
+	GetUserTokenExpiries(ctx context.Context, authId string) ([]time.Time, error)
 }
 
 type AccessTokenStoreServiceImpl struct {
@@ -20,6 +28,15 @@ type AccessTokenStoreServiceImpl struct {
 	accessTokenHolderRepository repositories.AccessTokenHolderRepository
 }
 
+func (a AccessTokenStoreServiceImpl) NewTokenId(authId string) (string, error) {
+	randomUUID, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+	// Token IDs start with the auth ID so the tokens of a user can be found
+	return tokenIdPrefix(authId) + randomUUID.String(), nil
+}
+
 func (a AccessTokenStoreServiceImpl) StoreToken(ctx context.Context, tokenId string, accessToken string) error {
 	tokenHolder := models.AccessTokenHolder{}
 	if err := tokenHolder.SetAccessToken(accessToken, a.sessionConf.GetAccessTokenKey()); err != nil {
@@ -45,6 +62,27 @@ func (a AccessTokenStoreServiceImpl) DeleteToken(ctx context.Context, tokenId st
 	return a.accessTokenHolderRepository.Del(ctx, tokenId)
 }
 
+func (a AccessTokenStoreServiceImpl) DeleteUserTokens(ctx context.Context, authId string) error {
+	return a.accessTokenHolderRepository.DelAllWithKeyPrefix(ctx, tokenIdPrefix(authId))
+}
+
+func (a AccessTokenStoreServiceImpl) GetUserTokenExpiries(ctx context.Context, authId string) ([]time.Time, error) {
+	ttls, err := a.accessTokenHolderRepository.GetTTLsWithKeyPrefix(ctx, tokenIdPrefix(authId))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expiries := make([]time.Time, 0, len(ttls))
+	for _, ttl := range ttls {
+		expiries = append(expiries, now.Add(ttl))
+	}
+	return expiries, nil
+}
+
+func tokenIdPrefix(authId string) string {
+	return authId + "/"
+}
+
 func NewAccessTokenStoreServiceImpl(
 	sessionConf conf.SessionConf,
 	accessTokenHolderRepository repositories.AccessTokenHolderRepository,
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+)
+
+type UserDataExportEventService interface {
+	// HandleUserDataExportRequest sends the user service when each of the
+	// user's web sessions expires. Session IDs and access tokens are left out.
+	HandleUserDataExportRequest(ctx context.Context, requestDto userdtos.UserDataExportRequestDto) error
+
+	// HandleUserDataExportRequestFailure replies to the user service that the
+	// export failed once it will no longer be retried
+	HandleUserDataExportRequestFailure(
+		ctx context.Context,
+		requestDto userdtos.UserDataExportRequestDto,
+		cause error,
+	) error
+}
+
+type UserDataExportEventServiceImpl struct {
+	accessTokenStoreService          AccessTokenStoreService
+	userDataExportPartMsgSendService sharedservices.UserDataExportPartMsgSendService
+}
+
+func (u UserDataExportEventServiceImpl) HandleUserDataExportRequest(
+	ctx context.Context,
+	requestDto userdtos.UserDataExportRequestDto,
+) error {
+	expiries, err := u.accessTokenStoreService.GetUserTokenExpiries(ctx, requestDto.AuthId)
+	if err != nil {
+		return err
+	}
+	exportDto := userdtos.WebSessionDataExportDto{Sessions: make([]userdtos.WebSessionExportDto, 0, len(expiries))}
+	for _, expiresAt := range expiries {
+		exportDto.Sessions = append(exportDto.Sessions, userdtos.WebSessionExportDto{ExpiresAt: expiresAt.UnixMilli()})
+	}
+	data, err := json.Marshal(exportDto)
+	if err != nil {
+		return err
+	}
+	partDto := userdtos.UserDataExportPartDto{
+		ExportId:    requestDto.ExportId,
+		UserId:      requestDto.UserId,
+		Participant: userdtos.UserDataExportParticipantUIService,
+		Succeeded:   true,
+		Data:        data,
+	}
+	return u.userDataExportPartMsgSendService.SendUserDataExportPart(ctx, partDto)
+}
+
+func (u UserDataExportEventServiceImpl) HandleUserDataExportRequestFailure(
+	ctx context.Context,
+	requestDto userdtos.UserDataExportRequestDto,
+	cause error,
+) error {
+	partDto := userdtos.UserDataExportPartDto{
+		ExportId:    requestDto.ExportId,
+		UserId:      requestDto.UserId,
+		Participant: userdtos.UserDataExportParticipantUIService,
+		Succeeded:   false,
+		Error:       cause.Error(),
+	}
+	return u.userDataExportPartMsgSendService.SendUserDataExportPart(ctx, partDto)
+}
+
+func NewUserDataExportEventServiceImpl(
+	accessTokenStoreService AccessTokenStoreService,
+	userDataExportPartMsgSendService sharedservices.UserDataExportPartMsgSendService,
+) *UserDataExportEventServiceImpl {
+	return &UserDataExportEventServiceImpl{
+		accessTokenStoreService:          accessTokenStoreService,
+		userDataExportPartMsgSendService: userDataExportPartMsgSendService,
+	}
+}
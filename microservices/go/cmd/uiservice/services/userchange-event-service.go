@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+)
+
+type UserChangeEventService interface {
+	// HandleUserChangeEvent ends the web sessions of suspended and deleted users
+	HandleUserChangeEvent(ctx context.Context, userEventDto userdtos.UserChangeEventDto) error
+}
+
+type UserChangeEventServiceImpl struct {
+	accessTokenStoreService AccessTokenStoreService
+}
+
+func (u UserChangeEventServiceImpl) HandleUserChangeEvent(
+	ctx context.Context,
+	userEventDto userdtos.UserChangeEventDto,
+) error {
+	if userEventDto.Action != userdtos.UserDelete && !userEventDto.Suspended {
+		return nil
+	}
+	if err := u.accessTokenStoreService.DeleteUserTokens(ctx, userEventDto.AuthId); err != nil {
+		return err
+	}
+	logger.Log.WithContext(ctx).Debugf("Ended the web sessions of user %v", userEventDto.Id)
+	return nil
+}
+
+func NewUserChangeEventServiceImpl(accessTokenStoreService AccessTokenStoreService) *UserChangeEventServiceImpl {
+	return &UserChangeEventServiceImpl{accessTokenStoreService: accessTokenStoreService}
+}
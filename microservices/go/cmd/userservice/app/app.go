@@ -2,6 +2,7 @@ package app
 
 import (
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/background"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/listeners"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/servers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
 )
@@ -13,6 +14,11 @@ func (a App) Start() {
 
 }
 
-func NewApp(_ servers.GrpcServer, _ servers.AppServer, _ background.CronRunner) *App {
+func NewApp(
+	_ servers.GrpcServer,
+	_ servers.AppServer,
+	_ listeners.KafkaListener,
+	_ background.CronRunner,
+) *App {
 	return &App{}
 }
@@ -1,6 +1,25 @@
 package app
 
+import (
+	"fmt"
+	"os"
+)
+
 func Start() {
 	application := InitializeApp()
 	application.Start()
 }
+
+// RunDeadLetterCli runs a dead letter command from the command line, exiting
+// with an error code if it fails
+func RunDeadLetterCli(args []string) {
+	deadLetterCli := InitializeDeadLetterCli()
+	runErr := deadLetterCli.Run(args, os.Stdout)
+	if err := deadLetterCli.Close(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+	}
+	if runErr != nil {
+		_, _ = fmt.Fprintln(os.Stderr, runErr)
+		os.Exit(1)
+	}
+}
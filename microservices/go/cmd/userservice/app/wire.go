@@ -7,8 +7,10 @@ import (
 	"github.com/google/wire"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/background"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/businessrules"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/cli"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/controllers"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/grpcapis"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/listeners"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/repositories"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/servers"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
@@ -16,8 +18,11 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userpb"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/identityproviders"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/middlewares"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/scheduler"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/ginservices"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/grpcserveropts"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/securityservices"
@@ -27,28 +32,64 @@ func InitializeApp() *App {
 	wire.Build(
 		conf.NewKafkaConfImpl,
 		wire.Bind(new(conf.KafkaConf), new(*conf.KafkaConfImpl)),
+		conf.NewHttpClientConfImpl,
+		wire.Bind(new(conf.HttpClientConf), new(*conf.HttpClientConfImpl)),
 		conf.NewServerConfImpl,
 		wire.Bind(new(conf.ServerConf), new(*conf.ServerConfImpl)),
 		authconf.NewAuth0SecurityConfImpl,
 		wire.Bind(new(authconf.Auth0SecurityConf), new(*authconf.Auth0RouteSecurityConfImpl)),
+		authconf.NewIdentityProviderConfImpl,
+		wire.Bind(new(authconf.IdentityProviderConf), new(*authconf.IdentityProviderConfImpl)),
+		identityproviders.NewIdentityProvider,
 		conf.NewMongoConfImpl,
 		wire.Bind(new(conf.MongoConf), new(*conf.MongoConfImpl)),
 		conf.NewTlsConfImpl,
 		wire.Bind(new(conf.TLSConf), new(*conf.TlsConfImpl)),
 		dshandlers.NewMongoDBHandler,
 		wire.Bind(new(dshandlers.CrudDSHandler), new(*dshandlers.MongoDBHandler)),
+		conf.NewRedisConfImpl,
+		wire.Bind(new(conf.RedisConf), new(*conf.RedisConfImpl)),
+		dshandlers.NewRedisDBHandler,
+		conf.NewSchedulerConfImpl,
+		wire.Bind(new(conf.SchedulerConf), new(*conf.SchedulerConfImpl)),
+		scheduler.NewRedisLeaseManagerImpl,
+		wire.Bind(new(scheduler.LeaseManager), new(*scheduler.RedisLeaseManagerImpl)),
 		repositories.NewUserRepositoryImpl,
 		wire.Bind(new(repositories.UserRepository), new(*repositories.UserRepositoryImpl)),
+		repositories.NewUserChangeOutboxRepositoryImpl,
+		wire.Bind(new(repositories.UserChangeOutboxRepository), new(*repositories.UserChangeOutboxRepositoryImpl)),
+		repositories.NewUserDeletionRepositoryImpl,
+		wire.Bind(new(repositories.UserDeletionRepository), new(*repositories.UserDeletionRepositoryImpl)),
+		repositories.NewUserDataExportRepositoryImpl,
+		wire.Bind(new(repositories.UserDataExportRepository), new(*repositories.UserDataExportRepositoryImpl)),
+		repositories.NewUserAvatarRepositoryImpl,
+		wire.Bind(new(repositories.UserAvatarRepository), new(*repositories.UserAvatarRepositoryImpl)),
+		repositories.NewDeadLetterRepositoryImpl,
+		wire.Bind(new(repositories.DeadLetterRepository), new(*repositories.DeadLetterRepositoryImpl)),
+		repositories.NewDeadLetterAuditRepositoryImpl,
+		wire.Bind(new(repositories.DeadLetterAuditRepository), new(*repositories.DeadLetterAuditRepositoryImpl)),
+		externalservices.NewHTTPClientProviderImpl,
+		wire.Bind(new(externalservices.HttpClientProvider), new(*externalservices.HttpClientProviderImpl)),
 		sharedservices.NewErrorServiceImpl,
 		wire.Bind(new(sharedservices.ErrorService), new(*sharedservices.ErrorServiceImpl)),
 		businessrules.NewUserBrImpl,
 		wire.Bind(new(businessrules.UserBr), new(*businessrules.UserBrImpl)),
+		businessrules.NewDeadLetterBrImpl,
+		wire.Bind(new(businessrules.DeadLetterBr), new(*businessrules.DeadLetterBrImpl)),
 		services.NewUserMessageServiceImpl,
 		wire.Bind(new(services.UserMsgSendService), new(*services.UserMessageServiceImpl)),
-		services.NewAuthServerMgmtServiceImpl,
-		wire.Bind(new(services.AuthServerMgmtService), new(*services.AuthServerMgmtServiceImpl)),
+		services.NewUserChangeOutboxServiceImpl,
+		wire.Bind(new(services.UserChangeOutboxService), new(*services.UserChangeOutboxServiceImpl)),
+		services.NewUserDeletionServiceImpl,
+		wire.Bind(new(services.UserDeletionService), new(*services.UserDeletionServiceImpl)),
 		services.NewUserServiceImpl,
 		wire.Bind(new(services.UserService), new(*services.UserServiceImpl)),
+		services.NewUserAdminServiceImpl,
+		wire.Bind(new(services.UserAdminService), new(*services.UserAdminServiceImpl)),
+		services.NewUserDataExportServiceImpl,
+		wire.Bind(new(services.UserDataExportService), new(*services.UserDataExportServiceImpl)),
+		services.NewDeadLetterServiceImpl,
+		wire.Bind(new(services.DeadLetterService), new(*services.DeadLetterServiceImpl)),
 		ginservices.NewGinCtxServiceImpl,
 		wire.Bind(new(ginservices.GinCtxService), new(*ginservices.GinCtxServiceImpl)),
 		securityservices.NewJwtValidateWebAppServiceImpl,
@@ -57,6 +98,14 @@ func InitializeApp() *App {
 		wire.Bind(new(middlewares.AuthMiddleware), new(*middlewares.AuthMiddlewareImpl)),
 		controllers.NewUserControllerImpl,
 		wire.Bind(new(controllers.UserController), new(*controllers.UserControllerImpl)),
+		controllers.NewUserDeletionControllerImpl,
+		wire.Bind(new(controllers.UserDeletionController), new(*controllers.UserDeletionControllerImpl)),
+		controllers.NewUserDataExportControllerImpl,
+		wire.Bind(new(controllers.UserDataExportController), new(*controllers.UserDataExportControllerImpl)),
+		controllers.NewUserAdminControllerImpl,
+		wire.Bind(new(controllers.UserAdminController), new(*controllers.UserAdminControllerImpl)),
+		controllers.NewDeadLetterControllerImpl,
+		wire.Bind(new(controllers.DeadLetterController), new(*controllers.DeadLetterControllerImpl)),
 		servers.NewAppServerImpl,
 		wire.Bind(new(servers.AppServer), new(*servers.AppServerImpl)),
 		securityservices.NewJwtValidateGrpcServiceImpl,
@@ -69,9 +118,40 @@ func InitializeApp() *App {
 		wire.Bind(new(userpb.UserServiceServer), new(*grpcapis.UserServiceServerImpl)),
 		servers.NewGrpcServerImpl,
 		wire.Bind(new(servers.GrpcServer), new(*servers.GrpcServerImpl)),
+		listeners.NewUserDeleteAckListenerImpl,
+		wire.Bind(new(listeners.UserDeleteAckListener), new(*listeners.UserDeleteAckListenerImpl)),
+		listeners.NewUserDataExportPartListenerImpl,
+		wire.Bind(new(listeners.UserDataExportPartListener), new(*listeners.UserDataExportPartListenerImpl)),
+		listeners.NewDeadLetterListenerImpl,
+		wire.Bind(new(listeners.DeadLetterListener), new(*listeners.DeadLetterListenerImpl)),
+		listeners.NewKafkaListenerImpl,
+		wire.Bind(new(listeners.KafkaListener), new(*listeners.KafkaListenerImpl)),
 		background.NewCronRunnerImpl,
 		wire.Bind(new(background.CronRunner), new(*background.CronRunnerImpl)),
 		NewApp,
 	)
 	return &App{}
 }
+
+func InitializeDeadLetterCli() cli.DeadLetterCli {
+	wire.Build(
+		conf.NewKafkaConfImpl,
+		wire.Bind(new(conf.KafkaConf), new(*conf.KafkaConfImpl)),
+		conf.NewMongoConfImpl,
+		wire.Bind(new(conf.MongoConf), new(*conf.MongoConfImpl)),
+		dshandlers.NewMongoDBHandler,
+		repositories.NewDeadLetterRepositoryImpl,
+		wire.Bind(new(repositories.DeadLetterRepository), new(*repositories.DeadLetterRepositoryImpl)),
+		repositories.NewDeadLetterAuditRepositoryImpl,
+		wire.Bind(new(repositories.DeadLetterAuditRepository), new(*repositories.DeadLetterAuditRepositoryImpl)),
+		sharedservices.NewErrorServiceImpl,
+		wire.Bind(new(sharedservices.ErrorService), new(*sharedservices.ErrorServiceImpl)),
+		businessrules.NewDeadLetterBrImpl,
+		wire.Bind(new(businessrules.DeadLetterBr), new(*businessrules.DeadLetterBrImpl)),
+		services.NewDeadLetterServiceImpl,
+		wire.Bind(new(services.DeadLetterService), new(*services.DeadLetterServiceImpl)),
+		cli.NewDeadLetterCliImpl,
+		wire.Bind(new(cli.DeadLetterCli), new(*cli.DeadLetterCliImpl)),
+	)
+	return nil
+}
@@ -2,11 +2,10 @@ package background
 
 import (
 	"context"
-	"github.com/go-co-op/gocron"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
-	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/scheduler"
 	"time"
 )
 
@@ -16,31 +15,70 @@ type CronRunner interface {
 }
 
 type CronRunnerImpl struct {
-	userService services.UserService
+	userChangeOutboxService services.UserChangeOutboxService
+	userDeletionService     services.UserDeletionService
+	userDataExportService   services.UserDataExportService
+	leaseManager            scheduler.LeaseManager
 }
 
 func (c CronRunnerImpl) Run() {
-	s := gocron.NewScheduler(time.UTC)
-
-	userChangeJob, err := s.Every(1).Second().Do(func() {
-		ctx := context.Background()
-		c.userService.UsersChangeTask(ctx)
-	})
-	if err != nil {
-		logger.Log.Fatal(err)
-	}
-	userChangeJob.SingletonMode()
+	s := scheduler.NewSchedulerImpl(c.leaseManager)
+
+	// Only one replica should relay user changes, otherwise the events of a user
+	// could be published out of order
+	s.Schedule(
+		scheduler.JobSettings{Name: "relay-user-changes", Interval: time.Second, ClusterSingleton: true},
+		func(ctx context.Context) {
+			c.userChangeOutboxService.RelayUserChangesTask(ctx)
+		},
+	)
+
+	// Users are deleted from the auth server by one replica so the same user is
+	// not deleted twice at once
+	s.Schedule(
+		scheduler.JobSettings{
+			Name:             "delete-users-from-identity-provider",
+			Interval:         5 * time.Second,
+			ClusterSingleton: true,
+		},
+		func(ctx context.Context) {
+			c.userDeletionService.DeleteFromIdentityProviderTask(ctx)
+		},
+	)
+
+	// Data export requests are relayed by one replica so a request is not
+	// published twice at once
+	s.Schedule(
+		scheduler.JobSettings{
+			Name:             "relay-user-data-export-requests",
+			Interval:         time.Second,
+			ClusterSingleton: true,
+		},
+		func(ctx context.Context) {
+			c.userDataExportService.RelayUserDataExportRequestsTask(ctx)
+		},
+	)
 
 	s.StartBlocking()
 }
 
-func NewCronRunnerImpl(userService services.UserService) *CronRunnerImpl {
+func NewCronRunnerImpl(
+	userChangeOutboxService services.UserChangeOutboxService,
+	userDeletionService services.UserDeletionService,
+	userDataExportService services.UserDataExportService,
+	leaseManager scheduler.LeaseManager,
+) *CronRunnerImpl {
 	if !environment.ActivateCronRunner() {
 		// Task runner is deactivated, ran via the lifecycle package,
 		// and is a root-child dependency so a nil is returned
 		return nil
 	}
-	c := &CronRunnerImpl{userService: userService}
+	c := &CronRunnerImpl{
+		userChangeOutboxService: userChangeOutboxService,
+		userDeletionService:     userDeletionService,
+		userDataExportService:   userDataExportService,
+		leaseManager:            leaseManager,
+	}
 	lifecycle.RegisterTaskRunner(c)
 	return c
 }
@@ -0,0 +1,77 @@
+package businessrules
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors/validationutils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/deadletterdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+)
+
+// MaxDeadLettersPerRequest is the most dead letters that can be selected by ID
+// at once
+const MaxDeadLettersPerRequest = 500
+
+type DeadLetterBr interface {
+	// ValidateGetDeadLetters allows listing the dead letters of a known topic or
+	// of every topic when the topic is blank
+	ValidateGetDeadLetters(topic string) error
+	ValidateDeadLetterPurge(dto deadletterdtos.DeadLetterSelectDto) error
+	ValidateDeadLetterReplay(dto deadletterdtos.DeadLetterReplayDto) error
+}
+
+type DeadLetterBrImpl struct {
+	errorService       sharedservices.ErrorService
+	deadLetterPolicies map[string]kfka.RetryPolicy
+}
+
+func (d DeadLetterBrImpl) ValidateGetDeadLetters(topic string) error {
+	if utils.StringIsBlank(topic) {
+		return nil
+	}
+	return validationutils.MergeRuleErrors(d.validateTopic(topic))
+}
+
+func (d DeadLetterBrImpl) ValidateDeadLetterPurge(dto deadletterdtos.DeadLetterSelectDto) error {
+	ruleErrs := d.validateTopic(dto.Topic)
+	ruleErrs = append(ruleErrs, d.validateSelection(dto)...)
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (d DeadLetterBrImpl) ValidateDeadLetterReplay(dto deadletterdtos.DeadLetterReplayDto) error {
+	ruleErrs := d.validateTopic(dto.Topic)
+	ruleErrs = append(ruleErrs, d.validateSelection(dto.DeadLetterSelectDto)...)
+	if policy, ok := d.deadLetterPolicies[dto.Topic]; ok && (dto.Stage < 0 || dto.Stage > len(policy.Stages)) {
+		ruleErrs = append(ruleErrs,
+			d.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidRetryStage, len(policy.Stages)))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (d DeadLetterBrImpl) validateTopic(topic string) []apperrors.RuleError {
+	var ruleErrs []apperrors.RuleError
+	if _, ok := d.deadLetterPolicies[topic]; !ok {
+		ruleErrs = append(ruleErrs, d.errorService.RuleErrorFromCode(apperrors.ErrCodeUnknownDeadLetterTopic))
+	}
+	return ruleErrs
+}
+
+func (d DeadLetterBrImpl) validateSelection(dto deadletterdtos.DeadLetterSelectDto) []apperrors.RuleError {
+	var ruleErrs []apperrors.RuleError
+	if !dto.All && len(dto.Ids) == 0 {
+		ruleErrs = append(ruleErrs, d.errorService.RuleErrorFromCode(apperrors.ErrCodeNoDeadLettersSelected))
+	} else if len(dto.Ids) > MaxDeadLettersPerRequest {
+		ruleErrs = append(ruleErrs,
+			d.errorService.RuleErrorFromCode(apperrors.ErrCodeTooManyItemsRequested, MaxDeadLettersPerRequest))
+	}
+	return ruleErrs
+}
+
+func NewDeadLetterBrImpl(errorService sharedservices.ErrorService) *DeadLetterBrImpl {
+	return &DeadLetterBrImpl{
+		errorService:       errorService,
+		deadLetterPolicies: retrypolicies.NewUserChange1PoliciesByDeadLetterTopic(),
+	}
+}
@@ -0,0 +1,65 @@
+package businessrules_test
+
+import (
+	"errors"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/businessrules"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/deadletterdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func ruleErrCodes(err error) []string {
+	var badReqErr apperrors.BadRequestError
+	if !errors.As(err, &badReqErr) {
+		return nil
+	}
+	var codes []string
+	for _, ruleErr := range badReqErr.RuleErrors {
+		codes = append(codes, ruleErr.Code)
+	}
+	return codes
+}
+
+func TestDeadLetterBr(t *testing.T) {
+	cv.Convey("When validating dead letter requests", t, func() {
+		deadLetterBr := businessrules.NewDeadLetterBrImpl(sharedservices.NewErrorServiceImpl())
+		policy := retrypolicies.NewUserChange1NoteServicePolicy()
+		selectDto := deadletterdtos.DeadLetterSelectDto{Topic: policy.DeadLetterTopic, Ids: []string{"id"}}
+
+		cv.Convey("Expect listing every topic or a known topic is allowed", func() {
+			cv.So(deadLetterBr.ValidateGetDeadLetters(""), cv.ShouldBeNil)
+			cv.So(deadLetterBr.ValidateGetDeadLetters(policy.DeadLetterTopic), cv.ShouldBeNil)
+		})
+		cv.Convey("Expect an unknown topic is rejected", func() {
+			err := deadLetterBr.ValidateGetDeadLetters(policy.MainTopic)
+			cv.So(ruleErrCodes(err), cv.ShouldResemble, []string{apperrors.ErrCodeUnknownDeadLetterTopic})
+		})
+		cv.Convey("Expect a purge selecting nothing is rejected", func() {
+			err := deadLetterBr.ValidateDeadLetterPurge(deadletterdtos.DeadLetterSelectDto{Topic: policy.DeadLetterTopic})
+			cv.So(ruleErrCodes(err), cv.ShouldResemble, []string{apperrors.ErrCodeNoDeadLettersSelected})
+		})
+		cv.Convey("Expect a purge selecting too many IDs is rejected", func() {
+			tooManyDto := selectDto
+			tooManyDto.Ids = make([]string, businessrules.MaxDeadLettersPerRequest+1)
+			err := deadLetterBr.ValidateDeadLetterPurge(tooManyDto)
+			cv.So(ruleErrCodes(err), cv.ShouldResemble, []string{apperrors.ErrCodeTooManyItemsRequested})
+		})
+		cv.Convey("Expect replaying to the main topic and the last retry stage is allowed", func() {
+			cv.So(deadLetterBr.ValidateDeadLetterReplay(
+				deadletterdtos.DeadLetterReplayDto{DeadLetterSelectDto: selectDto, Stage: 0}), cv.ShouldBeNil)
+			cv.So(deadLetterBr.ValidateDeadLetterReplay(
+				deadletterdtos.DeadLetterReplayDto{DeadLetterSelectDto: selectDto, Stage: len(policy.Stages)}),
+				cv.ShouldBeNil)
+		})
+		cv.Convey("Expect replaying to a stage out of the policy's stages is rejected", func() {
+			for _, stage := range []int{-1, len(policy.Stages) + 1} {
+				err := deadLetterBr.ValidateDeadLetterReplay(
+					deadletterdtos.DeadLetterReplayDto{DeadLetterSelectDto: selectDto, Stage: stage})
+				cv.So(ruleErrCodes(err), cv.ShouldResemble, []string{apperrors.ErrCodeInvalidRetryStage})
+			}
+		})
+	})
+}
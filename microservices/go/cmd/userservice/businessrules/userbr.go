@@ -7,20 +7,47 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors/validationutils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"golang.org/x/text/language"
+	"time"
+	// Time zones are validated against the embedded database so validation does
+	// not depend on the host
+	_ "time/tzdata"
 )
 
+// MaxUsersPerRequest is the most users that can be fetched or listed at once
+const MaxUsersPerRequest = 500
+
+// allowedAvatarContentTypes are the image types browsers can display
+var allowedAvatarContentTypes = map[string]any{
+	"image/png":  nil,
+	"image/jpeg": nil,
+	"image/gif":  nil,
+	"image/webp": nil,
+}
+
 type UserBr interface {
 	ValidateUserCreate(ctx context.Context, identity security.Identity, dto userdtos.UserSaveDto) error
 	ValidateUserUpdate(ctx context.Context, dto userdtos.UserSaveDto, existing models.User) error
+	ValidateUserProfileUpdate(dto userdtos.UserProfileSaveDto) error
+	ValidateUserAvatar(avatar models.UserAvatar) error
+	ValidateUserDataExportCreate(ctx context.Context, user models.User) error
+	ValidateGetUsers(pageRequest pagination.PageRequest) error
+	// ValidateUserNotSuspended keeps suspended users from changing their account
+	ValidateUserNotSuspended(user models.User) error
+	ValidateGetUsersByIds(userIds []string) error
 }
 
 type UserBrImpl struct {
-	crudDBHandler  dshandlers.CrudDSHandler
-	userRepository repositories.UserRepository
-	errorService   sharedservices.ErrorService
+	crudDBHandler            dshandlers.CrudDSHandler
+	userRepository           repositories.UserRepository
+	userDeletionRepository   repositories.UserDeletionRepository
+	userDataExportRepository repositories.UserDataExportRepository
+	errorService             sharedservices.ErrorService
+	validUserSortFields      map[string]any
 }
 
 func (u UserBrImpl) ValidateUserCreate(
@@ -32,7 +59,7 @@ func (u UserBrImpl) ValidateUserCreate(
 	if err != nil {
 		return err
 	}
-	userFindByAuthIdMaybe, err := u.userRepository.FindByAuthIdAndNotToBeDeleted(ctx, identity.GetAuthId())
+	userFindByAuthIdMaybe, err := u.userRepository.FindByAuthId(ctx, identity.GetAuthId())
 	if err != nil {
 		return err
 	}
@@ -41,7 +68,19 @@ func (u UserBrImpl) ValidateUserCreate(
 		userFindByAuthIdMaybe,
 		apperrors.ErrCodeResourceAlreadyCreated,
 	)
+	// The auth server account of a user is deleted once the deletion completes,
+	// so it cannot be reused before then
+	deletionFindMaybe, err := u.userDeletionRepository.FindUnfinishedByAuthId(ctx, identity.GetAuthId())
+	if err != nil {
+		return err
+	}
+	userNotDeletingValidationErrs := validationutils.ValidateValueIsNotPresent(
+		u.errorService,
+		deletionFindMaybe,
+		apperrors.ErrCodeUserDeletionInProgress,
+	)
 	ruleErrorsSrc := append(userNameNotTakenValidationErrs, userNotCreatedValidationErrs...)
+	ruleErrorsSrc = append(ruleErrorsSrc, userNotDeletingValidationErrs...)
 	return validationutils.MergeRuleErrors(ruleErrorsSrc)
 }
 
@@ -57,11 +96,85 @@ func (u UserBrImpl) ValidateUserUpdate(ctx context.Context, dto userdtos.UserSav
 	return validationutils.MergeRuleErrors(ruleErrs)
 }
 
+func (u UserBrImpl) ValidateUserProfileUpdate(dto userdtos.UserProfileSaveDto) error {
+	var ruleErrs []apperrors.RuleError
+	// LoadLocation treats an empty name as UTC and accepts the host's Local
+	// time zone, neither of which are time zone names
+	if dto.Timezone != "" {
+		if _, err := time.LoadLocation(dto.Timezone); err != nil || dto.Timezone == "Local" {
+			ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidTimezone))
+		}
+	}
+	if dto.Locale != "" {
+		if _, err := language.Parse(dto.Locale); err != nil {
+			ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidLocale))
+		}
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserBrImpl) ValidateUserAvatar(avatar models.UserAvatar) error {
+	var ruleErrs []apperrors.RuleError
+	if len(avatar.Data) > models.UserAvatarMaxBytes {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeAvatarTooLarge, models.UserAvatarMaxBytes)
+		ruleErrs = append(ruleErrs, ruleErr)
+	}
+	if _, ok := allowedAvatarContentTypes[avatar.ContentType]; !ok {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeUnsupportedAvatarType))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+// ValidateUserDataExportCreate allows one active data export per user
+func (u UserBrImpl) ValidateUserDataExportCreate(ctx context.Context, user models.User) error {
+	createdAfter := time.Now().Add(-models.UserDataExportActiveDuration)
+	activeExportMaybe, err := u.userDataExportRepository.FindPendingByUserIdCreatedAfter(
+		ctx,
+		user.GetIdStr(),
+		createdAfter,
+	)
+	if err != nil {
+		return err
+	}
+	return validationutils.MergeRuleErrors(validationutils.ValidateValueIsNotPresent(
+		u.errorService,
+		activeExportMaybe,
+		apperrors.ErrCodeDataExportInProgress,
+	))
+}
+
+func (u UserBrImpl) ValidateGetUsers(pageRequest pagination.PageRequest) error {
+	var ruleErrs []apperrors.RuleError
+	if len(pageRequest.Sort) != 1 {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeMustSortByOneOption))
+	} else if _, ok := u.validUserSortFields[pageRequest.Sort[0].Field]; !ok {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidSortOptions))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserBrImpl) ValidateUserNotSuspended(user models.User) error {
+	var ruleErrs []apperrors.RuleError
+	if user.Suspended {
+		ruleErrs = append(ruleErrs, u.errorService.RuleErrorFromCode(apperrors.ErrCodeUserSuspended))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
+func (u UserBrImpl) ValidateGetUsersByIds(userIds []string) error {
+	var ruleErrs []apperrors.RuleError
+	if len(userIds) > MaxUsersPerRequest {
+		ruleErrs = append(ruleErrs,
+			u.errorService.RuleErrorFromCode(apperrors.ErrCodeTooManyItemsRequested, MaxUsersPerRequest))
+	}
+	return validationutils.MergeRuleErrors(ruleErrs)
+}
+
 func (u UserBrImpl) validateUserNameNotTaken(
 	ctx context.Context,
 	dto userdtos.UserSaveDto,
 ) ([]apperrors.RuleError, error) {
-	maybe, err := u.userRepository.FindByUsernameAndNotToBeDeleted(ctx, dto.UserName)
+	maybe, err := u.userRepository.FindByUsername(ctx, dto.UserName)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +184,20 @@ func (u UserBrImpl) validateUserNameNotTaken(
 func NewUserBrImpl(
 	crudDBHandler dshandlers.CrudDSHandler,
 	userRepository repositories.UserRepository,
+	userDeletionRepository repositories.UserDeletionRepository,
+	userDataExportRepository repositories.UserDataExportRepository,
 	errorMessageService sharedservices.ErrorService,
 ) *UserBrImpl {
-	return &UserBrImpl{crudDBHandler: crudDBHandler, userRepository: userRepository, errorService: errorMessageService}
+	return &UserBrImpl{
+		crudDBHandler:            crudDBHandler,
+		userRepository:           userRepository,
+		userDeletionRepository:   userDeletionRepository,
+		userDataExportRepository: userDataExportRepository,
+		errorService:             errorMessageService,
+		validUserSortFields: map[string]any{
+			pagination.SortFieldCreatedAt: any(true),
+			pagination.SortFieldUpdatedAt: any(true),
+			"userName":                    any(true),
+		},
+	}
 }
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/deadletterdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// DeadLetterCommand is the command line argument running the DeadLetterCli
+const DeadLetterCommand = "deadletters"
+
+const deadLetterUsage = `Usage: userservice deadletters <command> [flags] [ids...]
+
+Commands:
+  topics                        List the dead letter topics and their retry topics
+  list [-topic T]               List dead letters, paged with -page and -size
+  inspect ID                    Show a dead letter with its tries and errors
+  replay -topic T (-all | ID...) [-stage N]
+                                Replay dead letters to the main topic, which is stage 0,
+                                or to a retry stage starting at 1
+  purge -topic T (-all | ID...) Delete dead letters
+  audits                        List replays and purges, paged with -page and -size`
+
+// DeadLetterCli inspects, replays and purges dead letters from the command line
+type DeadLetterCli interface {
+	lifecycle.Closable
+
+	// Run runs the dead letter command in args, writing the result to out as
+	// JSON
+	Run(args []string, out io.Writer) error
+}
+
+type DeadLetterCliImpl struct {
+	deadLetterService services.DeadLetterService
+	mongoDBHandler    *dshandlers.MongoDBHandler
+}
+
+func (d DeadLetterCliImpl) Run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(deadLetterUsage)
+	}
+	ctx := context.Background()
+	command := args[0]
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	topic := flags.String("topic", "", "dead letter topic")
+	page := flags.Int64("page", 0, "page to list")
+	size := flags.Int64("size", 20, "size of the page to list")
+	stage := flags.Int("stage", 0, "retry stage to replay to, 0 is the main topic")
+	all := flags.Bool("all", false, "select every dead letter of the topic")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	selectDto := deadletterdtos.DeadLetterSelectDto{Topic: *topic, Ids: flags.Args(), All: *all}
+
+	var result any
+	var err error
+	switch command {
+	case "topics":
+		result, err = d.deadLetterService.GetDeadLetterTopics(ctx)
+	case "list":
+		result, err = d.deadLetterService.GetDeadLetters(ctx, *topic, pagination.NewPageRequest(*page, *size))
+	case "inspect":
+		if flags.NArg() != 1 {
+			return errors.New(deadLetterUsage)
+		}
+		result, err = d.deadLetterService.GetDeadLetter(ctx, flags.Arg(0))
+	case "replay":
+		replayDto := deadletterdtos.DeadLetterReplayDto{DeadLetterSelectDto: selectDto, Stage: *stage}
+		result, err = d.deadLetterService.ReplayDeadLetters(ctx, cliActor(), replayDto)
+	case "purge":
+		result, err = d.deadLetterService.PurgeDeadLetters(ctx, cliActor(), selectDto)
+	case "audits":
+		pageReq := pagination.NewPageRequest(*page, *size)
+		pageReq.Sort = []pagination.SortField{
+			pagination.NewSortField(pagination.SortFieldCreatedAt, pagination.Descending),
+		}
+		result, err = d.deadLetterService.GetDeadLetterAudits(ctx, pageReq)
+	default:
+		return fmt.Errorf("unknown command %v\n%v", command, deadLetterUsage)
+	}
+
+	// Replays and purges that stopped part way are still audited, so the audit
+	// is shown along with the error
+	if auditDto, ok := result.(deadletterdtos.DeadLetterAuditDto); err == nil || (ok && auditDto.Id != "") {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(result); encodeErr != nil {
+			return encodeErr
+		}
+	}
+	return describeError(err)
+}
+
+// describeError adds the messages of a bad request to the error
+func describeError(err error) error {
+	var badReqErr apperrors.BadRequestError
+	if !errors.As(err, &badReqErr) {
+		return err
+	}
+	var messages []string
+	for _, ruleErr := range badReqErr.RuleErrors {
+		messages = append(messages, ruleErr.Message)
+	}
+	for _, validationErr := range badReqErr.ValidationErrors {
+		messages = append(messages, validationErr.Field+": "+validationErr.Message)
+	}
+	return fmt.Errorf("%v: %v", err, strings.Join(messages, ", "))
+}
+
+// cliActor identifies who ran the command line in audits
+func cliActor() string {
+	userName := "unknown"
+	if currentUser, err := user.Current(); err == nil {
+		userName = currentUser.Username
+	}
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("cli:%v@%v", userName, hostname)
+}
+
+// Close closes the dead letter service's senders and then disconnects from the
+// database
+func (d DeadLetterCliImpl) Close() error {
+	return utils.ConcatErrors(d.deadLetterService.Close(), d.mongoDBHandler.Close())
+}
+
+func NewDeadLetterCliImpl(
+	deadLetterService services.DeadLetterService,
+	mongoDBHandler *dshandlers.MongoDBHandler,
+) *DeadLetterCliImpl {
+	return &DeadLetterCliImpl{deadLetterService: deadLetterService, mongoDBHandler: mongoDBHandler}
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/middlewares"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/deadletterdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/ginservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/controller"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/routing"
+	"net/http"
+)
+
+type DeadLetterController interface {
+	controller.Controller
+}
+
+type DeadLetterControllerImpl struct {
+	deadLetterService services.DeadLetterService
+	authMiddleware    middlewares.AuthMiddleware
+	ginCtxService     ginservices.GinCtxService
+}
+
+func (d DeadLetterControllerImpl) AddRoutes(r *gin.Engine) {
+	deadLetterGroupV1 := r.Group(routing.APIPath(1, "deadLetters"), d.authMiddleware.Authentication())
+	adminAuthorization := d.authMiddleware.Authorization(middlewares.AuthorizerSettings{
+		AllAuthoritiesToVerify: []string{security.AuthorityAdminUsers},
+	})
+
+	deadLetterGroupV1.GET("/topics", adminAuthorization, func(c *gin.Context) {
+		var resBody []deadletterdtos.DeadLetterTopicDto
+
+		d.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			resBody, err = d.deadLetterService.GetDeadLetterTopics(c)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	// Dead letters of the topic query parameter, or of every topic if it is
+	// missing
+	deadLetterGroupV1.GET("/messages", adminAuthorization, func(c *gin.Context) {
+		var topic string
+		var pageReq pagination.PageRequest
+		var resBody pagination.Page[deadletterdtos.DeadLetterDto]
+
+		d.ginCtxService.RestControllerPipeline(c).Next(func() error {
+			return d.ginCtxService.ReqQueryReader(c).
+				ReadStringOrDefault("topic", &topic, "").
+				ReadPageRequestOrDefault("page", "size", "sort", &pageReq, pagination.NewPageRequest(0, 20)).
+				Complete()
+		}).Next(func() (err error) {
+			resBody, err = d.deadLetterService.GetDeadLetters(c, topic, pageReq)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	deadLetterGroupV1.GET("/messages/:deadLetterId", adminAuthorization, func(c *gin.Context) {
+		var resBody deadletterdtos.DeadLetterDto
+		deadLetterId := c.Param("deadLetterId")
+
+		d.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			resBody, err = d.deadLetterService.GetDeadLetter(c, deadLetterId)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	deadLetterGroupV1.POST("/replay", adminAuthorization, func(c *gin.Context) {
+		var reqBody deadletterdtos.DeadLetterReplayDto
+		var resBody deadletterdtos.DeadLetterAuditDto
+
+		d.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			reqBody, err = ginservices.ReadValueFromBody[deadletterdtos.DeadLetterReplayDto](d.ginCtxService, c)
+			return
+		}).Next(func() (err error) {
+			actor := security.GetIdentityFromGinContext(c).GetAuthId()
+			resBody, err = d.deadLetterService.ReplayDeadLetters(c, actor, reqBody)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	deadLetterGroupV1.POST("/purge", adminAuthorization, func(c *gin.Context) {
+		var reqBody deadletterdtos.DeadLetterSelectDto
+		var resBody deadletterdtos.DeadLetterAuditDto
+
+		d.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			reqBody, err = ginservices.ReadValueFromBody[deadletterdtos.DeadLetterSelectDto](d.ginCtxService, c)
+			return
+		}).Next(func() (err error) {
+			actor := security.GetIdentityFromGinContext(c).GetAuthId()
+			resBody, err = d.deadLetterService.PurgeDeadLetters(c, actor, reqBody)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	deadLetterGroupV1.GET("/audits", adminAuthorization, func(c *gin.Context) {
+		var pageReq pagination.PageRequest
+		var resBody pagination.Page[deadletterdtos.DeadLetterAuditDto]
+
+		d.ginCtxService.RestControllerPipeline(c).Next(func() error {
+			defaultPageReq := pagination.NewPageRequest(0, 20)
+			defaultPageReq.Sort = []pagination.SortField{
+				pagination.NewSortField(pagination.SortFieldCreatedAt, pagination.Descending),
+			}
+			return d.ginCtxService.ReqQueryReader(c).
+				ReadPageRequestOrDefault("page", "size", "sort", &pageReq, defaultPageReq).
+				Complete()
+		}).Next(func() (err error) {
+			resBody, err = d.deadLetterService.GetDeadLetterAudits(c, pageReq)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+}
+
+func NewDeadLetterControllerImpl(
+	deadLetterService services.DeadLetterService,
+	authMiddleware middlewares.AuthMiddleware,
+	ginCtxService ginservices.GinCtxService,
+) *DeadLetterControllerImpl {
+	return &DeadLetterControllerImpl{
+		deadLetterService: deadLetterService,
+		authMiddleware:    authMiddleware,
+		ginCtxService:     ginCtxService,
+	}
+}
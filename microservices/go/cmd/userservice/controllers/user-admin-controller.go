@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/middlewares"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/ginservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/controller"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/routing"
+	"net/http"
+)
+
+type UserAdminController interface {
+	controller.Controller
+}
+
+type UserAdminControllerImpl struct {
+	userAdminService services.UserAdminService
+	authMiddleware   middlewares.AuthMiddleware
+	ginCtxService    ginservices.GinCtxService
+}
+
+func (u UserAdminControllerImpl) AddRoutes(r *gin.Engine) {
+	userAdminGroupV1 := r.Group(routing.APIPath(1, "userAdmin"), u.authMiddleware.Authentication())
+	adminAuthorization := u.authMiddleware.Authorization(middlewares.AuthorizerSettings{
+		AllAuthoritiesToVerify: []string{security.AuthorityAdminUsers},
+	})
+
+	// Users whose username starts with the search query parameter or whose auth
+	// ID is the search
+	userAdminGroupV1.GET("", adminAuthorization, func(c *gin.Context) {
+		var search string
+		var pageReq pagination.PageRequest
+		var resBody pagination.Page[userdtos.UserAdminDto]
+
+		u.ginCtxService.RestControllerPipeline(c).Next(func() error {
+			defaultPageReq := pagination.NewPageRequest(0, 20)
+			defaultPageReq.Sort = []pagination.SortField{
+				pagination.NewSortField(pagination.SortFieldCreatedAt, pagination.Descending),
+			}
+			return u.ginCtxService.ReqQueryReader(c).
+				ReadStringOrDefault("search", &search, "").
+				ReadPageRequestOrDefault("page", "size", "sort", &pageReq, defaultPageReq).
+				Complete()
+		}).Next(func() (err error) {
+			resBody, err = u.userAdminService.GetUsers(c, search, pageReq)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	userAdminGroupV1.GET("/:userId", adminAuthorization, func(c *gin.Context) {
+		var resBody userdtos.UserAdminDetailsDto
+		userId := c.Param("userId")
+
+		u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			resBody, err = u.userAdminService.GetUserDetails(c, userId)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	userAdminGroupV1.POST("/:userId/redistribute", adminAuthorization, func(c *gin.Context) {
+		var resBody userdtos.UserAdminDetailsDto
+		userId := c.Param("userId")
+
+		u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			resBody, err = u.userAdminService.RedistributeUserTxn(c, userId)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	userAdminGroupV1.POST("/:userId/suspend", adminAuthorization, func(c *gin.Context) {
+		var resBody userdtos.UserAdminDetailsDto
+		userId := c.Param("userId")
+
+		u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			resBody, err = u.userAdminService.SetUserSuspendedTxn(c, userId, true)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	userAdminGroupV1.POST("/:userId/reinstate", adminAuthorization, func(c *gin.Context) {
+		var resBody userdtos.UserAdminDetailsDto
+		userId := c.Param("userId")
+
+		u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			resBody, err = u.userAdminService.SetUserSuspendedTxn(c, userId, false)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+
+	userAdminGroupV1.DELETE("/:userId", adminAuthorization, func(c *gin.Context) {
+		var resBody userdtos.UserAdminDetailsDto
+		userId := c.Param("userId")
+
+		u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+			resBody, err = u.userAdminService.BeginDeletingUserTxn(c, userId)
+			return
+		}).Next(func() error {
+			c.JSON(http.StatusOK, resBody)
+			return nil
+		})
+	})
+}
+
+func NewUserAdminControllerImpl(
+	userAdminService services.UserAdminService,
+	authMiddleware middlewares.AuthMiddleware,
+	ginCtxService ginservices.GinCtxService,
+) *UserAdminControllerImpl {
+	return &UserAdminControllerImpl{
+		userAdminService: userAdminService,
+		authMiddleware:   authMiddleware,
+		ginCtxService:    ginCtxService,
+	}
+}
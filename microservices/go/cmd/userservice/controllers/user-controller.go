@@ -61,6 +61,92 @@ func (u UserControllerImpl) AddRoutes(r *gin.Engine) {
 			})
 		})
 
+	userGroupV1.PUT("/profile",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var reqBody userdtos.UserProfileSaveDto
+			var resBody userdtos.UserReadDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[userdtos.UserProfileSaveDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userService.UpdateUserProfileTxn(c, security.GetIdentityFromGinContext(c), reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return nil
+			})
+		})
+
+	userGroupV1.PUT("/preferences",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var reqBody userdtos.UserPreferencesSaveDto
+			var resBody userdtos.UserReadDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[userdtos.UserPreferencesSaveDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userService.UpdateUserPreferencesTxn(c, security.GetIdentityFromGinContext(c), reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return nil
+			})
+		})
+
+	// Uploads the avatar as the "avatar" file of a multipart form
+	userGroupV1.PUT("/avatar",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var reqBody userdtos.UserAvatarSaveDto
+			var resBody userdtos.UserReadDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				reqBody, err = ginservices.ReadValueFromBody[userdtos.UserAvatarSaveDto](u.ginCtxService, c)
+				return
+			}).Next(func() (err error) {
+				resBody, err = u.userService.SetUserAvatar(c, security.GetIdentityFromGinContext(c), reqBody)
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return nil
+			})
+		})
+
+	userGroupV1.DELETE("/avatar",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var resBody userdtos.UserReadDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				resBody, err = u.userService.RemoveUserAvatar(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() (err error) {
+				c.JSON(http.StatusOK, resBody)
+				return nil
+			})
+		})
+
+	// Avatars are immutable as a new avatar gets a new ID, so they can be cached
+	userGroupV1.GET("/avatar/:avatarId",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var resBody userdtos.UserAvatarDto
+			avatarId := c.Param("avatarId")
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				resBody, err = u.userService.GetUserAvatar(c, avatarId)
+				return
+			}).Next(func() (err error) {
+				c.Header("Cache-Control", "private, max-age=31536000, immutable")
+				c.Data(http.StatusOK, resBody.ContentType, resBody.Data)
+				return nil
+			})
+		})
+
 	userGroupV1.DELETE("",
 		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
 		func(c *gin.Context) {
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/middlewares"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/ginservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/controller"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/routing"
+	"net/http"
+)
+
+type UserDataExportController interface {
+	controller.Controller
+}
+
+type UserDataExportControllerImpl struct {
+	userDataExportService services.UserDataExportService
+	authMiddleware        middlewares.AuthMiddleware
+	ginCtxService         ginservices.GinCtxService
+}
+
+func (u UserDataExportControllerImpl) AddRoutes(r *gin.Engine) {
+	dataExportGroupV1 := r.Group(routing.APIPath(1, "dataExport"), u.authMiddleware.Authentication())
+
+	dataExportGroupV1.POST("",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var resBody userdtos.UserDataExportDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				resBody, err = u.userDataExportService.RequestUserDataExportTxn(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				c.JSON(http.StatusOK, resBody)
+				return nil
+			})
+		})
+
+	// Status of the latest export, which can be polled until it completes
+	dataExportGroupV1.GET("",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var resBody userdtos.UserDataExportDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				resBody, err = u.userDataExportService.GetLatestUserDataExport(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				c.JSON(http.StatusOK, resBody)
+				return nil
+			})
+		})
+
+	dataExportGroupV1.GET("/download",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{VerifyIsUser: true}),
+		func(c *gin.Context) {
+			var resBody userdtos.UserDataExportBundleDto
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() (err error) {
+				resBody, err = u.userDataExportService.GetUserDataExportBundle(c, security.GetIdentityFromGinContext(c))
+				return
+			}).Next(func() error {
+				fileName := fmt.Sprintf("cypherlog-export-%v.json", resBody.ExportId)
+				c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+				c.JSON(http.StatusOK, resBody)
+				return nil
+			})
+		})
+}
+
+func NewUserDataExportControllerImpl(
+	userDataExportService services.UserDataExportService,
+	authMiddleware middlewares.AuthMiddleware,
+	ginCtxService ginservices.GinCtxService,
+) *UserDataExportControllerImpl {
+	return &UserDataExportControllerImpl{
+		userDataExportService: userDataExportService,
+		authMiddleware:        authMiddleware,
+		ginCtxService:         ginCtxService,
+	}
+}
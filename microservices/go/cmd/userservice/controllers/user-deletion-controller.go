@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/middlewares"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/ginservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/controller"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/routing"
+	"net/http"
+	"time"
+)
+
+const defaultStuckUserDeletionMinutes = 60
+
+type UserDeletionController interface {
+	controller.Controller
+}
+
+type UserDeletionControllerImpl struct {
+	userDeletionService services.UserDeletionService
+	authMiddleware      middlewares.AuthMiddleware
+	ginCtxService       ginservices.GinCtxService
+}
+
+func (u UserDeletionControllerImpl) AddRoutes(r *gin.Engine) {
+	userDeletionGroupV1 := r.Group(routing.APIPath(1, "userDeletion"), u.authMiddleware.Authentication())
+
+	// Deletions that failed or are still waiting on services after
+	// olderThanMinutes
+	userDeletionGroupV1.GET("/stuck",
+		u.authMiddleware.Authorization(middlewares.AuthorizerSettings{
+			AllAuthoritiesToVerify: []string{security.AuthorityAdminUsers},
+		}),
+		func(c *gin.Context) {
+			var olderThanMinutes int64
+			var pageReq pagination.PageRequest
+			var resBody pagination.Page[userdtos.UserDeletionDto]
+
+			u.ginCtxService.RestControllerPipeline(c).Next(func() error {
+				return u.ginCtxService.ReqQueryReader(c).
+					ReadInt64OrDefault("olderThanMinutes", &olderThanMinutes, defaultStuckUserDeletionMinutes).
+					ReadPageRequestOrDefault("page", "size", "sort", &pageReq, pagination.NewPageRequest(0, 20)).
+					Complete()
+			}).Next(func() (err error) {
+				olderThan := time.Duration(olderThanMinutes) * time.Minute
+				resBody, err = u.userDeletionService.GetStuckUserDeletions(c, olderThan, pageReq)
+				return
+			}).Next(func() error {
+				c.JSON(http.StatusOK, resBody)
+				return nil
+			})
+		})
+}
+
+func NewUserDeletionControllerImpl(
+	userDeletionService services.UserDeletionService,
+	authMiddleware middlewares.AuthMiddleware,
+	ginCtxService ginservices.GinCtxService,
+) *UserDeletionControllerImpl {
+	return &UserDeletionControllerImpl{
+		userDeletionService: userDeletionService,
+		authMiddleware:      authMiddleware,
+		ginCtxService:       ginCtxService,
+	}
+}
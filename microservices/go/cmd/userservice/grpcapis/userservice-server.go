@@ -6,11 +6,20 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/gtools"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userpb"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmappers/grpcmappers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"time"
 )
 
+// watchUserChangesPollInterval is how often new changes are looked for while
+// watching user changes
+const watchUserChangesPollInterval = time.Second
+
+const watchUserChangesBatchSize = 100
+
 type UserServiceServerImpl struct {
 	userpb.UnimplementedUserServiceServer
-	userService services.UserService
+	userService             services.UserService
+	userChangeOutboxService services.UserChangeOutboxService
 }
 
 func (u UserServiceServerImpl) GetUserById(ctx context.Context, request *userpb.IdRequest) (*userpb.UserReply, error) {
@@ -36,6 +45,84 @@ func (u UserServiceServerImpl) GetUserByAuthId(
 	return userReply, err
 }
 
-func NewUserServiceServerImpl(userService services.UserService) *UserServiceServerImpl {
-	return &UserServiceServerImpl{userService: userService}
+func (u UserServiceServerImpl) GetUsersByIds(
+	ctx context.Context,
+	request *userpb.IdsRequest,
+) (*userpb.UsersReply, error) {
+	userDtos, err := u.userService.GetByIds(ctx, request.GetIds())
+	if err != nil {
+		return nil, gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+	}
+	return &userpb.UsersReply{Users: grpcmappers.UserReadDtosToUserReplies(userDtos)}, nil
+}
+
+// ListUsers sends the users ordered by ID a page at a time until every user is
+// sent
+func (u UserServiceServerImpl) ListUsers(
+	request *userpb.ListUsersRequest,
+	stream userpb.UserService_ListUsersServer,
+) error {
+	ctx := stream.Context()
+	afterId := request.GetAfterId()
+	for {
+		userDtos, err := u.userService.GetUsersAfterId(ctx, afterId, request.GetPageSize())
+		if err != nil {
+			return gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+		}
+		if len(userDtos) == 0 {
+			return nil
+		}
+		if err := stream.Send(&userpb.UsersReply{Users: grpcmappers.UserReadDtosToUserReplies(userDtos)}); err != nil {
+			return err
+		}
+		afterId = userDtos[len(userDtos)-1].Id
+	}
+}
+
+// WatchUserChanges sends the user changes as they are published until the
+// client stops watching
+func (u UserServiceServerImpl) WatchUserChanges(
+	request *userpb.WatchUserChangesRequest,
+	stream userpb.UserService_WatchUserChangesServer,
+) error {
+	ctx := stream.Context()
+	cursor := request.GetCursor()
+	if utils.StringIsBlank(cursor) {
+		var err error
+		if cursor, err = u.userChangeOutboxService.CurrentUserChangeCursor(ctx); err != nil {
+			return gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+		}
+	}
+	ticker := time.NewTicker(watchUserChangesPollInterval)
+	defer ticker.Stop()
+	for {
+		changeDtos, err := u.userChangeOutboxService.GetUserChangesAfter(ctx, cursor, watchUserChangesBatchSize)
+		if err != nil {
+			return gtools.ProcessErrorToGrpcStatusError(ctx, gtools.ReadAction, err)
+		}
+		for i := range changeDtos {
+			changeReply := &userpb.UserChangeReply{}
+			grpcmappers.UserChangeFeedDtoToUserChangeReply(&changeDtos[i], changeReply)
+			if err := stream.Send(changeReply); err != nil {
+				return err
+			}
+			cursor = changeDtos[i].Cursor
+		}
+		// A full batch means more changes may be waiting
+		if len(changeDtos) == watchUserChangesBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func NewUserServiceServerImpl(
+	userService services.UserService,
+	userChangeOutboxService services.UserChangeOutboxService,
+) *UserServiceServerImpl {
+	return &UserServiceServerImpl{userService: userService, userChangeOutboxService: userChangeOutboxService}
 }
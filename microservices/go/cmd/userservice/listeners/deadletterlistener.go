@@ -0,0 +1,97 @@
+package listeners
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/akrennmair/slice"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/deadletterdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/segmentio/kafka-go"
+)
+
+// DeadLetterListener archives the messages of the dead letter topics of user
+// changes
+type DeadLetterListener interface {
+	lifecycle.Closable
+	ListenDeadLetters()
+}
+
+type DeadLetterListenerImpl struct {
+	deadLetterService   services.DeadLetterService
+	deadLetterReceivers []*kfka.KafkaReceiver[kfka.RetryDto[json.RawMessage]]
+}
+
+func (d DeadLetterListenerImpl) ListenDeadLetters() {
+	for _, receiver := range d.deadLetterReceivers {
+		receiver.ListenWithMessage(false, func(retry kfka.RetryDto[json.RawMessage], msg kafka.Message) error {
+			ctx := context.Background()
+			deadLetterDto := deadletterdtos.DeadLetterDto{
+				Topic:     msg.Topic,
+				Partition: msg.Partition,
+				Offset:    msg.Offset,
+				Key:       string(msg.Key),
+				Value:     retry.Value,
+				Tries:     retry.Tries,
+			}
+			for _, retryErr := range retry.Errors {
+				deadLetterDto.Errors = append(deadLetterDto.Errors, deadletterdtos.DeadLetterErrorDto{
+					Topic:    retryErr.Topic,
+					Error:    retryErr.Error,
+					FailedAt: retryErr.FailedAt,
+				})
+			}
+			return d.deadLetterService.ArchiveDeadLetter(ctx, deadLetterDto)
+		})
+	}
+	logger.Log.Info("Listening for dead letters")
+}
+
+func (d DeadLetterListenerImpl) Close() error {
+	logger.Log.Info("Closing dead letter listener")
+	closableList := slice.Map(d.deadLetterReceivers,
+		func(r *kfka.KafkaReceiver[kfka.RetryDto[json.RawMessage]]) lifecycle.Closable { return r })
+	errs := slice.MapConcurrent(closableList, lifecycle.Closable.Close)
+	err := utils.ConcatErrors(errs...)
+	if err != nil {
+		logger.Log.WithError(err).Error("Errors closing listener")
+	}
+	logger.Log.Info("Finish closing dead letter listener")
+	return err
+}
+
+func NewDeadLetterListenerImpl(
+	deadLetterService services.DeadLetterService,
+	kafkaConf conf.KafkaConf,
+) *DeadLetterListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+
+	var deadLetterReceivers []*kfka.KafkaReceiver[kfka.RetryDto[json.RawMessage]]
+	for _, policy := range retrypolicies.NewUserChange1Policies() {
+		deadLetterReceivers = append(deadLetterReceivers, kfka.NewKafkaReceiver[kfka.RetryDto[json.RawMessage]](
+			kafka.NewReader(kafka.ReaderConfig{
+				Brokers:  kafkaConf.GetBootstrapServers(),
+				GroupID:  policy.DeadLetterTopic + "-user-service",
+				Topic:    policy.DeadLetterTopic,
+				MinBytes: 10e3, // 10KB
+				MaxBytes: 10e6, // 10MB
+			}),
+		))
+	}
+	r := &DeadLetterListenerImpl{
+		deadLetterService:   deadLetterService,
+		deadLetterReceivers: deadLetterReceivers,
+	}
+	lifecycle.RegisterClosable(r)
+	return r
+}
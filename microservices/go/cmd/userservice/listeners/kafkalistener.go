@@ -0,0 +1,43 @@
+package listeners
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+)
+
+type KafkaListener interface {
+	lifecycle.TaskRunner
+}
+
+type KafkaListenerImpl struct {
+	userDeleteAckListener      UserDeleteAckListener
+	userDataExportPartListener UserDataExportPartListener
+	deadLetterListener         DeadLetterListener
+}
+
+func (k KafkaListenerImpl) Run() {
+	k.userDeleteAckListener.ListenUserDeleteAck()
+	k.userDataExportPartListener.ListenUserDataExportPart()
+	k.deadLetterListener.ListenDeadLetters()
+	forever := make(chan any)
+	<-forever
+}
+
+func NewKafkaListenerImpl(
+	userDeleteAckListener UserDeleteAckListener,
+	userDataExportPartListener UserDataExportPartListener,
+	deadLetterListener DeadLetterListener,
+) *KafkaListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+	r := &KafkaListenerImpl{
+		userDeleteAckListener:      userDeleteAckListener,
+		userDataExportPartListener: userDataExportPartListener,
+		deadLetterListener:         deadLetterListener,
+	}
+	lifecycle.RegisterTaskRunner(r)
+	return r
+}
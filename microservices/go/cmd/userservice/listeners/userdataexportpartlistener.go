@@ -0,0 +1,69 @@
+package listeners
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+)
+
+type UserDataExportPartListener interface {
+	lifecycle.Closable
+	ListenUserDataExportPart()
+}
+
+type UserDataExportPartListenerImpl struct {
+	userDataExportService services.UserDataExportService
+	partConsumer          *kfka.RetryingConsumer[userdtos.UserDataExportPartDto]
+}
+
+func (k UserDataExportPartListenerImpl) ListenUserDataExportPart() {
+	k.partConsumer.Listen(
+		func(ctx context.Context, dto userdtos.UserDataExportPartDto) error {
+			err := k.userDataExportService.HandleUserDataExportPartTxn(ctx, dto)
+			if err != nil {
+				logger.Log.WithError(err).Error("Error handling user data export part")
+			}
+			return err
+		},
+		nil,
+	)
+	logger.Log.Info("Listening for user data export parts")
+}
+
+func (k UserDataExportPartListenerImpl) Close() error {
+	logger.Log.Info("Closing user data export part listener")
+	err := k.partConsumer.Close()
+	if err != nil {
+		logger.Log.WithError(err).Error("Errors closing listener")
+	}
+	logger.Log.Info("Finish closing user data export part listener")
+	return err
+}
+
+func NewUserDataExportPartListenerImpl(
+	userDataExportService services.UserDataExportService,
+	kafkaConf conf.KafkaConf,
+) *UserDataExportPartListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+
+	partConsumer := kfka.NewRetryingConsumer(
+		kafkaConf,
+		retrypolicies.NewUserDataExportPartUserServicePolicy(),
+		userdtos.UserDataExportPartDto.MessageKey,
+	)
+
+	return &UserDataExportPartListenerImpl{
+		userDataExportService: userDataExportService,
+		partConsumer:          partConsumer,
+	}
+}
@@ -0,0 +1,69 @@
+package listeners
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+)
+
+type UserDeleteAckListener interface {
+	lifecycle.Closable
+	ListenUserDeleteAck()
+}
+
+type UserDeleteAckListenerImpl struct {
+	userDeletionService services.UserDeletionService
+	ackConsumer         *kfka.RetryingConsumer[userdtos.UserDeleteAckDto]
+}
+
+func (k UserDeleteAckListenerImpl) ListenUserDeleteAck() {
+	k.ackConsumer.Listen(
+		func(ctx context.Context, dto userdtos.UserDeleteAckDto) error {
+			err := k.userDeletionService.HandleUserDeleteAckTxn(ctx, dto)
+			if err != nil {
+				logger.Log.WithError(err).Error("Error handling user deletion acknowledgement")
+			}
+			return err
+		},
+		nil,
+	)
+	logger.Log.Info("Listening for user deletion acknowledgements")
+}
+
+func (k UserDeleteAckListenerImpl) Close() error {
+	logger.Log.Info("Closing user deletion acknowledgement listener")
+	err := k.ackConsumer.Close()
+	if err != nil {
+		logger.Log.WithError(err).Error("Errors closing listener")
+	}
+	logger.Log.Info("Finish closing user deletion acknowledgement listener")
+	return err
+}
+
+func NewUserDeleteAckListenerImpl(
+	userDeletionService services.UserDeletionService,
+	kafkaConf conf.KafkaConf,
+) *UserDeleteAckListenerImpl {
+	if !environment.ActivateKafkaListener() {
+		// Listener is deactivated, ran via the lifecycle package,
+		// and is a root-child dependency so a nil is returned
+		return nil
+	}
+
+	ackConsumer := kfka.NewRetryingConsumer(
+		kafkaConf,
+		retrypolicies.NewUserDeleteAckUserServicePolicy(),
+		userdtos.UserDeleteAckDto.MessageKey,
+	)
+
+	return &UserDeleteAckListenerImpl{
+		userDeletionService: userDeletionService,
+		ackConsumer:         ackConsumer,
+	}
+}
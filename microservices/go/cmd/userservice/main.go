@@ -2,12 +2,18 @@ package main
 
 import (
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/app"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/cli"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"os"
 )
 
 func main() {
 	environment.ReadEnvFiles(".env", "userservice.env") // Load env files
 	logger.ConfigureLoggerFromEnv()
+	if len(os.Args) > 1 && os.Args[1] == cli.DeadLetterCommand {
+		app.RunDeadLetterCli(os.Args[2:])
+		return
+	}
 	app.Start()
 }
@@ -1,10 +1,14 @@
 package mappers
 
 import (
+	"encoding/json"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/deadletterdtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded/embeddeduser"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmappers"
+	"time"
 )
 
 func UserSaveDtoToUser(source userdtos.UserSaveDto, dest *models.User) {
@@ -12,6 +16,19 @@ func UserSaveDtoToUser(source userdtos.UserSaveDto, dest *models.User) {
 	dest.DisplayName = source.DisplayName
 }
 
+func UserProfileSaveDtoToUser(source userdtos.UserProfileSaveDto, dest *models.User) {
+	dest.Timezone = source.Timezone
+	dest.Locale = source.Locale
+	dest.Bio = source.Bio
+}
+
+func UserPreferencesSaveDtoToUser(source userdtos.UserPreferencesSaveDto, dest *models.User) {
+	dest.Preferences.DefaultNoteSortField = source.DefaultNoteSort.Field
+	dest.Preferences.DefaultNoteSortDirection = string(source.DefaultNoteSort.Direction)
+	dest.Preferences.KeySessionMinutes = source.KeySessionMinutes
+	dest.Preferences.Theme = source.Theme
+}
+
 func UserToUserDto(source models.User, dest *userdtos.UserReadDto) {
 	dest.Exists = !source.IsIdEmpty()
 	userToUserPublicDto(source, &dest.BaseUserPublicDto)
@@ -22,8 +39,110 @@ func UserToUserChangeEventDto(source models.User, dest *userdtos.UserChangeEvent
 	userToUserPublicDto(source, &dest.BaseUserPublicDto)
 }
 
+func UserToUserAdminDto(source models.User, dest *userdtos.UserAdminDto) {
+	dest.Exists = !source.IsIdEmpty()
+	dest.AuthId = source.AuthId
+	dest.ChangeSequence = source.ChangeSequence
+	userToUserPublicDto(source, &dest.BaseUserPublicDto)
+}
+
 func userToUserPublicDto(source models.User, dest *embeddeduser.BaseUserPublicDto) {
 	sharedmappers.MapMongoModelToBaseCrudObject(&source, &dest.BaseCRUDObject)
 	dest.UserName = source.UserName
 	dest.DisplayName = source.DisplayName
+	dest.Timezone = source.Timezone
+	dest.Locale = source.Locale
+	dest.Bio = source.Bio
+	dest.AvatarId = source.AvatarId
+	dest.Suspended = source.Suspended
+	dest.Preferences.DefaultNoteSort.Field = source.Preferences.DefaultNoteSortField
+	dest.Preferences.DefaultNoteSort.Direction = pagination.Direction(source.Preferences.DefaultNoteSortDirection)
+	dest.Preferences.KeySessionMinutes = source.Preferences.KeySessionMinutes
+	dest.Preferences.Theme = source.Preferences.Theme
+}
+
+func UserDeletionToUserDeletionDto(source models.UserDeletion, dest *userdtos.UserDeletionDto) {
+	dest.UserId = source.UserId
+	dest.UserName = source.UserName
+	dest.Status = source.Status
+	dest.IdentityProviderDeleted = source.IdentityProviderDeleted
+	dest.CreatedAt = source.CreatedAt.UnixMilli()
+	dest.UpdatedAt = source.UpdatedAt.UnixMilli()
+	dest.Participants = make([]userdtos.UserDeletionParticipantDto, 0, len(source.Participants))
+	for _, participant := range source.Participants {
+		dest.Participants = append(dest.Participants, userdtos.UserDeletionParticipantDto{
+			Participant: participant.Participant,
+			Status:      participant.Status,
+			Error:       participant.Error,
+			UpdatedAt:   participant.UpdatedAt.UnixMilli(),
+		})
+	}
+}
+
+func UserDataExportToUserDataExportDto(source models.UserDataExport, dest *userdtos.UserDataExportDto) {
+	dest.Id = source.GetIdStr()
+	dest.Status = source.Status
+	dest.CreatedAt = source.CreatedAt.UnixMilli()
+	dest.UpdatedAt = source.UpdatedAt.UnixMilli()
+	dest.ExpiresAt = source.GetExpiresAt().UnixMilli()
+	dest.Participants = make([]userdtos.UserDataExportParticipantDto, 0, len(source.Parts))
+	for _, part := range source.Parts {
+		dest.Participants = append(dest.Participants, userdtos.UserDataExportParticipantDto{
+			Participant: part.Participant,
+			Status:      part.Status,
+			Error:       part.Error,
+			UpdatedAt:   part.UpdatedAt.UnixMilli(),
+		})
+	}
+}
+
+func DeadLetterDtoToDeadLetter(source deadletterdtos.DeadLetterDto, dest *models.DeadLetter) {
+	dest.Topic = source.Topic
+	dest.Partition = source.Partition
+	dest.Offset = source.Offset
+	dest.Key = source.Key
+	dest.Value = string(source.Value)
+	dest.Tries = source.Tries
+	dest.Errors = make([]models.DeadLetterError, 0, len(source.Errors))
+	for _, deadLetterErr := range source.Errors {
+		dest.Errors = append(dest.Errors, models.DeadLetterError{
+			Topic:    deadLetterErr.Topic,
+			Error:    deadLetterErr.Error,
+			FailedAt: time.UnixMilli(deadLetterErr.FailedAt),
+		})
+	}
+}
+
+func DeadLetterToDeadLetterDto(source models.DeadLetter, dest *deadletterdtos.DeadLetterDto) {
+	dest.Id = source.GetIdStr()
+	dest.CreatedAt = source.CreatedAt.UnixMilli()
+	dest.UpdatedAt = source.UpdatedAt.UnixMilli()
+	dest.Topic = source.Topic
+	dest.Partition = source.Partition
+	dest.Offset = source.Offset
+	dest.Key = source.Key
+	dest.Value = json.RawMessage(source.Value)
+	dest.Tries = source.Tries
+	dest.Errors = make([]deadletterdtos.DeadLetterErrorDto, 0, len(source.Errors))
+	for _, deadLetterErr := range source.Errors {
+		dest.Errors = append(dest.Errors, deadletterdtos.DeadLetterErrorDto{
+			Topic:    deadLetterErr.Topic,
+			Error:    deadLetterErr.Error,
+			FailedAt: deadLetterErr.FailedAt.UnixMilli(),
+		})
+	}
+}
+
+func DeadLetterAuditToDeadLetterAuditDto(source models.DeadLetterAudit, dest *deadletterdtos.DeadLetterAuditDto) {
+	dest.Id = source.GetIdStr()
+	dest.CreatedAt = source.CreatedAt.UnixMilli()
+	dest.UpdatedAt = source.UpdatedAt.UnixMilli()
+	dest.Action = source.Action
+	dest.Actor = source.Actor
+	dest.Topic = source.Topic
+	dest.TargetTopic = source.TargetTopic
+	dest.All = source.All
+	dest.Ids = source.Ids
+	dest.Count = source.Count
+	dest.Error = source.Error
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/kamva/mgm/v3"
+	"time"
+)
+
+// DeadLetterAudit records an operator replaying or purging dead letters
+type DeadLetterAudit struct {
+	// DefaultModel adds _id, created_at and updated_at fields to the Model
+	mgm.DefaultModel `bson:",inline"`
+	Action           string   `bson:"action"`
+	Actor            string   `bson:"actor"`
+	Topic            string   `bson:"topic"`
+	TargetTopic      string   `bson:"targetTopic"`
+	All              bool     `bson:"all"`
+	Ids              []string `bson:"ids"`
+	Count            int64    `bson:"count"`
+	Error            string   `bson:"error"`
+}
+
+func (d DeadLetterAudit) GetIdStr() string {
+	return d.ID.Hex()
+}
+
+func (d DeadLetterAudit) IsIdEmpty() bool {
+	return d.ID.IsZero()
+}
+
+func (d *DeadLetterAudit) CollectionName() string {
+	return "deadLetterAudits"
+}
+
+func (d DeadLetterAudit) GetCreatedAt() time.Time {
+	return d.CreatedAt
+}
+
+func (d DeadLetterAudit) GetUpdatedAt() time.Time {
+	return d.UpdatedAt
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/kamva/mgm/v3"
+	"time"
+)
+
+// DeadLetter is a message read from a dead letter topic, kept until it is
+// replayed or purged. Value is the JSON of the message's value.
+type DeadLetter struct {
+	// DefaultModel adds _id, created_at and updated_at fields to the Model
+	mgm.DefaultModel `bson:",inline"`
+	Topic            string            `bson:"topic"`
+	Partition        int               `bson:"partition"`
+	Offset           int64             `bson:"offset"`
+	Key              string            `bson:"key"`
+	Value            string            `bson:"value"`
+	Tries            int               `bson:"tries"`
+	Errors           []DeadLetterError `bson:"errors"`
+}
+
+type DeadLetterError struct {
+	Topic    string    `bson:"topic"`
+	Error    string    `bson:"error"`
+	FailedAt time.Time `bson:"failedAt"`
+}
+
+func (d DeadLetter) GetIdStr() string {
+	return d.ID.Hex()
+}
+
+func (d DeadLetter) IsIdEmpty() bool {
+	return d.ID.IsZero()
+}
+
+func (d *DeadLetter) CollectionName() string {
+	return "deadLetters"
+}
+
+func (d DeadLetter) GetCreatedAt() time.Time {
+	return d.CreatedAt
+}
+
+func (d DeadLetter) GetUpdatedAt() time.Time {
+	return d.UpdatedAt
+}
@@ -0,0 +1,11 @@
+package models
+
+// UserAvatar is an avatar image stored in GridFS
+type UserAvatar struct {
+	UserId      string
+	ContentType string
+	Data        []byte
+}
+
+// UserAvatarMaxBytes is the largest avatar image that can be uploaded
+const UserAvatarMaxBytes = 1 << 20
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"github.com/kamva/mgm/v3"
+	"time"
+)
+
+// UserChangeOutboxEvent is a user change event waiting to be published. It is
+// written in the same transaction as the change so no change is published
+// without being saved or saved without being published.
+type UserChangeOutboxEvent struct {
+	// DefaultModel adds _id, created_at and updated_at fields to the Model
+	mgm.DefaultModel `bson:",inline"`
+	UserId           string `bson:"userId"`
+	// Sequence orders the events of a user and matches the user's change
+	// sequence when the event was written
+	Sequence int64 `bson:"sequence"`
+	// Payload is the event as it is published in JSON
+	Payload string    `bson:"payload"`
+	Sent    bool      `bson:"sent"`
+	SentAt  time.Time `bson:"sentAt"`
+	// FeedPosition orders the sent events of every user in the change feed
+	FeedPosition int64 `bson:"feedPosition"`
+}
+
+func (u UserChangeOutboxEvent) GetIdStr() string {
+	return u.ID.Hex()
+}
+
+func (u UserChangeOutboxEvent) IsIdEmpty() bool {
+	return u.ID.IsZero()
+}
+
+func (u *UserChangeOutboxEvent) CollectionName() string {
+	return "userChangeOutbox"
+}
+
+func (u UserChangeOutboxEvent) GetCreatedAt() time.Time {
+	return u.CreatedAt
+}
+
+func (u UserChangeOutboxEvent) GetUpdatedAt() time.Time {
+	return u.UpdatedAt
+}
+
+// UserChangeOutboxEvents are the unsent events of a user ordered by sequence
+type UserChangeOutboxEvents struct {
+	UserId string                  `bson:"_id"`
+	Events []UserChangeOutboxEvent `bson:"events"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"github.com/kamva/mgm/v3"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"time"
+)
+
+// UserDataExportActiveDuration is how long a pending data export blocks the
+// user from requesting another one. Participants that have not replied by then
+// are assumed to have lost the request and the export is failed.
+const UserDataExportActiveDuration = 24 * time.Hour
+
+// UserDataExportRetention is how long a data export is kept before it expires
+const UserDataExportRetention = 7 * 24 * time.Hour
+
+// UserDataExport collects the data every service holds about a user so it can
+// be downloaded as a single bundle
+type UserDataExport struct {
+	// DefaultModel adds _id, created_at and updated_at fields to the Model
+	mgm.DefaultModel `bson:",inline"`
+	UserId           string `bson:"userId"`
+	Status           string `bson:"status"`
+	// Profile is the JSON document of the user's profile when the export was
+	// requested
+	Profile string               `bson:"profile"`
+	Parts   []UserDataExportPart `bson:"parts"`
+	// RequestSent is set once the export request is published to the
+	// participants. Exports double as the outbox of their requests.
+	RequestSent   bool      `bson:"requestSent"`
+	RequestSentAt time.Time `bson:"requestSentAt,omitempty"`
+}
+
+type UserDataExportPart struct {
+	Participant string `bson:"participant"`
+	Status      string `bson:"status"`
+	Error       string `bson:"error"`
+	// Data is the JSON document the participant exported
+	Data      string    `bson:"data"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+}
+
+func (u UserDataExport) GetIdStr() string {
+	return u.ID.Hex()
+}
+
+func (u UserDataExport) IsIdEmpty() bool {
+	return u.ID.IsZero()
+}
+
+func (u *UserDataExport) CollectionName() string {
+	return "userDataExports"
+}
+
+func (u UserDataExport) GetCreatedAt() time.Time {
+	return u.CreatedAt
+}
+
+func (u UserDataExport) GetUpdatedAt() time.Time {
+	return u.UpdatedAt
+}
+
+func (u UserDataExport) GetExpiresAt() time.Time {
+	return u.CreatedAt.Add(UserDataExportRetention)
+}
+
+func (u UserDataExport) IsCompleted() bool {
+	return u.Status == userdtos.UserDataExportCompleted
+}
+
+func (u UserDataExport) IsPending() bool {
+	return u.Status == userdtos.UserDataExportPending
+}
+
+// PartsStatus returns completed if every participant completed, failed if any
+// participant failed, and pending otherwise
+func (u UserDataExport) PartsStatus() string {
+	status := userdtos.UserDataExportCompleted
+	for _, part := range u.Parts {
+		switch part.Status {
+		case userdtos.UserDataExportFailed:
+			return userdtos.UserDataExportFailed
+		case userdtos.UserDataExportPending:
+			status = userdtos.UserDataExportPending
+		}
+	}
+	return status
+}
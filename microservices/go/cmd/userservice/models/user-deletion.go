@@ -0,0 +1,70 @@
+package models
+
+import (
+	"github.com/kamva/mgm/v3"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"time"
+)
+
+// UserDeletion tracks a deleted user until every service that holds the user's
+// data acknowledged deleting it. The user is then deleted from the auth server
+// after the deletion is completed, so the record also serves as the outbox of
+// auth server deletions.
+type UserDeletion struct {
+	// DefaultModel adds _id, created_at and updated_at fields to the Model
+	mgm.DefaultModel `bson:",inline"`
+	UserId           string                    `bson:"userId"`
+	AuthId           string                    `bson:"authId"`
+	UserName         string                    `bson:"userName"`
+	Status           string                    `bson:"status"`
+	Participants     []UserDeletionParticipant `bson:"participants"`
+	// IdentityProviderDeleted is true once the user is deleted from the auth
+	// server, which finishes the deletion
+	IdentityProviderDeleted bool `bson:"identityProviderDeleted"`
+}
+
+type UserDeletionParticipant struct {
+	Participant string    `bson:"participant"`
+	Status      string    `bson:"status"`
+	Error       string    `bson:"error"`
+	UpdatedAt   time.Time `bson:"updatedAt"`
+}
+
+func (u UserDeletion) GetIdStr() string {
+	return u.ID.Hex()
+}
+
+func (u UserDeletion) IsIdEmpty() bool {
+	return u.ID.IsZero()
+}
+
+func (u *UserDeletion) CollectionName() string {
+	return "userDeletions"
+}
+
+func (u UserDeletion) GetCreatedAt() time.Time {
+	return u.CreatedAt
+}
+
+func (u UserDeletion) GetUpdatedAt() time.Time {
+	return u.UpdatedAt
+}
+
+func (u UserDeletion) IsCompleted() bool {
+	return u.Status == userdtos.UserDeletionCompleted
+}
+
+// ParticipantsStatus returns completed if every participant completed, failed
+// if any participant failed, and pending otherwise
+func (u UserDeletion) ParticipantsStatus() string {
+	status := userdtos.UserDeletionCompleted
+	for _, participant := range u.Participants {
+		switch participant.Status {
+		case userdtos.UserDeletionFailed:
+			return userdtos.UserDeletionFailed
+		case userdtos.UserDeletionPending:
+			status = userdtos.UserDeletionPending
+		}
+	}
+	return status
+}
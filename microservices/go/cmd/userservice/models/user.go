@@ -11,8 +11,27 @@ type User struct {
 	AuthId           string `json:"authId" bson:"authId"`
 	UserName         string `json:"userName" bson:"userName"`
 	DisplayName      string `json:"displayName" bson:"displayName"`
-	Distributed      bool   `json:"distributed" bson:"distributed"`
-	ToBeDeleted      bool   `json:"toBeDeleted" bson:"toBeDeleted"`
+	Timezone         string `json:"timezone" bson:"timezone"`
+	Locale           string `json:"locale" bson:"locale"`
+	Bio              string `json:"bio" bson:"bio"`
+	// AvatarId is the ID of the avatar image in GridFS, if any
+	AvatarId    string          `json:"avatarId" bson:"avatarId"`
+	Preferences UserPreferences `json:"preferences" bson:"preferences"`
+	Suspended   bool            `json:"suspended" bson:"suspended"`
+	// ChangeSequence is incremented with each change so the change events of a
+	// user can be published in order
+	ChangeSequence int64 `json:"changeSequence" bson:"changeSequence"`
+}
+
+type UserPreferences struct {
+	DefaultNoteSortField     string `json:"defaultNoteSortField" bson:"defaultNoteSortField"`
+	DefaultNoteSortDirection string `json:"defaultNoteSortDirection" bson:"defaultNoteSortDirection"`
+	KeySessionMinutes        int64  `json:"keySessionMinutes" bson:"keySessionMinutes"`
+	Theme                    string `json:"theme" bson:"theme"`
+}
+
+func (u User) HasAvatar() bool {
+	return u.AvatarId != ""
 }
 
 func (u User) GetIdStr() string {
@@ -34,7 +53,3 @@ func (u User) GetCreatedAt() time.Time {
 func (u User) GetUpdatedAt() time.Time {
 	return u.UpdatedAt
 }
-
-func (u User) WillNotBeDeleted() bool {
-	return !u.ToBeDeleted
-}
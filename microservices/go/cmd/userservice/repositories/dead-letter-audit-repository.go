@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"github.com/kamva/mgm/v3"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type DeadLetterAuditRepository interface {
+	baserepos.CRUDRepository[models.DeadLetterAudit, string]
+	GetPaginated(ctx context.Context, pageReq pagination.PageRequest) ([]models.DeadLetterAudit, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+type DeadLetterAuditRepositoryImpl struct {
+	baserepos.BaseRepositoryMongo[models.DeadLetterAudit]
+}
+
+func (d DeadLetterAuditRepositoryImpl) Create(
+	ctx context.Context,
+	model models.DeadLetterAudit,
+) (models.DeadLetterAudit, error) {
+	err := mgm.Coll(d.ModelColl).CreateWithCtx(d.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (d DeadLetterAuditRepositoryImpl) Update(
+	ctx context.Context,
+	model models.DeadLetterAudit,
+) (models.DeadLetterAudit, error) {
+	err := mgm.Coll(d.ModelColl).UpdateWithCtx(d.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (d DeadLetterAuditRepositoryImpl) Delete(
+	ctx context.Context,
+	model models.DeadLetterAudit,
+) (models.DeadLetterAudit, error) {
+	err := mgm.Coll(d.ModelColl).DeleteWithCtx(d.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (d DeadLetterAuditRepositoryImpl) FindById(
+	ctx context.Context,
+	id string,
+) (option.Maybe[models.DeadLetterAudit], error) {
+	return dshandlers.HandleSingleFind(d.MongoDBHandler, func() (models.DeadLetterAudit, error) {
+		model := models.DeadLetterAudit{}
+		err := mgm.Coll(d.ModelColl).FindByIDWithCtx(d.MongoDBHandler.ToChildCtx(ctx), id, &model)
+		return model, err
+	})
+}
+
+func (d DeadLetterAuditRepositoryImpl) GetPaginated(
+	ctx context.Context,
+	pageReq pagination.PageRequest,
+) ([]models.DeadLetterAudit, error) {
+	findOpts := mgmtools.CreatePaginatedFindOpts(pageReq)
+	childCtx := d.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(d.ModelColl).Find(childCtx, bson.M{}, findOpts)
+	return mgmtools.HandleFindManyRes[models.DeadLetterAudit](childCtx, cursor, err)
+}
+
+func (d DeadLetterAuditRepositoryImpl) Count(ctx context.Context) (int64, error) {
+	return mgm.Coll(d.ModelColl).CountDocuments(d.MongoDBHandler.ToChildCtx(ctx), bson.M{})
+}
+
+func NewDeadLetterAuditRepositoryImpl(mongoDBHandler *dshandlers.MongoDBHandler) *DeadLetterAuditRepositoryImpl {
+	return &DeadLetterAuditRepositoryImpl{
+		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.DeadLetterAudit](
+			models.DeadLetterAudit{},
+			mongoDBHandler,
+		),
+	}
+}
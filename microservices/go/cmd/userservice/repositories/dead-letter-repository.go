@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/operator"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DeadLetterRepository interface {
+	baserepos.CRUDRepository[models.DeadLetter, string]
+
+	// CreateIfAbsent creates the dead letter unless a dead letter was already
+	// read from the same topic, partition and offset
+	CreateIfAbsent(ctx context.Context, model models.DeadLetter) error
+
+	// GetPaginatedByTopic returns the dead letters of a topic, or of every topic
+	// if the topic is blank
+	GetPaginatedByTopic(ctx context.Context, topic string, pageReq pagination.PageRequest) ([]models.DeadLetter, error)
+	CountByTopic(ctx context.Context, topic string) (int64, error)
+
+	// FindByIdsInTopic returns the dead letters of a topic with the IDs. IDs of
+	// missing dead letters are ignored.
+	FindByIdsInTopic(ctx context.Context, topic string, ids []string) ([]models.DeadLetter, error)
+
+	// GetAfterIdInTopic returns up to limit dead letters of a topic ordered by
+	// ID, starting after the dead letter with afterId or from the first dead
+	// letter if afterId is blank
+	GetAfterIdInTopic(ctx context.Context, topic string, afterId string, limit int64) ([]models.DeadLetter, error)
+
+	DeleteByIdsInTopicAndGetCount(ctx context.Context, topic string, ids []string) (int64, error)
+	DeleteByTopicAndGetCount(ctx context.Context, topic string) (int64, error)
+}
+
+type DeadLetterRepositoryImpl struct {
+	baserepos.BaseRepositoryMongo[models.DeadLetter]
+}
+
+func (d DeadLetterRepositoryImpl) Create(ctx context.Context, model models.DeadLetter) (models.DeadLetter, error) {
+	err := mgm.Coll(d.ModelColl).CreateWithCtx(d.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (d DeadLetterRepositoryImpl) Update(ctx context.Context, model models.DeadLetter) (models.DeadLetter, error) {
+	err := mgm.Coll(d.ModelColl).UpdateWithCtx(d.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (d DeadLetterRepositoryImpl) Delete(ctx context.Context, model models.DeadLetter) (models.DeadLetter, error) {
+	err := mgm.Coll(d.ModelColl).DeleteWithCtx(d.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (d DeadLetterRepositoryImpl) FindById(ctx context.Context, id string) (option.Maybe[models.DeadLetter], error) {
+	return dshandlers.HandleSingleFind(d.MongoDBHandler, func() (models.DeadLetter, error) {
+		model := models.DeadLetter{}
+		err := mgm.Coll(d.ModelColl).FindByIDWithCtx(d.MongoDBHandler.ToChildCtx(ctx), id, &model)
+		return model, err
+	})
+}
+
+// CreateIfAbsent relies on the unique index of the topic, partition and offset
+// of dead letters, as messages are read again if reading them was not committed
+func (d DeadLetterRepositoryImpl) CreateIfAbsent(ctx context.Context, model models.DeadLetter) error {
+	err := mgm.Coll(d.ModelColl).CreateWithCtx(d.MongoDBHandler.ToChildCtx(ctx), &model)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (d DeadLetterRepositoryImpl) GetPaginatedByTopic(
+	ctx context.Context,
+	topic string,
+	pageReq pagination.PageRequest,
+) ([]models.DeadLetter, error) {
+	findOpts := mgmtools.CreatePaginatedFindOpts(pageReq)
+	childCtx := d.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(d.ModelColl).Find(childCtx, deadLetterTopicFilter(topic), findOpts)
+	return mgmtools.HandleFindManyRes[models.DeadLetter](childCtx, cursor, err)
+}
+
+func (d DeadLetterRepositoryImpl) CountByTopic(ctx context.Context, topic string) (int64, error) {
+	return mgm.Coll(d.ModelColl).CountDocuments(d.MongoDBHandler.ToChildCtx(ctx), deadLetterTopicFilter(topic))
+}
+
+func (d DeadLetterRepositoryImpl) FindByIdsInTopic(
+	ctx context.Context,
+	topic string,
+	ids []string,
+) ([]models.DeadLetter, error) {
+	childCtx := d.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(d.ModelColl).Find(childCtx, deadLetterIdsFilter(topic, ids))
+	return mgmtools.HandleFindManyRes[models.DeadLetter](childCtx, cursor, err)
+}
+
+func (d DeadLetterRepositoryImpl) GetAfterIdInTopic(
+	ctx context.Context,
+	topic string,
+	afterId string,
+	limit int64,
+) ([]models.DeadLetter, error) {
+	filter := bson.M{"topic": topic}
+	if utils.StringIsNotBlank(afterId) {
+		afterObjectId, err := primitive.ObjectIDFromHex(afterId)
+		if err != nil {
+			return nil, err
+		}
+		filter["_id"] = bson.M{operator.Gt: afterObjectId}
+	}
+	findOpts := options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(limit)
+	childCtx := d.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(d.ModelColl).Find(childCtx, filter, findOpts)
+	return mgmtools.HandleFindManyRes[models.DeadLetter](childCtx, cursor, err)
+}
+
+func (d DeadLetterRepositoryImpl) DeleteByIdsInTopicAndGetCount(
+	ctx context.Context,
+	topic string,
+	ids []string,
+) (int64, error) {
+	res, err := mgm.Coll(d.ModelColl).DeleteMany(d.MongoDBHandler.ToChildCtx(ctx), deadLetterIdsFilter(topic, ids))
+	if res != nil {
+		return res.DeletedCount, err
+	}
+	return -1, err
+}
+
+func (d DeadLetterRepositoryImpl) DeleteByTopicAndGetCount(ctx context.Context, topic string) (int64, error) {
+	res, err := mgm.Coll(d.ModelColl).DeleteMany(d.MongoDBHandler.ToChildCtx(ctx), bson.M{"topic": topic})
+	if res != nil {
+		return res.DeletedCount, err
+	}
+	return -1, err
+}
+
+func deadLetterTopicFilter(topic string) bson.M {
+	if utils.StringIsBlank(topic) {
+		return bson.M{}
+	}
+	return bson.M{"topic": topic}
+}
+
+func deadLetterIdsFilter(topic string, ids []string) bson.M {
+	objectIds := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objectId, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIds = append(objectIds, objectId)
+		}
+	}
+	return bson.M{"topic": topic, "_id": bson.M{operator.In: objectIds}}
+}
+
+func NewDeadLetterRepositoryImpl(mongoDBHandler *dshandlers.MongoDBHandler) *DeadLetterRepositoryImpl {
+	return &DeadLetterRepositoryImpl{
+		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.DeadLetter](models.DeadLetter{}, mongoDBHandler),
+	}
+}
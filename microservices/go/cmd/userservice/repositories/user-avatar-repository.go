@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/kamva/mgm/v3"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const userAvatarBucketName = "userAvatars"
+
+// UserAvatarRepository stores avatar images in GridFS. GridFS does not take
+// part in transactions, so files must be removed if the transaction that
+// references them fails.
+type UserAvatarRepository interface {
+	// Create stores an avatar and returns its ID
+	Create(ctx context.Context, avatar models.UserAvatar) (string, error)
+	FindById(ctx context.Context, avatarId string) (option.Maybe[models.UserAvatar], error)
+	Delete(ctx context.Context, avatarId string) error
+}
+
+type UserAvatarRepositoryImpl struct {
+	mongoDBHandler *dshandlers.MongoDBHandler
+}
+
+type userAvatarMetadata struct {
+	UserId      string `bson:"userId"`
+	ContentType string `bson:"contentType"`
+}
+
+func (u UserAvatarRepositoryImpl) Create(ctx context.Context, avatar models.UserAvatar) (string, error) {
+	bucket, err := u.openBucket(ctx)
+	if err != nil {
+		return "", err
+	}
+	uploadOpts := options.GridFSUpload().
+		SetMetadata(userAvatarMetadata{UserId: avatar.UserId, ContentType: avatar.ContentType})
+	fileId, err := bucket.UploadFromStream(avatar.UserId, bytes.NewReader(avatar.Data), uploadOpts)
+	if err != nil {
+		return "", err
+	}
+	return fileId.Hex(), nil
+}
+
+func (u UserAvatarRepositoryImpl) FindById(
+	ctx context.Context,
+	avatarId string,
+) (option.Maybe[models.UserAvatar], error) {
+	fileId, err := primitive.ObjectIDFromHex(avatarId)
+	if err != nil {
+		return option.None[models.UserAvatar](), nil
+	}
+	bucket, err := u.openBucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	downloadStream, err := bucket.OpenDownloadStream(fileId)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return option.None[models.UserAvatar](), nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer downloadStream.Close()
+
+	metadata := userAvatarMetadata{}
+	if err := bson.Unmarshal(downloadStream.GetFile().Metadata, &metadata); err != nil {
+		return nil, err
+	}
+	data := bytes.Buffer{}
+	if _, err := data.ReadFrom(downloadStream); err != nil {
+		return nil, err
+	}
+	return option.Perhaps(models.UserAvatar{
+		UserId:      metadata.UserId,
+		ContentType: metadata.ContentType,
+		Data:        data.Bytes(),
+	}), nil
+}
+
+func (u UserAvatarRepositoryImpl) Delete(ctx context.Context, avatarId string) error {
+	fileId, err := primitive.ObjectIDFromHex(avatarId)
+	if err != nil {
+		return err
+	}
+	bucket, err := u.openBucket(ctx)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Delete(fileId); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return err
+	}
+	return nil
+}
+
+// openBucket opens the avatar bucket with the deadline of a database operation
+func (u UserAvatarRepositoryImpl) openBucket(ctx context.Context) (*gridfs.Bucket, error) {
+	_, _, db, err := mgm.DefaultConfigs()
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(userAvatarBucketName))
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := u.mongoDBHandler.ToChildCtx(ctx).Deadline(); ok {
+		if err := bucket.SetWriteDeadline(deadline); err != nil {
+			return nil, err
+		}
+		if err := bucket.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	return bucket, nil
+}
+
+func NewUserAvatarRepositoryImpl(mongoDBHandler *dshandlers.MongoDBHandler) *UserAvatarRepositoryImpl {
+	return &UserAvatarRepositoryImpl{mongoDBHandler: mongoDBHandler}
+}
@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"context"
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/operator"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type UserChangeOutboxRepository interface {
+	Create(ctx context.Context, model models.UserChangeOutboxEvent) (models.UserChangeOutboxEvent, error)
+
+	// FindUnsentGroupedByUserId returns the unsent events of up to userLimit
+	// users, with the events of each user ordered by sequence
+	FindUnsentGroupedByUserId(ctx context.Context, userLimit int64) ([]models.UserChangeOutboxEvents, error)
+
+	// MarkSent marks an unsent event as sent at the position of the change
+	// feed, returning false if the event was already sent
+	MarkSent(ctx context.Context, id string, sentAt time.Time, feedPosition int64) (bool, error)
+
+	// NextFeedPosition reserves the change feed position after the last one
+	// given out
+	NextFeedPosition(ctx context.Context) (int64, error)
+
+	// GetFeedPosition returns the last change feed position given out
+	GetFeedPosition(ctx context.Context) (int64, error)
+
+	// FindLatestByUserId returns the event with the highest sequence of a user,
+	// or of the user's sent events if onlySent is true
+	FindLatestByUserId(
+		ctx context.Context,
+		userId string,
+		onlySent bool,
+	) (option.Maybe[models.UserChangeOutboxEvent], error)
+	CountUnsentByUserId(ctx context.Context, userId string) (int64, error)
+
+	// FindSentAfter returns up to limit sent events ordered by change feed
+	// position, starting after the given position
+	FindSentAfter(ctx context.Context, afterFeedPosition int64, limit int64) ([]models.UserChangeOutboxEvent, error)
+}
+
+// userChangeFeedCounterColl holds the last change feed position given out,
+// which outlives the sent events removed by their TTL index
+const userChangeFeedCounterColl = "userChangeFeedCounter"
+
+const userChangeFeedCounterId = "userChangeFeed"
+
+type userChangeFeedCounter struct {
+	Position int64 `bson:"position"`
+}
+
+type UserChangeOutboxRepositoryImpl struct {
+	baserepos.BaseRepositoryMongo[models.UserChangeOutboxEvent]
+}
+
+func (u UserChangeOutboxRepositoryImpl) Create(
+	ctx context.Context,
+	model models.UserChangeOutboxEvent,
+) (models.UserChangeOutboxEvent, error) {
+	err := mgm.Coll(u.ModelColl).CreateWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (u UserChangeOutboxRepositoryImpl) FindUnsentGroupedByUserId(
+	ctx context.Context,
+	userLimit int64,
+) ([]models.UserChangeOutboxEvents, error) {
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	coll := mgm.Coll(u.ModelColl)
+	cursor, err := coll.Aggregate(childCtx, mongo.Pipeline{
+		// The users are limited before their events are looked up so only the
+		// events of the returned users are read. Users whose oldest unsent event
+		// was written first are returned first.
+		{{operator.Match, bson.M{"sent": false}}},
+		{{operator.Group, bson.M{"_id": "$userId", "firstId": bson.M{operator.Min: "$_id"}}}},
+		{{operator.Sort, bson.D{{"firstId", 1}}}},
+		{{operator.Limit, userLimit}},
+		{{operator.Lookup, bson.M{
+			"from": coll.Name(),
+			"let":  bson.M{"userId": "$_id"},
+			"pipeline": mongo.Pipeline{
+				{{operator.Match, bson.M{operator.Expr: bson.M{operator.And: bson.A{
+					bson.M{operator.Eq: bson.A{"$userId", "$$userId"}},
+					bson.M{operator.Eq: bson.A{"$sent", false}},
+				}}}}},
+				{{operator.Sort, bson.D{{"sequence", 1}}}},
+			},
+			"as": "events",
+		}}},
+	})
+	return mgmtools.HandleFindManyRes[models.UserChangeOutboxEvents](childCtx, cursor, err)
+}
+
+func (u UserChangeOutboxRepositoryImpl) MarkSent(
+	ctx context.Context,
+	id string,
+	sentAt time.Time,
+	feedPosition int64,
+) (bool, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+	res, err := mgm.Coll(u.ModelColl).UpdateOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"_id": objectId, "sent": false},
+		bson.M{operator.Set: bson.M{"sent": true, "sentAt": sentAt, "feedPosition": feedPosition}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (u UserChangeOutboxRepositoryImpl) NextFeedPosition(ctx context.Context) (int64, error) {
+	counter := userChangeFeedCounter{}
+	err := mgm.CollectionByName(userChangeFeedCounterColl).FindOneAndUpdate(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"_id": userChangeFeedCounterId},
+		bson.M{operator.Inc: bson.M{"position": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	return counter.Position, err
+}
+
+func (u UserChangeOutboxRepositoryImpl) GetFeedPosition(ctx context.Context) (int64, error) {
+	counter := userChangeFeedCounter{}
+	err := mgm.CollectionByName(userChangeFeedCounterColl).FindOne(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"_id": userChangeFeedCounterId},
+	).Decode(&counter)
+	// No position has been given out yet
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	return counter.Position, err
+}
+
+func (u UserChangeOutboxRepositoryImpl) FindLatestByUserId(
+	ctx context.Context,
+	userId string,
+	onlySent bool,
+) (option.Maybe[models.UserChangeOutboxEvent], error) {
+	filter := bson.M{"userId": userId}
+	if onlySent {
+		filter["sent"] = true
+	}
+	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.UserChangeOutboxEvent, error) {
+		model := models.UserChangeOutboxEvent{}
+		err := mgm.Coll(u.ModelColl).FirstWithCtx(
+			u.MongoDBHandler.ToChildCtx(ctx),
+			filter,
+			&model,
+			options.FindOne().SetSort(bson.D{{"sequence", -1}}),
+		)
+		return model, err
+	})
+}
+
+func (u UserChangeOutboxRepositoryImpl) CountUnsentByUserId(ctx context.Context, userId string) (int64, error) {
+	return mgm.Coll(u.ModelColl).CountDocuments(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		bson.M{"userId": userId, "sent": false},
+	)
+}
+
+func (u UserChangeOutboxRepositoryImpl) FindSentAfter(
+	ctx context.Context,
+	afterFeedPosition int64,
+	limit int64,
+) ([]models.UserChangeOutboxEvent, error) {
+	filter := bson.M{"sent": true, "feedPosition": bson.M{operator.Gt: afterFeedPosition}}
+	findOpts := options.Find().SetSort(bson.D{{"feedPosition", 1}}).SetLimit(limit)
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, filter, findOpts)
+	return mgmtools.HandleFindManyRes[models.UserChangeOutboxEvent](childCtx, cursor, err)
+}
+
+func NewUserChangeOutboxRepositoryImpl(mongoDBHandler *dshandlers.MongoDBHandler) *UserChangeOutboxRepositoryImpl {
+	return &UserChangeOutboxRepositoryImpl{
+		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.UserChangeOutboxEvent](
+			models.UserChangeOutboxEvent{},
+			mongoDBHandler,
+		),
+	}
+}
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/operator"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type UserDataExportRepository interface {
+	baserepos.CRUDRepository[models.UserDataExport, string]
+	FindLatestByUserId(ctx context.Context, userId string) (option.Maybe[models.UserDataExport], error)
+	FindPendingByUserIdCreatedAfter(
+		ctx context.Context,
+		userId string,
+		createdAfter time.Time,
+	) (option.Maybe[models.UserDataExport], error)
+
+	// CreateIfNoneActive creates the export unless the user already has a
+	// pending one, which a unique index on the pending exports of a user
+	// enforces. An empty Maybe is returned if the export was not created.
+	CreateIfNoneActive(ctx context.Context, model models.UserDataExport) (option.Maybe[models.UserDataExport], error)
+
+	// FailPendingByUserIdCreatedBefore fails the user's pending exports created
+	// before the time so a new export can be created
+	FailPendingByUserIdCreatedBefore(ctx context.Context, userId string, createdBefore time.Time) error
+
+	// FindRequestUnsent returns up to limit exports whose request has not been
+	// published in the order they were created
+	FindRequestUnsent(ctx context.Context, limit int64) ([]models.UserDataExport, error)
+
+	// MarkRequestSent marks the request of an export as published
+	MarkRequestSent(ctx context.Context, id string, sentAt time.Time) error
+}
+
+type UserDataExportRepositoryImpl struct {
+	baserepos.BaseRepositoryMongo[models.UserDataExport]
+}
+
+func (u UserDataExportRepositoryImpl) Create(
+	ctx context.Context,
+	model models.UserDataExport,
+) (models.UserDataExport, error) {
+	err := mgm.Coll(u.ModelColl).CreateWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (u UserDataExportRepositoryImpl) Update(
+	ctx context.Context,
+	model models.UserDataExport,
+) (models.UserDataExport, error) {
+	err := mgm.Coll(u.ModelColl).UpdateWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (u UserDataExportRepositoryImpl) Delete(
+	ctx context.Context,
+	model models.UserDataExport,
+) (models.UserDataExport, error) {
+	err := mgm.Coll(u.ModelColl).DeleteWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (u UserDataExportRepositoryImpl) FindById(
+	ctx context.Context,
+	id string,
+) (option.Maybe[models.UserDataExport], error) {
+	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.UserDataExport, error) {
+		model := models.UserDataExport{}
+		err := mgm.Coll(u.ModelColl).FindByIDWithCtx(u.MongoDBHandler.ToChildCtx(ctx), id, &model)
+		return model, err
+	})
+}
+
+func (u UserDataExportRepositoryImpl) FindLatestByUserId(
+	ctx context.Context,
+	userId string,
+) (option.Maybe[models.UserDataExport], error) {
+	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.UserDataExport, error) {
+		model := models.UserDataExport{}
+		findOpts := options.FindOne().SetSort(bson.M{"created_at": -1})
+		err := mgm.Coll(u.ModelColl).FirstWithCtx(
+			u.MongoDBHandler.ToChildCtx(ctx),
+			bson.M{"userId": userId},
+			&model,
+			findOpts,
+		)
+		return model, err
+	})
+}
+
+func (u UserDataExportRepositoryImpl) FindPendingByUserIdCreatedAfter(
+	ctx context.Context,
+	userId string,
+	createdAfter time.Time,
+) (option.Maybe[models.UserDataExport], error) {
+	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.UserDataExport, error) {
+		model := models.UserDataExport{}
+		filter := bson.M{
+			"userId":     userId,
+			"status":     userdtos.UserDataExportPending,
+			"created_at": bson.M{operator.Gt: createdAfter},
+		}
+		err := mgm.Coll(u.ModelColl).FirstWithCtx(u.MongoDBHandler.ToChildCtx(ctx), filter, &model)
+		return model, err
+	})
+}
+
+func (u UserDataExportRepositoryImpl) CreateIfNoneActive(
+	ctx context.Context,
+	model models.UserDataExport,
+) (option.Maybe[models.UserDataExport], error) {
+	err := mgm.Coll(u.ModelColl).CreateWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	if mongo.IsDuplicateKeyError(err) {
+		return option.None[models.UserDataExport](), nil
+	}
+	if err != nil {
+		return option.None[models.UserDataExport](), err
+	}
+	return option.Perhaps(model), nil
+}
+
+func (u UserDataExportRepositoryImpl) FailPendingByUserIdCreatedBefore(
+	ctx context.Context,
+	userId string,
+	createdBefore time.Time,
+) error {
+	filter := bson.M{
+		"userId":     userId,
+		"status":     userdtos.UserDataExportPending,
+		"created_at": bson.M{operator.Lte: createdBefore},
+	}
+	_, err := mgm.Coll(u.ModelColl).UpdateMany(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		filter,
+		bson.M{operator.Set: bson.M{"status": userdtos.UserDataExportFailed}},
+	)
+	return err
+}
+
+func (u UserDataExportRepositoryImpl) FindRequestUnsent(
+	ctx context.Context,
+	limit int64,
+) ([]models.UserDataExport, error) {
+	findOpts := options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(limit)
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, bson.M{"requestSent": false}, findOpts)
+	return mgmtools.HandleFindManyRes[models.UserDataExport](childCtx, cursor, err)
+}
+
+func (u UserDataExportRepositoryImpl) MarkRequestSent(ctx context.Context, id string, sentAt time.Time) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = mgm.Coll(u.ModelColl).UpdateByID(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		objectId,
+		bson.M{operator.Set: bson.M{"requestSent": true, "requestSentAt": sentAt}},
+	)
+	return err
+}
+
+func NewUserDataExportRepositoryImpl(mongoDBHandler *dshandlers.MongoDBHandler) *UserDataExportRepositoryImpl {
+	return &UserDataExportRepositoryImpl{
+		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.UserDataExport](
+			models.UserDataExport{},
+			mongoDBHandler,
+		),
+	}
+}
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"github.com/kamva/mgm/v3"
+	"github.com/kamva/mgm/v3/operator"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type UserDeletionRepository interface {
+	baserepos.CRUDRepository[models.UserDeletion, string]
+	FindByUserId(ctx context.Context, userId string) (option.Maybe[models.UserDeletion], error)
+
+	// FindUnfinishedByAuthId returns the deletion of a user with the auth ID
+	// that has yet to delete the user from the auth server
+	FindUnfinishedByAuthId(ctx context.Context, authId string) (option.Maybe[models.UserDeletion], error)
+
+	// FindCompletedNotIdentityProviderDeleted returns up to limit deletions every
+	// participant completed whose user is still in the auth server
+	FindCompletedNotIdentityProviderDeleted(ctx context.Context, limit int64) ([]models.UserDeletion, error)
+
+	MarkIdentityProviderDeleted(ctx context.Context, id string) error
+
+	// GetPaginatedStuck returns the unfinished deletions that failed or were
+	// started before a time
+	GetPaginatedStuck(
+		ctx context.Context,
+		startedBefore time.Time,
+		pageReq pagination.PageRequest,
+	) ([]models.UserDeletion, error)
+	CountStuck(ctx context.Context, startedBefore time.Time) (int64, error)
+}
+
+type UserDeletionRepositoryImpl struct {
+	baserepos.BaseRepositoryMongo[models.UserDeletion]
+}
+
+func (u UserDeletionRepositoryImpl) Create(ctx context.Context, model models.UserDeletion) (models.UserDeletion, error) {
+	err := mgm.Coll(u.ModelColl).CreateWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (u UserDeletionRepositoryImpl) Update(ctx context.Context, model models.UserDeletion) (models.UserDeletion, error) {
+	err := mgm.Coll(u.ModelColl).UpdateWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (u UserDeletionRepositoryImpl) Delete(ctx context.Context, model models.UserDeletion) (models.UserDeletion, error) {
+	err := mgm.Coll(u.ModelColl).DeleteWithCtx(u.MongoDBHandler.ToChildCtx(ctx), &model)
+	return model, err
+}
+
+func (u UserDeletionRepositoryImpl) FindById(ctx context.Context, id string) (option.Maybe[models.UserDeletion], error) {
+	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.UserDeletion, error) {
+		model := models.UserDeletion{}
+		err := mgm.Coll(u.ModelColl).FindByIDWithCtx(u.MongoDBHandler.ToChildCtx(ctx), id, &model)
+		return model, err
+	})
+}
+
+func (u UserDeletionRepositoryImpl) FindByUserId(
+	ctx context.Context,
+	userId string,
+) (option.Maybe[models.UserDeletion], error) {
+	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.UserDeletion, error) {
+		model := models.UserDeletion{}
+		err := mgm.Coll(u.ModelColl).FirstWithCtx(u.MongoDBHandler.ToChildCtx(ctx), bson.M{"userId": userId}, &model)
+		return model, err
+	})
+}
+
+func (u UserDeletionRepositoryImpl) FindUnfinishedByAuthId(
+	ctx context.Context,
+	authId string,
+) (option.Maybe[models.UserDeletion], error) {
+	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.UserDeletion, error) {
+		model := models.UserDeletion{}
+		err := mgm.Coll(u.ModelColl).FirstWithCtx(
+			u.MongoDBHandler.ToChildCtx(ctx),
+			bson.M{"authId": authId, "identityProviderDeleted": false},
+			&model,
+		)
+		return model, err
+	})
+}
+
+func (u UserDeletionRepositoryImpl) FindCompletedNotIdentityProviderDeleted(
+	ctx context.Context,
+	limit int64,
+) ([]models.UserDeletion, error) {
+	findOpts := options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(limit)
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(
+		childCtx,
+		bson.M{"status": userdtos.UserDeletionCompleted, "identityProviderDeleted": false},
+		findOpts,
+	)
+	return mgmtools.HandleFindManyRes[models.UserDeletion](childCtx, cursor, err)
+}
+
+func (u UserDeletionRepositoryImpl) MarkIdentityProviderDeleted(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = mgm.Coll(u.ModelColl).UpdateByID(
+		u.MongoDBHandler.ToChildCtx(ctx),
+		objectId,
+		bson.M{operator.Set: bson.M{"identityProviderDeleted": true}},
+	)
+	return err
+}
+
+func (u UserDeletionRepositoryImpl) GetPaginatedStuck(
+	ctx context.Context,
+	startedBefore time.Time,
+	pageReq pagination.PageRequest,
+) ([]models.UserDeletion, error) {
+	findOpts := mgmtools.CreatePaginatedFindOpts(pageReq)
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, stuckUserDeletionFilter(startedBefore), findOpts)
+	return mgmtools.HandleFindManyRes[models.UserDeletion](childCtx, cursor, err)
+}
+
+func (u UserDeletionRepositoryImpl) CountStuck(ctx context.Context, startedBefore time.Time) (int64, error) {
+	return mgm.Coll(u.ModelColl).CountDocuments(u.MongoDBHandler.ToChildCtx(ctx), stuckUserDeletionFilter(startedBefore))
+}
+
+func stuckUserDeletionFilter(startedBefore time.Time) bson.M {
+	return bson.M{
+		"identityProviderDeleted": false,
+		operator.Or: bson.A{
+			bson.M{"status": userdtos.UserDeletionFailed},
+			bson.M{"created_at": bson.M{operator.Lt: startedBefore}},
+		},
+	}
+}
+
+func NewUserDeletionRepositoryImpl(mongoDBHandler *dshandlers.MongoDBHandler) *UserDeletionRepositoryImpl {
+	return &UserDeletionRepositoryImpl{
+		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.UserDeletion](models.UserDeletion{}, mongoDBHandler),
+	}
+}
@@ -8,16 +8,33 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/baserepos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/mgmtools"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
 )
 
 type UserRepository interface {
 	baserepos.CRUDRepository[models.User, string]
-	FindByAuthIdAndNotToBeDeleted(ctx context.Context, authId string) (option.Maybe[models.User], error)
-	FindByUsernameAndNotToBeDeleted(ctx context.Context, username string) (option.Maybe[models.User], error)
-	SampleUndistributedUsers(ctx context.Context, size int64) ([]models.User, error)
+	FindByAuthId(ctx context.Context, authId string) (option.Maybe[models.User], error)
+	FindByUsername(ctx context.Context, username string) (option.Maybe[models.User], error)
+
+	// GetPaginatedBySearch returns the users whose username starts with the
+	// search, ignoring case, or whose auth ID is the search. Every user matches
+	// a blank search.
+	GetPaginatedBySearch(ctx context.Context, search string, pageReq pagination.PageRequest) ([]models.User, error)
+	CountBySearch(ctx context.Context, search string) (int64, error)
+
+	// FindByIds returns the users with the IDs in no particular order. IDs of
+	// missing users are ignored.
+	FindByIds(ctx context.Context, ids []string) ([]models.User, error)
+
+	// GetAfterId returns up to limit users ordered by ID, starting after the
+	// user with afterId or from the first user if afterId is blank
+	GetAfterId(ctx context.Context, afterId string, limit int64) ([]models.User, error)
 }
 
 type UserRepositoryImpl struct {
@@ -47,48 +64,76 @@ func (u UserRepositoryImpl) FindById(ctx context.Context, id string) (option.May
 	})
 }
 
-func (u UserRepositoryImpl) FindByAuthIdAndNotToBeDeleted(
-	ctx context.Context,
-	authId string,
-) (option.Maybe[models.User], error) {
+func (u UserRepositoryImpl) FindByAuthId(ctx context.Context, authId string) (option.Maybe[models.User], error) {
 	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.User, error) {
 		user := models.User{}
-		err := mgm.Coll(u.ModelColl).FirstWithCtx(u.MongoDBHandler.ToChildCtx(ctx),
-			bson.M{
-				"authId":      authId,
-				"toBeDeleted": bson.M{operator.Ne: true}},
-			&user)
+		err := mgm.Coll(u.ModelColl).FirstWithCtx(u.MongoDBHandler.ToChildCtx(ctx), bson.M{"authId": authId}, &user)
 		return user, err
 	})
 }
 
-func (u UserRepositoryImpl) FindByUsernameAndNotToBeDeleted(
-	ctx context.Context,
-	username string,
-) (option.Maybe[models.User], error) {
+func (u UserRepositoryImpl) FindByUsername(ctx context.Context, username string) (option.Maybe[models.User], error) {
 	return dshandlers.HandleSingleFind(u.MongoDBHandler, func() (models.User, error) {
 		user := models.User{}
-		err := mgm.Coll(u.ModelColl).FirstWithCtx(
-			u.MongoDBHandler.ToChildCtx(ctx),
-			bson.M{
-				"userName":    username,
-				"toBeDeleted": bson.M{operator.Ne: true},
-			},
-			&user,
-		)
+		err := mgm.Coll(u.ModelColl).FirstWithCtx(u.MongoDBHandler.ToChildCtx(ctx), bson.M{"userName": username}, &user)
 		return user, err
 	})
 }
 
-func (u UserRepositoryImpl) SampleUndistributedUsers(ctx context.Context, size int64) ([]models.User, error) {
+func (u UserRepositoryImpl) GetPaginatedBySearch(
+	ctx context.Context,
+	search string,
+	pageReq pagination.PageRequest,
+) ([]models.User, error) {
+	findOpts := mgmtools.CreatePaginatedFindOpts(pageReq)
 	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
-	cursor, err := mgm.Coll(u.ModelColl).Aggregate(ctx, mongo.Pipeline{
-		{{operator.Match, bson.D{{"distributed", bson.D{{operator.Ne, true}}}}}},
-		{{operator.Sample, bson.D{{"size", size}}}},
-	})
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, userSearchFilter(search), findOpts)
 	return mgmtools.HandleFindManyRes[models.User](childCtx, cursor, err)
 }
 
+func (u UserRepositoryImpl) CountBySearch(ctx context.Context, search string) (int64, error) {
+	return mgm.Coll(u.ModelColl).CountDocuments(u.MongoDBHandler.ToChildCtx(ctx), userSearchFilter(search))
+}
+
+func (u UserRepositoryImpl) FindByIds(ctx context.Context, ids []string) ([]models.User, error) {
+	objectIds := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objectId, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIds = append(objectIds, objectId)
+		}
+	}
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, bson.M{"_id": bson.M{operator.In: objectIds}})
+	return mgmtools.HandleFindManyRes[models.User](childCtx, cursor, err)
+}
+
+func (u UserRepositoryImpl) GetAfterId(ctx context.Context, afterId string, limit int64) ([]models.User, error) {
+	filter := bson.M{}
+	if utils.StringIsNotBlank(afterId) {
+		afterObjectId, err := primitive.ObjectIDFromHex(afterId)
+		if err != nil {
+			return nil, err
+		}
+		filter["_id"] = bson.M{operator.Gt: afterObjectId}
+	}
+	findOpts := options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(limit)
+	childCtx := u.MongoDBHandler.ToChildCtx(ctx)
+	cursor, err := mgm.Coll(u.ModelColl).Find(childCtx, filter, findOpts)
+	return mgmtools.HandleFindManyRes[models.User](childCtx, cursor, err)
+}
+
+func userSearchFilter(search string) bson.M {
+	if utils.StringIsBlank(search) {
+		return bson.M{}
+	}
+	return bson.M{
+		operator.Or: bson.A{
+			bson.M{"userName": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(search), Options: "i"}},
+			bson.M{"authId": search},
+		},
+	}
+}
+
 func NewUserRepositoryImpl(mongoDBHandler *dshandlers.MongoDBHandler) *UserRepositoryImpl {
 	return &UserRepositoryImpl{
 		BaseRepositoryMongo: *baserepos.NewBaseRepositoryMongo[models.User](models.User{}, mongoDBHandler),
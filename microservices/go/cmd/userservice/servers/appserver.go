@@ -20,13 +20,25 @@ func NewAppServerImpl(
 	serverConf conf.ServerConf,
 	tlsConf conf.TLSConf,
 	userController controllers.UserController,
+	userDeletionController controllers.UserDeletionController,
+	userDataExportController controllers.UserDataExportController,
+	userAdminController controllers.UserAdminController,
+	deadLetterController controllers.DeadLetterController,
 ) *AppServerImpl {
 	if !environment.ActivateAppServer() {
 		// App server is deactivated, ran via the lifecycle package,
 		// and is a root-child dependency so a nil is returned
 		return nil
 	}
-	coreAppServer := commonservers.NewCoreAppServerImpl(serverConf, tlsConf, userController)
+	coreAppServer := commonservers.NewCoreAppServerImpl(
+		serverConf,
+		tlsConf,
+		userController,
+		userDeletionController,
+		userDataExportController,
+		userAdminController,
+		deadLetterController,
+	)
 	a := &AppServerImpl{CoreAppServer: coreAppServer}
 	lifecycle.RegisterTaskRunner(a)
 	return a
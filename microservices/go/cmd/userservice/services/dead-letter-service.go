@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/akrennmair/slice"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/businessrules"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/mappers"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/deadletterdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/segmentio/kafka-go"
+)
+
+// deadLetterBatchSize is how many dead letters are replayed at a time when
+// replaying every dead letter of a topic
+const deadLetterBatchSize = 100
+
+// DeadLetterService keeps the messages of the dead letter topics of user
+// changes so operators can inspect them and then replay or purge them
+type DeadLetterService interface {
+	lifecycle.Closable
+
+	// ArchiveDeadLetter keeps a message read from a dead letter topic
+	ArchiveDeadLetter(ctx context.Context, deadLetterDto deadletterdtos.DeadLetterDto) error
+
+	// GetDeadLetterTopics returns every dead letter topic along with how many
+	// dead letters it has
+	GetDeadLetterTopics(ctx context.Context) ([]deadletterdtos.DeadLetterTopicDto, error)
+
+	// GetDeadLetters returns the dead letters of a topic, or of every topic if
+	// the topic is blank
+	GetDeadLetters(
+		ctx context.Context,
+		topic string,
+		pageReq pagination.PageRequest,
+	) (pagination.Page[deadletterdtos.DeadLetterDto], error)
+
+	GetDeadLetter(ctx context.Context, deadLetterId string) (deadletterdtos.DeadLetterDto, error)
+
+	// ReplayDeadLetters sends the selected dead letters back to the main topic
+	// or to a retry stage and no longer keeps them. Every service reads the main
+	// topic, so dead letters replayed to it are sent to the group's first retry
+	// stage with no tries, as if they had just failed on the main topic. Each
+	// replay is audited, even if it fails part way.
+	ReplayDeadLetters(
+		ctx context.Context,
+		actor string,
+		replayDto deadletterdtos.DeadLetterReplayDto,
+	) (deadletterdtos.DeadLetterAuditDto, error)
+
+	// PurgeDeadLetters no longer keeps the selected dead letters. Each purge is
+	// audited.
+	PurgeDeadLetters(
+		ctx context.Context,
+		actor string,
+		selectDto deadletterdtos.DeadLetterSelectDto,
+	) (deadletterdtos.DeadLetterAuditDto, error)
+
+	GetDeadLetterAudits(
+		ctx context.Context,
+		pageReq pagination.PageRequest,
+	) (pagination.Page[deadletterdtos.DeadLetterAuditDto], error)
+}
+
+type DeadLetterServiceImpl struct {
+	deadLetterRepository      repositories.DeadLetterRepository
+	deadLetterAuditRepository repositories.DeadLetterAuditRepository
+	deadLetterBr              businessrules.DeadLetterBr
+	errorService              sharedservices.ErrorService
+	deadLetterPolicies        map[string]kfka.RetryPolicy
+	// Senders by retry topic, the values of dead letters are sent as they were
+	// read
+	retrySenders map[string]deadLetterSender
+}
+
+// deadLetterSender sends a dead letter with the key it was read with
+type deadLetterSender interface {
+	lifecycle.Closable
+	SendWithKey(ctx context.Context, key []byte, body kfka.RetryDto[json.RawMessage]) error
+}
+
+func (d DeadLetterServiceImpl) ArchiveDeadLetter(ctx context.Context, deadLetterDto deadletterdtos.DeadLetterDto) error {
+	deadLetter := models.DeadLetter{}
+	mappers.DeadLetterDtoToDeadLetter(deadLetterDto, &deadLetter)
+	logger.Log.WithContext(ctx).Warnf("Archiving dead letter from topic %v", deadLetter.Topic)
+	return d.deadLetterRepository.CreateIfAbsent(ctx, deadLetter)
+}
+
+func (d DeadLetterServiceImpl) GetDeadLetterTopics(ctx context.Context) ([]deadletterdtos.DeadLetterTopicDto, error) {
+	policies := retrypolicies.NewUserChange1Policies()
+	topicDtos := make([]deadletterdtos.DeadLetterTopicDto, 0, len(policies))
+	for _, policy := range policies {
+		count, err := d.deadLetterRepository.CountByTopic(ctx, policy.DeadLetterTopic)
+		if err != nil {
+			return nil, err
+		}
+		topicDto := deadletterdtos.DeadLetterTopicDto{
+			Topic:       policy.DeadLetterTopic,
+			MainTopic:   policy.MainTopic,
+			GroupId:     policy.GroupID,
+			RetryTopics: make([]string, 0, len(policy.Stages)),
+			Count:       count,
+		}
+		for _, stage := range policy.Stages {
+			topicDto.RetryTopics = append(topicDto.RetryTopics, stage.Topic)
+		}
+		topicDtos = append(topicDtos, topicDto)
+	}
+	return topicDtos, nil
+}
+
+func (d DeadLetterServiceImpl) GetDeadLetters(
+	ctx context.Context,
+	topic string,
+	pageReq pagination.PageRequest,
+) (pagination.Page[deadletterdtos.DeadLetterDto], error) {
+	if err := d.deadLetterBr.ValidateGetDeadLetters(topic); err != nil {
+		return pagination.Page[deadletterdtos.DeadLetterDto]{}, err
+	}
+	deadLetters, err := d.deadLetterRepository.GetPaginatedByTopic(ctx, topic, pageReq)
+	if err != nil {
+		return pagination.Page[deadletterdtos.DeadLetterDto]{}, err
+	}
+	count, err := d.deadLetterRepository.CountByTopic(ctx, topic)
+	if err != nil {
+		return pagination.Page[deadletterdtos.DeadLetterDto]{}, err
+	}
+	deadLetterDtos := make([]deadletterdtos.DeadLetterDto, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		deadLetterDto := deadletterdtos.DeadLetterDto{}
+		mappers.DeadLetterToDeadLetterDto(deadLetter, &deadLetterDto)
+		deadLetterDtos = append(deadLetterDtos, deadLetterDto)
+	}
+	return pagination.NewPage(deadLetterDtos, count), nil
+}
+
+func (d DeadLetterServiceImpl) GetDeadLetter(
+	ctx context.Context,
+	deadLetterId string,
+) (deadletterdtos.DeadLetterDto, error) {
+	deadLetterFind, err := d.deadLetterRepository.FindById(ctx, deadLetterId)
+	if err != nil {
+		return deadletterdtos.DeadLetterDto{}, err
+	}
+	deadLetter, ok := deadLetterFind.Get()
+	if !ok {
+		ruleErr := d.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+		return deadletterdtos.DeadLetterDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	deadLetterDto := deadletterdtos.DeadLetterDto{}
+	mappers.DeadLetterToDeadLetterDto(deadLetter, &deadLetterDto)
+	return deadLetterDto, nil
+}
+
+func (d DeadLetterServiceImpl) ReplayDeadLetters(
+	ctx context.Context,
+	actor string,
+	replayDto deadletterdtos.DeadLetterReplayDto,
+) (deadletterdtos.DeadLetterAuditDto, error) {
+	if err := d.deadLetterBr.ValidateDeadLetterReplay(replayDto); err != nil {
+		return deadletterdtos.DeadLetterAuditDto{}, err
+	}
+	policy := d.deadLetterPolicies[replayDto.Topic]
+	targetTopic := policy.Stages[replayStageIndex(replayDto.Stage)].Topic
+
+	var replayCount int64
+	replayErr := d.forEachSelected(ctx, replayDto.DeadLetterSelectDto, func(deadLetter models.DeadLetter) error {
+		if err := d.replay(ctx, policy, replayDto.Stage, deadLetter); err != nil {
+			return err
+		}
+		replayCount++
+		_, err := d.deadLetterRepository.Delete(ctx, deadLetter)
+		return err
+	})
+	logger.Log.WithContext(ctx).Infof("%v replayed %v dead letters of %v to %v",
+		actor, replayCount, replayDto.Topic, targetTopic)
+
+	audit := models.DeadLetterAudit{
+		Action:      deadletterdtos.DeadLetterActionReplay,
+		Actor:       actor,
+		Topic:       replayDto.Topic,
+		TargetTopic: targetTopic,
+		All:         replayDto.All,
+		Ids:         replayDto.Ids,
+		Count:       replayCount,
+	}
+	return d.saveAudit(ctx, audit, replayErr)
+}
+
+// replay sends a dead letter to the main topic or to the retry stage,
+// restarting the message's tries at the start of the stage
+func (d DeadLetterServiceImpl) replay(
+	ctx context.Context,
+	policy kfka.RetryPolicy,
+	stage int,
+	deadLetter models.DeadLetter,
+) error {
+	stageIndex := replayStageIndex(stage)
+	retry := kfka.CreateRetryDto(json.RawMessage(deadLetter.Value), policy.TriesBefore(stageIndex))
+	for _, deadLetterErr := range deadLetter.Errors {
+		retry.Errors = append(retry.Errors, kfka.RetryErrorDto{
+			Topic:    deadLetterErr.Topic,
+			Error:    deadLetterErr.Error,
+			FailedAt: deadLetterErr.FailedAt.UnixMilli(),
+		})
+	}
+	return d.retrySenders[policy.Stages[stageIndex].Topic].SendWithKey(ctx, []byte(deadLetter.Key), retry)
+}
+
+// replayStageIndex returns the index of the retry stage dead letters replayed
+// to a stage are sent to. Stage 0 is the main topic, which is replayed to
+// through the first retry stage so only the dead letter topic's group reads
+// the dead letters again, and stage 1 is the first retry stage.
+func replayStageIndex(stage int) int {
+	if stage == 0 {
+		return 0
+	}
+	return stage - 1
+}
+
+// forEachSelected handles the selected dead letters of a topic in order until
+// handling one fails. Every dead letter of the topic is read in batches, so
+// handling can remove dead letters.
+func (d DeadLetterServiceImpl) forEachSelected(
+	ctx context.Context,
+	selectDto deadletterdtos.DeadLetterSelectDto,
+	handle func(deadLetter models.DeadLetter) error,
+) error {
+	if !selectDto.All {
+		deadLetters, err := d.deadLetterRepository.FindByIdsInTopic(ctx, selectDto.Topic, selectDto.Ids)
+		if err != nil {
+			return err
+		}
+		for _, deadLetter := range deadLetters {
+			if err := handle(deadLetter); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	afterId := ""
+	for {
+		deadLetters, err := d.deadLetterRepository.GetAfterIdInTopic(ctx, selectDto.Topic, afterId, deadLetterBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, deadLetter := range deadLetters {
+			if err := handle(deadLetter); err != nil {
+				return err
+			}
+		}
+		if len(deadLetters) < deadLetterBatchSize {
+			return nil
+		}
+		afterId = deadLetters[len(deadLetters)-1].GetIdStr()
+	}
+}
+
+func (d DeadLetterServiceImpl) PurgeDeadLetters(
+	ctx context.Context,
+	actor string,
+	selectDto deadletterdtos.DeadLetterSelectDto,
+) (deadletterdtos.DeadLetterAuditDto, error) {
+	if err := d.deadLetterBr.ValidateDeadLetterPurge(selectDto); err != nil {
+		return deadletterdtos.DeadLetterAuditDto{}, err
+	}
+	var purgeCount int64
+	var purgeErr error
+	if selectDto.All {
+		purgeCount, purgeErr = d.deadLetterRepository.DeleteByTopicAndGetCount(ctx, selectDto.Topic)
+	} else {
+		purgeCount, purgeErr = d.deadLetterRepository.DeleteByIdsInTopicAndGetCount(ctx, selectDto.Topic, selectDto.Ids)
+	}
+	if purgeCount < 0 {
+		purgeCount = 0
+	}
+	logger.Log.WithContext(ctx).Infof("%v purged %v dead letters of %v", actor, purgeCount, selectDto.Topic)
+
+	audit := models.DeadLetterAudit{
+		Action: deadletterdtos.DeadLetterActionPurge,
+		Actor:  actor,
+		Topic:  selectDto.Topic,
+		All:    selectDto.All,
+		Ids:    selectDto.Ids,
+		Count:  purgeCount,
+	}
+	return d.saveAudit(ctx, audit, purgeErr)
+}
+
+// saveAudit saves the audit of an action, recording the error that stopped the
+// action, which is then returned
+func (d DeadLetterServiceImpl) saveAudit(
+	ctx context.Context,
+	audit models.DeadLetterAudit,
+	actionErr error,
+) (deadletterdtos.DeadLetterAuditDto, error) {
+	if actionErr != nil {
+		audit.Error = actionErr.Error()
+	}
+	savedAudit, err := d.deadLetterAuditRepository.Create(ctx, audit)
+	if err != nil {
+		return deadletterdtos.DeadLetterAuditDto{}, utils.ConcatErrors(actionErr, err)
+	}
+	auditDto := deadletterdtos.DeadLetterAuditDto{}
+	mappers.DeadLetterAuditToDeadLetterAuditDto(savedAudit, &auditDto)
+	return auditDto, actionErr
+}
+
+func (d DeadLetterServiceImpl) GetDeadLetterAudits(
+	ctx context.Context,
+	pageReq pagination.PageRequest,
+) (pagination.Page[deadletterdtos.DeadLetterAuditDto], error) {
+	audits, err := d.deadLetterAuditRepository.GetPaginated(ctx, pageReq)
+	if err != nil {
+		return pagination.Page[deadletterdtos.DeadLetterAuditDto]{}, err
+	}
+	count, err := d.deadLetterAuditRepository.Count(ctx)
+	if err != nil {
+		return pagination.Page[deadletterdtos.DeadLetterAuditDto]{}, err
+	}
+	auditDtos := make([]deadletterdtos.DeadLetterAuditDto, 0, len(audits))
+	for _, audit := range audits {
+		auditDto := deadletterdtos.DeadLetterAuditDto{}
+		mappers.DeadLetterAuditToDeadLetterAuditDto(audit, &auditDto)
+		auditDtos = append(auditDtos, auditDto)
+	}
+	return pagination.NewPage(auditDtos, count), nil
+}
+
+func (d DeadLetterServiceImpl) Close() error {
+	var closableList []lifecycle.Closable
+	for _, sender := range d.retrySenders {
+		closableList = append(closableList, sender)
+	}
+	errs := slice.MapConcurrent(closableList, lifecycle.Closable.Close)
+	return utils.ConcatErrors(errs...)
+}
+
+func NewDeadLetterServiceImpl(
+	kafkaConf conf.KafkaConf,
+	deadLetterRepository repositories.DeadLetterRepository,
+	deadLetterAuditRepository repositories.DeadLetterAuditRepository,
+	deadLetterBr businessrules.DeadLetterBr,
+	errorService sharedservices.ErrorService,
+) *DeadLetterServiceImpl {
+	newWriter := func(topic string) *kafka.Writer {
+		return &kafka.Writer{
+			Addr:     kafka.TCP(kafkaConf.GetBootstrapServers()...),
+			Topic:    topic,
+			Balancer: &kafka.Murmur2Balancer{},
+		}
+	}
+	deadLetterPolicies := retrypolicies.NewUserChange1PoliciesByDeadLetterTopic()
+	// Dead letters are sent with the key they were read with, so the senders
+	// have no key readers
+	retrySenders := map[string]deadLetterSender{}
+	for _, policy := range deadLetterPolicies {
+		for _, stage := range policy.Stages {
+			retrySenders[stage.Topic] = kfka.NewKafkaSender[kfka.RetryDto[json.RawMessage]](newWriter(stage.Topic), nil)
+		}
+	}
+	d := &DeadLetterServiceImpl{
+		deadLetterRepository:      deadLetterRepository,
+		deadLetterAuditRepository: deadLetterAuditRepository,
+		deadLetterBr:              deadLetterBr,
+		errorService:              errorService,
+		deadLetterPolicies:        deadLetterPolicies,
+		retrySenders:              retrySenders,
+	}
+	lifecycle.RegisterClosable(d)
+	return d
+}
@@ -0,0 +1,234 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kamva/mgm/v3"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/services"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/deadletterdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	cv "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+type sentRetry struct {
+	key   string
+	retry kfka.RetryDto[json.RawMessage]
+}
+
+type stubDeadLetterSender struct {
+	sent *[]sentRetry
+}
+
+func (s stubDeadLetterSender) SendWithKey(_ context.Context, key []byte, body kfka.RetryDto[json.RawMessage]) error {
+	*s.sent = append(*s.sent, sentRetry{key: string(key), retry: body})
+	return nil
+}
+
+func (s stubDeadLetterSender) Close() error {
+	return nil
+}
+
+// stubDeadLetterRepository keeps dead letters in memory, ordered by ID
+type stubDeadLetterRepository struct {
+	repositories.DeadLetterRepository
+	deadLetters []models.DeadLetter
+}
+
+func (s *stubDeadLetterRepository) FindByIdsInTopic(
+	_ context.Context,
+	topic string,
+	ids []string,
+) ([]models.DeadLetter, error) {
+	var found []models.DeadLetter
+	for _, deadLetter := range s.deadLetters {
+		for _, id := range ids {
+			if deadLetter.Topic == topic && deadLetter.GetIdStr() == id {
+				found = append(found, deadLetter)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (s *stubDeadLetterRepository) GetAfterIdInTopic(
+	_ context.Context,
+	topic string,
+	afterId string,
+	limit int64,
+) ([]models.DeadLetter, error) {
+	var found []models.DeadLetter
+	for _, deadLetter := range s.deadLetters {
+		if deadLetter.Topic == topic && deadLetter.GetIdStr() > afterId && int64(len(found)) < limit {
+			found = append(found, deadLetter)
+		}
+	}
+	return found, nil
+}
+
+func (s *stubDeadLetterRepository) Delete(_ context.Context, model models.DeadLetter) (models.DeadLetter, error) {
+	s.deleteWhere(func(deadLetter models.DeadLetter) bool { return deadLetter.ID == model.ID })
+	return model, nil
+}
+
+func (s *stubDeadLetterRepository) DeleteByIdsInTopicAndGetCount(
+	_ context.Context,
+	topic string,
+	ids []string,
+) (int64, error) {
+	return s.deleteWhere(func(deadLetter models.DeadLetter) bool {
+		for _, id := range ids {
+			if deadLetter.Topic == topic && deadLetter.GetIdStr() == id {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (s *stubDeadLetterRepository) DeleteByTopicAndGetCount(_ context.Context, topic string) (int64, error) {
+	return s.deleteWhere(func(deadLetter models.DeadLetter) bool { return deadLetter.Topic == topic }), nil
+}
+
+func (s *stubDeadLetterRepository) deleteWhere(matches func(deadLetter models.DeadLetter) bool) int64 {
+	var kept []models.DeadLetter
+	for _, deadLetter := range s.deadLetters {
+		if !matches(deadLetter) {
+			kept = append(kept, deadLetter)
+		}
+	}
+	deleteCount := int64(len(s.deadLetters) - len(kept))
+	s.deadLetters = kept
+	return deleteCount
+}
+
+type stubDeadLetterAuditRepository struct {
+	repositories.DeadLetterAuditRepository
+	audits []models.DeadLetterAudit
+}
+
+func (s *stubDeadLetterAuditRepository) Create(
+	_ context.Context,
+	model models.DeadLetterAudit,
+) (models.DeadLetterAudit, error) {
+	model.ID = primitive.NewObjectID()
+	s.audits = append(s.audits, model)
+	return model, nil
+}
+
+func newDeadLetter(topic string, key string) models.DeadLetter {
+	return models.DeadLetter{
+		DefaultModel: mgm.DefaultModel{IDField: mgm.IDField{ID: primitive.NewObjectID()}},
+		Topic:        topic,
+		Key:          key,
+		Value:        `{"userId":"` + key + `"}`,
+		Errors:       []models.DeadLetterError{{Topic: topic, Error: "failed", FailedAt: time.UnixMilli(1000)}},
+	}
+}
+
+func TestDeadLetterService(t *testing.T) {
+	cv.Convey("When replaying and purging the dead letters of a topic", t, func() {
+		ctx := context.Background()
+		policy := retrypolicies.NewUserChange1NoteServicePolicy()
+		otherPolicy := retrypolicies.NewUserChange1KeyServicePolicy()
+		deadLetterRepository := &stubDeadLetterRepository{}
+		for _, key := range []string{"user1", "user2", "user3"} {
+			deadLetterRepository.deadLetters = append(deadLetterRepository.deadLetters,
+				newDeadLetter(policy.DeadLetterTopic, key))
+		}
+		otherDeadLetter := newDeadLetter(otherPolicy.DeadLetterTopic, "user4")
+		deadLetterRepository.deadLetters = append(deadLetterRepository.deadLetters, otherDeadLetter)
+		auditRepository := &stubDeadLetterAuditRepository{}
+
+		sentByTopic := map[string]*[]sentRetry{}
+		retrySenders := map[string]interface {
+			SendWithKey(ctx context.Context, key []byte, body kfka.RetryDto[json.RawMessage]) error
+			Close() error
+		}{}
+		for _, p := range []kfka.RetryPolicy{policy, otherPolicy} {
+			for _, stage := range p.Stages {
+				sent := &[]sentRetry{}
+				sentByTopic[stage.Topic] = sent
+				retrySenders[stage.Topic] = stubDeadLetterSender{sent: sent}
+			}
+		}
+		deadLetterService := services.NewTestDeadLetterServiceImpl(
+			deadLetterRepository,
+			auditRepository,
+			sharedservices.NewErrorServiceImpl(),
+			retrySenders,
+		)
+
+		cv.Convey("Expect replaying to the main topic sends to the first retry topic with no tries", func() {
+			replayDto := deadletterdtos.DeadLetterReplayDto{
+				DeadLetterSelectDto: deadletterdtos.DeadLetterSelectDto{Topic: policy.DeadLetterTopic, All: true},
+			}
+			auditDto, err := deadLetterService.ReplayDeadLetters(ctx, "tester", replayDto)
+			cv.So(err, cv.ShouldBeNil)
+			sent := *sentByTopic[policy.Stages[0].Topic]
+			cv.So(len(sent), cv.ShouldEqual, 3)
+			cv.So(sent[0].key, cv.ShouldEqual, "user1")
+			cv.So(string(sent[0].retry.Value), cv.ShouldEqual, `{"userId":"user1"}`)
+			cv.So(sent[0].retry.Tries, cv.ShouldEqual, 0)
+			cv.So(len(sent[0].retry.Errors), cv.ShouldEqual, 1)
+			cv.So(auditDto.TargetTopic, cv.ShouldEqual, policy.Stages[0].Topic)
+			cv.So(auditDto.Count, cv.ShouldEqual, 3)
+			cv.So(deadLetterRepository.deadLetters, cv.ShouldResemble, []models.DeadLetter{otherDeadLetter})
+		})
+		cv.Convey("Expect replaying to a later stage gives the message the tries before the stage", func() {
+			selected := deadLetterRepository.deadLetters[1]
+			replayDto := deadletterdtos.DeadLetterReplayDto{
+				DeadLetterSelectDto: deadletterdtos.DeadLetterSelectDto{
+					Topic: policy.DeadLetterTopic,
+					Ids:   []string{selected.GetIdStr()},
+				},
+				Stage: 2,
+			}
+			auditDto, err := deadLetterService.ReplayDeadLetters(ctx, "tester", replayDto)
+			cv.So(err, cv.ShouldBeNil)
+			sent := *sentByTopic[policy.Stages[1].Topic]
+			cv.So(len(sent), cv.ShouldEqual, 1)
+			cv.So(sent[0].key, cv.ShouldEqual, selected.Key)
+			cv.So(sent[0].retry.Tries, cv.ShouldEqual, policy.TriesBefore(1))
+			cv.So(auditDto.Count, cv.ShouldEqual, 1)
+			cv.So(len(deadLetterRepository.deadLetters), cv.ShouldEqual, 3)
+		})
+		cv.Convey("Expect replaying to an invalid stage sends nothing and is not audited", func() {
+			replayDto := deadletterdtos.DeadLetterReplayDto{
+				DeadLetterSelectDto: deadletterdtos.DeadLetterSelectDto{Topic: policy.DeadLetterTopic, All: true},
+				Stage:               len(policy.Stages) + 1,
+			}
+			_, err := deadLetterService.ReplayDeadLetters(ctx, "tester", replayDto)
+			cv.So(err, cv.ShouldNotBeNil)
+			for _, sent := range sentByTopic {
+				cv.So(len(*sent), cv.ShouldEqual, 0)
+			}
+			cv.So(len(auditRepository.audits), cv.ShouldEqual, 0)
+			cv.So(len(deadLetterRepository.deadLetters), cv.ShouldEqual, 4)
+		})
+		cv.Convey("Expect purging a topic only removes its dead letters and is audited", func() {
+			selectDto := deadletterdtos.DeadLetterSelectDto{Topic: policy.DeadLetterTopic, All: true}
+			auditDto, err := deadLetterService.PurgeDeadLetters(ctx, "tester", selectDto)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(auditDto.Action, cv.ShouldEqual, deadletterdtos.DeadLetterActionPurge)
+			cv.So(auditDto.Count, cv.ShouldEqual, 3)
+			cv.So(deadLetterRepository.deadLetters, cv.ShouldResemble, []models.DeadLetter{otherDeadLetter})
+		})
+		cv.Convey("Expect purging dead letters by ID only removes them", func() {
+			selectDto := deadletterdtos.DeadLetterSelectDto{
+				Topic: policy.DeadLetterTopic,
+				Ids:   []string{deadLetterRepository.deadLetters[0].GetIdStr(), otherDeadLetter.GetIdStr()},
+			}
+			auditDto, err := deadLetterService.PurgeDeadLetters(ctx, "tester", selectDto)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(auditDto.Count, cv.ShouldEqual, 1)
+			cv.So(len(deadLetterRepository.deadLetters), cv.ShouldEqual, 3)
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/businessrules"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/retrypolicies"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+)
+
+// NewTestDeadLetterServiceImpl creates a DeadLetterServiceImpl that replays
+// dead letters with the given senders by retry topic instead of writing to
+// Kafka
+func NewTestDeadLetterServiceImpl(
+	deadLetterRepository repositories.DeadLetterRepository,
+	deadLetterAuditRepository repositories.DeadLetterAuditRepository,
+	errorService sharedservices.ErrorService,
+	retrySenders map[string]interface {
+		SendWithKey(ctx context.Context, key []byte, body kfka.RetryDto[json.RawMessage]) error
+		Close() error
+	},
+) *DeadLetterServiceImpl {
+	d := &DeadLetterServiceImpl{
+		deadLetterRepository:      deadLetterRepository,
+		deadLetterAuditRepository: deadLetterAuditRepository,
+		deadLetterBr:              businessrules.NewDeadLetterBrImpl(errorService),
+		errorService:              errorService,
+		deadLetterPolicies:        retrypolicies.NewUserChange1PoliciesByDeadLetterTopic(),
+		retrySenders:              map[string]deadLetterSender{},
+	}
+	for topic, retrySender := range retrySenders {
+		d.retrySenders[topic] = retrySender
+	}
+	return d
+}
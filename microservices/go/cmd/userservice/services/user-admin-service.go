@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/businessrules"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/mappers"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"strings"
+)
+
+// UserAdminService lets operators find and manage users
+type UserAdminService interface {
+	// GetUsers returns the users whose username starts with the search or whose
+	// auth ID is the search
+	GetUsers(
+		ctx context.Context,
+		search string,
+		pageReq pagination.PageRequest,
+	) (pagination.Page[userdtos.UserAdminDto], error)
+
+	// GetUserDetails returns a user along with how far the user's changes were
+	// published and the user's deletion, if any. It works for deleted users.
+	GetUserDetails(ctx context.Context, userId string) (userdtos.UserAdminDetailsDto, error)
+
+	// RedistributeUserTxn publishes the user as a new change so services with a
+	// missing or outdated copy of the user catch up
+	RedistributeUserTxn(ctx context.Context, userId string) (userdtos.UserAdminDetailsDto, error)
+
+	// SetUserSuspendedTxn suspends or reinstates a user. Services reject
+	// suspended users and revoke their sessions.
+	SetUserSuspendedTxn(ctx context.Context, userId string, suspended bool) (userdtos.UserAdminDetailsDto, error)
+
+	// BeginDeletingUserTxn deletes a user on the user's behalf
+	BeginDeletingUserTxn(ctx context.Context, userId string) (userdtos.UserAdminDetailsDto, error)
+}
+
+type UserAdminServiceImpl struct {
+	userService             UserService
+	userChangeOutboxService UserChangeOutboxService
+	userDeletionService     UserDeletionService
+	crudDSHandler           dshandlers.CrudDSHandler
+	userRepository          repositories.UserRepository
+	userBr                  businessrules.UserBr
+	errorService            sharedservices.ErrorService
+}
+
+func (u UserAdminServiceImpl) GetUsers(
+	ctx context.Context,
+	search string,
+	pageReq pagination.PageRequest,
+) (pagination.Page[userdtos.UserAdminDto], error) {
+	if err := u.userBr.ValidateGetUsers(pageReq); err != nil {
+		return pagination.Page[userdtos.UserAdminDto]{}, err
+	}
+	search = strings.TrimSpace(search)
+	users, err := u.userRepository.GetPaginatedBySearch(ctx, search, pageReq)
+	if err != nil {
+		return pagination.Page[userdtos.UserAdminDto]{}, err
+	}
+	count, err := u.userRepository.CountBySearch(ctx, search)
+	if err != nil {
+		return pagination.Page[userdtos.UserAdminDto]{}, err
+	}
+	userAdminDtos := make([]userdtos.UserAdminDto, 0, len(users))
+	for _, user := range users {
+		userAdminDto := userdtos.UserAdminDto{}
+		mappers.UserToUserAdminDto(user, &userAdminDto)
+		userAdminDtos = append(userAdminDtos, userAdminDto)
+	}
+	return pagination.NewPage(userAdminDtos, count), nil
+}
+
+func (u UserAdminServiceImpl) GetUserDetails(
+	ctx context.Context,
+	userId string,
+) (userdtos.UserAdminDetailsDto, error) {
+	detailsDto := userdtos.UserAdminDetailsDto{}
+	userFind, err := u.userRepository.FindById(ctx, userId)
+	if err != nil {
+		return userdtos.UserAdminDetailsDto{}, err
+	}
+	if user, ok := userFind.Get(); ok {
+		mappers.UserToUserAdminDto(user, &detailsDto.User)
+	}
+	detailsDto.Distribution, err = u.userChangeOutboxService.GetUserDistribution(ctx, userId)
+	if err != nil {
+		return userdtos.UserAdminDetailsDto{}, err
+	}
+	detailsDto.Deletion, err = u.userDeletionService.GetUserDeletionState(ctx, userId)
+	if err != nil {
+		return userdtos.UserAdminDetailsDto{}, err
+	}
+	if !detailsDto.User.Exists && !detailsDto.Deletion.Exists {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+		return userdtos.UserAdminDetailsDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	return detailsDto, nil
+}
+
+// RedistributeUserTxn saves and publishes the user unchanged. Saving moves the
+// user's UpdatedAt forward, which services compare against their copy to
+// discard stale events, so the redistributed user replaces every copy.
+func (u UserAdminServiceImpl) RedistributeUserTxn(
+	ctx context.Context,
+	userId string,
+) (userdtos.UserAdminDetailsDto, error) {
+	logger.Log.WithContext(ctx).Infof("Redistributing user %v", userId)
+	return u.changeUserTxn(ctx, userId, func(user *models.User) {})
+}
+
+func (u UserAdminServiceImpl) SetUserSuspendedTxn(
+	ctx context.Context,
+	userId string,
+	suspended bool,
+) (userdtos.UserAdminDetailsDto, error) {
+	logger.Log.WithContext(ctx).Infof("Setting user %v as suspended: %v", userId, suspended)
+	return u.changeUserTxn(ctx, userId, func(user *models.User) {
+		user.Suspended = suspended
+	})
+}
+
+func (u UserAdminServiceImpl) changeUserTxn(
+	ctx context.Context,
+	userId string,
+	change func(user *models.User),
+) (userdtos.UserAdminDetailsDto, error) {
+	_, err := dshandlers.Txn(ctx, u.crudDSHandler,
+		func(s dshandlers.Session, ctx context.Context) (bool, error) {
+			return true, u.changeUser(ctx, userId, change)
+		})
+	if err != nil {
+		return userdtos.UserAdminDetailsDto{}, err
+	}
+	return u.GetUserDetails(ctx, userId)
+}
+
+// changeUser applies a change to the user and publishes it
+func (u UserAdminServiceImpl) changeUser(ctx context.Context, userId string, change func(user *models.User)) error {
+	userFind, err := u.userRepository.FindById(ctx, userId)
+	if err != nil {
+		return err
+	}
+	user, ok := userFind.Get()
+	if !ok {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+		return apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+
+	change(&user)
+	user.ChangeSequence++
+	updatedUser, err := u.userRepository.Update(ctx, user)
+	if err != nil {
+		return err
+	}
+	return u.userChangeOutboxService.AddUserChange(ctx, updatedUser, userdtos.UserSave)
+}
+
+func (u UserAdminServiceImpl) BeginDeletingUserTxn(
+	ctx context.Context,
+	userId string,
+) (userdtos.UserAdminDetailsDto, error) {
+	if _, err := u.userService.BeginDeletingUserByIdTxn(ctx, userId); err != nil {
+		return userdtos.UserAdminDetailsDto{}, err
+	}
+	logger.Log.WithContext(ctx).Infof("Deleting user %v on behalf of the user", userId)
+	return u.GetUserDetails(ctx, userId)
+}
+
+func NewUserAdminServiceImpl(
+	userService UserService,
+	userChangeOutboxService UserChangeOutboxService,
+	userDeletionService UserDeletionService,
+	crudDSHandler dshandlers.CrudDSHandler,
+	userRepository repositories.UserRepository,
+	userBr businessrules.UserBr,
+	errorService sharedservices.ErrorService,
+) *UserAdminServiceImpl {
+	return &UserAdminServiceImpl{
+		userService:             userService,
+		userChangeOutboxService: userChangeOutboxService,
+		userDeletionService:     userDeletionService,
+		crudDSHandler:           crudDSHandler,
+		userRepository:          userRepository,
+		userBr:                  userBr,
+		errorService:            errorService,
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/mappers"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"strconv"
+	"time"
+)
+
+const relayUserBatchSize = 100
+
+type UserChangeOutboxService interface {
+	// AddUserChange writes a change event of a saved or deleted user to the
+	// outbox. It should run in the transaction that changed the user.
+	AddUserChange(ctx context.Context, user models.User, action userdtos.UserChangeAction) error
+
+	// RelayUserChangesTask publishes the unsent events in the outbox. The events
+	// of a user are published in order, stopping at the first that fails so
+	// later events are not published ahead of it.
+	RelayUserChangesTask(ctx context.Context)
+
+	// GetUserDistribution describes how far the change events of a user have
+	// been published
+	GetUserDistribution(ctx context.Context, userId string) (userdtos.UserDistributionDto, error)
+
+	// CurrentUserChangeCursor returns a cursor to watch the changes published
+	// from now on
+	CurrentUserChangeCursor(ctx context.Context) (string, error)
+
+	// GetUserChangesAfter returns up to limit published changes in the order
+	// they were published, starting after the change with the cursor. Published
+	// changes are only kept for a while, so a cursor should not be left unused
+	// for long.
+	GetUserChangesAfter(ctx context.Context, cursor string, limit int64) ([]userdtos.UserChangeFeedDto, error)
+}
+
+type UserChangeOutboxServiceImpl struct {
+	userChangeOutboxRepository repositories.UserChangeOutboxRepository
+	userMsgSendService         UserMsgSendService
+	errorService               sharedservices.ErrorService
+	crudDSHandler              dshandlers.CrudDSHandler
+}
+
+func (u UserChangeOutboxServiceImpl) AddUserChange(
+	ctx context.Context,
+	user models.User,
+	action userdtos.UserChangeAction,
+) error {
+	eventDto := userdtos.UserChangeEventDto{}
+	mappers.UserToUserChangeEventDto(user, &eventDto)
+	eventDto.Action = action
+	payload, err := json.Marshal(eventDto)
+	if err != nil {
+		return err
+	}
+	outboxEvent := models.UserChangeOutboxEvent{
+		UserId:   user.GetIdStr(),
+		Sequence: user.ChangeSequence,
+		Payload:  string(payload),
+	}
+	_, err = u.userChangeOutboxRepository.Create(ctx, outboxEvent)
+	return err
+}
+
+func (u UserChangeOutboxServiceImpl) RelayUserChangesTask(ctx context.Context) {
+	unsentByUser, err := u.userChangeOutboxRepository.FindUnsentGroupedByUserId(ctx, relayUserBatchSize)
+	if err != nil {
+		logger.Log.WithContext(ctx).Error(err)
+		return
+	}
+	for _, userEvents := range unsentByUser {
+		// The context is done once this replica is no longer the relay
+		if ctx.Err() != nil {
+			return
+		}
+		for _, outboxEvent := range userEvents.Events {
+			if err := u.relayUserChange(ctx, outboxEvent); err != nil {
+				logger.Log.WithContext(ctx).Error(err)
+				break
+			}
+		}
+	}
+}
+
+// relayUserChange publishes an event and marks it as sent. Events may be
+// published more than once if marking them fails, which consumers tolerate as
+// they discard stale saves and deleting a deleted user does nothing.
+//
+// The event is given the next change feed position and marked as sent in one
+// transaction, which fails if the event was already sent. A relay that lost
+// its lease but is still running conflicts with the new relay on the position
+// counter rather than giving out positions out of order, so an event is saved
+// before the next position is given out and watchers never pass over an event
+// that is not saved yet.
+func (u UserChangeOutboxServiceImpl) relayUserChange(ctx context.Context, outboxEvent models.UserChangeOutboxEvent) error {
+	eventDto := userdtos.UserChangeEventDto{}
+	if err := json.Unmarshal([]byte(outboxEvent.Payload), &eventDto); err != nil {
+		return err
+	}
+	if err := u.userMsgSendService.SendUserSave(ctx, eventDto); err != nil {
+		return err
+	}
+	if _, err := dshandlers.Txn(ctx, u.crudDSHandler,
+		func(_ dshandlers.Session, ctx context.Context) (bool, error) {
+			feedPosition, err := u.userChangeOutboxRepository.NextFeedPosition(ctx)
+			if err != nil {
+				return false, err
+			}
+			marked, err := u.userChangeOutboxRepository.MarkSent(ctx, outboxEvent.GetIdStr(), time.Now(), feedPosition)
+			if err == nil && !marked {
+				err = fmt.Errorf("user change event %v of user %v was already sent",
+					outboxEvent.Sequence, outboxEvent.UserId)
+			}
+			return marked, err
+		}); err != nil {
+		return err
+	}
+	logger.Log.WithContext(ctx).Debugf(
+		"Sent user change event %v of user %v",
+		outboxEvent.Sequence,
+		outboxEvent.UserId,
+	)
+	return nil
+}
+
+func (u UserChangeOutboxServiceImpl) GetUserDistribution(
+	ctx context.Context,
+	userId string,
+) (userdtos.UserDistributionDto, error) {
+	distributionDto := userdtos.UserDistributionDto{}
+	latestFind, err := u.userChangeOutboxRepository.FindLatestByUserId(ctx, userId, false)
+	if err != nil {
+		return userdtos.UserDistributionDto{}, err
+	}
+	if latest, ok := latestFind.Get(); ok {
+		distributionDto.LatestSequence = latest.Sequence
+	}
+	latestSentFind, err := u.userChangeOutboxRepository.FindLatestByUserId(ctx, userId, true)
+	if err != nil {
+		return userdtos.UserDistributionDto{}, err
+	}
+	if latestSent, ok := latestSentFind.Get(); ok {
+		distributionDto.SentSequence = latestSent.Sequence
+		distributionDto.LastSentAt = latestSent.SentAt.UnixMilli()
+	}
+	distributionDto.PendingEvents, err = u.userChangeOutboxRepository.CountUnsentByUserId(ctx, userId)
+	if err != nil {
+		return userdtos.UserDistributionDto{}, err
+	}
+	return distributionDto, nil
+}
+
+func (u UserChangeOutboxServiceImpl) CurrentUserChangeCursor(ctx context.Context) (string, error) {
+	feedPosition, err := u.userChangeOutboxRepository.GetFeedPosition(ctx)
+	if err != nil {
+		return "", err
+	}
+	return newUserChangeCursor(feedPosition), nil
+}
+
+func (u UserChangeOutboxServiceImpl) GetUserChangesAfter(
+	ctx context.Context,
+	cursor string,
+	limit int64,
+) ([]userdtos.UserChangeFeedDto, error) {
+	feedPosition, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || feedPosition < 0 {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeInvalidCursor)
+		return nil, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	outboxEvents, err := u.userChangeOutboxRepository.FindSentAfter(ctx, feedPosition, limit)
+	if err != nil {
+		return nil, err
+	}
+	changeDtos := make([]userdtos.UserChangeFeedDto, 0, len(outboxEvents))
+	for _, outboxEvent := range outboxEvents {
+		changeDto := userdtos.UserChangeFeedDto{}
+		if err := json.Unmarshal([]byte(outboxEvent.Payload), &changeDto.UserChangeEventDto); err != nil {
+			return nil, err
+		}
+		changeDto.Sequence = outboxEvent.Sequence
+		changeDto.Cursor = newUserChangeCursor(outboxEvent.FeedPosition)
+		changeDtos = append(changeDtos, changeDto)
+	}
+	return changeDtos, nil
+}
+
+// newUserChangeCursor creates a cursor from the change feed position of a
+// change
+func newUserChangeCursor(feedPosition int64) string {
+	return strconv.FormatInt(feedPosition, 10)
+}
+
+func NewUserChangeOutboxServiceImpl(
+	userChangeOutboxRepository repositories.UserChangeOutboxRepository,
+	userMsgSendService UserMsgSendService,
+	errorService sharedservices.ErrorService,
+	crudDSHandler dshandlers.CrudDSHandler,
+) *UserChangeOutboxServiceImpl {
+	return &UserChangeOutboxServiceImpl{
+		userChangeOutboxRepository: userChangeOutboxRepository,
+		userMsgSendService:         userMsgSendService,
+		errorService:               errorService,
+		crudDSHandler:              crudDSHandler,
+	}
+}
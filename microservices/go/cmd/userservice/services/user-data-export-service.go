@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/businessrules"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/mappers"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"time"
+)
+
+const relayUserDataExportRequestBatchSize = 50
+
+type UserDataExportService interface {
+	// RequestUserDataExportTxn starts exporting the data every service holds
+	// about the user. A user can have one active export at a time.
+	RequestUserDataExportTxn(ctx context.Context, identity security.Identity) (userdtos.UserDataExportDto, error)
+
+	// GetLatestUserDataExport returns the status of the user's latest export
+	GetLatestUserDataExport(ctx context.Context, identity security.Identity) (userdtos.UserDataExportDto, error)
+
+	// GetUserDataExportBundle assembles the user's latest export once every
+	// service contributed its part
+	GetUserDataExportBundle(
+		ctx context.Context,
+		identity security.Identity,
+	) (userdtos.UserDataExportBundleDto, error)
+
+	// HandleUserDataExportPartTxn records a service's part of a data export
+	HandleUserDataExportPartTxn(ctx context.Context, partDto userdtos.UserDataExportPartDto) error
+
+	// RelayUserDataExportRequestsTask publishes the requests of the exports that
+	// have not been sent to the participants
+	RelayUserDataExportRequestsTask(ctx context.Context)
+}
+
+type UserDataExportServiceImpl struct {
+	userDataExportRepository repositories.UserDataExportRepository
+	userRepository           repositories.UserRepository
+	userMsgSendService       UserMsgSendService
+	userBr                   businessrules.UserBr
+	errorService             sharedservices.ErrorService
+	crudDSHandler            dshandlers.CrudDSHandler
+}
+
+func (u UserDataExportServiceImpl) RequestUserDataExportTxn(
+	ctx context.Context,
+	identity security.Identity,
+) (userdtos.UserDataExportDto, error) {
+	return dshandlers.Txn(ctx, u.crudDSHandler,
+		func(_ dshandlers.Session, ctx context.Context) (userdtos.UserDataExportDto, error) {
+			return u.requestUserDataExport(ctx, identity)
+		})
+}
+
+func (u UserDataExportServiceImpl) requestUserDataExport(
+	ctx context.Context,
+	identity security.Identity,
+) (userdtos.UserDataExportDto, error) {
+	user, err := u.getUser(ctx, identity)
+	if err != nil {
+		return userdtos.UserDataExportDto{}, err
+	}
+	createdBefore := time.Now().Add(-models.UserDataExportActiveDuration)
+	if err := u.userDataExportRepository.FailPendingByUserIdCreatedBefore(
+		ctx,
+		user.GetIdStr(),
+		createdBefore,
+	); err != nil {
+		return userdtos.UserDataExportDto{}, err
+	}
+	if err := u.userBr.ValidateUserDataExportCreate(ctx, user); err != nil {
+		return userdtos.UserDataExportDto{}, err
+	}
+
+	profile, err := json.Marshal(userToUserReadDto(user))
+	if err != nil {
+		return userdtos.UserDataExportDto{}, err
+	}
+	now := time.Now()
+	parts := make([]models.UserDataExportPart, 0, len(userdtos.UserDataExportParticipants))
+	for _, participant := range userdtos.UserDataExportParticipants {
+		parts = append(parts, models.UserDataExportPart{
+			Participant: participant,
+			Status:      userdtos.UserDataExportPending,
+			UpdatedAt:   now,
+		})
+	}
+	userDataExport := models.UserDataExport{
+		UserId:  user.GetIdStr(),
+		Status:  userdtos.UserDataExportPending,
+		Profile: string(profile),
+		Parts:   parts,
+	}
+	// The export is the outbox of its request, which is relayed to the
+	// participants once the transaction commits
+	createdExportFind, err := u.userDataExportRepository.CreateIfNoneActive(ctx, userDataExport)
+	if err != nil {
+		return userdtos.UserDataExportDto{}, err
+	}
+	createdExport, ok := createdExportFind.Get()
+	if !ok {
+		// Another export was requested after the rules were validated
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeDataExportInProgress)
+		return userdtos.UserDataExportDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+
+	logger.Log.WithContext(ctx).Debugf("Requested data export %v", createdExport.GetIdStr())
+	return userDataExportToDto(createdExport), nil
+}
+
+func (u UserDataExportServiceImpl) GetLatestUserDataExport(
+	ctx context.Context,
+	identity security.Identity,
+) (userdtos.UserDataExportDto, error) {
+	userDataExport, err := u.getLatestUserDataExport(ctx, identity)
+	if err != nil {
+		return userdtos.UserDataExportDto{}, err
+	}
+	return userDataExportToDto(userDataExport), nil
+}
+
+func (u UserDataExportServiceImpl) GetUserDataExportBundle(
+	ctx context.Context,
+	identity security.Identity,
+) (userdtos.UserDataExportBundleDto, error) {
+	userDataExport, err := u.getLatestUserDataExport(ctx, identity)
+	if err != nil {
+		return userdtos.UserDataExportBundleDto{}, err
+	}
+	if !userDataExport.IsCompleted() {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeDataExportNotReady)
+		return userdtos.UserDataExportBundleDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+
+	bundleDto := userdtos.UserDataExportBundleDto{
+		ExportId:   userDataExport.GetIdStr(),
+		ExportedAt: userDataExport.UpdatedAt.UnixMilli(),
+		Services:   make(map[string]json.RawMessage, len(userDataExport.Parts)),
+	}
+	if err := json.Unmarshal([]byte(userDataExport.Profile), &bundleDto.User); err != nil {
+		return userdtos.UserDataExportBundleDto{}, err
+	}
+	for _, part := range userDataExport.Parts {
+		bundleDto.Services[part.Participant] = json.RawMessage(part.Data)
+	}
+	return bundleDto, nil
+}
+
+func (u UserDataExportServiceImpl) HandleUserDataExportPartTxn(
+	ctx context.Context,
+	partDto userdtos.UserDataExportPartDto,
+) error {
+	_, err := dshandlers.Txn(ctx, u.crudDSHandler,
+		func(_ dshandlers.Session, ctx context.Context) (bool, error) {
+			return true, u.handleUserDataExportPart(ctx, partDto)
+		})
+	return err
+}
+
+func (u UserDataExportServiceImpl) handleUserDataExportPart(
+	ctx context.Context,
+	partDto userdtos.UserDataExportPartDto,
+) error {
+	userDataExportFind, err := u.userDataExportRepository.FindById(ctx, partDto.ExportId)
+	if err != nil {
+		return err
+	}
+	userDataExport, ok := userDataExportFind.Get()
+	if !ok || userDataExport.UserId != partDto.UserId {
+		logger.Log.WithContext(ctx).Warnf("Discarding part of unknown data export %v", partDto.ExportId)
+		return nil
+	}
+	if !userDataExport.IsPending() {
+		return nil
+	}
+
+	partFound := false
+	for i := range userDataExport.Parts {
+		part := &userDataExport.Parts[i]
+		if part.Participant != partDto.Participant {
+			continue
+		}
+		partFound = true
+		part.UpdatedAt = time.Now()
+		if partDto.Succeeded {
+			part.Status = userdtos.UserDataExportCompleted
+			part.Error = ""
+			part.Data = string(partDto.Data)
+		} else {
+			part.Status = userdtos.UserDataExportFailed
+			part.Error = partDto.Error
+		}
+	}
+	if !partFound {
+		logger.Log.WithContext(ctx).Warnf(
+			"Discarding part of data export %v from unknown participant %v",
+			partDto.ExportId,
+			partDto.Participant,
+		)
+		return nil
+	}
+
+	userDataExport.Status = userDataExport.PartsStatus()
+	_, err = u.userDataExportRepository.Update(ctx, userDataExport)
+	return err
+}
+
+func (u UserDataExportServiceImpl) RelayUserDataExportRequestsTask(ctx context.Context) {
+	userDataExports, err := u.userDataExportRepository.FindRequestUnsent(ctx, relayUserDataExportRequestBatchSize)
+	if err != nil {
+		logger.Log.WithContext(ctx).Error(err)
+		return
+	}
+	for _, userDataExport := range userDataExports {
+		if err := u.relayUserDataExportRequest(ctx, userDataExport); err != nil {
+			logger.Log.WithContext(ctx).Error(err)
+		}
+	}
+}
+
+// relayUserDataExportRequest publishes the request of an export and marks it
+// as sent. If marking fails the request is published again, which
+// participants tolerate as the export ignores parts once it is no longer
+// pending and replaces a participant's part otherwise.
+func (u UserDataExportServiceImpl) relayUserDataExportRequest(
+	ctx context.Context,
+	userDataExport models.UserDataExport,
+) error {
+	userFind, err := u.userRepository.FindById(ctx, userDataExport.UserId)
+	if err != nil {
+		return err
+	}
+	user, ok := userFind.Get()
+	// Exports of deleted users or that are no longer pending are not requested
+	if ok && userDataExport.IsPending() {
+		requestDto := userdtos.UserDataExportRequestDto{
+			ExportId: userDataExport.GetIdStr(),
+			UserId:   userDataExport.UserId,
+			AuthId:   user.AuthId,
+		}
+		if err := u.userMsgSendService.SendUserDataExportRequest(ctx, requestDto); err != nil {
+			return err
+		}
+	}
+	if err := u.userDataExportRepository.MarkRequestSent(ctx, userDataExport.GetIdStr(), time.Now()); err != nil {
+		return err
+	}
+	logger.Log.WithContext(ctx).Debugf("Sent request of data export %v", userDataExport.GetIdStr())
+	return nil
+}
+
+func (u UserDataExportServiceImpl) getLatestUserDataExport(
+	ctx context.Context,
+	identity security.Identity,
+) (models.UserDataExport, error) {
+	user, err := u.getUser(ctx, identity)
+	if err != nil {
+		return models.UserDataExport{}, err
+	}
+	userDataExportFind, err := u.userDataExportRepository.FindLatestByUserId(ctx, user.GetIdStr())
+	if err != nil {
+		return models.UserDataExport{}, err
+	}
+	userDataExport, ok := userDataExportFind.Get()
+	// Expired exports are removed by a TTL index, which may lag behind
+	if !ok || userDataExport.GetExpiresAt().Before(time.Now()) {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+		return models.UserDataExport{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	return userDataExport, nil
+}
+
+func (u UserDataExportServiceImpl) getUser(ctx context.Context, identity security.Identity) (models.User, error) {
+	userSearch, err := u.userRepository.FindByAuthId(ctx, identity.GetAuthId())
+	if err != nil {
+		return models.User{}, err
+	}
+	user, isPresent := userSearch.Get()
+	if !isPresent {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+		return models.User{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	return user, nil
+}
+
+func userDataExportToDto(userDataExport models.UserDataExport) userdtos.UserDataExportDto {
+	userDataExportDto := userdtos.UserDataExportDto{}
+	mappers.UserDataExportToUserDataExportDto(userDataExport, &userDataExportDto)
+	return userDataExportDto
+}
+
+func NewUserDataExportServiceImpl(
+	userDataExportRepository repositories.UserDataExportRepository,
+	userRepository repositories.UserRepository,
+	userMsgSendService UserMsgSendService,
+	userBr businessrules.UserBr,
+	errorService sharedservices.ErrorService,
+	crudDSHandler dshandlers.CrudDSHandler,
+) *UserDataExportServiceImpl {
+	return &UserDataExportServiceImpl{
+		userDataExportRepository: userDataExportRepository,
+		userRepository:           userRepository,
+		userMsgSendService:       userMsgSendService,
+		userBr:                   userBr,
+		errorService:             errorService,
+		crudDSHandler:            crudDSHandler,
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/mappers"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/models"
+	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/repositories"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/identityproviders"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"time"
+)
+
+const identityProviderDeleteBatchSize = 50
+
+type UserDeletionService interface {
+	// StartUserDeletion records that every participant has yet to delete the
+	// data of a deleted user. It should run in the transaction that deleted the
+	// user.
+	StartUserDeletion(ctx context.Context, user models.User) error
+
+	// HandleUserDeleteAckTxn records a participant's reply to a user deletion.
+	// Once every participant succeeded, the deletion is completed and the user
+	// is left to be deleted from the auth server by
+	// DeleteFromIdentityProviderTask.
+	HandleUserDeleteAckTxn(ctx context.Context, ackDto userdtos.UserDeleteAckDto) error
+
+	// DeleteFromIdentityProviderTask deletes the users of completed deletions
+	// from the auth server. It runs after the deletions are committed so a
+	// failed transaction never leaves a user deleted only from the auth server.
+	DeleteFromIdentityProviderTask(ctx context.Context)
+
+	// GetStuckUserDeletions returns the unfinished deletions that failed or have
+	// been waiting on participants for longer than a duration
+	GetStuckUserDeletions(
+		ctx context.Context,
+		olderThan time.Duration,
+		pageReq pagination.PageRequest,
+	) (pagination.Page[userdtos.UserDeletionDto], error)
+
+	GetUserDeletionState(ctx context.Context, userId string) (userdtos.UserDeletionStateDto, error)
+}
+
+type UserDeletionServiceImpl struct {
+	userDeletionRepository repositories.UserDeletionRepository
+	identityProvider       identityproviders.IdentityProvider
+	crudDSHandler          dshandlers.CrudDSHandler
+}
+
+func (u UserDeletionServiceImpl) StartUserDeletion(ctx context.Context, user models.User) error {
+	now := time.Now()
+	participants := make([]models.UserDeletionParticipant, 0, len(userdtos.UserDeleteParticipants))
+	for _, participant := range userdtos.UserDeleteParticipants {
+		participants = append(participants, models.UserDeletionParticipant{
+			Participant: participant,
+			Status:      userdtos.UserDeletionPending,
+			UpdatedAt:   now,
+		})
+	}
+	userDeletion := models.UserDeletion{
+		UserId:       user.GetIdStr(),
+		AuthId:       user.AuthId,
+		UserName:     user.UserName,
+		Status:       userdtos.UserDeletionPending,
+		Participants: participants,
+	}
+	_, err := u.userDeletionRepository.Create(ctx, userDeletion)
+	return err
+}
+
+func (u UserDeletionServiceImpl) HandleUserDeleteAckTxn(ctx context.Context, ackDto userdtos.UserDeleteAckDto) error {
+	_, err := dshandlers.Txn(ctx, u.crudDSHandler,
+		func(_ dshandlers.Session, ctx context.Context) (bool, error) {
+			return true, u.handleUserDeleteAck(ctx, ackDto)
+		})
+	return err
+}
+
+func (u UserDeletionServiceImpl) handleUserDeleteAck(ctx context.Context, ackDto userdtos.UserDeleteAckDto) error {
+	userDeletionFind, err := u.userDeletionRepository.FindByUserId(ctx, ackDto.UserId)
+	if err != nil {
+		return err
+	}
+	userDeletion, ok := userDeletionFind.Get()
+	if !ok {
+		logger.Log.WithContext(ctx).Warnf("Discarding acknowledgement of unknown user deletion %v", ackDto.UserId)
+		return nil
+	}
+	if userDeletion.IsCompleted() {
+		return nil
+	}
+
+	participantFound := false
+	for i := range userDeletion.Participants {
+		participant := &userDeletion.Participants[i]
+		if participant.Participant != ackDto.Participant {
+			continue
+		}
+		participantFound = true
+		participant.UpdatedAt = time.Now()
+		if ackDto.Succeeded {
+			participant.Status = userdtos.UserDeletionCompleted
+			participant.Error = ""
+		} else {
+			participant.Status = userdtos.UserDeletionFailed
+			participant.Error = ackDto.Error
+		}
+	}
+	if !participantFound {
+		logger.Log.WithContext(ctx).Warnf(
+			"Discarding acknowledgement of user deletion %v from unknown participant %v",
+			ackDto.UserId,
+			ackDto.Participant,
+		)
+		return nil
+	}
+
+	userDeletion.Status = userDeletion.ParticipantsStatus()
+	_, err = u.userDeletionRepository.Update(ctx, userDeletion)
+	return err
+}
+
+func (u UserDeletionServiceImpl) DeleteFromIdentityProviderTask(ctx context.Context) {
+	userDeletions, err := u.userDeletionRepository.FindCompletedNotIdentityProviderDeleted(
+		ctx,
+		identityProviderDeleteBatchSize,
+	)
+	if err != nil {
+		logger.Log.WithContext(ctx).Error(err)
+		return
+	}
+	for _, userDeletion := range userDeletions {
+		if err := u.deleteFromIdentityProvider(ctx, userDeletion); err != nil {
+			logger.Log.WithContext(ctx).WithError(err).
+				Errorf("Failed to delete user %v from the auth server", userDeletion.UserId)
+		}
+	}
+}
+
+// deleteFromIdentityProvider deletes the user of a deletion from the auth
+// server and marks it as deleted. If marking fails the user is deleted again,
+// which the auth server reports as not found.
+func (u UserDeletionServiceImpl) deleteFromIdentityProvider(
+	ctx context.Context,
+	userDeletion models.UserDeletion,
+) error {
+	if _, err := u.identityProvider.DeleteUser(ctx, userDeletion.AuthId); err != nil {
+		return err
+	}
+	if err := u.userDeletionRepository.MarkIdentityProviderDeleted(ctx, userDeletion.GetIdStr()); err != nil {
+		return err
+	}
+	logger.Log.WithContext(ctx).Debugf("Finished deleting user %v", userDeletion.UserId)
+	return nil
+}
+
+func (u UserDeletionServiceImpl) GetStuckUserDeletions(
+	ctx context.Context,
+	olderThan time.Duration,
+	pageReq pagination.PageRequest,
+) (pagination.Page[userdtos.UserDeletionDto], error) {
+	startedBefore := time.Now().Add(-olderThan)
+	userDeletions, err := u.userDeletionRepository.GetPaginatedStuck(ctx, startedBefore, pageReq)
+	if err != nil {
+		return pagination.Page[userdtos.UserDeletionDto]{}, err
+	}
+	count, err := u.userDeletionRepository.CountStuck(ctx, startedBefore)
+	if err != nil {
+		return pagination.Page[userdtos.UserDeletionDto]{}, err
+	}
+	userDeletionDtos := make([]userdtos.UserDeletionDto, 0, len(userDeletions))
+	for _, userDeletion := range userDeletions {
+		userDeletionDto := userdtos.UserDeletionDto{}
+		mappers.UserDeletionToUserDeletionDto(userDeletion, &userDeletionDto)
+		userDeletionDtos = append(userDeletionDtos, userDeletionDto)
+	}
+	return pagination.NewPage(userDeletionDtos, count), nil
+}
+
+func (u UserDeletionServiceImpl) GetUserDeletionState(
+	ctx context.Context,
+	userId string,
+) (userdtos.UserDeletionStateDto, error) {
+	userDeletionFind, err := u.userDeletionRepository.FindByUserId(ctx, userId)
+	if err != nil {
+		return userdtos.UserDeletionStateDto{}, err
+	}
+	deletionStateDto := userdtos.UserDeletionStateDto{}
+	if userDeletion, ok := userDeletionFind.Get(); ok {
+		deletionStateDto.Exists = true
+		mappers.UserDeletionToUserDeletionDto(userDeletion, &deletionStateDto.UserDeletionDto)
+	}
+	return deletionStateDto, nil
+}
+
+func NewUserDeletionServiceImpl(
+	userDeletionRepository repositories.UserDeletionRepository,
+	identityProvider identityproviders.IdentityProvider,
+	crudDSHandler dshandlers.CrudDSHandler,
+) *UserDeletionServiceImpl {
+	return &UserDeletionServiceImpl{
+		userDeletionRepository: userDeletionRepository,
+		identityProvider:       identityProvider,
+		crudDSHandler:          crudDSHandler,
+	}
+}
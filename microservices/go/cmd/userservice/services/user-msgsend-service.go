@@ -7,24 +7,34 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
 	"github.com/segmentio/kafka-go"
 )
 
 type UserMsgSendService interface {
 	SendUserSave(ctx context.Context, dto userdtos.UserChangeEventDto) error
+	SendUserDataExportRequest(ctx context.Context, dto userdtos.UserDataExportRequestDto) error
 	lifecycle.Closable
 }
 
 type UserMessageServiceImpl struct {
-	userSaveSender *kfka.KafkaSender[userdtos.UserChangeEventDto]
+	userSaveSender              *kfka.KafkaSender[userdtos.UserChangeEventDto]
+	userDataExportRequestSender *kfka.KafkaSender[userdtos.UserDataExportRequestDto]
 }
 
 func (u *UserMessageServiceImpl) SendUserSave(ctx context.Context, dto userdtos.UserChangeEventDto) error {
 	return u.userSaveSender.Send(ctx, dto)
 }
 
+func (u *UserMessageServiceImpl) SendUserDataExportRequest(
+	ctx context.Context,
+	dto userdtos.UserDataExportRequestDto,
+) error {
+	return u.userDataExportRequestSender.Send(ctx, dto)
+}
+
 func (u *UserMessageServiceImpl) Close() error {
-	return u.userSaveSender.Close()
+	return utils.ConcatErrors(u.userSaveSender.Close(), u.userDataExportRequestSender.Close())
 }
 
 func NewUserMessageServiceImpl(kafkaConf conf.KafkaConf) *UserMessageServiceImpl {
@@ -36,7 +46,18 @@ func NewUserMessageServiceImpl(kafkaConf conf.KafkaConf) *UserMessageServiceImpl
 		},
 		userdtos.UserChangeEventDto.MessageKey,
 	)
-	u := &UserMessageServiceImpl{userSaveSender: userSaveSender}
+	userDataExportRequestSender := kfka.NewKafkaSender(
+		&kafka.Writer{
+			Addr:     kafka.TCP(kafkaConf.GetBootstrapServers()...),
+			Topic:    topics.UserDataExportTopic,
+			Balancer: &kafka.Murmur2Balancer{},
+		},
+		userdtos.UserDataExportRequestDto.MessageKey,
+	)
+	u := &UserMessageServiceImpl{
+		userSaveSender:              userSaveSender,
+		userDataExportRequestSender: userDataExportRequestSender,
+	}
 	lifecycle.RegisterClosable(u)
 	return u
 }
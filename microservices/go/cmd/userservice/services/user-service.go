@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/businessrules"
 	"github.com/obenkenobi/cypher-log/microservices/go/cmd/userservice/mappers"
@@ -13,6 +14,9 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmappers"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
+	"io"
+	"net/http"
 )
 
 type UserService interface {
@@ -26,20 +30,49 @@ type UserService interface {
 		identity security.Identity,
 		userSaveDto userdtos.UserSaveDto,
 	) (userdtos.UserReadDto, error)
+	UpdateUserProfileTxn(
+		ctx context.Context,
+		identity security.Identity,
+		profileSaveDto userdtos.UserProfileSaveDto,
+	) (userdtos.UserReadDto, error)
+	UpdateUserPreferencesTxn(
+		ctx context.Context,
+		identity security.Identity,
+		preferencesSaveDto userdtos.UserPreferencesSaveDto,
+	) (userdtos.UserReadDto, error)
+	// SetUserAvatar replaces the user's avatar with an uploaded image
+	SetUserAvatar(
+		ctx context.Context,
+		identity security.Identity,
+		avatarSaveDto userdtos.UserAvatarSaveDto,
+	) (userdtos.UserReadDto, error)
+	RemoveUserAvatar(ctx context.Context, identity security.Identity) (userdtos.UserReadDto, error)
+	GetUserAvatar(ctx context.Context, avatarId string) (userdtos.UserAvatarDto, error)
 	BeginDeletingUserTxn(ctx context.Context, identity security.Identity) (userdtos.UserReadDto, error)
+	// BeginDeletingUserByIdTxn deletes a user on the user's behalf
+	BeginDeletingUserByIdTxn(ctx context.Context, userId string) (userdtos.UserReadDto, error)
 	GetByAuthId(ctx context.Context, authId string) (userdtos.UserReadDto, error)
 	GetById(ctx context.Context, userId string) (userdtos.UserReadDto, error)
+
+	// GetByIds returns a user for each ID in the same order. Users that do not
+	// exist only have their ID set.
+	GetByIds(ctx context.Context, userIds []string) ([]userdtos.UserReadDto, error)
+
+	// GetUsersAfterId returns up to limit users ordered by ID, starting after
+	// the user with afterId or from the first user if afterId is blank. The limit
+	// is kept within 1 and businessrules.MaxUsersPerRequest.
+	GetUsersAfterId(ctx context.Context, afterId string, limit int64) ([]userdtos.UserReadDto, error)
 	GetUserIdentity(ctx context.Context, identity security.Identity) (userdtos.UserIdentityDto, error)
-	UsersChangeTask(ctx context.Context)
 }
 
 type UserServiceImpl struct {
-	userMsgSendService    UserMsgSendService
-	crudDSHandler         dshandlers.CrudDSHandler
-	userRepository        repositories.UserRepository
-	userBr                businessrules.UserBr
-	errorService          sharedservices.ErrorService
-	authServerMgmtService AuthServerMgmtService
+	userChangeOutboxService UserChangeOutboxService
+	userDeletionService     UserDeletionService
+	crudDSHandler           dshandlers.CrudDSHandler
+	userRepository          repositories.UserRepository
+	userAvatarRepository    repositories.UserAvatarRepository
+	userBr                  businessrules.UserBr
+	errorService            sharedservices.ErrorService
 }
 
 func (u UserServiceImpl) AddUserTxn(
@@ -66,13 +99,15 @@ func (u UserServiceImpl) addUser(
 	user := models.User{}
 	mappers.UserSaveDtoToUser(userSaveDto, &user)
 	user.AuthId = identity.GetAuthId()
-	user.Distributed = false
-	user.ToBeDeleted = false
+	user.ChangeSequence = 0
 
 	createdUser, err := u.userRepository.Create(ctx, user)
 	if err != nil {
 		return userdtos.UserReadDto{}, err
 	}
+	if err := u.userChangeOutboxService.AddUserChange(ctx, createdUser, userdtos.UserSave); err != nil {
+		return userdtos.UserReadDto{}, err
+	}
 
 	logger.Log.WithContext(ctx).Debug("Saved user ", user)
 	return userToUserReadDto(createdUser), nil
@@ -95,7 +130,7 @@ func (u UserServiceImpl) updateUser(
 	identity security.Identity,
 	userSaveDto userdtos.UserSaveDto,
 ) (userdtos.UserReadDto, error) {
-	userSearch, err := u.userRepository.FindByAuthIdAndNotToBeDeleted(ctx, identity.GetAuthId())
+	userSearch, err := u.userRepository.FindByAuthId(ctx, identity.GetAuthId())
 	if err != nil {
 		return userdtos.UserReadDto{}, err
 	}
@@ -107,59 +142,261 @@ func (u UserServiceImpl) updateUser(
 		return userdtos.UserReadDto{}, err
 	}
 
+	if err := u.userBr.ValidateUserNotSuspended(user); err != nil {
+		return userdtos.UserReadDto{}, err
+	}
 	if err = u.userBr.ValidateUserUpdate(ctx, userSaveDto, user); err != nil {
 		return userdtos.UserReadDto{}, err
 	}
 
 	mappers.UserSaveDtoToUser(userSaveDto, &user)
-	user.Distributed = false
-	user.ToBeDeleted = false
+	user.ChangeSequence++
 
 	updatedUser, err := u.userRepository.Update(ctx, user)
 	if err != nil {
 		return userdtos.UserReadDto{}, err
 	}
+	if err := u.userChangeOutboxService.AddUserChange(ctx, updatedUser, userdtos.UserSave); err != nil {
+		return userdtos.UserReadDto{}, err
+	}
 
 	logger.Log.WithContext(ctx).Debug("Saved user ", updatedUser)
 	return userToUserReadDto(updatedUser), nil
 }
 
-func (u UserServiceImpl) BeginDeletingUserTxn(
+func (u UserServiceImpl) UpdateUserProfileTxn(
+	ctx context.Context,
+	identity security.Identity,
+	profileSaveDto userdtos.UserProfileSaveDto,
+) (userdtos.UserReadDto, error) {
+	if err := u.userBr.ValidateUserProfileUpdate(profileSaveDto); err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+	return dshandlers.Txn(ctx, u.crudDSHandler,
+		func(s dshandlers.Session, ctx context.Context) (userdtos.UserReadDto, error) {
+			updatedUser, err := u.changeUser(ctx, identity, func(user *models.User) {
+				mappers.UserProfileSaveDtoToUser(profileSaveDto, user)
+			})
+			return userToUserReadDto(updatedUser), err
+		},
+	)
+}
+
+func (u UserServiceImpl) UpdateUserPreferencesTxn(
 	ctx context.Context,
 	identity security.Identity,
+	preferencesSaveDto userdtos.UserPreferencesSaveDto,
 ) (userdtos.UserReadDto, error) {
 	return dshandlers.Txn(ctx, u.crudDSHandler,
 		func(s dshandlers.Session, ctx context.Context) (userdtos.UserReadDto, error) {
-			return u.beginDeletingUser(ctx, identity)
-		})
+			updatedUser, err := u.changeUser(ctx, identity, func(user *models.User) {
+				mappers.UserPreferencesSaveDtoToUser(preferencesSaveDto, user)
+			})
+			return userToUserReadDto(updatedUser), err
+		},
+	)
 }
 
-func (u UserServiceImpl) beginDeletingUser(
+func (u UserServiceImpl) SetUserAvatar(
 	ctx context.Context,
 	identity security.Identity,
+	avatarSaveDto userdtos.UserAvatarSaveDto,
 ) (userdtos.UserReadDto, error) {
-	userSearch, err := u.userRepository.FindByAuthIdAndNotToBeDeleted(ctx, identity.GetAuthId())
+	avatar, err := u.readUserAvatar(avatarSaveDto)
+	if err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+	if err := u.userBr.ValidateUserAvatar(avatar); err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+	userDto, err := u.GetByAuthId(ctx, identity.GetAuthId())
 	if err != nil {
 		return userdtos.UserReadDto{}, err
 	}
+	avatar.UserId = userDto.Id
+	avatarId, err := u.userAvatarRepository.Create(ctx, avatar)
+	if err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+
+	previousAvatarId := ""
+	updatedUserDto, err := dshandlers.Txn(ctx, u.crudDSHandler,
+		func(s dshandlers.Session, ctx context.Context) (userdtos.UserReadDto, error) {
+			updatedUser, err := u.changeUser(ctx, identity, func(user *models.User) {
+				previousAvatarId = user.AvatarId
+				user.AvatarId = avatarId
+			})
+			return userToUserReadDto(updatedUser), err
+		},
+	)
+	if err != nil {
+		u.deleteUserAvatar(ctx, avatarId)
+		return userdtos.UserReadDto{}, err
+	}
+	u.deleteUserAvatar(ctx, previousAvatarId)
+	return updatedUserDto, nil
+}
+
+func (u UserServiceImpl) RemoveUserAvatar(
+	ctx context.Context,
+	identity security.Identity,
+) (userdtos.UserReadDto, error) {
+	previousAvatarId := ""
+	updatedUserDto, err := dshandlers.Txn(ctx, u.crudDSHandler,
+		func(s dshandlers.Session, ctx context.Context) (userdtos.UserReadDto, error) {
+			updatedUser, err := u.changeUser(ctx, identity, func(user *models.User) {
+				previousAvatarId = user.AvatarId
+				user.AvatarId = ""
+			})
+			return userToUserReadDto(updatedUser), err
+		},
+	)
+	if err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+	u.deleteUserAvatar(ctx, previousAvatarId)
+	return updatedUserDto, nil
+}
+
+func (u UserServiceImpl) GetUserAvatar(ctx context.Context, avatarId string) (userdtos.UserAvatarDto, error) {
+	avatarFind, err := u.userAvatarRepository.FindById(ctx, avatarId)
+	if err != nil {
+		return userdtos.UserAvatarDto{}, err
+	}
+	avatar, ok := avatarFind.Get()
+	if !ok {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+		return userdtos.UserAvatarDto{}, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+	}
+	return userdtos.UserAvatarDto{ContentType: avatar.ContentType, Data: avatar.Data}, nil
+}
+
+// readUserAvatar reads at most one byte more than the largest avatar allowed
+// so larger uploads can be rejected without being read entirely
+func (u UserServiceImpl) readUserAvatar(avatarSaveDto userdtos.UserAvatarSaveDto) (models.UserAvatar, error) {
+	file, err := avatarSaveDto.Avatar.Open()
+	if err != nil {
+		return models.UserAvatar{}, err
+	}
+	defer file.Close()
+	data := bytes.Buffer{}
+	if _, err := data.ReadFrom(io.LimitReader(file, models.UserAvatarMaxBytes+1)); err != nil {
+		return models.UserAvatar{}, err
+	}
+	// The content type is sniffed rather than trusting the client
+	return models.UserAvatar{ContentType: http.DetectContentType(data.Bytes()), Data: data.Bytes()}, nil
+}
+
+// deleteUserAvatar deletes an avatar that is no longer referenced. Failures
+// are logged since the user's change is already committed.
+func (u UserServiceImpl) deleteUserAvatar(ctx context.Context, avatarId string) {
+	if avatarId == "" {
+		return
+	}
+	if err := u.userAvatarRepository.Delete(ctx, avatarId); err != nil {
+		logger.Log.WithContext(ctx).WithError(err).Errorf("Failed to delete avatar %v", avatarId)
+	}
+}
+
+// changeUser applies a change to the user and publishes it
+func (u UserServiceImpl) changeUser(
+	ctx context.Context,
+	identity security.Identity,
+	change func(user *models.User),
+) (models.User, error) {
+	userSearch, err := u.userRepository.FindByAuthId(ctx, identity.GetAuthId())
+	if err != nil {
+		return models.User{}, err
+	}
 
 	user, isPresent := userSearch.Get()
 	if !isPresent {
 		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
 		err := apperrors.NewBadReqErrorFromRuleError(ruleErr)
-		return userdtos.UserReadDto{}, err
+		return models.User{}, err
+	}
+
+	if err := u.userBr.ValidateUserNotSuspended(user); err != nil {
+		return models.User{}, err
 	}
 
-	user.Distributed = false
-	user.ToBeDeleted = true
+	change(&user)
+	user.ChangeSequence++
 
 	updatedUser, err := u.userRepository.Update(ctx, user)
+	if err != nil {
+		return models.User{}, err
+	}
+	if err := u.userChangeOutboxService.AddUserChange(ctx, updatedUser, userdtos.UserSave); err != nil {
+		return models.User{}, err
+	}
+
+	logger.Log.WithContext(ctx).Debug("Saved user ", updatedUser)
+	return updatedUser, nil
+}
+
+func (u UserServiceImpl) BeginDeletingUserTxn(
+	ctx context.Context,
+	identity security.Identity,
+) (userdtos.UserReadDto, error) {
+	return u.beginDeletingUserTxn(ctx, func(ctx context.Context) (option.Maybe[models.User], error) {
+		return u.userRepository.FindByAuthId(ctx, identity.GetAuthId())
+	})
+}
+
+func (u UserServiceImpl) BeginDeletingUserByIdTxn(ctx context.Context, userId string) (userdtos.UserReadDto, error) {
+	return u.beginDeletingUserTxn(ctx, func(ctx context.Context) (option.Maybe[models.User], error) {
+		return u.userRepository.FindById(ctx, userId)
+	})
+}
+
+func (u UserServiceImpl) beginDeletingUserTxn(
+	ctx context.Context,
+	findUser func(ctx context.Context) (option.Maybe[models.User], error),
+) (userdtos.UserReadDto, error) {
+	deletedUserDto, err := dshandlers.Txn(ctx, u.crudDSHandler,
+		func(s dshandlers.Session, ctx context.Context) (userdtos.UserReadDto, error) {
+			return u.beginDeletingUser(ctx, findUser)
+		})
 	if err != nil {
 		return userdtos.UserReadDto{}, err
 	}
+	u.deleteUserAvatar(ctx, deletedUserDto.AvatarId)
+	return deletedUserDto, nil
+}
 
-	logger.Log.WithContext(ctx).Debug("Starting to delete user ", updatedUser)
-	return userToUserReadDto(updatedUser), nil
+func (u UserServiceImpl) beginDeletingUser(
+	ctx context.Context,
+	findUser func(ctx context.Context) (option.Maybe[models.User], error),
+) (userdtos.UserReadDto, error) {
+	userSearch, err := findUser(ctx)
+	if err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+
+	user, isPresent := userSearch.Get()
+	if !isPresent {
+		ruleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeReqResourcesNotFound)
+		err := apperrors.NewBadReqErrorFromRuleError(ruleErr)
+		return userdtos.UserReadDto{}, err
+	}
+
+	// The user is removed from the auth server once every service acknowledged
+	// deleting the user's data
+	deletedUser, err := u.userRepository.Delete(ctx, user)
+	if err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+	deletedUser.ChangeSequence++
+	if err := u.userChangeOutboxService.AddUserChange(ctx, deletedUser, userdtos.UserDelete); err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+	if err := u.userDeletionService.StartUserDeletion(ctx, deletedUser); err != nil {
+		return userdtos.UserReadDto{}, err
+	}
+
+	logger.Log.WithContext(ctx).Debug("Starting to delete user ", deletedUser)
+	return userToUserReadDto(deletedUser), nil
 }
 
 func (u UserServiceImpl) GetUserIdentity(
@@ -176,7 +413,7 @@ func (u UserServiceImpl) GetUserIdentity(
 }
 
 func (u UserServiceImpl) GetByAuthId(ctx context.Context, authId string) (userdtos.UserReadDto, error) {
-	userSearch, err := u.userRepository.FindByAuthIdAndNotToBeDeleted(ctx, authId)
+	userSearch, err := u.userRepository.FindByAuthId(ctx, authId)
 	if err != nil {
 		return userdtos.UserReadDto{}, err
 	}
@@ -189,98 +426,48 @@ func (u UserServiceImpl) GetById(ctx context.Context, userId string) (userdtos.U
 	if err != nil {
 		return userdtos.UserReadDto{}, err
 	}
-	user := userSearch.Filter(models.User.WillNotBeDeleted).OrElse(models.User{})
+	user := userSearch.OrElse(models.User{})
 	return userToUserReadDto(user), nil
 }
 
-func (u UserServiceImpl) UsersChangeTask(ctx context.Context) {
-	userSample, err := u.userRepository.SampleUndistributedUsers(ctx, 100)
-	if err != nil {
-		logger.Log.WithContext(ctx).Error(err)
-		return
+func (u UserServiceImpl) GetByIds(ctx context.Context, userIds []string) ([]userdtos.UserReadDto, error) {
+	if err := u.userBr.ValidateGetUsersByIds(userIds); err != nil {
+		return nil, err
 	}
-	for _, user := range userSample {
-		var err error
-		if user.ToBeDeleted {
-			_, err = u.deleteUserTxn(ctx, user)
-		} else {
-			_, err = u.distributeUserChangeTxn(ctx, user)
-		}
-		if err != nil {
-			logger.Log.WithContext(ctx).Error(err)
-		}
-	}
-
-}
-
-func (u UserServiceImpl) deleteUserTxn(
-	ctx context.Context,
-	user models.User,
-) (userdtos.UserChangeEventDto, error) {
-	return dshandlers.Txn(ctx, u.crudDSHandler,
-		func(s dshandlers.Session, ctx context.Context) (userdtos.UserChangeEventDto, error) {
-			return u.deleteUser(ctx, user)
-		})
-}
-
-func (u UserServiceImpl) deleteUser(ctx context.Context, user models.User) (userdtos.UserChangeEventDto, error) {
-	event, err := u.sendUserChange(ctx, user, userdtos.UserDelete)
+	users, err := u.userRepository.FindByIds(ctx, userIds)
 	if err != nil {
-		return event, err
+		return nil, err
 	}
-
-	deletedUser, err := u.userRepository.Delete(ctx, user)
-	if err != nil {
-		return event, err
+	usersById := make(map[string]models.User, len(users))
+	for _, user := range users {
+		usersById[user.GetIdStr()] = user
 	}
-
-	if _, err := u.authServerMgmtService.DeleteUser(deletedUser.AuthId); err != nil {
-		return event, err
+	userDtos := make([]userdtos.UserReadDto, 0, len(userIds))
+	for _, userId := range userIds {
+		userDto := userToUserReadDto(usersById[userId])
+		userDto.Id = userId
+		userDtos = append(userDtos, userDto)
 	}
-
-	logger.Log.WithContext(ctx).Debug("Deleted user ", deletedUser)
-	return event, err
+	return userDtos, nil
 }
 
-func (u UserServiceImpl) distributeUserChangeTxn(
+func (u UserServiceImpl) GetUsersAfterId(
 	ctx context.Context,
-	user models.User,
-) (userdtos.UserChangeEventDto, error) {
-	return dshandlers.Txn(ctx, u.crudDSHandler,
-		func(session dshandlers.Session, ctx context.Context) (userdtos.UserChangeEventDto, error) {
-			return u.distributeUserChange(ctx, user)
-		},
-	)
-}
-
-func (u UserServiceImpl) distributeUserChange(
-	ctx context.Context,
-	user models.User,
-) (userdtos.UserChangeEventDto, error) {
-	event, err := u.sendUserChange(ctx, user, userdtos.UserSave)
+	afterId string,
+	limit int64,
+) ([]userdtos.UserReadDto, error) {
+	if limit <= 0 || limit > businessrules.MaxUsersPerRequest {
+		limit = businessrules.MaxUsersPerRequest
+	}
+	users, err := u.userRepository.GetAfterId(ctx, afterId, limit)
 	if err != nil {
-		return event, err
+		return nil, err
 	}
-
-	user.ToBeDeleted = false
-	user.Distributed = true
-	updatedUser, err := u.userRepository.Update(ctx, user)
-	if err == nil {
-		logger.Log.WithContext(ctx).Debugf("Sent user save event for user %v", updatedUser)
+	userDtos := make([]userdtos.UserReadDto, 0, len(users))
+	for _, user := range users {
+		userDtos = append(userDtos, userToUserReadDto(user))
 	}
-	return event, err
-}
-
-func (u UserServiceImpl) sendUserChange(
-	ctx context.Context,
-	user models.User,
-	action userdtos.UserChangeAction,
-) (userdtos.UserChangeEventDto, error) {
-	eventDto := userdtos.UserChangeEventDto{}
-	mappers.UserToUserChangeEventDto(user, &eventDto)
-	eventDto.Action = action
-	err := u.userMsgSendService.SendUserSave(ctx, eventDto)
-	return eventDto, err
+	return userDtos, nil
 }
 
 func userToUserReadDto(user models.User) userdtos.UserReadDto {
@@ -290,19 +477,21 @@ func userToUserReadDto(user models.User) userdtos.UserReadDto {
 }
 
 func NewUserServiceImpl(
-	userMsgSendService UserMsgSendService,
+	userChangeOutboxService UserChangeOutboxService,
+	userDeletionService UserDeletionService,
 	crudDBHandler dshandlers.CrudDSHandler,
 	userRepository repositories.UserRepository,
+	userAvatarRepository repositories.UserAvatarRepository,
 	userBr businessrules.UserBr,
 	errorService sharedservices.ErrorService,
-	authServerMgmtService AuthServerMgmtService,
 ) *UserServiceImpl {
 	return &UserServiceImpl{
-		userMsgSendService:    userMsgSendService,
-		crudDSHandler:         crudDBHandler,
-		userRepository:        userRepository,
-		userBr:                userBr,
-		errorService:          errorService,
-		authServerMgmtService: authServerMgmtService,
+		userChangeOutboxService: userChangeOutboxService,
+		userDeletionService:     userDeletionService,
+		crudDSHandler:           crudDBHandler,
+		userRepository:          userRepository,
+		userAvatarRepository:    userAvatarRepository,
+		userBr:                  userBr,
+		errorService:            errorService,
 	}
 }
@@ -14,3 +14,29 @@ const ErrCodeInvalidSession = "InvalidSession"
 const ErrCodeDataRace = "DataRace"
 const ErrCodeMustSortByOneOption = "MustSortByOneOption"
 const ErrCodeInvalidSortOptions = "InvalidSortOptions"
+const ErrCodeKeyRotationInProgress = "KeyRotationInProgress"
+const ErrCodeInvalidRewrapGrant = "InvalidRewrapGrant"
+const ErrCodeSrpNotEnabled = "SrpNotEnabled"
+const ErrCodeInvalidSrpChallenge = "InvalidSrpChallenge"
+const ErrCodeInvalidUserKey = "InvalidUserKey"
+const ErrCodeTotpRequired = "TotpRequired"
+const ErrCodeIncorrectTotpCode = "IncorrectTotpCode"
+const ErrCodeTooManyUnlockAttempts = "TooManyUnlockAttempts"
+const ErrCodeTotpAlreadyEnabled = "TotpAlreadyEnabled"
+const ErrCodeTotpNotEnrolled = "TotpNotEnrolled"
+const ErrCodeInvalidVaultId = "InvalidVaultId"
+const ErrCodeSessionNotElevated = "SessionNotElevated"
+const ErrCodeDuressPasscodeMatchesPasscode = "DuressPasscodeMatchesPasscode"
+const ErrCodeUserDeletionInProgress = "UserDeletionInProgress"
+const ErrCodeDataExportInProgress = "DataExportInProgress"
+const ErrCodeDataExportNotReady = "DataExportNotReady"
+const ErrCodeInvalidTimezone = "InvalidTimezone"
+const ErrCodeInvalidLocale = "InvalidLocale"
+const ErrCodeAvatarTooLarge = "AvatarTooLarge"
+const ErrCodeUnsupportedAvatarType = "UnsupportedAvatarType"
+const ErrCodeUserSuspended = "UserSuspended"
+const ErrCodeTooManyItemsRequested = "TooManyItemsRequested"
+const ErrCodeInvalidCursor = "InvalidCursor"
+const ErrCodeUnknownDeadLetterTopic = "UnknownDeadLetterTopic"
+const ErrCodeInvalidRetryStage = "InvalidRetryStage"
+const ErrCodeNoDeadLettersSelected = "NoDeadLettersSelected"
@@ -1,7 +1,9 @@
 package apperrors
 
 type ValidationError struct {
-	Field   string `json:"field"`
+	Field string `json:"field"`
+	// Rule identifies the rule the field failed if the message alone does not
+	Rule    string `json:"rule,omitempty"`
 	Message string `json:"message"`
 }
 
@@ -73,6 +73,11 @@ func NewCoreAppServerWithHooksImpl(
 		gin.SetMode(gin.ReleaseMode)
 	}
 	r := gin.New()
+	// Gin trusts forwarded headers from every proxy by default, which would let
+	// clients choose the IP address they are seen as
+	if err := r.SetTrustedProxies(serverConf.GetTrustedProxies()); err != nil {
+		logger.Log.WithError(err).Fatal("Invalid trusted proxies")
+	}
 	middlewares.AddGlobalMiddleWares(r)
 	beforeControllers(r)
 	for _, c := range controllers {
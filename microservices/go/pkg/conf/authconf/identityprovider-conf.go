@@ -0,0 +1,132 @@
+package authconf
+
+import (
+	"fmt"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"net/url"
+	"strings"
+)
+
+const IdentityProviderAuth0 = "auth0"
+const IdentityProviderOidc = "oidc"
+const IdentityProviderLocal = "local"
+
+type IdentityProviderConf interface {
+	// GetIdentityProvider returns which identity provider issues the tokens of
+	// users and system clients, either IdentityProviderAuth0,
+	// IdentityProviderOidc or IdentityProviderLocal
+	GetIdentityProvider() string
+	// GetIssuerUrl returns the URL of the issuer of the tokens
+	GetIssuerUrl() *url.URL
+	// GetOidcAuthorityClaimPaths returns the dot separated paths to the claims
+	// holding the authorities of an identity, such as realm_access.roles for
+	// Keycloak realm roles
+	GetOidcAuthorityClaimPaths() []string
+	// GetOidcSystemClientClaimPath returns the dot separated path to the claim
+	// that marks a token as issued to a system client
+	GetOidcSystemClientClaimPath() string
+	// GetOidcSystemClientClaimValue returns the value the system client claim
+	// must hold. If blank, having the claim is enough.
+	GetOidcSystemClientClaimValue() string
+	GetOidcTokenUrl() string
+	GetOidcClientId() string
+	GetOidcClientSecret() string
+	// GetOidcUserDeleteUrl returns the URL users are deleted with, where
+	// {authId} is replaced by the auth ID of the user. If blank, users are not
+	// deleted from the identity provider.
+	GetOidcUserDeleteUrl() string
+}
+
+type IdentityProviderConfImpl struct {
+	identityProvider           string
+	issuerUrl                  *url.URL
+	oidcAuthorityClaimPaths    []string
+	oidcSystemClientClaimPath  string
+	oidcSystemClientClaimValue string
+	oidcTokenUrl               string
+	oidcClientId               string
+	oidcClientSecret           string
+	oidcUserDeleteUrl          string
+}
+
+func (i IdentityProviderConfImpl) GetIdentityProvider() string {
+	return i.identityProvider
+}
+
+func (i IdentityProviderConfImpl) GetIssuerUrl() *url.URL {
+	return i.issuerUrl
+}
+
+func (i IdentityProviderConfImpl) GetOidcAuthorityClaimPaths() []string {
+	return i.oidcAuthorityClaimPaths
+}
+
+func (i IdentityProviderConfImpl) GetOidcSystemClientClaimPath() string {
+	return i.oidcSystemClientClaimPath
+}
+
+func (i IdentityProviderConfImpl) GetOidcSystemClientClaimValue() string {
+	return i.oidcSystemClientClaimValue
+}
+
+func (i IdentityProviderConfImpl) GetOidcTokenUrl() string {
+	return i.oidcTokenUrl
+}
+
+func (i IdentityProviderConfImpl) GetOidcClientId() string {
+	return i.oidcClientId
+}
+
+func (i IdentityProviderConfImpl) GetOidcClientSecret() string {
+	return i.oidcClientSecret
+}
+
+func (i IdentityProviderConfImpl) GetOidcUserDeleteUrl() string {
+	return i.oidcUserDeleteUrl
+}
+
+func NewIdentityProviderConfImpl() *IdentityProviderConfImpl {
+	identityProvider := environment.GetEnvVarOrDefault(environment.EnvVarKeyIdentityProvider, IdentityProviderAuth0)
+	if identityProvider != IdentityProviderAuth0 &&
+		identityProvider != IdentityProviderOidc &&
+		identityProvider != IdentityProviderLocal {
+		logger.Log.Fatalf("Invalid identity provider %v", identityProvider)
+	}
+	if identityProvider == IdentityProviderLocal && environment.IsProduction() {
+		logger.Log.Fatal("The local identity provider cannot be used in production")
+	}
+
+	issuerUrlStr := environment.GetEnvVar(environment.EnvVarKeyOidcIssuerUrl)
+	if identityProvider == IdentityProviderAuth0 {
+		issuerUrlStr = fmt.Sprintf("https://%v/", environment.GetEnvVar(environment.EnvVarKeyAuth0Domain))
+	}
+	issuerUrl, err := url.Parse(issuerUrlStr)
+	if err != nil {
+		logger.Log.Fatalf("Failed to parse issuer url %v", issuerUrlStr)
+	}
+
+	var authorityClaimPaths []string
+	authorityClaims := environment.GetEnvVarOrDefault(environment.EnvVarKeyOidcAuthorityClaims, "scope")
+	for _, claimPath := range strings.Split(authorityClaims, ",") {
+		if utils.StringIsNotBlank(claimPath) {
+			authorityClaimPaths = append(authorityClaimPaths, strings.TrimSpace(claimPath))
+		}
+	}
+
+	return &IdentityProviderConfImpl{
+		identityProvider:        identityProvider,
+		issuerUrl:               issuerUrl,
+		oidcAuthorityClaimPaths: authorityClaimPaths,
+		oidcSystemClientClaimPath: environment.GetEnvVarOrDefault(
+			environment.EnvVarKeyOidcSystemClientClaim,
+			"client_id",
+		),
+		oidcSystemClientClaimValue: environment.GetEnvVar(environment.EnvVarKeyOidcSystemClientClaimValue),
+		oidcTokenUrl:               environment.GetEnvVar(environment.EnvVarKeyOidcTokenUrl),
+		oidcClientId:               environment.GetEnvVar(environment.EnvVarKeyOidcClientId),
+		oidcClientSecret:           environment.GetEnvVar(environment.EnvVarKeyOidcClientSecret),
+		oidcUserDeleteUrl:          environment.GetEnvVar(environment.EnvVarKeyOidcUserDeleteUrl),
+	}
+}
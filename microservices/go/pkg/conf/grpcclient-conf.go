@@ -1,15 +1,30 @@
 package conf
 
-import "github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+)
+
+// User key client modes
+const (
+	// UserKeyClientModeLocal retrieves the user key from the key service and
+	// encrypts and decrypts payloads locally
+	UserKeyClientModeLocal = "local"
+	// UserKeyClientModeRemote has the key service encrypt and decrypt payloads
+	// so the user key never leaves the key service
+	UserKeyClientModeRemote = "remote"
+)
 
 type GrpcClientConf interface {
 	UserServiceAddress() string
 	KeyServiceAddress() string
+	UserKeyClientMode() string
 }
 
 type GrpcClientConfImpl struct {
-	userServiceAddr string
-	keyServiceAddr  string
+	userServiceAddr   string
+	keyServiceAddr    string
+	userKeyClientMode string
 }
 
 func (g GrpcClientConfImpl) UserServiceAddress() string {
@@ -20,9 +35,21 @@ func (g GrpcClientConfImpl) KeyServiceAddress() string {
 	return g.keyServiceAddr
 }
 
+func (g GrpcClientConfImpl) UserKeyClientMode() string {
+	return g.userKeyClientMode
+}
+
 func NewGrpcClientConfImpl() *GrpcClientConfImpl {
+	userKeyClientMode := environment.GetEnvVarOrDefault(
+		environment.EnvVarKeyGrpcUserKeyClientMode,
+		UserKeyClientModeRemote,
+	)
+	if userKeyClientMode != UserKeyClientModeLocal && userKeyClientMode != UserKeyClientModeRemote {
+		logger.Log.Fatalf("Invalid user key client mode %v", userKeyClientMode)
+	}
 	return &GrpcClientConfImpl{
-		userServiceAddr: environment.GetEnvVar(environment.EnvVarKeyGrpcUserServiceAddress),
-		keyServiceAddr:  environment.GetEnvVar(environment.EnvVarKeyGrpcKeyServiceAddress),
+		userServiceAddr:   environment.GetEnvVar(environment.EnvVarKeyGrpcUserServiceAddress),
+		keyServiceAddr:    environment.GetEnvVar(environment.EnvVarKeyGrpcKeyServiceAddress),
+		userKeyClientMode: userKeyClientMode,
 	}
 }
@@ -0,0 +1,24 @@
+package conf
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"time"
+)
+
+type SchedulerConf interface {
+	// GetLeaseTtl returns how long a replica holds the lease of a cluster
+	// singleton job without renewing it, which is how long a job is left without
+	// a replica running it when the replica holding its lease dies
+	GetLeaseTtl() time.Duration
+}
+
+type SchedulerConfImpl struct {
+	leaseTtl time.Duration
+}
+
+func (s SchedulerConfImpl) GetLeaseTtl() time.Duration { return s.leaseTtl }
+
+func NewSchedulerConfImpl() *SchedulerConfImpl {
+	leaseTtlMs := environment.GetEnvVarAsIntOrDefault(environment.EnvVarKeySchedulerLeaseTtlMs, 15000)
+	return &SchedulerConfImpl{leaseTtl: time.Duration(leaseTtlMs) * time.Millisecond}
+}
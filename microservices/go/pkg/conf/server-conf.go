@@ -1,26 +1,40 @@
 package conf
 
 import (
+	"github.com/akrennmair/slice"
 	environment2 "github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"strings"
 )
 
 type ServerConf interface {
 	GetAppServerPort() string
 	GetGrpcServerPort() string
+	// GetTrustedProxies are the IPs and CIDRs of proxies whose forwarded client
+	// IP headers are trusted. Client IPs are read from the connection if empty.
+	GetTrustedProxies() []string
 }
 
 type ServerConfImpl struct {
 	appServerPort  string
 	grpcServerPort string
+	trustedProxies []string
 }
 
 func (s ServerConfImpl) GetGrpcServerPort() string { return s.grpcServerPort }
 
 func (s ServerConfImpl) GetAppServerPort() string { return s.appServerPort }
 
+func (s ServerConfImpl) GetTrustedProxies() []string { return s.trustedProxies }
+
 func NewServerConfImpl() *ServerConfImpl {
+	trustedProxies := slice.Filter(
+		slice.Map(environment2.GetEnvVariableAsListSplitByComma(environment2.EnvVarKeyTrustedProxies), strings.TrimSpace),
+		utils.StringIsNotBlank,
+	)
 	return &ServerConfImpl{
 		appServerPort:  environment2.GetEnvVarOrDefault(environment2.EnvVarKeyAppServerPort, "8080"),
 		grpcServerPort: environment2.GetEnvVarOrDefault(environment2.EnvVarKeyGrpcServerPort, "50051"),
+		trustedProxies: trustedProxies,
 	}
 }
@@ -71,6 +71,17 @@ func (d MongoDBHandler) ExecTxn(ctx context.Context, transactionFunc func(Sessio
 	)
 }
 
+// Close disconnects from MongoDB
+func (d MongoDBHandler) Close() error {
+	_, client, _, err := mgm.DefaultConfigs()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := d.GetChildDBCtxWithCancel(context.Background())
+	defer cancel()
+	return client.Disconnect(ctx)
+}
+
 func NewMongoDBHandler(mongoConf conf.MongoConf) *MongoDBHandler {
 	err := mgm.SetDefaultConfig(
 		&mgm.Config{CtxTimeout: mongoConf.GetConnectionTimeout()},
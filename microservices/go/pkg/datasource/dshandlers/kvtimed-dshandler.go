@@ -1,8 +1,10 @@
 package dshandlers
 
 import (
+	"context"
 	"github.com/go-redis/redis/v9"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"time"
 )
 
 type KeyValueTimedDSHandler interface {
@@ -21,6 +23,67 @@ func (r RedisDBHandler) IsNotFoundError(err error) bool {
 	return err == redis.Nil
 }
 
+// ScanAndDel deletes every key matching the pattern, scanning the keyspace in
+// batches so the store is not blocked
+func (r RedisDBHandler) ScanAndDel(ctx context.Context, pattern string) error {
+	return r.ScanAndDelExcept(ctx, pattern)
+}
+
+// ScanAndDelExcept deletes every key matching the pattern other than the kept
+// keys
+func (r RedisDBHandler) ScanAndDelExcept(ctx context.Context, pattern string, keptKeys ...string) error {
+	const batchSize = 100
+	kept := make(map[string]bool, len(keptKeys))
+	for _, key := range keptKeys {
+		kept[key] = true
+	}
+	iter := r.redisClient.Scan(ctx, 0, pattern, batchSize).Iterator()
+	keys := make([]string, 0, batchSize)
+	for iter.Next(ctx) {
+		if kept[iter.Val()] {
+			continue
+		}
+		keys = append(keys, iter.Val())
+		if len(keys) >= batchSize {
+			if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return r.redisClient.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+// ScanTTLs returns the time to live of every key matching the pattern,
+// scanning the keyspace in batches so the store is not blocked. Keys without
+// an expiration have a negative time to live.
+func (r RedisDBHandler) ScanTTLs(ctx context.Context, pattern string) (map[string]time.Duration, error) {
+	const batchSize = 100
+	ttls := map[string]time.Duration{}
+	iter := r.redisClient.Scan(ctx, 0, pattern, batchSize).Iterator()
+	for iter.Next(ctx) {
+		ttl, err := r.redisClient.TTL(ctx, iter.Val()).Result()
+		if err != nil {
+			return nil, err
+		}
+		// Keys that expired since they were scanned are left out
+		if ttl == -2 {
+			continue
+		}
+		ttls[iter.Val()] = ttl
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return ttls, nil
+}
+
 func NewRedisDBHandler(redisConf conf.RedisConf) *RedisDBHandler {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     redisConf.GetAddress(),
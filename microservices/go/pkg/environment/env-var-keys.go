@@ -24,6 +24,7 @@ const EnvVarLoadCACert = "LOAD_CA_CERT"
 
 const EnvVarKeyGrpcUserServiceAddress = "GRPC_USER_SERVICE_ADDRESS"
 const EnvVarKeyGrpcKeyServiceAddress = "GRPC_KEY_SERVICE_ADDRESS"
+const EnvVarKeyGrpcUserKeyClientMode = "GRPC_USER_KEY_CLIENT_MODE"
 
 // App Server Addresses (standard http, i.e. not GRPC)
 
@@ -35,6 +36,7 @@ const EnvVarKeyAppserverNoteServiceAddress = "APPSERVER_NOTE_SERVICE_ADDRESS"
 
 const EnvVarKeyAppServerPort = "APP_SERVER_PORT"
 const EnvVarKeyGrpcServerPort = "GRPC_SERVER_PORT"
+const EnvVarKeyTrustedProxies = "TRUSTED_PROXIES"
 
 // Auth0
 
@@ -47,6 +49,18 @@ const EnvVarKeyAuth0WebappClientId = "AUTH0_WEBAPP_CLIENT_ID"
 const EnvVarKeyAuth0WebappClientSecret = "AUTH0_WEBAPP_CLIENT_SECRET"
 const EnvVarKeyAuth0WebappCallbackUrl = "AUTH0_WEBAPP_CALLBACK_URL"
 
+// Identity provider
+
+const EnvVarKeyIdentityProvider = "IDENTITY_PROVIDER"
+const EnvVarKeyOidcIssuerUrl = "OIDC_ISSUER_URL"
+const EnvVarKeyOidcAuthorityClaims = "OIDC_AUTHORITY_CLAIMS"
+const EnvVarKeyOidcSystemClientClaim = "OIDC_SYSTEM_CLIENT_CLAIM"
+const EnvVarKeyOidcSystemClientClaimValue = "OIDC_SYSTEM_CLIENT_CLAIM_VALUE"
+const EnvVarKeyOidcTokenUrl = "OIDC_TOKEN_URL"
+const EnvVarKeyOidcClientId = "OIDC_CLIENT_ID"
+const EnvVarKeyOidcClientSecret = "OIDC_CLIENT_SECRET"
+const EnvVarKeyOidcUserDeleteUrl = "OIDC_USER_DELETE_URL"
+
 // MongoDB
 
 const EnvVarKeyMongoUri = "MONGO_URI"
@@ -65,6 +79,10 @@ const EnvVarKeyRedisAddr = "REDIS_ADDRESS"
 const EnvVarKeyRedisPassword = "REDIS_PASSWORD"
 const EnvVarKeyRedisDB = "REDIS_DB"
 
+// Scheduler
+
+const EnvVarKeySchedulerLeaseTtlMs = "SCHEDULER_LEASE_TTL_MS"
+
 // Static files
 
 const EnvVarStaticFilesPath = "STATIC_FILES_PATH"
@@ -74,3 +92,41 @@ const EnvVarStaticFilesPath = "STATIC_FILES_PATH"
 const EnvVarSessionStoreSecret = "SESSION_STORE_SECRET"
 const EnvVarCsrfSecret = "CSRF_SECRET"
 const EnvVarAccessTokenSecret = "ACCESS_TOKEN_SECRET"
+
+// Key derivation
+
+const EnvVarKeyKdfAlgorithm = "KDF_ALGORITHM"
+const EnvVarKeyKdfArgon2idTime = "KDF_ARGON2ID_TIME"
+const EnvVarKeyKdfArgon2idMemoryKiB = "KDF_ARGON2ID_MEMORY_KIB"
+const EnvVarKeyKdfArgon2idThreads = "KDF_ARGON2ID_THREADS"
+const EnvVarKeyKdfScryptN = "KDF_SCRYPT_N"
+const EnvVarKeyKdfScryptR = "KDF_SCRYPT_R"
+const EnvVarKeyKdfScryptP = "KDF_SCRYPT_P"
+
+// Master key
+
+const EnvVarKeyProvider = "KEY_PROVIDER"
+const EnvVarMasterKey = "MASTER_KEY"
+const EnvVarMasterKeyPath = "MASTER_KEY_PATH"
+const EnvVarMasterKeyAllowEphemeral = "MASTER_KEY_ALLOW_EPHEMERAL"
+const EnvVarVaultAddress = "VAULT_ADDRESS"
+const EnvVarVaultToken = "VAULT_TOKEN"
+const EnvVarVaultTransitKeyName = "VAULT_TRANSIT_KEY_NAME"
+
+// Key session binding
+
+const EnvVarKeySessionBindDevice = "KEY_SESSION_BIND_DEVICE"
+const EnvVarKeySessionBindIpNetwork = "KEY_SESSION_BIND_IP_NETWORK"
+const EnvVarKeySessionIpv4PrefixLength = "KEY_SESSION_IPV4_PREFIX_LENGTH"
+const EnvVarKeySessionIpv6PrefixLength = "KEY_SESSION_IPV6_PREFIX_LENGTH"
+
+// Passcode policy
+
+const EnvVarPasscodeMinLength = "PASSCODE_MIN_LENGTH"
+const EnvVarPasscodeMaxLength = "PASSCODE_MAX_LENGTH"
+const EnvVarPasscodeRequireLowercase = "PASSCODE_REQUIRE_LOWERCASE"
+const EnvVarPasscodeRequireUppercase = "PASSCODE_REQUIRE_UPPERCASE"
+const EnvVarPasscodeRequireDigit = "PASSCODE_REQUIRE_DIGIT"
+const EnvVarPasscodeRequireSymbol = "PASSCODE_REQUIRE_SYMBOL"
+const EnvVarPasscodeMinStrengthScore = "PASSCODE_MIN_STRENGTH_SCORE"
+const EnvVarPasscodeBreachedListPath = "PASSCODE_BREACHED_LIST_PATH"
@@ -23,3 +23,8 @@ func LoadTLSCredentialsOption(certPath string, overrideServerName bool) (grpc.Di
 func OathAccessOption(token oauth2.Token) grpc.DialOption {
 	return grpc.WithPerRPCCredentials(oauth.NewOauthAccess(&token))
 }
+
+// OathTokenSourceOption sends a token from the token source with each call
+func OathTokenSourceOption(tokenSource oauth2.TokenSource) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: tokenSource})
+}
@@ -25,12 +25,14 @@ type UserKeySession struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ProxyKid      string `protobuf:"bytes,1,opt,name=proxyKid,proto3" json:"proxyKid,omitempty"`
-	Token         string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
-	UserId        string `protobuf:"bytes,3,opt,name=userId,proto3" json:"userId,omitempty"`
-	KeyVersion    int64  `protobuf:"varint,4,opt,name=keyVersion,proto3" json:"keyVersion,omitempty"`
-	StartTime     int64  `protobuf:"varint,5,opt,name=startTime,proto3" json:"startTime,omitempty"`
-	DurationMilli int64  `protobuf:"varint,6,opt,name=durationMilli,proto3" json:"durationMilli,omitempty"`
+	ProxyKid      string         `protobuf:"bytes,1,opt,name=proxyKid,proto3" json:"proxyKid,omitempty"`
+	Token         string         `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	UserId        string         `protobuf:"bytes,3,opt,name=userId,proto3" json:"userId,omitempty"`
+	KeyVersion    int64          `protobuf:"varint,4,opt,name=keyVersion,proto3" json:"keyVersion,omitempty"`
+	StartTime     int64          `protobuf:"varint,5,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	DurationMilli int64          `protobuf:"varint,6,opt,name=durationMilli,proto3" json:"durationMilli,omitempty"`
+	Binding       *ClientBinding `protobuf:"bytes,7,opt,name=binding,proto3" json:"binding,omitempty"`
+	VaultId       string         `protobuf:"bytes,8,opt,name=vaultId,proto3" json:"vaultId,omitempty"`
 }
 
 func (x *UserKeySession) Reset() {
@@ -107,7 +109,76 @@ func (x *UserKeySession) GetDurationMilli() int64 {
 	return 0
 }
 
-type UserKey struct {
+func (x *UserKeySession) GetBinding() *ClientBinding {
+	if x != nil {
+		return x.Binding
+	}
+	return nil
+}
+
+func (x *UserKeySession) GetVaultId() string {
+	if x != nil {
+		return x.VaultId
+	}
+	return ""
+}
+
+type ClientBinding struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceId string `protobuf:"bytes,1,opt,name=deviceId,proto3" json:"deviceId,omitempty"`
+	ClientIp string `protobuf:"bytes,2,opt,name=clientIp,proto3" json:"clientIp,omitempty"`
+}
+
+func (x *ClientBinding) Reset() {
+	*x = ClientBinding{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientBinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientBinding) ProtoMessage() {}
+
+func (x *ClientBinding) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientBinding.ProtoReflect.Descriptor instead.
+func (*ClientBinding) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ClientBinding) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ClientBinding) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+type PreviousUserKey struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
@@ -116,10 +187,68 @@ type UserKey struct {
 	KeyVersion int64  `protobuf:"varint,2,opt,name=keyVersion,proto3" json:"keyVersion,omitempty"`
 }
 
+func (x *PreviousUserKey) Reset() {
+	*x = PreviousUserKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PreviousUserKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreviousUserKey) ProtoMessage() {}
+
+func (x *PreviousUserKey) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreviousUserKey.ProtoReflect.Descriptor instead.
+func (*PreviousUserKey) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PreviousUserKey) GetKeyBase64() string {
+	if x != nil {
+		return x.KeyBase64
+	}
+	return ""
+}
+
+func (x *PreviousUserKey) GetKeyVersion() int64 {
+	if x != nil {
+		return x.KeyVersion
+	}
+	return 0
+}
+
+type UserKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyBase64    string             `protobuf:"bytes,1,opt,name=keyBase64,proto3" json:"keyBase64,omitempty"`
+	KeyVersion   int64              `protobuf:"varint,2,opt,name=keyVersion,proto3" json:"keyVersion,omitempty"`
+	PreviousKeys []*PreviousUserKey `protobuf:"bytes,3,rep,name=previousKeys,proto3" json:"previousKeys,omitempty"`
+	// True if the session's user recently re-entered their passcode
+	Elevated bool `protobuf:"varint,4,opt,name=elevated,proto3" json:"elevated,omitempty"`
+}
+
 func (x *UserKey) Reset() {
 	*x = UserKey{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_userkeypb_userkey_proto_msgTypes[1]
+		mi := &file_userkeypb_userkey_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -132,7 +261,7 @@ func (x *UserKey) String() string {
 func (*UserKey) ProtoMessage() {}
 
 func (x *UserKey) ProtoReflect() protoreflect.Message {
-	mi := &file_userkeypb_userkey_proto_msgTypes[1]
+	mi := &file_userkeypb_userkey_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -145,7 +274,7 @@ func (x *UserKey) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserKey.ProtoReflect.Descriptor instead.
 func (*UserKey) Descriptor() ([]byte, []int) {
-	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{1}
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *UserKey) GetKeyBase64() string {
@@ -162,11 +291,423 @@ func (x *UserKey) GetKeyVersion() int64 {
 	return 0
 }
 
+func (x *UserKey) GetPreviousKeys() []*PreviousUserKey {
+	if x != nil {
+		return x.PreviousKeys
+	}
+	return nil
+}
+
+func (x *UserKey) GetElevated() bool {
+	if x != nil {
+		return x.Elevated
+	}
+	return false
+}
+
+// The key versions a session can encrypt and decrypt with, without the keys
+type KeySessionInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyVersion          int64   `protobuf:"varint,1,opt,name=keyVersion,proto3" json:"keyVersion,omitempty"`
+	PreviousKeyVersions []int64 `protobuf:"varint,2,rep,packed,name=previousKeyVersions,proto3" json:"previousKeyVersions,omitempty"`
+	Elevated            bool    `protobuf:"varint,3,opt,name=elevated,proto3" json:"elevated,omitempty"`
+}
+
+func (x *KeySessionInfo) Reset() {
+	*x = KeySessionInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeySessionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeySessionInfo) ProtoMessage() {}
+
+func (x *KeySessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeySessionInfo.ProtoReflect.Descriptor instead.
+func (*KeySessionInfo) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *KeySessionInfo) GetKeyVersion() int64 {
+	if x != nil {
+		return x.KeyVersion
+	}
+	return 0
+}
+
+func (x *KeySessionInfo) GetPreviousKeyVersions() []int64 {
+	if x != nil {
+		return x.PreviousKeyVersions
+	}
+	return nil
+}
+
+func (x *KeySessionInfo) GetElevated() bool {
+	if x != nil {
+		return x.Elevated
+	}
+	return false
+}
+
+// A plaintext or ciphertext payload. The key version is the version of the
+// user key the ciphertext was encrypted with.
+type CipherPayload struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data       []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	KeyVersion int64  `protobuf:"varint,2,opt,name=keyVersion,proto3" json:"keyVersion,omitempty"`
+}
+
+func (x *CipherPayload) Reset() {
+	*x = CipherPayload{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CipherPayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CipherPayload) ProtoMessage() {}
+
+func (x *CipherPayload) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CipherPayload.ProtoReflect.Descriptor instead.
+func (*CipherPayload) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CipherPayload) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CipherPayload) GetKeyVersion() int64 {
+	if x != nil {
+		return x.KeyVersion
+	}
+	return 0
+}
+
+type SessionCipherPayload struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session *UserKeySession `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	Payload *CipherPayload  `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (x *SessionCipherPayload) Reset() {
+	*x = SessionCipherPayload{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionCipherPayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionCipherPayload) ProtoMessage() {}
+
+func (x *SessionCipherPayload) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionCipherPayload.ProtoReflect.Descriptor instead.
+func (*SessionCipherPayload) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SessionCipherPayload) GetSession() *UserKeySession {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *SessionCipherPayload) GetPayload() *CipherPayload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type SessionCipherPayloadBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session  *UserKeySession  `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	Payloads []*CipherPayload `protobuf:"bytes,2,rep,name=payloads,proto3" json:"payloads,omitempty"`
+}
+
+func (x *SessionCipherPayloadBatch) Reset() {
+	*x = SessionCipherPayloadBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionCipherPayloadBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionCipherPayloadBatch) ProtoMessage() {}
+
+func (x *SessionCipherPayloadBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionCipherPayloadBatch.ProtoReflect.Descriptor instead.
+func (*SessionCipherPayloadBatch) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SessionCipherPayloadBatch) GetSession() *UserKeySession {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *SessionCipherPayloadBatch) GetPayloads() []*CipherPayload {
+	if x != nil {
+		return x.Payloads
+	}
+	return nil
+}
+
+type CipherPayloadBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Payloads []*CipherPayload `protobuf:"bytes,1,rep,name=payloads,proto3" json:"payloads,omitempty"`
+}
+
+func (x *CipherPayloadBatch) Reset() {
+	*x = CipherPayloadBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CipherPayloadBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CipherPayloadBatch) ProtoMessage() {}
+
+func (x *CipherPayloadBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CipherPayloadBatch.ProtoReflect.Descriptor instead.
+func (*CipherPayloadBatch) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CipherPayloadBatch) GetPayloads() []*CipherPayload {
+	if x != nil {
+		return x.Payloads
+	}
+	return nil
+}
+
+// A grant letting a consumer re-encrypt a user's data with a rotated user key
+type RewrapGrant struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GrantId string `protobuf:"bytes,1,opt,name=grantId,proto3" json:"grantId,omitempty"`
+	Token   string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// consumer is the service the grant was issued to
+	Consumer string `protobuf:"bytes,3,opt,name=consumer,proto3" json:"consumer,omitempty"`
+}
+
+func (x *RewrapGrant) Reset() {
+	*x = RewrapGrant{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RewrapGrant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewrapGrant) ProtoMessage() {}
+
+func (x *RewrapGrant) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewrapGrant.ProtoReflect.Descriptor instead.
+func (*RewrapGrant) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RewrapGrant) GetGrantId() string {
+	if x != nil {
+		return x.GrantId
+	}
+	return ""
+}
+
+func (x *RewrapGrant) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RewrapGrant) GetConsumer() string {
+	if x != nil {
+		return x.Consumer
+	}
+	return ""
+}
+
+type RewrapGrantPayloadBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Grant    *RewrapGrant     `protobuf:"bytes,1,opt,name=grant,proto3" json:"grant,omitempty"`
+	Payloads []*CipherPayload `protobuf:"bytes,2,rep,name=payloads,proto3" json:"payloads,omitempty"`
+}
+
+func (x *RewrapGrantPayloadBatch) Reset() {
+	*x = RewrapGrantPayloadBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_userkeypb_userkey_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RewrapGrantPayloadBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewrapGrantPayloadBatch) ProtoMessage() {}
+
+func (x *RewrapGrantPayloadBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_userkeypb_userkey_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewrapGrantPayloadBatch.ProtoReflect.Descriptor instead.
+func (*RewrapGrantPayloadBatch) Descriptor() ([]byte, []int) {
+	return file_userkeypb_userkey_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RewrapGrantPayloadBatch) GetGrant() *RewrapGrant {
+	if x != nil {
+		return x.Grant
+	}
+	return nil
+}
+
+func (x *RewrapGrantPayloadBatch) GetPayloads() []*CipherPayload {
+	if x != nil {
+		return x.Payloads
+	}
+	return nil
+}
+
 var File_userkeypb_userkey_proto protoreflect.FileDescriptor
 
 var file_userkeypb_userkey_proto_rawDesc = []byte{
 	0x0a, 0x17, 0x75, 0x73, 0x65, 0x72, 0x6b, 0x65, 0x79, 0x70, 0x62, 0x2f, 0x75, 0x73, 0x65, 0x72,
-	0x6b, 0x65, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xbe, 0x01, 0x0a, 0x0e, 0x55, 0x73,
+	0x6b, 0x65, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x82, 0x02, 0x0a, 0x0e, 0x55, 0x73,
 	0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
 	0x70, 0x72, 0x6f, 0x78, 0x79, 0x4b, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
 	0x70, 0x72, 0x6f, 0x78, 0x79, 0x4b, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65,
@@ -178,21 +719,117 @@ var file_userkeypb_userkey_proto_rawDesc = []byte{
 	0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74,
 	0x54, 0x69, 0x6d, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
 	0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x64, 0x75, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x22, 0x47, 0x0a, 0x07, 0x55, 0x73,
-	0x65, 0x72, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x6b, 0x65, 0x79, 0x42, 0x61, 0x73, 0x65,
-	0x36, 0x34, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x42, 0x61, 0x73,
-	0x65, 0x36, 0x34, 0x12, 0x1e, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
-	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6b, 0x65, 0x79, 0x56, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x32, 0x42, 0x0a, 0x0e, 0x55, 0x73, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x65,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x12, 0x28, 0x0a, 0x07, 0x62, 0x69,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x07, 0x62, 0x69, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x49, 0x64, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x49, 0x64, 0x22, 0x47,
+	0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x42, 0x69, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12,
+	0x1a, 0x0a, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x70, 0x22, 0x4f, 0x0a, 0x0f, 0x50, 0x72, 0x65, 0x76, 0x69,
+	0x6f, 0x75, 0x73, 0x55, 0x73, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x6b, 0x65,
+	0x79, 0x42, 0x61, 0x73, 0x65, 0x36, 0x34, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b,
+	0x65, 0x79, 0x42, 0x61, 0x73, 0x65, 0x36, 0x34, 0x12, 0x1e, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6b, 0x65,
+	0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x99, 0x01, 0x0a, 0x07, 0x55, 0x73, 0x65,
+	0x72, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x6b, 0x65, 0x79, 0x42, 0x61, 0x73, 0x65, 0x36,
+	0x34, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x42, 0x61, 0x73, 0x65,
+	0x36, 0x34, 0x12, 0x1e, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6b, 0x65, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x34, 0x0a, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4b, 0x65,
+	0x79, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x50, 0x72, 0x65, 0x76, 0x69,
+	0x6f, 0x75, 0x73, 0x55, 0x73, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76,
+	0x69, 0x6f, 0x75, 0x73, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6c, 0x65, 0x76,
+	0x61, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x65, 0x6c, 0x65, 0x76,
+	0x61, 0x74, 0x65, 0x64, 0x22, 0x7e, 0x0a, 0x0e, 0x4b, 0x65, 0x79, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1e, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6b, 0x65, 0x79, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x13, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f,
+	0x75, 0x73, 0x4b, 0x65, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x03, 0x52, 0x13, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4b, 0x65, 0x79,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6c, 0x65, 0x76,
+	0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x65, 0x6c, 0x65, 0x76,
+	0x61, 0x74, 0x65, 0x64, 0x22, 0x43, 0x0a, 0x0d, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61,
+	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1e, 0x0a, 0x0a, 0x6b, 0x65, 0x79,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6b,
+	0x65, 0x79, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x6b, 0x0a, 0x14, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x12, 0x29, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x07,
+	0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x07, 0x70,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x72, 0x0a, 0x19, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x12, 0x29, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2a,
+	0x0a, 0x08, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0e, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x52, 0x08, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x22, 0x40, 0x0a, 0x12, 0x43, 0x69,
+	0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x12, 0x2a, 0x0a, 0x08, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f,
+	0x61, 0x64, 0x52, 0x08, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x22, 0x59, 0x0a, 0x0b,
+	0x52, 0x65, 0x77, 0x72, 0x61, 0x70, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x67,
+	0x72, 0x61, 0x6e, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x67, 0x72,
+	0x61, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x63,
+	0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63,
+	0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x72, 0x22, 0x69, 0x0a, 0x17, 0x52, 0x65, 0x77, 0x72, 0x61,
+	0x70, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x12, 0x22, 0x0a, 0x05, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x52, 0x65, 0x77, 0x72, 0x61, 0x70, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x52,
+	0x05, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x12, 0x2a, 0x0a, 0x08, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65,
+	0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x08, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x73, 0x32, 0xeb, 0x04, 0x0a, 0x0e, 0x55, 0x73, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x65,
 	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x30, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x46,
 	0x72, 0x6f, 0x6d, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0f, 0x2e, 0x55, 0x73, 0x65,
 	0x72, 0x4b, 0x65, 0x79, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x1a, 0x08, 0x2e, 0x55, 0x73,
-	0x65, 0x72, 0x4b, 0x65, 0x79, 0x22, 0x00, 0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x62, 0x65, 0x6e, 0x6b, 0x65, 0x6e, 0x6f, 0x62, 0x69,
-	0x2f, 0x63, 0x79, 0x70, 0x68, 0x65, 0x72, 0x2d, 0x6c, 0x6f, 0x67, 0x2f, 0x6d, 0x69, 0x63, 0x72,
-	0x6f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x2f, 0x67, 0x6f, 0x2f, 0x70, 0x6b, 0x67,
-	0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x6b, 0x65, 0x79, 0x70, 0x62, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x72, 0x4b, 0x65, 0x79, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4b, 0x65,
+	0x79, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0f, 0x2e, 0x55,
+	0x73, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x1a, 0x0f, 0x2e,
+	0x4b, 0x65, 0x79, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00,
+	0x12, 0x3d, 0x0a, 0x12, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x57, 0x69, 0x74, 0x68, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x1a, 0x0e, 0x2e,
+	0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x00, 0x12,
+	0x3d, 0x0a, 0x12, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x57, 0x69, 0x74, 0x68, 0x53, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43,
+	0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x1a, 0x0e, 0x2e, 0x43,
+	0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x00, 0x12, 0x4c,
+	0x0a, 0x17, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x57, 0x69,
+	0x74, 0x68, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x2e, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x1a, 0x13, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61,
+	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68, 0x22, 0x00, 0x12, 0x4c, 0x0a, 0x17,
+	0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x57, 0x69, 0x74, 0x68,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x1a, 0x13, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c,
+	0x6f, 0x61, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68, 0x22, 0x00, 0x12, 0x47, 0x0a, 0x18, 0x45, 0x6e,
+	0x63, 0x72, 0x79, 0x70, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x57, 0x69, 0x74, 0x68, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x1a, 0x0e, 0x2e,
+	0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x00, 0x28,
+	0x01, 0x30, 0x01, 0x12, 0x47, 0x0a, 0x18, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x57, 0x69, 0x74, 0x68, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x15, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x1a, 0x0e, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x50,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x0f,
+	0x52, 0x65, 0x77, 0x72, 0x61, 0x70, 0x57, 0x69, 0x74, 0x68, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x12,
+	0x18, 0x2e, 0x52, 0x65, 0x77, 0x72, 0x61, 0x70, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x50, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68, 0x1a, 0x13, 0x2e, 0x43, 0x69, 0x70, 0x68,
+	0x65, 0x72, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68, 0x22, 0x00,
+	0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f,
+	0x62, 0x65, 0x6e, 0x6b, 0x65, 0x6e, 0x6f, 0x62, 0x69, 0x2f, 0x63, 0x79, 0x70, 0x68, 0x65, 0x72,
+	0x2d, 0x6c, 0x6f, 0x67, 0x2f, 0x6d, 0x69, 0x63, 0x72, 0x6f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x73, 0x2f, 0x67, 0x6f, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x75,
+	0x73, 0x65, 0x72, 0x6b, 0x65, 0x79, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -207,19 +844,53 @@ func file_userkeypb_userkey_proto_rawDescGZIP() []byte {
 	return file_userkeypb_userkey_proto_rawDescData
 }
 
-var file_userkeypb_userkey_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_userkeypb_userkey_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_userkeypb_userkey_proto_goTypes = []interface{}{
-	(*UserKeySession)(nil), // 0: UserKeySession
-	(*UserKey)(nil),        // 1: UserKey
+	(*UserKeySession)(nil),            // 0: UserKeySession
+	(*ClientBinding)(nil),             // 1: ClientBinding
+	(*PreviousUserKey)(nil),           // 2: PreviousUserKey
+	(*UserKey)(nil),                   // 3: UserKey
+	(*KeySessionInfo)(nil),            // 4: KeySessionInfo
+	(*CipherPayload)(nil),             // 5: CipherPayload
+	(*SessionCipherPayload)(nil),      // 6: SessionCipherPayload
+	(*SessionCipherPayloadBatch)(nil), // 7: SessionCipherPayloadBatch
+	(*CipherPayloadBatch)(nil),        // 8: CipherPayloadBatch
+	(*RewrapGrant)(nil),               // 9: RewrapGrant
+	(*RewrapGrantPayloadBatch)(nil),   // 10: RewrapGrantPayloadBatch
 }
 var file_userkeypb_userkey_proto_depIdxs = []int32{
-	0, // 0: UserKeyService.GetKeyFromSession:input_type -> UserKeySession
-	1, // 1: UserKeyService.GetKeyFromSession:output_type -> UserKey
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: UserKeySession.binding:type_name -> ClientBinding
+	2,  // 1: UserKey.previousKeys:type_name -> PreviousUserKey
+	0,  // 2: SessionCipherPayload.session:type_name -> UserKeySession
+	5,  // 3: SessionCipherPayload.payload:type_name -> CipherPayload
+	0,  // 4: SessionCipherPayloadBatch.session:type_name -> UserKeySession
+	5,  // 5: SessionCipherPayloadBatch.payloads:type_name -> CipherPayload
+	5,  // 6: CipherPayloadBatch.payloads:type_name -> CipherPayload
+	9,  // 7: RewrapGrantPayloadBatch.grant:type_name -> RewrapGrant
+	5,  // 8: RewrapGrantPayloadBatch.payloads:type_name -> CipherPayload
+	0,  // 9: UserKeyService.GetKeyFromSession:input_type -> UserKeySession
+	0,  // 10: UserKeyService.GetKeySessionInfo:input_type -> UserKeySession
+	6,  // 11: UserKeyService.EncryptWithSession:input_type -> SessionCipherPayload
+	6,  // 12: UserKeyService.DecryptWithSession:input_type -> SessionCipherPayload
+	7,  // 13: UserKeyService.EncryptBatchWithSession:input_type -> SessionCipherPayloadBatch
+	7,  // 14: UserKeyService.DecryptBatchWithSession:input_type -> SessionCipherPayloadBatch
+	6,  // 15: UserKeyService.EncryptStreamWithSession:input_type -> SessionCipherPayload
+	6,  // 16: UserKeyService.DecryptStreamWithSession:input_type -> SessionCipherPayload
+	10, // 17: UserKeyService.RewrapWithGrant:input_type -> RewrapGrantPayloadBatch
+	3,  // 18: UserKeyService.GetKeyFromSession:output_type -> UserKey
+	4,  // 19: UserKeyService.GetKeySessionInfo:output_type -> KeySessionInfo
+	5,  // 20: UserKeyService.EncryptWithSession:output_type -> CipherPayload
+	5,  // 21: UserKeyService.DecryptWithSession:output_type -> CipherPayload
+	8,  // 22: UserKeyService.EncryptBatchWithSession:output_type -> CipherPayloadBatch
+	8,  // 23: UserKeyService.DecryptBatchWithSession:output_type -> CipherPayloadBatch
+	5,  // 24: UserKeyService.EncryptStreamWithSession:output_type -> CipherPayload
+	5,  // 25: UserKeyService.DecryptStreamWithSession:output_type -> CipherPayload
+	8,  // 26: UserKeyService.RewrapWithGrant:output_type -> CipherPayloadBatch
+	18, // [18:27] is the sub-list for method output_type
+	9,  // [9:18] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_userkeypb_userkey_proto_init() }
@@ -241,6 +912,30 @@ func file_userkeypb_userkey_proto_init() {
 			}
 		}
 		file_userkeypb_userkey_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientBinding); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_userkeypb_userkey_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PreviousUserKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_userkeypb_userkey_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*UserKey); i {
 			case 0:
 				return &v.state
@@ -252,6 +947,90 @@ func file_userkeypb_userkey_proto_init() {
 				return nil
 			}
 		}
+		file_userkeypb_userkey_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeySessionInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_userkeypb_userkey_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CipherPayload); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_userkeypb_userkey_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionCipherPayload); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_userkeypb_userkey_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionCipherPayloadBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_userkeypb_userkey_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CipherPayloadBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_userkeypb_userkey_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RewrapGrant); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_userkeypb_userkey_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RewrapGrantPayloadBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -259,7 +1038,7 @@ func file_userkeypb_userkey_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_userkeypb_userkey_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
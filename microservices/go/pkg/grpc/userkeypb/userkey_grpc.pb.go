@@ -23,6 +23,16 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type UserKeyServiceClient interface {
 	GetKeyFromSession(ctx context.Context, in *UserKeySession, opts ...grpc.CallOption) (*UserKey, error)
+	GetKeySessionInfo(ctx context.Context, in *UserKeySession, opts ...grpc.CallOption) (*KeySessionInfo, error)
+	EncryptWithSession(ctx context.Context, in *SessionCipherPayload, opts ...grpc.CallOption) (*CipherPayload, error)
+	DecryptWithSession(ctx context.Context, in *SessionCipherPayload, opts ...grpc.CallOption) (*CipherPayload, error)
+	EncryptBatchWithSession(ctx context.Context, in *SessionCipherPayloadBatch, opts ...grpc.CallOption) (*CipherPayloadBatch, error)
+	DecryptBatchWithSession(ctx context.Context, in *SessionCipherPayloadBatch, opts ...grpc.CallOption) (*CipherPayloadBatch, error)
+	EncryptStreamWithSession(ctx context.Context, opts ...grpc.CallOption) (UserKeyService_EncryptStreamWithSessionClient, error)
+	DecryptStreamWithSession(ctx context.Context, opts ...grpc.CallOption) (UserKeyService_DecryptStreamWithSessionClient, error)
+	// Re-encrypts ciphertexts of previous user key versions with the current
+	// user key. Plaintexts are never returned.
+	RewrapWithGrant(ctx context.Context, in *RewrapGrantPayloadBatch, opts ...grpc.CallOption) (*CipherPayloadBatch, error)
 }
 
 type userKeyServiceClient struct {
@@ -42,11 +52,137 @@ func (c *userKeyServiceClient) GetKeyFromSession(ctx context.Context, in *UserKe
 	return out, nil
 }
 
+func (c *userKeyServiceClient) GetKeySessionInfo(ctx context.Context, in *UserKeySession, opts ...grpc.CallOption) (*KeySessionInfo, error) {
+	out := new(KeySessionInfo)
+	err := c.cc.Invoke(ctx, "/UserKeyService/GetKeySessionInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userKeyServiceClient) EncryptWithSession(ctx context.Context, in *SessionCipherPayload, opts ...grpc.CallOption) (*CipherPayload, error) {
+	out := new(CipherPayload)
+	err := c.cc.Invoke(ctx, "/UserKeyService/EncryptWithSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userKeyServiceClient) DecryptWithSession(ctx context.Context, in *SessionCipherPayload, opts ...grpc.CallOption) (*CipherPayload, error) {
+	out := new(CipherPayload)
+	err := c.cc.Invoke(ctx, "/UserKeyService/DecryptWithSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userKeyServiceClient) EncryptBatchWithSession(ctx context.Context, in *SessionCipherPayloadBatch, opts ...grpc.CallOption) (*CipherPayloadBatch, error) {
+	out := new(CipherPayloadBatch)
+	err := c.cc.Invoke(ctx, "/UserKeyService/EncryptBatchWithSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userKeyServiceClient) DecryptBatchWithSession(ctx context.Context, in *SessionCipherPayloadBatch, opts ...grpc.CallOption) (*CipherPayloadBatch, error) {
+	out := new(CipherPayloadBatch)
+	err := c.cc.Invoke(ctx, "/UserKeyService/DecryptBatchWithSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userKeyServiceClient) EncryptStreamWithSession(ctx context.Context, opts ...grpc.CallOption) (UserKeyService_EncryptStreamWithSessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserKeyService_ServiceDesc.Streams[0], "/UserKeyService/EncryptStreamWithSession", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userKeyServiceEncryptStreamWithSessionClient{stream}
+	return x, nil
+}
+
+type UserKeyService_EncryptStreamWithSessionClient interface {
+	Send(*SessionCipherPayload) error
+	Recv() (*CipherPayload, error)
+	grpc.ClientStream
+}
+
+type userKeyServiceEncryptStreamWithSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *userKeyServiceEncryptStreamWithSessionClient) Send(m *SessionCipherPayload) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *userKeyServiceEncryptStreamWithSessionClient) Recv() (*CipherPayload, error) {
+	m := new(CipherPayload)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userKeyServiceClient) DecryptStreamWithSession(ctx context.Context, opts ...grpc.CallOption) (UserKeyService_DecryptStreamWithSessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserKeyService_ServiceDesc.Streams[1], "/UserKeyService/DecryptStreamWithSession", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userKeyServiceDecryptStreamWithSessionClient{stream}
+	return x, nil
+}
+
+type UserKeyService_DecryptStreamWithSessionClient interface {
+	Send(*SessionCipherPayload) error
+	Recv() (*CipherPayload, error)
+	grpc.ClientStream
+}
+
+type userKeyServiceDecryptStreamWithSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *userKeyServiceDecryptStreamWithSessionClient) Send(m *SessionCipherPayload) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *userKeyServiceDecryptStreamWithSessionClient) Recv() (*CipherPayload, error) {
+	m := new(CipherPayload)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userKeyServiceClient) RewrapWithGrant(ctx context.Context, in *RewrapGrantPayloadBatch, opts ...grpc.CallOption) (*CipherPayloadBatch, error) {
+	out := new(CipherPayloadBatch)
+	err := c.cc.Invoke(ctx, "/UserKeyService/RewrapWithGrant", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserKeyServiceServer is the server API for UserKeyService service.
 // All implementations must embed UnimplementedUserKeyServiceServer
 // for forward compatibility
 type UserKeyServiceServer interface {
 	GetKeyFromSession(context.Context, *UserKeySession) (*UserKey, error)
+	GetKeySessionInfo(context.Context, *UserKeySession) (*KeySessionInfo, error)
+	EncryptWithSession(context.Context, *SessionCipherPayload) (*CipherPayload, error)
+	DecryptWithSession(context.Context, *SessionCipherPayload) (*CipherPayload, error)
+	EncryptBatchWithSession(context.Context, *SessionCipherPayloadBatch) (*CipherPayloadBatch, error)
+	DecryptBatchWithSession(context.Context, *SessionCipherPayloadBatch) (*CipherPayloadBatch, error)
+	EncryptStreamWithSession(UserKeyService_EncryptStreamWithSessionServer) error
+	DecryptStreamWithSession(UserKeyService_DecryptStreamWithSessionServer) error
+	// Re-encrypts ciphertexts of previous user key versions with the current
+	// user key. Plaintexts are never returned.
+	RewrapWithGrant(context.Context, *RewrapGrantPayloadBatch) (*CipherPayloadBatch, error)
 	mustEmbedUnimplementedUserKeyServiceServer()
 }
 
@@ -57,6 +193,30 @@ type UnimplementedUserKeyServiceServer struct {
 func (UnimplementedUserKeyServiceServer) GetKeyFromSession(context.Context, *UserKeySession) (*UserKey, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetKeyFromSession not implemented")
 }
+func (UnimplementedUserKeyServiceServer) GetKeySessionInfo(context.Context, *UserKeySession) (*KeySessionInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeySessionInfo not implemented")
+}
+func (UnimplementedUserKeyServiceServer) EncryptWithSession(context.Context, *SessionCipherPayload) (*CipherPayload, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EncryptWithSession not implemented")
+}
+func (UnimplementedUserKeyServiceServer) DecryptWithSession(context.Context, *SessionCipherPayload) (*CipherPayload, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecryptWithSession not implemented")
+}
+func (UnimplementedUserKeyServiceServer) EncryptBatchWithSession(context.Context, *SessionCipherPayloadBatch) (*CipherPayloadBatch, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EncryptBatchWithSession not implemented")
+}
+func (UnimplementedUserKeyServiceServer) DecryptBatchWithSession(context.Context, *SessionCipherPayloadBatch) (*CipherPayloadBatch, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecryptBatchWithSession not implemented")
+}
+func (UnimplementedUserKeyServiceServer) EncryptStreamWithSession(UserKeyService_EncryptStreamWithSessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method EncryptStreamWithSession not implemented")
+}
+func (UnimplementedUserKeyServiceServer) DecryptStreamWithSession(UserKeyService_DecryptStreamWithSessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method DecryptStreamWithSession not implemented")
+}
+func (UnimplementedUserKeyServiceServer) RewrapWithGrant(context.Context, *RewrapGrantPayloadBatch) (*CipherPayloadBatch, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RewrapWithGrant not implemented")
+}
 func (UnimplementedUserKeyServiceServer) mustEmbedUnimplementedUserKeyServiceServer() {}
 
 // UnsafeUserKeyServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +248,166 @@ func _UserKeyService_GetKeyFromSession_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserKeyService_GetKeySessionInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserKeySession)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserKeyServiceServer).GetKeySessionInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/UserKeyService/GetKeySessionInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserKeyServiceServer).GetKeySessionInfo(ctx, req.(*UserKeySession))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserKeyService_EncryptWithSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionCipherPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserKeyServiceServer).EncryptWithSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/UserKeyService/EncryptWithSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserKeyServiceServer).EncryptWithSession(ctx, req.(*SessionCipherPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserKeyService_DecryptWithSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionCipherPayload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserKeyServiceServer).DecryptWithSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/UserKeyService/DecryptWithSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserKeyServiceServer).DecryptWithSession(ctx, req.(*SessionCipherPayload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserKeyService_EncryptBatchWithSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionCipherPayloadBatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserKeyServiceServer).EncryptBatchWithSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/UserKeyService/EncryptBatchWithSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserKeyServiceServer).EncryptBatchWithSession(ctx, req.(*SessionCipherPayloadBatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserKeyService_DecryptBatchWithSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionCipherPayloadBatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserKeyServiceServer).DecryptBatchWithSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/UserKeyService/DecryptBatchWithSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserKeyServiceServer).DecryptBatchWithSession(ctx, req.(*SessionCipherPayloadBatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserKeyService_EncryptStreamWithSession_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UserKeyServiceServer).EncryptStreamWithSession(&userKeyServiceEncryptStreamWithSessionServer{stream})
+}
+
+type UserKeyService_EncryptStreamWithSessionServer interface {
+	Send(*CipherPayload) error
+	Recv() (*SessionCipherPayload, error)
+	grpc.ServerStream
+}
+
+type userKeyServiceEncryptStreamWithSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *userKeyServiceEncryptStreamWithSessionServer) Send(m *CipherPayload) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *userKeyServiceEncryptStreamWithSessionServer) Recv() (*SessionCipherPayload, error) {
+	m := new(SessionCipherPayload)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _UserKeyService_DecryptStreamWithSession_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UserKeyServiceServer).DecryptStreamWithSession(&userKeyServiceDecryptStreamWithSessionServer{stream})
+}
+
+type UserKeyService_DecryptStreamWithSessionServer interface {
+	Send(*CipherPayload) error
+	Recv() (*SessionCipherPayload, error)
+	grpc.ServerStream
+}
+
+type userKeyServiceDecryptStreamWithSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *userKeyServiceDecryptStreamWithSessionServer) Send(m *CipherPayload) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *userKeyServiceDecryptStreamWithSessionServer) Recv() (*SessionCipherPayload, error) {
+	m := new(SessionCipherPayload)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _UserKeyService_RewrapWithGrant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RewrapGrantPayloadBatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserKeyServiceServer).RewrapWithGrant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/UserKeyService/RewrapWithGrant",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserKeyServiceServer).RewrapWithGrant(ctx, req.(*RewrapGrantPayloadBatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserKeyService_ServiceDesc is the grpc.ServiceDesc for UserKeyService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,7 +419,44 @@ var UserKeyService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetKeyFromSession",
 			Handler:    _UserKeyService_GetKeyFromSession_Handler,
 		},
+		{
+			MethodName: "GetKeySessionInfo",
+			Handler:    _UserKeyService_GetKeySessionInfo_Handler,
+		},
+		{
+			MethodName: "EncryptWithSession",
+			Handler:    _UserKeyService_EncryptWithSession_Handler,
+		},
+		{
+			MethodName: "DecryptWithSession",
+			Handler:    _UserKeyService_DecryptWithSession_Handler,
+		},
+		{
+			MethodName: "EncryptBatchWithSession",
+			Handler:    _UserKeyService_EncryptBatchWithSession_Handler,
+		},
+		{
+			MethodName: "DecryptBatchWithSession",
+			Handler:    _UserKeyService_DecryptBatchWithSession_Handler,
+		},
+		{
+			MethodName: "RewrapWithGrant",
+			Handler:    _UserKeyService_RewrapWithGrant_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "EncryptStreamWithSession",
+			Handler:       _UserKeyService_EncryptStreamWithSession_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DecryptStreamWithSession",
+			Handler:       _UserKeyService_DecryptStreamWithSession_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "userkeypb/userkey.proto",
 }
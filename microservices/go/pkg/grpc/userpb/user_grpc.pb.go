@@ -24,6 +24,9 @@ const _ = grpc.SupportPackageIsVersion7
 type UserServiceClient interface {
 	GetUserByAuthId(ctx context.Context, in *AuthIdRequest, opts ...grpc.CallOption) (*UserReply, error)
 	GetUserById(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*UserReply, error)
+	GetUsersByIds(ctx context.Context, in *IdsRequest, opts ...grpc.CallOption) (*UsersReply, error)
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (UserService_ListUsersClient, error)
+	WatchUserChanges(ctx context.Context, in *WatchUserChangesRequest, opts ...grpc.CallOption) (UserService_WatchUserChangesClient, error)
 }
 
 type userServiceClient struct {
@@ -52,12 +55,88 @@ func (c *userServiceClient) GetUserById(ctx context.Context, in *IdRequest, opts
 	return out, nil
 }
 
+func (c *userServiceClient) GetUsersByIds(ctx context.Context, in *IdsRequest, opts ...grpc.CallOption) (*UsersReply, error) {
+	out := new(UsersReply)
+	err := c.cc.Invoke(ctx, "/UserService/GetUsersByIds", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (UserService_ListUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserService_ServiceDesc.Streams[0], "/UserService/ListUsers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceListUsersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserService_ListUsersClient interface {
+	Recv() (*UsersReply, error)
+	grpc.ClientStream
+}
+
+type userServiceListUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceListUsersClient) Recv() (*UsersReply, error) {
+	m := new(UsersReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userServiceClient) WatchUserChanges(ctx context.Context, in *WatchUserChangesRequest, opts ...grpc.CallOption) (UserService_WatchUserChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &UserService_ServiceDesc.Streams[1], "/UserService/WatchUserChanges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceWatchUserChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserService_WatchUserChangesClient interface {
+	Recv() (*UserChangeReply, error)
+	grpc.ClientStream
+}
+
+type userServiceWatchUserChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceWatchUserChangesClient) Recv() (*UserChangeReply, error) {
+	m := new(UserChangeReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 // All implementations must embed UnimplementedUserServiceServer
 // for forward compatibility
 type UserServiceServer interface {
 	GetUserByAuthId(context.Context, *AuthIdRequest) (*UserReply, error)
 	GetUserById(context.Context, *IdRequest) (*UserReply, error)
+	GetUsersByIds(context.Context, *IdsRequest) (*UsersReply, error)
+	ListUsers(*ListUsersRequest, UserService_ListUsersServer) error
+	WatchUserChanges(*WatchUserChangesRequest, UserService_WatchUserChangesServer) error
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -71,6 +150,15 @@ func (UnimplementedUserServiceServer) GetUserByAuthId(context.Context, *AuthIdRe
 func (UnimplementedUserServiceServer) GetUserById(context.Context, *IdRequest) (*UserReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserById not implemented")
 }
+func (UnimplementedUserServiceServer) GetUsersByIds(context.Context, *IdsRequest) (*UsersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsersByIds not implemented")
+}
+func (UnimplementedUserServiceServer) ListUsers(*ListUsersRequest, UserService_ListUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedUserServiceServer) WatchUserChanges(*WatchUserChangesRequest, UserService_WatchUserChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchUserChanges not implemented")
+}
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 
 // UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -120,6 +208,66 @@ func _UserService_GetUserById_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_GetUsersByIds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetUsersByIds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/UserService/GetUsersByIds",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetUsersByIds(ctx, req.(*IdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListUsersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).ListUsers(m, &userServiceListUsersServer{stream})
+}
+
+type UserService_ListUsersServer interface {
+	Send(*UsersReply) error
+	grpc.ServerStream
+}
+
+type userServiceListUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceListUsersServer) Send(m *UsersReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _UserService_WatchUserChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchUserChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).WatchUserChanges(m, &userServiceWatchUserChangesServer{stream})
+}
+
+type UserService_WatchUserChangesServer interface {
+	Send(*UserChangeReply) error
+	grpc.ServerStream
+}
+
+type userServiceWatchUserChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceWatchUserChangesServer) Send(m *UserChangeReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -135,7 +283,22 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserById",
 			Handler:    _UserService_GetUserById_Handler,
 		},
+		{
+			MethodName: "GetUsersByIds",
+			Handler:    _UserService_GetUsersByIds_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListUsers",
+			Handler:       _UserService_ListUsers_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchUserChanges",
+			Handler:       _UserService_WatchUserChanges_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "userpb/user.proto",
 }
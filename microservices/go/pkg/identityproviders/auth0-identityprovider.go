@@ -0,0 +1,49 @@
+package identityproviders
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"gopkg.in/auth0.v5/management"
+	"net/http"
+	"strings"
+)
+
+// Auth0IdentityProviderImpl manages identities with Auth0. Auth0 grants
+// authorities with the permissions claim.
+type Auth0IdentityProviderImpl struct {
+	auth0SecurityConf authconf.Auth0SecurityConf
+}
+
+// IsSystemClient checks the subject, as Auth0 prefixes the subjects of users
+// with their connection, such as auth0|123, while system clients have subjects
+// such as 123@clients
+func (a Auth0IdentityProviderImpl) IsSystemClient(subject string, _ security.Claims) bool {
+	return !strings.Contains(subject, "|")
+}
+
+func (a Auth0IdentityProviderImpl) GetAuthorities(claims security.Claims) []string {
+	return claims.GetStrings("permissions")
+}
+
+func (a Auth0IdentityProviderImpl) DeleteUser(ctx context.Context, authId string) (bool, error) {
+	m, err := management.New(a.auth0SecurityConf.GetDomain(), management.WithClientCredentials(
+		a.auth0SecurityConf.GetClientCredentialsId(),
+		a.auth0SecurityConf.GetClientCredentialsSecret(),
+	))
+	if err != nil {
+		return false, err
+	}
+	// login.auth0.com/api/v2
+	if err := m.User.Delete(authId, management.Context(ctx)); err != nil {
+		if mgmtErr, ok := err.(management.Error); ok && mgmtErr.Status() == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func NewAuth0IdentityProviderImpl(auth0SecurityConf authconf.Auth0SecurityConf) *Auth0IdentityProviderImpl {
+	return &Auth0IdentityProviderImpl{auth0SecurityConf: auth0SecurityConf}
+}
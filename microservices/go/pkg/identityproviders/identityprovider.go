@@ -0,0 +1,69 @@
+package identityproviders
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+)
+
+// IdentityProvider is the authentication server that issues the tokens of
+// users and system clients and manages their accounts
+type IdentityProvider interface {
+	security.ClaimsMapper
+
+	// DeleteUser deletes the user with the auth ID from the identity provider.
+	// Returns false if the user does not exist, such as when an earlier attempt
+	// already deleted the user.
+	DeleteUser(ctx context.Context, authId string) (bool, error)
+}
+
+// NewIdentityProvider creates the IdentityProvider configured by the
+// IdentityProviderConf
+func NewIdentityProvider(
+	identityProviderConf authconf.IdentityProviderConf,
+	auth0SecurityConf authconf.Auth0SecurityConf,
+	clientProvider externalservices.HttpClientProvider,
+) IdentityProvider {
+	switch identityProviderConf.GetIdentityProvider() {
+	case authconf.IdentityProviderOidc:
+		return NewOidcIdentityProviderImpl(identityProviderConf, clientProvider)
+	case authconf.IdentityProviderLocal:
+		return NewLocalIdentityProviderImpl(identityProviderConf)
+	default:
+		return NewAuth0IdentityProviderImpl(auth0SecurityConf)
+	}
+}
+
+// claimPathsMapper maps claims with the claim paths of the
+// IdentityProviderConf
+type claimPathsMapper struct {
+	identityProviderConf authconf.IdentityProviderConf
+}
+
+func (c claimPathsMapper) IsSystemClient(_ string, claims security.Claims) bool {
+	claimPath := c.identityProviderConf.GetOidcSystemClientClaimPath()
+	if utils.StringIsBlank(claimPath) {
+		return false
+	}
+	values := claims.GetStrings(claimPath)
+	expectedValue := c.identityProviderConf.GetOidcSystemClientClaimValue()
+	if utils.StringIsBlank(expectedValue) {
+		return len(values) > 0
+	}
+	for _, value := range values {
+		if value == expectedValue {
+			return true
+		}
+	}
+	return false
+}
+
+func (c claimPathsMapper) GetAuthorities(claims security.Claims) []string {
+	authorities := []string{}
+	for _, claimPath := range c.identityProviderConf.GetOidcAuthorityClaimPaths() {
+		authorities = append(authorities, claims.GetStrings(claimPath)...)
+	}
+	return authorities
+}
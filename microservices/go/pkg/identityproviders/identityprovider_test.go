@@ -0,0 +1,162 @@
+package identityproviders_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/identityproviders"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
+	cv "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const stubClientId = "cypher-log-admin"
+const stubClientSecret = "stub-secret"
+const stubAccessToken = "stub-access-token"
+const stubExistingAuthId = "existing-user"
+
+// newOidcStub creates a server imitating the token endpoint of an OIDC provider
+// and a Keycloak style admin API that only knows stubExistingAuthId
+func newOidcStub() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientId, clientSecret, ok := r.BasicAuth()
+		if !ok || clientId != stubClientId || clientSecret != stubClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": stubAccessToken, "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/admin/realms/cypher-log/users/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+stubAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodDelete || !strings.HasSuffix(r.URL.Path, "/"+stubExistingAuthId) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return httptest.NewServer(mux)
+}
+
+func newIdentityProvider() identityproviders.IdentityProvider {
+	return identityproviders.NewIdentityProvider(
+		authconf.NewIdentityProviderConfImpl(),
+		authconf.NewAuth0SecurityConfImpl(),
+		externalservices.NewHTTPClientProviderImpl(conf.NewHttpClientConfImpl()),
+	)
+}
+
+func parseClaims(jsonClaims string) security.Claims {
+	claims := security.Claims{}
+	if err := json.Unmarshal([]byte(jsonClaims), &claims); err != nil {
+		panic(err)
+	}
+	return claims
+}
+
+func TestAuth0IdentityProvider(t *testing.T) {
+	t.Setenv(environment.EnvVarKeyIdentityProvider, authconf.IdentityProviderAuth0)
+	identityProvider := newIdentityProvider()
+
+	cv.Convey("When mapping the claims of an Auth0 token", t, func() {
+		claims := parseClaims(`{"scope": "openid", "permissions": ["admin:users", "admin:keys"]}`)
+
+		cv.Convey("Expect the permissions are the authorities", func() {
+			cv.So(identityProvider.GetAuthorities(claims), cv.ShouldResemble, []string{"admin:users", "admin:keys"})
+		})
+		cv.Convey("Expect subjects with a connection are users", func() {
+			cv.So(identityProvider.IsSystemClient("auth0|123", claims), cv.ShouldBeFalse)
+		})
+		cv.Convey("Expect subjects without a connection are system clients", func() {
+			cv.So(identityProvider.IsSystemClient("123@clients", claims), cv.ShouldBeTrue)
+		})
+	})
+}
+
+func TestOidcIdentityProvider(t *testing.T) {
+	stub := newOidcStub()
+	defer stub.Close()
+	t.Setenv(environment.EnvVarKeyIdentityProvider, authconf.IdentityProviderOidc)
+	t.Setenv(environment.EnvVarKeyOidcAuthorityClaims, "realm_access.roles, resource_access.cypher-log.roles")
+	t.Setenv(environment.EnvVarKeyOidcTokenUrl, stub.URL+"/token")
+	t.Setenv(environment.EnvVarKeyOidcClientId, stubClientId)
+	t.Setenv(environment.EnvVarKeyOidcClientSecret, stubClientSecret)
+	t.Setenv(environment.EnvVarKeyOidcUserDeleteUrl, stub.URL+"/admin/realms/cypher-log/users/{authId}")
+	userClaims := parseClaims(`{
+		"realm_access": {"roles": ["admin:users"]},
+		"resource_access": {"cypher-log": {"roles": ["admin:keys"]}, "account": {"roles": ["manage-account"]}}
+	}`)
+	systemClientClaims := parseClaims(`{"client_id": "keyservice", "realm_access": {"roles": ["system-client"]}}`)
+
+	cv.Convey("When mapping the claims of a Keycloak token", t, func() {
+		cv.Convey("Expect the roles at the claim paths are the authorities", func() {
+			identityProvider := newIdentityProvider()
+			cv.So(identityProvider.GetAuthorities(userClaims), cv.ShouldResemble, []string{"admin:users", "admin:keys"})
+		})
+		cv.Convey("Expect tokens with the system client claim are system clients", func() {
+			identityProvider := newIdentityProvider()
+			cv.So(identityProvider.IsSystemClient("1", userClaims), cv.ShouldBeFalse)
+			cv.So(identityProvider.IsSystemClient("2", systemClientClaims), cv.ShouldBeTrue)
+		})
+		cv.Convey("Expect tokens need the system client claim value when one is configured", func() {
+			t.Setenv(environment.EnvVarKeyOidcSystemClientClaim, "realm_access.roles")
+			t.Setenv(environment.EnvVarKeyOidcSystemClientClaimValue, "system-client")
+			identityProvider := newIdentityProvider()
+			cv.So(identityProvider.IsSystemClient("1", userClaims), cv.ShouldBeFalse)
+			cv.So(identityProvider.IsSystemClient("2", systemClientClaims), cv.ShouldBeTrue)
+		})
+	})
+
+	cv.Convey("When deleting a user with the OIDC identity provider", t, func() {
+		ctx := context.Background()
+		identityProvider := newIdentityProvider()
+
+		cv.Convey("Expect an existing user is deleted", func() {
+			deleted, err := identityProvider.DeleteUser(ctx, stubExistingAuthId)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(deleted, cv.ShouldBeTrue)
+		})
+		cv.Convey("Expect a missing user is reported as not deleted", func() {
+			deleted, err := identityProvider.DeleteUser(ctx, "missing-user")
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(deleted, cv.ShouldBeFalse)
+		})
+		cv.Convey("Expect invalid client credentials fail to delete the user", func() {
+			t.Setenv(environment.EnvVarKeyOidcClientSecret, "wrong-secret")
+			_, err := newIdentityProvider().DeleteUser(ctx, stubExistingAuthId)
+			cv.So(err, cv.ShouldNotBeNil)
+		})
+		cv.Convey("Expect a missing user delete URL fails to delete the user", func() {
+			t.Setenv(environment.EnvVarKeyOidcUserDeleteUrl, "")
+			_, err := newIdentityProvider().DeleteUser(ctx, stubExistingAuthId)
+			cv.So(err, cv.ShouldNotBeNil)
+		})
+	})
+}
+
+func TestLocalIdentityProvider(t *testing.T) {
+	t.Setenv(environment.EnvVarKeyIdentityProvider, authconf.IdentityProviderLocal)
+	identityProvider := newIdentityProvider()
+
+	cv.Convey("When using the local identity provider", t, func() {
+		cv.Convey("Expect the scope is the authorities by default", func() {
+			claims := parseClaims(`{"scope": "openid admin:users"}`)
+			cv.So(identityProvider.GetAuthorities(claims), cv.ShouldResemble, []string{"openid", "admin:users"})
+		})
+		cv.Convey("Expect deleting a user succeeds", func() {
+			deleted, err := identityProvider.DeleteUser(context.Background(), "local-user")
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(deleted, cv.ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,25 @@
+package identityproviders
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+)
+
+// LocalIdentityProviderImpl is a stand-in identity provider for development.
+// Claims are mapped like the OIDC identity provider, but users are only
+// pretended to be deleted.
+type LocalIdentityProviderImpl struct {
+	claimPathsMapper
+}
+
+func (l LocalIdentityProviderImpl) DeleteUser(ctx context.Context, authId string) (bool, error) {
+	logger.Log.WithContext(ctx).Infof("Local identity provider skipped deleting user %v", authId)
+	return true, nil
+}
+
+func NewLocalIdentityProviderImpl(identityProviderConf authconf.IdentityProviderConf) *LocalIdentityProviderImpl {
+	return &LocalIdentityProviderImpl{
+		claimPathsMapper: claimPathsMapper{identityProviderConf: identityProviderConf},
+	}
+}
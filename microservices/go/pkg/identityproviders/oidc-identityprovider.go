@@ -0,0 +1,77 @@
+package identityproviders
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OidcIdentityProviderImpl manages identities with a generic OpenID Connect
+// provider, such as Keycloak. Claims are mapped with the claim paths of the
+// IdentityProviderConf and users are deleted with the provider's admin API,
+// authenticated with client credentials.
+type OidcIdentityProviderImpl struct {
+	claimPathsMapper
+	identityProviderConf authconf.IdentityProviderConf
+	clientProvider       externalservices.HttpClientProvider
+}
+
+func (o OidcIdentityProviderImpl) DeleteUser(ctx context.Context, authId string) (bool, error) {
+	userDeleteUrl := o.identityProviderConf.GetOidcUserDeleteUrl()
+	// Erroring keeps the user to be deleted once a URL is configured, rather
+	// than treating the user as deleted from the provider
+	if utils.StringIsBlank(userDeleteUrl) {
+		return false, fmt.Errorf("no user delete URL is configured to delete user %v", authId)
+	}
+	userDeleteUrl = strings.ReplaceAll(userDeleteUrl, "{authId}", url.PathEscape(authId))
+
+	client := o.clientProvider.Client()
+	tokenCtx := context.WithValue(ctx, oauth2.HTTPClient, client.StandardClient())
+	clientCredentialsConf := clientcredentials.Config{
+		ClientID:     o.identityProviderConf.GetOidcClientId(),
+		ClientSecret: o.identityProviderConf.GetOidcClientSecret(),
+		TokenURL:     o.identityProviderConf.GetOidcTokenUrl(),
+	}
+	token, err := clientCredentialsConf.Token(tokenCtx)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodDelete, userDeleteUrl, nil)
+	if err != nil {
+		return false, err
+	}
+	token.SetAuthHeader(req.Request)
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, fmt.Errorf("deleting user %v failed with status %v", authId, resp.StatusCode)
+	}
+	return true, nil
+}
+
+func NewOidcIdentityProviderImpl(
+	identityProviderConf authconf.IdentityProviderConf,
+	clientProvider externalservices.HttpClientProvider,
+) *OidcIdentityProviderImpl {
+	return &OidcIdentityProviderImpl{
+		claimPathsMapper:     claimPathsMapper{identityProviderConf: identityProviderConf},
+		identityProviderConf: identityProviderConf,
+		clientProvider:       clientProvider,
+	}
+}
@@ -0,0 +1,40 @@
+package kfka
+
+import "context"
+
+// NewTestRetryingConsumer creates a RetryingConsumer that sends retries with
+// the given senders instead of writing to Kafka
+func NewTestRetryingConsumer[T any](
+	policy RetryPolicy,
+	stageSenders []interface {
+		Send(ctx context.Context, body RetryDto[T]) error
+		Close() error
+	},
+	deadLetterSender interface {
+		Send(ctx context.Context, body RetryDto[T]) error
+		Close() error
+	},
+) *RetryingConsumer[T] {
+	r := &RetryingConsumer[T]{
+		policy:           policy,
+		deadLetterSender: deadLetterSender,
+		counters:         []*stageCounters{{}},
+		done:             make(chan struct{}),
+	}
+	for _, stageSender := range stageSenders {
+		r.stageSenders = append(r.stageSenders, stageSender)
+		r.counters = append(r.counters, &stageCounters{})
+	}
+	return r
+}
+
+// Route exposes route to tests
+func (r *RetryingConsumer[T]) Route(
+	ctx context.Context,
+	stage int,
+	retry RetryDto[T],
+	cause error,
+	deadLetter func(ctx context.Context, retry RetryDto[T], cause error) error,
+) error {
+	return r.route(ctx, stage, retry, cause, deadLetter)
+}
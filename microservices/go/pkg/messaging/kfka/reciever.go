@@ -47,6 +47,14 @@ func (r *KafkaReceiver[T]) ListenAutoCommit(processMsg func(T) error) bool {
 // and exits the application. If the intention of the processing is to continue
 // even if there is an error, return a nil.
 func (r *KafkaReceiver[T]) Listen(autoCommit bool, processMsg func(T) error) bool {
+	return r.ListenWithMessage(autoCommit, func(body T, _ kafka.Message) error {
+		return processMsg(body)
+	})
+}
+
+// ListenWithMessage listens for messages like Listen, also passing processMsg
+// the message the body was read from for its key, topic, partition and offset
+func (r *KafkaReceiver[T]) ListenWithMessage(autoCommit bool, processMsg func(T, kafka.Message) error) bool {
 	if r.shouldNotListen() {
 		return false
 	}
@@ -71,7 +79,7 @@ func (r *KafkaReceiver[T]) Close() error {
 	return err
 }
 
-func (r *KafkaReceiver[T]) runListen(autoCommit bool, processMsg func(T) error) {
+func (r *KafkaReceiver[T]) runListen(autoCommit bool, processMsg func(T, kafka.Message) error) {
 	msgChan := make(chan tuple.T2[context.Context, kafka.Message])
 	errChan := make(chan tuple.T2[context.Context, error])
 
@@ -101,7 +109,7 @@ func (r *KafkaReceiver[T]) runListen(autoCommit bool, processMsg func(T) error)
 			if body, err := r.readBody(msg); err != nil {
 				r.logger().WithContext(ctx).WithError(err).Error("Cannot parse message value, skipping message")
 			} else {
-				if err := processMsg(body); err != nil {
+				if err := processMsg(body, msg); err != nil {
 					r.logger().WithContext(ctx).WithError(err).Error("Failed to process message")
 					willContinue = false
 					shutDownErr = err
@@ -0,0 +1,69 @@
+package kfka
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"time"
+)
+
+// RetryStage is a topic where failed messages wait to be retried
+type RetryStage struct {
+	Topic string
+	// BackoffMin and BackoffMax bound how long the stage waits between reads of
+	// its topic, which spaces out the retries
+	BackoffMin time.Duration
+	BackoffMax time.Duration
+	// MaxTries is how many times a message is retried in the stage before it
+	// moves on to the next stage
+	MaxTries int
+}
+
+// RetryPolicy declares how the messages of a topic are retried. Messages that
+// fail on the main topic go through each stage in order and end up in the
+// dead letter topic once every stage has run out of tries.
+type RetryPolicy struct {
+	MainTopic string
+	// GroupID is the consumer group reading the main topic
+	GroupID         string
+	Stages          []RetryStage
+	DeadLetterTopic string
+}
+
+// NewRetryPolicy creates a RetryPolicy with retry and dead letter topics named
+// after the consumer group. Stages with a topic keep it.
+func NewRetryPolicy(mainTopic string, groupID string, stages ...RetryStage) RetryPolicy {
+	for i := range stages {
+		if stages[i].Topic == "" {
+			stages[i].Topic = topics.AppendRetry(groupID, i+1)
+		}
+	}
+	return RetryPolicy{
+		MainTopic:       mainTopic,
+		GroupID:         groupID,
+		Stages:          stages,
+		DeadLetterTopic: topics.AppendDeadLetter(groupID),
+	}
+}
+
+// TriesBefore gets the tries a message has when it enters the stage at the
+// given index
+func (p RetryPolicy) TriesBefore(stage int) int {
+	triesBefore := 0
+	for _, s := range p.Stages[:stage] {
+		triesBefore += s.MaxTries
+	}
+	return triesBefore
+}
+
+// StageOf gets the index of the stage a message retried the given number of
+// tries belongs in. An index equal to the number of stages is the dead letter
+// topic.
+func (p RetryPolicy) StageOf(tries int) int {
+	triesBefore := 0
+	for i, stage := range p.Stages {
+		triesBefore += stage.MaxTries
+		if tries < triesBefore {
+			return i
+		}
+	}
+	return len(p.Stages)
+}
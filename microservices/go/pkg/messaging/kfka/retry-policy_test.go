@@ -0,0 +1,46 @@
+package kfka_test
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	cv.Convey("When declaring a retry policy with two stages", t, func() {
+		policy := kfka.NewRetryPolicy(
+			"user-change-1",
+			"user-change-1-key-service",
+			kfka.RetryStage{MaxTries: 3},
+			kfka.RetryStage{Topic: "custom-retry", MaxTries: 2},
+		)
+
+		cv.Convey("Expect topics without a name are named after the consumer group", func() {
+			cv.So(policy.Stages[0].Topic, cv.ShouldEqual, "user-change-1-key-service-retry-1")
+			cv.So(policy.Stages[1].Topic, cv.ShouldEqual, "custom-retry")
+			cv.So(policy.DeadLetterTopic, cv.ShouldEqual, "user-change-1-key-service-dead-letter")
+		})
+		cv.Convey("Expect a message stays in a stage until it runs out of tries", func() {
+			cv.So(policy.StageOf(0), cv.ShouldEqual, 0)
+			cv.So(policy.StageOf(2), cv.ShouldEqual, 0)
+			cv.So(policy.StageOf(3), cv.ShouldEqual, 1)
+			cv.So(policy.StageOf(4), cv.ShouldEqual, 1)
+		})
+		cv.Convey("Expect a message is dead lettered once every stage runs out of tries", func() {
+			cv.So(policy.StageOf(5), cv.ShouldEqual, 2)
+		})
+		cv.Convey("Expect a message entering a stage has the tries of the stages before it", func() {
+			cv.So(policy.TriesBefore(0), cv.ShouldEqual, 0)
+			cv.So(policy.TriesBefore(1), cv.ShouldEqual, 3)
+			cv.So(policy.StageOf(policy.TriesBefore(1)), cv.ShouldEqual, 1)
+		})
+	})
+	cv.Convey("When declaring a retry policy without stages", t, func() {
+		policy := kfka.NewRetryPolicy("user-change-1", "user-change-1-ui-service")
+
+		cv.Convey("Expect failed messages are dead lettered right away", func() {
+			cv.So(policy.StageOf(0), cv.ShouldEqual, 0)
+			cv.So(len(policy.Stages), cv.ShouldEqual, 0)
+		})
+	})
+}
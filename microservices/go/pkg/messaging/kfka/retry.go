@@ -1,8 +1,31 @@
 package kfka
 
+import "time"
+
+// MaxRetryErrors is how many of the latest errors a RetryDto keeps
+const MaxRetryErrors = 10
+
 type RetryDto[T any] struct {
 	Value T   `json:"value"`
 	Tries int `json:"tries"`
+	// Errors are the latest errors processing the value, oldest first
+	Errors []RetryErrorDto `json:"errors,omitempty"`
+}
+
+// RetryErrorDto is an error processing a message read from a topic
+type RetryErrorDto struct {
+	Topic    string `json:"topic"`
+	Error    string `json:"error"`
+	FailedAt int64  `json:"failedAt"`
+}
+
+// AddError records an error processing the value read from a topic, dropping
+// the oldest error once MaxRetryErrors are kept
+func (r *RetryDto[T]) AddError(topic string, err error) {
+	r.Errors = append(r.Errors, RetryErrorDto{Topic: topic, Error: err.Error(), FailedAt: time.Now().UnixMilli()})
+	if len(r.Errors) > MaxRetryErrors {
+		r.Errors = r.Errors[len(r.Errors)-MaxRetryErrors:]
+	}
 }
 
 func CreateRetryDto[T any](value T, tries int) RetryDto[T] {
@@ -0,0 +1,25 @@
+package kfka_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestRetryDtoErrors(t *testing.T) {
+	cv.Convey("When a message fails more times than the errors kept", t, func() {
+		retry := kfka.CreateRetryDto("value", 0)
+		for i := 0; i < kfka.MaxRetryErrors+2; i++ {
+			retry.AddError("retry-topic", errors.New(fmt.Sprint("error ", i)))
+		}
+
+		cv.Convey("Expect only the latest errors are kept, oldest first", func() {
+			cv.So(len(retry.Errors), cv.ShouldEqual, kfka.MaxRetryErrors)
+			cv.So(retry.Errors[0].Error, cv.ShouldEqual, "error 2")
+			cv.So(retry.Errors[kfka.MaxRetryErrors-1].Error, cv.ShouldEqual, fmt.Sprint("error ", kfka.MaxRetryErrors+1))
+			cv.So(retry.Errors[0].Topic, cv.ShouldEqual, "retry-topic")
+		})
+	})
+}
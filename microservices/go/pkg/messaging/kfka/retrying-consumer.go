@@ -0,0 +1,248 @@
+package kfka
+
+import (
+	"context"
+	"github.com/akrennmair/slice"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/segmentio/kafka-go"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryStageStats counts the messages handled by a topic of a RetryingConsumer
+type RetryStageStats struct {
+	Topic string
+	// Received is the number of messages read
+	Received int64
+	// Succeeded is the number of messages processed without an error
+	Succeeded int64
+	// Failed is the number of messages that failed and were sent to be retried
+	// in the same stage
+	Failed int64
+	// Forwarded is the number of messages that failed and were sent to the next
+	// stage or to the dead letter topic
+	Forwarded int64
+}
+
+// retryStatsLogInterval is how often a RetryingConsumer logs its stats
+const retryStatsLogInterval = 10 * time.Minute
+
+// retrySender sends messages to a retry or dead letter topic
+type retrySender[T any] interface {
+	Send(ctx context.Context, body RetryDto[T]) error
+	lifecycle.Closable
+}
+
+type stageCounters struct {
+	received  int64
+	succeeded int64
+	failed    int64
+	forwarded int64
+}
+
+// RetryingConsumer consumes a topic, routing the messages that fail through
+// the stages of a RetryPolicy
+type RetryingConsumer[T any] struct {
+	policy           RetryPolicy
+	mainReceiver     *KafkaReceiver[T]
+	stageReceivers   []*KafkaReceiver[RetryDto[T]]
+	stageSenders     []retrySender[T]
+	deadLetterSender retrySender[T]
+	// counters of the main topic followed by the counters of each stage
+	counters  []*stageCounters
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Listen processes the messages of the main topic and of every stage. Once a
+// message has run out of tries, deadLetter is called, if it is not nil, before
+// the message is sent to the dead letter topic.
+func (r *RetryingConsumer[T]) Listen(
+	process func(ctx context.Context, value T) error,
+	deadLetter func(ctx context.Context, retry RetryDto[T], cause error) error,
+) {
+	r.mainReceiver.ListenSyncCommit(func(value T) error {
+		ctx := context.Background()
+		atomic.AddInt64(&r.counters[0].received, 1)
+		if err := process(ctx, value); err != nil {
+			retry := CreateRetryDto(value, 0)
+			retry.AddError(r.policy.MainTopic, err)
+			return r.route(ctx, -1, retry, err, deadLetter)
+		}
+		atomic.AddInt64(&r.counters[0].succeeded, 1)
+		return nil
+	})
+
+	for i, stageReceiver := range r.stageReceivers {
+		stage := i
+		stageReceiver.ListenSyncCommit(func(retry RetryDto[T]) error {
+			ctx := context.Background()
+			atomic.AddInt64(&r.counters[stage+1].received, 1)
+			if err := process(ctx, retry.Value); err != nil {
+				retry.Tries += 1
+				retry.AddError(r.policy.Stages[stage].Topic, err)
+				return r.route(ctx, stage, retry, err, deadLetter)
+			}
+			atomic.AddInt64(&r.counters[stage+1].succeeded, 1)
+			return nil
+		})
+	}
+	go r.logStatsPeriodically()
+	logger.Log.Infof("Listening for messages on topic %v", r.policy.MainTopic)
+}
+
+// route sends a message that failed in the given stage, where -1 is the main
+// topic, to the stage matching its tries
+func (r *RetryingConsumer[T]) route(
+	ctx context.Context,
+	stage int,
+	retry RetryDto[T],
+	cause error,
+	deadLetter func(ctx context.Context, retry RetryDto[T], cause error) error,
+) error {
+	nextStage := r.policy.StageOf(retry.Tries)
+	if nextStage == stage {
+		atomic.AddInt64(&r.counters[stage+1].failed, 1)
+	} else {
+		atomic.AddInt64(&r.counters[stage+1].forwarded, 1)
+	}
+	if nextStage < len(r.stageSenders) {
+		logger.Log.WithContext(ctx).WithError(cause).
+			Warnf("Failed to process message, retrying on topic %v", r.policy.Stages[nextStage].Topic)
+		return r.stageSenders[nextStage].Send(ctx, retry)
+	}
+	logger.Log.WithContext(ctx).WithError(cause).
+		Errorf("Failed to process message, sending to topic %v", r.policy.DeadLetterTopic)
+	if deadLetter != nil {
+		if err := deadLetter(ctx, retry, cause); err != nil {
+			return err
+		}
+	}
+	return r.deadLetterSender.Send(ctx, retry)
+}
+
+// Stats gets the counters of the main topic followed by the counters of each
+// stage. The stats are logged periodically while listening and when the
+// consumer is closed.
+func (r *RetryingConsumer[T]) Stats() []RetryStageStats {
+	topicNames := append(
+		[]string{r.policy.MainTopic},
+		slice.Map(r.policy.Stages, func(s RetryStage) string { return s.Topic })...,
+	)
+	stats := make([]RetryStageStats, len(topicNames))
+	for i, topic := range topicNames {
+		stats[i] = RetryStageStats{
+			Topic:     topic,
+			Received:  atomic.LoadInt64(&r.counters[i].received),
+			Succeeded: atomic.LoadInt64(&r.counters[i].succeeded),
+			Failed:    atomic.LoadInt64(&r.counters[i].failed),
+			Forwarded: atomic.LoadInt64(&r.counters[i].forwarded),
+		}
+	}
+	return stats
+}
+
+// logStatsPeriodically logs the stats of the consumer until it is closed
+func (r *RetryingConsumer[T]) logStatsPeriodically() {
+	ticker := time.NewTicker(retryStatsLogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.logStats()
+		}
+	}
+}
+
+func (r *RetryingConsumer[T]) logStats() {
+	for _, stats := range r.Stats() {
+		logger.Log.WithField("topic", stats.Topic).Infof(
+			"Messages received: %v, succeeded: %v, failed: %v, forwarded: %v",
+			stats.Received,
+			stats.Succeeded,
+			stats.Failed,
+			stats.Forwarded,
+		)
+	}
+}
+
+// Close closes the readers and writers of the consumer after logging its
+// stats
+func (r *RetryingConsumer[T]) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.logStats()
+		closableList := []lifecycle.Closable{r.mainReceiver, r.deadLetterSender}
+		for _, stageReceiver := range r.stageReceivers {
+			closableList = append(closableList, stageReceiver)
+		}
+		for _, stageSender := range r.stageSenders {
+			closableList = append(closableList, stageSender)
+		}
+		errs := slice.MapConcurrent(closableList, lifecycle.Closable.Close)
+		r.closeErr = utils.ConcatErrors(errs...)
+	})
+	return r.closeErr
+}
+
+// NewRetryingConsumer creates the readers and writers of every topic in the
+// policy and registers the consumer to be closed with the app. The key of a
+// message is kept as it moves between topics.
+func NewRetryingConsumer[T any](
+	kafkaConf conf.KafkaConf,
+	policy RetryPolicy,
+	keyReader func(value T) ([]byte, error),
+) *RetryingConsumer[T] {
+	newRetrySender := func(topic string) retrySender[T] {
+		return NewKafkaSender(
+			&kafka.Writer{
+				Addr:     kafka.TCP(kafkaConf.GetBootstrapServers()...),
+				Topic:    topic,
+				Balancer: &kafka.Murmur2Balancer{},
+			},
+			func(b RetryDto[T]) ([]byte, error) {
+				return keyReader(b.Value)
+			},
+		)
+	}
+
+	r := &RetryingConsumer[T]{
+		policy: policy,
+		mainReceiver: NewKafkaReceiver[T](
+			kafka.NewReader(kafka.ReaderConfig{
+				Brokers:  kafkaConf.GetBootstrapServers(),
+				GroupID:  policy.GroupID,
+				Topic:    policy.MainTopic,
+				MinBytes: 10e3, // 10KB
+				MaxBytes: 10e6, // 10MB
+			}),
+		),
+		deadLetterSender: newRetrySender(policy.DeadLetterTopic),
+		counters:         []*stageCounters{{}},
+		done:             make(chan struct{}),
+	}
+	for _, stage := range policy.Stages {
+		r.stageReceivers = append(r.stageReceivers, NewKafkaReceiver[RetryDto[T]](
+			kafka.NewReader(kafka.ReaderConfig{
+				Brokers:        kafkaConf.GetBootstrapServers(),
+				GroupID:        stage.Topic,
+				Topic:          stage.Topic,
+				MinBytes:       10e3, // 10KB
+				MaxBytes:       10e6, // 10MB
+				ReadBackoffMin: stage.BackoffMin,
+				ReadBackoffMax: stage.BackoffMax,
+			}),
+		))
+		r.stageSenders = append(r.stageSenders, newRetrySender(stage.Topic))
+		r.counters = append(r.counters, &stageCounters{})
+	}
+	lifecycle.RegisterClosable(r)
+	return r
+}
@@ -0,0 +1,83 @@
+package kfka_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+type stubRetrySender struct {
+	sent []kfka.RetryDto[string]
+}
+
+func (s *stubRetrySender) Send(_ context.Context, body kfka.RetryDto[string]) error {
+	s.sent = append(s.sent, body)
+	return nil
+}
+
+func (s *stubRetrySender) Close() error {
+	return nil
+}
+
+func TestRetryingConsumerRoute(t *testing.T) {
+	cv.Convey("When routing failed messages of a consumer with two stages", t, func() {
+		ctx := context.Background()
+		policy := kfka.NewRetryPolicy(
+			"main",
+			"main-group",
+			kfka.RetryStage{MaxTries: 3},
+			kfka.RetryStage{MaxTries: 2},
+		)
+		stage1Sender, stage2Sender, deadLetterSender := &stubRetrySender{}, &stubRetrySender{}, &stubRetrySender{}
+		consumer := kfka.NewTestRetryingConsumer[string](
+			policy,
+			[]interface {
+				Send(ctx context.Context, body kfka.RetryDto[string]) error
+				Close() error
+			}{stage1Sender, stage2Sender},
+			deadLetterSender,
+		)
+		var deadLettered []kfka.RetryDto[string]
+		deadLetter := func(_ context.Context, retry kfka.RetryDto[string], _ error) error {
+			deadLettered = append(deadLettered, retry)
+			return nil
+		}
+		cause := errors.New("failed")
+
+		cv.Convey("Expect a message failing on the main topic goes to the first stage", func() {
+			err := consumer.Route(ctx, -1, kfka.CreateRetryDto("value", 0), cause, deadLetter)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(len(stage1Sender.sent), cv.ShouldEqual, 1)
+			cv.So(consumer.Stats()[0].Forwarded, cv.ShouldEqual, 1)
+		})
+		cv.Convey("Expect a message with tries left in its stage is retried in the stage", func() {
+			err := consumer.Route(ctx, 0, kfka.CreateRetryDto("value", 2), cause, deadLetter)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(len(stage1Sender.sent), cv.ShouldEqual, 1)
+			cv.So(consumer.Stats()[1].Failed, cv.ShouldEqual, 1)
+		})
+		cv.Convey("Expect a message out of tries in its stage goes to the next stage", func() {
+			err := consumer.Route(ctx, 0, kfka.CreateRetryDto("value", 3), cause, deadLetter)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(len(stage2Sender.sent), cv.ShouldEqual, 1)
+			cv.So(consumer.Stats()[1].Forwarded, cv.ShouldEqual, 1)
+		})
+		cv.Convey("Expect a message out of tries in every stage is dead lettered", func() {
+			err := consumer.Route(ctx, 1, kfka.CreateRetryDto("value", 5), cause, deadLetter)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(len(deadLettered), cv.ShouldEqual, 1)
+			cv.So(len(deadLetterSender.sent), cv.ShouldEqual, 1)
+			cv.So(consumer.Stats()[2].Forwarded, cv.ShouldEqual, 1)
+		})
+		cv.Convey("Expect a message is not dead lettered if the dead letter callback fails", func() {
+			failingDeadLetter := func(context.Context, kfka.RetryDto[string], error) error {
+				return errors.New("callback failed")
+			}
+			err := consumer.Route(ctx, 1, kfka.CreateRetryDto("value", 5), cause, failingDeadLetter)
+			cv.So(err, cv.ShouldNotBeNil)
+			cv.So(len(deadLetterSender.sent), cv.ShouldEqual, 0)
+		})
+	})
+}
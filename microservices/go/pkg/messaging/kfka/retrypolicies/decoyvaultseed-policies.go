@@ -0,0 +1,19 @@
+package retrypolicies
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"time"
+)
+
+const DecoyVaultSeedNoteServiceGroup = topics.DecoyVaultSeedTopic + "-note-service"
+
+// NewDecoyVaultSeedNoteServicePolicy creates the retry policy of decoy vault
+// seeds read by the note service, which retries every few minutes for a day
+func NewDecoyVaultSeedNoteServicePolicy() kfka.RetryPolicy {
+	return kfka.NewRetryPolicy(
+		topics.DecoyVaultSeedTopic,
+		DecoyVaultSeedNoteServiceGroup,
+		kfka.RetryStage{BackoffMin: time.Minute, BackoffMax: 2 * time.Minute, MaxTries: 1440},
+	)
+}
@@ -0,0 +1,71 @@
+package retrypolicies
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"time"
+)
+
+// Consumer groups of the services reading user changes
+const (
+	UserChange1KeyServiceGroup  = topics.UserChange1Topic + "-key-service"
+	UserChange1NoteServiceGroup = topics.UserChange1Topic + "-note-service"
+	UserChange1UiServiceGroup   = topics.UserChange1Topic + "-ui-service"
+)
+
+// NewUserChange1KeyServicePolicy creates the retry policy of user changes read
+// by the key service
+func NewUserChange1KeyServicePolicy() kfka.RetryPolicy {
+	return newUserChange1DataPolicy(UserChange1KeyServiceGroup)
+}
+
+// NewUserChange1NoteServicePolicy creates the retry policy of user changes read
+// by the note service
+func NewUserChange1NoteServicePolicy() kfka.RetryPolicy {
+	return newUserChange1DataPolicy(UserChange1NoteServiceGroup)
+}
+
+// NewUserChange1UiServicePolicy creates the retry policy of user changes read
+// by the ui service, which retries every few minutes for a day
+func NewUserChange1UiServicePolicy() kfka.RetryPolicy {
+	return kfka.NewRetryPolicy(
+		topics.UserChange1Topic,
+		UserChange1UiServiceGroup,
+		kfka.RetryStage{BackoffMin: time.Minute, BackoffMax: 2 * time.Minute, MaxTries: 1440},
+	)
+}
+
+// NewUserChange1Policies creates the retry policy of every service reading user
+// changes
+func NewUserChange1Policies() []kfka.RetryPolicy {
+	return []kfka.RetryPolicy{
+		NewUserChange1KeyServicePolicy(),
+		NewUserChange1NoteServicePolicy(),
+		NewUserChange1UiServicePolicy(),
+	}
+}
+
+// NewUserChange1PoliciesByDeadLetterTopic creates the retry policy of every
+// service reading user changes, keyed by dead letter topic
+func NewUserChange1PoliciesByDeadLetterTopic() map[string]kfka.RetryPolicy {
+	policies := map[string]kfka.RetryPolicy{}
+	for _, policy := range NewUserChange1Policies() {
+		policies[policy.DeadLetterTopic] = policy
+	}
+	return policies
+}
+
+// newUserChange1DataPolicy creates the retry policy of services holding user
+// data. A user change is retried every few seconds for an hour, then every few
+// minutes for a day, then hourly for about four days and then every few hours
+// for about a week before it is dead lettered.
+func newUserChange1DataPolicy(groupID string) kfka.RetryPolicy {
+	return kfka.NewRetryPolicy(
+		topics.UserChange1Topic,
+		groupID,
+		kfka.RetryStage{BackoffMin: time.Second, BackoffMax: 10 * time.Second, MaxTries: 3600},
+		kfka.RetryStage{BackoffMin: time.Minute, BackoffMax: 2 * time.Minute, MaxTries: 1440},
+		kfka.RetryStage{BackoffMin: time.Hour, BackoffMax: time.Hour + 15*time.Minute, MaxTries: 100},
+		kfka.RetryStage{BackoffMin: 5 * time.Hour, BackoffMax: 10 * time.Hour, MaxTries: 20},
+	)
+}
@@ -0,0 +1,50 @@
+package retrypolicies
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"time"
+)
+
+// Consumer groups of the services reading data export requests and parts
+const (
+	UserDataExportKeyServiceGroup      = topics.UserDataExportTopic + "-key-service"
+	UserDataExportNoteServiceGroup     = topics.UserDataExportTopic + "-note-service"
+	UserDataExportUiServiceGroup       = topics.UserDataExportTopic + "-ui-service"
+	UserDataExportPartUserServiceGroup = topics.UserDataExportPartTopic + "-user-service"
+)
+
+// NewUserDataExportKeyServicePolicy creates the retry policy of data export
+// requests read by the key service
+func NewUserDataExportKeyServicePolicy() kfka.RetryPolicy {
+	return newUserDataExportPolicy(topics.UserDataExportTopic, UserDataExportKeyServiceGroup)
+}
+
+// NewUserDataExportNoteServicePolicy creates the retry policy of data export
+// requests read by the note service
+func NewUserDataExportNoteServicePolicy() kfka.RetryPolicy {
+	return newUserDataExportPolicy(topics.UserDataExportTopic, UserDataExportNoteServiceGroup)
+}
+
+// NewUserDataExportUiServicePolicy creates the retry policy of data export
+// requests read by the ui service
+func NewUserDataExportUiServicePolicy() kfka.RetryPolicy {
+	return newUserDataExportPolicy(topics.UserDataExportTopic, UserDataExportUiServiceGroup)
+}
+
+// NewUserDataExportPartUserServicePolicy creates the retry policy of data
+// export parts read by the user service
+func NewUserDataExportPartUserServicePolicy() kfka.RetryPolicy {
+	return newUserDataExportPolicy(topics.UserDataExportPartTopic, UserDataExportPartUserServiceGroup)
+}
+
+// newUserDataExportPolicy creates the retry policy of data export messages,
+// which are retried every few minutes for about an hour as the user is waiting
+// for the export
+func newUserDataExportPolicy(mainTopic string, groupID string) kfka.RetryPolicy {
+	return kfka.NewRetryPolicy(
+		mainTopic,
+		groupID,
+		kfka.RetryStage{BackoffMin: time.Minute, BackoffMax: 2 * time.Minute, MaxTries: 60},
+	)
+}
@@ -0,0 +1,20 @@
+package retrypolicies
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"time"
+)
+
+const UserDeleteAckUserServiceGroup = topics.UserDeleteAckTopic + "-user-service"
+
+// NewUserDeleteAckUserServicePolicy creates the retry policy of user deletion
+// acknowledgements read by the user service, which retries every few minutes
+// for a day
+func NewUserDeleteAckUserServicePolicy() kfka.RetryPolicy {
+	return kfka.NewRetryPolicy(
+		topics.UserDeleteAckTopic,
+		UserDeleteAckUserServiceGroup,
+		kfka.RetryStage{BackoffMin: time.Minute, BackoffMax: 2 * time.Minute, MaxTries: 1440},
+	)
+}
@@ -0,0 +1,36 @@
+package retrypolicies
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"time"
+)
+
+// Consumer groups of the services reading user key rotations and their
+// acknowledgements
+const (
+	UserKeyRotationNoteServiceGroup   = topics.UserKeyRotationTopic + "-note-service"
+	UserKeyRotationAckKeyServiceGroup = topics.UserKeyRotationAckTopic + "-key-service"
+)
+
+// NewUserKeyRotationNoteServicePolicy creates the retry policy of user key
+// rotations read by the note service, which retries every few minutes for
+// about ten hours
+func NewUserKeyRotationNoteServicePolicy() kfka.RetryPolicy {
+	return kfka.NewRetryPolicy(
+		topics.UserKeyRotationTopic,
+		UserKeyRotationNoteServiceGroup,
+		kfka.RetryStage{BackoffMin: time.Minute, BackoffMax: 2 * time.Minute, MaxTries: 600},
+	)
+}
+
+// NewUserKeyRotationAckKeyServicePolicy creates the retry policy of user key
+// rotation acknowledgements read by the key service, which retries every few
+// minutes for a day
+func NewUserKeyRotationAckKeyServicePolicy() kfka.RetryPolicy {
+	return kfka.NewRetryPolicy(
+		topics.UserKeyRotationAckTopic,
+		UserKeyRotationAckKeyServiceGroup,
+		kfka.RetryStage{BackoffMin: time.Minute, BackoffMax: 2 * time.Minute, MaxTries: 1440},
+	)
+}
@@ -14,21 +14,27 @@ type KafkaSender[T any] struct {
 	keyReader func(body T) ([]byte, error)
 }
 
-func (k KafkaSender[T]) Send(ctx context.Context, body T) error {
-	k.rwLock.RLock()
-	k.rwLock.RUnlock()
-	msgBytes, err := json.Marshal(body)
+func (k *KafkaSender[T]) Send(ctx context.Context, body T) error {
+	key, err := k.keyReader(body)
 	if err != nil {
 		return err
 	}
-	key, err := k.keyReader(body)
+	return k.SendWithKey(ctx, key, body)
+}
+
+// SendWithKey sends the body with the given key rather than the key read from
+// the body
+func (k *KafkaSender[T]) SendWithKey(ctx context.Context, key []byte, body T) error {
+	k.rwLock.RLock()
+	k.rwLock.RUnlock()
+	msgBytes, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
 	return k.writer.WriteMessages(ctx, kafka.Message{Key: key, Value: msgBytes})
 }
 
-func (k KafkaSender[T]) Close() error {
+func (k *KafkaSender[T]) Close() error {
 	k.rwLock.Lock()
 	k.rwLock.Unlock()
 	if k.writer != nil {
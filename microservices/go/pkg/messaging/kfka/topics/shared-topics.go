@@ -1,3 +1,15 @@
 package topics
 
 const UserChange1Topic = "user-change-1"
+
+const UserKeyRotationTopic = "user-key-rotation"
+
+const UserKeyRotationAckTopic = "user-key-rotation-ack"
+
+const UserDeleteAckTopic = "user-delete-ack"
+
+const UserDataExportTopic = "user-data-export"
+
+const UserDataExportPartTopic = "user-data-export-part"
+
+const DecoyVaultSeedTopic = "decoy-vault-seed"
@@ -1,12 +1,40 @@
 package commondtos
 
+// DefaultVaultId identifies the vault of users who never named one. It is
+// used wherever a vault ID is not provided.
+const DefaultVaultId = "default"
+
+// VaultIdOrDefault returns the vault ID or DefaultVaultId if it is blank
+func VaultIdOrDefault(vaultId string) string {
+	if vaultId == "" {
+		return DefaultVaultId
+	}
+	return vaultId
+}
+
 type UKeySessionDto struct {
 	ProxyKid      string `json:"proxyKid" binding:"required"`
 	Token         string `json:"token" binding:"required"`
 	UserId        string `json:"userId" binding:"required"`
+	VaultId       string `json:"vaultId"`
 	KeyVersion    int64  `json:"keyVersion"`
 	StartTime     int64  `json:"startTime"`     // In unix timestamp in milliseconds
 	DurationMilli int64  `json:"durationMilli"` // In milliseconds
+	// Binding identifies the client presenting the session. It is read from the
+	// headers the gateway sets on every request, never from the request body.
+	Binding ClientBindingDto `json:"-"`
+}
+
+// GetVaultId returns the vault the session unlocks. Sessions created before
+// vaults existed unlock the default vault.
+func (u UKeySessionDto) GetVaultId() string {
+	return VaultIdOrDefault(u.VaultId)
+}
+
+// ClientBindingDto identifies the client a key session was created for
+type ClientBindingDto struct {
+	DeviceId string `json:"deviceId"`
+	ClientIp string `json:"clientIp"`
 }
 
 type UKeySessionReqDto[T any] struct {
@@ -0,0 +1,77 @@
+package deadletterdtos
+
+import (
+	"encoding/json"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded"
+)
+
+// DeadLetterTopicDto is a dead letter topic along with the topics its messages
+// can be replayed to
+type DeadLetterTopicDto struct {
+	Topic     string `json:"topic"`
+	MainTopic string `json:"mainTopic"`
+	GroupId   string `json:"groupId"`
+	// RetryTopics are the topics of the retry stages, in order, where stage 1 is
+	// the first retry topic
+	RetryTopics []string `json:"retryTopics"`
+	Count       int64    `json:"count"`
+}
+
+// DeadLetterDto is a message that was dead lettered after running out of tries
+type DeadLetterDto struct {
+	embedded.BaseCRUDObject
+	Topic     string          `json:"topic"`
+	Partition int             `json:"partition"`
+	Offset    int64           `json:"offset"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	Tries     int             `json:"tries"`
+	// Errors are the latest errors processing the message, oldest first
+	Errors []DeadLetterErrorDto `json:"errors"`
+}
+
+type DeadLetterErrorDto struct {
+	Topic    string `json:"topic"`
+	Error    string `json:"error"`
+	FailedAt int64  `json:"failedAt"`
+}
+
+// DeadLetterSelectDto selects the given dead letters of a topic, or every dead
+// letter of the topic when All is true
+type DeadLetterSelectDto struct {
+	Topic string   `json:"topic" binding:"required"`
+	Ids   []string `json:"ids"`
+	All   bool     `json:"all"`
+}
+
+// DeadLetterReplayDto replays dead letters to the main topic when Stage is 0 or
+// to the topic of a retry stage, where stage 1 is the first retry topic. Dead
+// letters replayed to the main topic only reach the dead letter topic's group,
+// as they are sent to its first retry stage with no tries. Dead letters
+// replayed to a retry stage are given every try of the stage.
+type DeadLetterReplayDto struct {
+	DeadLetterSelectDto
+	Stage int `json:"stage"`
+}
+
+// Actions of a DeadLetterAuditDto
+const (
+	DeadLetterActionReplay = "replay"
+	DeadLetterActionPurge  = "purge"
+)
+
+// DeadLetterAuditDto records who replayed or purged dead letters
+type DeadLetterAuditDto struct {
+	embedded.BaseCRUDObject
+	Action string `json:"action"`
+	// Actor is the auth ID of the operator, or the user and host of the command
+	// line
+	Actor       string   `json:"actor"`
+	Topic       string   `json:"topic"`
+	TargetTopic string   `json:"targetTopic"`
+	All         bool     `json:"all"`
+	Ids         []string `json:"ids"`
+	Count       int64    `json:"count"`
+	// Error is why the action stopped before handling every dead letter
+	Error string `json:"error"`
+}
@@ -1,20 +1,108 @@
 package keydtos
 
 import (
+	"errors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/encodingutils"
+	"golang.org/x/exp/slices"
 )
 
+// ErrKeyVersionNotFound is returned when a payload was encrypted with a key
+// version that is neither the current nor a previous user key
+var ErrKeyVersionNotFound = errors.New("no user key found for the key version")
+
 type PasscodeCreateDto struct {
-	Passcode string `json:"passcode" binding:"required,alphanumunicode,min=4,max=20"`
+	// Passcode is checked against keyservice's passcode policy, the binding
+	// only bounds the work of deriving a key from it
+	Passcode  string `json:"passcode" binding:"required,max=1024"`
+	VaultName string `json:"vaultName" binding:"max=64"`
 }
 
 type PasscodeDto struct {
 	Passcode string `json:"passcode" binding:"required"`
+	// TotpCode is a TOTP or backup code, required if the user enabled TOTP
+	TotpCode string `json:"totpCode"`
+}
+
+// TotpEnrolmentDto has the TOTP secret for the user to add to their
+// authenticator app, both for manual entry and as a URI for a QR code
+type TotpEnrolmentDto struct {
+	Secret string `json:"secret"`
+	KeyUri string `json:"keyUri"`
+}
+
+type TotpCodeDto struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TotpBackupCodesDto has single use codes that can replace a TOTP code. They
+// are only returned once.
+type TotpBackupCodesDto struct {
+	BackupCodes []string `json:"backupCodes"`
+}
+
+// Silent actions that run when a vault is unlocked with its duress passcode
+const (
+	DuressSilentActionNone           = "none"
+	DuressSilentActionRevokeSessions = "revokeSessions"
+)
+
+// DuressPasscodeCreateDto registers a duress passcode for a vault. Unlocking
+// the vault with the duress passcode opens a decoy vault instead.
+type DuressPasscodeCreateDto struct {
+	Passcode string `json:"passcode" binding:"required"`
+	// TotpCode is a TOTP or backup code, required if the user enabled TOTP
+	TotpCode       string `json:"totpCode"`
+	DuressPasscode string `json:"duressPasscode" binding:"required,max=1024"`
+	// SilentAction is run without any sign to the client when the duress
+	// passcode is used
+	SilentAction string `json:"silentAction" binding:"omitempty,oneof=none revokeSessions"`
+	// DecoyNotes fill the decoy vault so it does not look unused
+	DecoyNotes []DecoyNoteDto `json:"decoyNotes" binding:"required,min=1,max=100,dive"`
+}
+
+// DecoyNoteDto is a note the user writes for their decoy vault
+type DecoyNoteDto struct {
+	Title string `json:"title" binding:"required,min=4,max=1000"`
+	Text  string `json:"text" binding:"max=40000"`
+}
+
+// DecoyVaultSeedEventDto is sent to fill a new decoy vault with the notes the
+// user wrote for it. Each note is encrypted by its own data encryption key
+// wrapped by the decoy vault's user key, so no plaintext leaves the key
+// service.
+type DecoyVaultSeedEventDto struct {
+	UserId     string               `json:"userId"`
+	VaultId    string               `json:"vaultId"`
+	KeyVersion int64                `json:"keyVersion"`
+	Notes      []DecoyNoteCipherDto `json:"notes"`
+}
+
+func (d DecoyVaultSeedEventDto) MessageKey() ([]byte, error) {
+	return []byte(d.UserId), nil
+}
+
+type DecoyNoteCipherDto struct {
+	TitleCipher []byte `json:"titleCipher"`
+	TextCipher  []byte `json:"textCipher"`
+	WrappedDek  []byte `json:"wrappedDek"`
+}
+
+type TotpDisableDto struct {
+	Passcode string `json:"passcode" binding:"required"`
+	// Code is a TOTP or backup code
+	Code string `json:"code" binding:"required"`
 }
 
 type UserKeyDto struct {
 	KeyBase64  string `json:"keyBase64"`
 	KeyVersion int64  `json:"keyVersion"`
+	// PreviousKeys are the keys of versions that are still being rotated out
+	PreviousKeys []PreviousUserKeyDto `json:"previousKeys"`
+	// Elevated is true if the session's user recently re-entered their
+	// passcode, allowing sensitive operations
+	Elevated bool `json:"elevated"`
 }
 
 func NewUserKeyDto(keyBytes []byte, keyVersion int64) UserKeyDto {
@@ -27,3 +115,290 @@ func NewUserKeyDto(keyBytes []byte, keyVersion int64) UserKeyDto {
 func (u UserKeyDto) GetKey() ([]byte, error) {
 	return encodingutils.DecodeBase64String(u.KeyBase64)
 }
+
+// GetKeyForVersion returns the key for the version provided, looking through
+// the previous keys if it is not the current version. False is returned if no
+// key for the version is found.
+func (u UserKeyDto) GetKeyForVersion(keyVersion int64) ([]byte, bool, error) {
+	if keyVersion == u.KeyVersion {
+		key, err := u.GetKey()
+		return key, err == nil, err
+	}
+	for _, previousKey := range u.PreviousKeys {
+		if previousKey.KeyVersion == keyVersion {
+			key, err := previousKey.GetKey()
+			return key, err == nil, err
+		}
+	}
+	return nil, false, nil
+}
+
+// HasKeyVersion returns true if the current or a previous key has the version
+func (u UserKeyDto) HasKeyVersion(keyVersion int64) bool {
+	if keyVersion == u.KeyVersion {
+		return true
+	}
+	for _, previousKey := range u.PreviousKeys {
+		if previousKey.KeyVersion == keyVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSessionInfo returns the key versions of the user key and whether the
+// session is elevated without the keys
+func (u UserKeyDto) GetSessionInfo() KeySessionInfoDto {
+	previousKeyVersions := make([]int64, 0, len(u.PreviousKeys))
+	for _, previousKey := range u.PreviousKeys {
+		previousKeyVersions = append(previousKeyVersions, previousKey.KeyVersion)
+	}
+	return KeySessionInfoDto{
+		KeyVersion:          u.KeyVersion,
+		PreviousKeyVersions: previousKeyVersions,
+		Elevated:            u.Elevated,
+	}
+}
+
+// EncryptPayloads encrypts the payloads with the current key. The key version
+// of each payload is ignored and set to the current key version.
+func (u UserKeyDto) EncryptPayloads(payloads []CipherPayloadDto) ([]CipherPayloadDto, error) {
+	key, err := u.GetKey()
+	if err != nil {
+		return nil, err
+	}
+	cipherPayloads := make([]CipherPayloadDto, 0, len(payloads))
+	for _, payload := range payloads {
+		cipherData, err := cipherutils.EncryptAES(key, payload.Data)
+		if err != nil {
+			return nil, err
+		}
+		cipherPayloads = append(cipherPayloads, NewCipherPayloadDto(cipherData, u.KeyVersion))
+	}
+	return cipherPayloads, nil
+}
+
+// DecryptPayloads decrypts each payload with the key of the payload's key
+// version. ErrKeyVersionNotFound is returned if a payload's key version is not
+// available.
+func (u UserKeyDto) DecryptPayloads(payloads []CipherPayloadDto) ([]CipherPayloadDto, error) {
+	plainPayloads := make([]CipherPayloadDto, 0, len(payloads))
+	for _, payload := range payloads {
+		key, found, err := u.GetKeyForVersion(payload.KeyVersion)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, ErrKeyVersionNotFound
+		}
+		plainData, err := cipherutils.DecryptAES(key, payload.Data)
+		if err != nil {
+			return nil, err
+		}
+		plainPayloads = append(plainPayloads, NewCipherPayloadDto(plainData, payload.KeyVersion))
+	}
+	return plainPayloads, nil
+}
+
+// KeySessionInfoDto describes the user key of a session without the keys
+type KeySessionInfoDto struct {
+	KeyVersion          int64   `json:"keyVersion"`
+	PreviousKeyVersions []int64 `json:"previousKeyVersions"`
+	Elevated            bool    `json:"elevated"`
+}
+
+// HasKeyVersion returns true if the current or a previous key has the version
+func (k KeySessionInfoDto) HasKeyVersion(keyVersion int64) bool {
+	return keyVersion == k.KeyVersion || slices.Contains(k.PreviousKeyVersions, keyVersion)
+}
+
+type PreviousUserKeyDto struct {
+	KeyBase64  string `json:"keyBase64"`
+	KeyVersion int64  `json:"keyVersion"`
+}
+
+func NewPreviousUserKeyDto(keyBytes []byte, keyVersion int64) PreviousUserKeyDto {
+	return PreviousUserKeyDto{
+		KeyBase64:  encodingutils.EncodeBase64String(keyBytes),
+		KeyVersion: keyVersion,
+	}
+}
+
+func (p PreviousUserKeyDto) GetKey() ([]byte, error) {
+	return encodingutils.DecodeBase64String(p.KeyBase64)
+}
+
+// CipherPayloadDto is a plaintext or ciphertext payload. The key version is the
+// version of the user key the ciphertext was encrypted with.
+type CipherPayloadDto struct {
+	Data       []byte `json:"data"`
+	KeyVersion int64  `json:"keyVersion"`
+}
+
+func NewCipherPayloadDto(data []byte, keyVersion int64) CipherPayloadDto {
+	return CipherPayloadDto{Data: data, KeyVersion: keyVersion}
+}
+
+// Services that re-encrypt their data when a user key is rotated and must
+// acknowledge the rotation before the previous key version is retired
+const (
+	KeyRotationConsumerNoteService = "note-service"
+)
+
+// UserKeyRotationEventDto is sent when a user key is rotated. Each consumer
+// re-encrypts the vault's data with its own rewrap grant.
+type UserKeyRotationEventDto struct {
+	UserId             string           `json:"userId"`
+	VaultId            string           `json:"vaultId"`
+	KeyVersion         int64            `json:"keyVersion"`
+	PreviousKeyVersion int64            `json:"previousKeyVersion"`
+	Grants             []RewrapGrantDto `json:"grants"`
+}
+
+func (u UserKeyRotationEventDto) MessageKey() ([]byte, error) {
+	return []byte(u.UserId), nil
+}
+
+// GetGrant returns the rewrap grant of a consumer
+func (u UserKeyRotationEventDto) GetGrant(consumer string) (RewrapGrantDto, bool) {
+	for _, grant := range u.Grants {
+		if grant.Consumer == consumer {
+			return grant, true
+		}
+	}
+	return RewrapGrantDto{}, false
+}
+
+// RewrapGrantDto lets a consumer re-encrypt ciphertexts of previous key
+// versions with the current user key. It never gives access to plaintexts or
+// keys, expires shortly and is revoked once the consumer acknowledges the
+// rotation.
+type RewrapGrantDto struct {
+	Consumer string `json:"consumer"`
+	GrantId  string `json:"grantId"`
+	Token    string `json:"token"`
+}
+
+// UserKeyRotationAckDto is sent by a consumer once it has finished
+// re-encrypting a user's data with the new key version
+type UserKeyRotationAckDto struct {
+	UserId     string `json:"userId"`
+	VaultId    string `json:"vaultId"`
+	KeyVersion int64  `json:"keyVersion"`
+	Consumer   string `json:"consumer"`
+	GrantId    string `json:"grantId"`
+}
+
+func (u UserKeyRotationAckDto) MessageKey() ([]byte, error) {
+	return []byte(u.UserId), nil
+}
+
+// SrpCreateParamsDto has the salts and KDF parameters a client derives its
+// passcode key and SRP verifier with when creating a user key. The client must
+// check the passcode against PasscodePolicy as the service never sees it.
+type SrpCreateParamsDto struct {
+	KdfSalt        []byte                `json:"kdfSalt"`
+	KdfParams      cipherutils.KDFParams `json:"kdfParams"`
+	SrpSalt        []byte                `json:"srpSalt"`
+	PasscodePolicy PasscodePolicyDto     `json:"passcodePolicy"`
+}
+
+// PasscodePolicyDto is the set of rules a new passcode must satisfy. The
+// breached passcode list is only checked for passcodes sent to the service.
+type PasscodePolicyDto struct {
+	MinLength        int  `json:"minLength"`
+	MaxLength        int  `json:"maxLength"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireSymbol    bool `json:"requireSymbol"`
+	MinStrengthScore int  `json:"minStrengthScore"`
+}
+
+// SrpPasscodeCreateDto creates a user key verified with SRP. The client
+// generates the user key and derives its passcode key from the passcode with
+// the KDF salt and parameters of SrpCreateParamsDto. Neither the passcode nor
+// the passcode key is sent, only the verifier and the user key wrapped by the
+// passcode key.
+type SrpPasscodeCreateDto struct {
+	KdfSalt    []byte `json:"kdfSalt" binding:"required"`
+	SrpSalt    []byte `json:"srpSalt" binding:"required"`
+	Verifier   []byte `json:"verifier" binding:"required"`
+	WrappedKey []byte `json:"wrappedKey" binding:"required"`
+	// Key is the user key, which key sessions hold once the vault is unlocked.
+	// Its hash verifies the key a client unwraps on later unlocks.
+	Key       []byte `json:"key" binding:"required"`
+	VaultName string `json:"vaultName" binding:"max=64"`
+}
+
+// SrpChallengeDto is the first round of an SRP unlock. The client derives its
+// passcode key with the KDF salt and parameters and answers the challenge with
+// the server public value.
+type SrpChallengeDto struct {
+	ChallengeId  string                `json:"challengeId"`
+	KdfSalt      []byte                `json:"kdfSalt"`
+	KdfParams    cipherutils.KDFParams `json:"kdfParams"`
+	SrpSalt      []byte                `json:"srpSalt"`
+	ServerPublic []byte                `json:"serverPublic"`
+}
+
+// SrpProofDto is the second round of an SRP unlock
+type SrpProofDto struct {
+	ChallengeId  string `json:"challengeId" binding:"required"`
+	ClientPublic []byte `json:"clientPublic" binding:"required"`
+	ClientProof  []byte `json:"clientProof" binding:"required"`
+	// TotpCode is a TOTP or backup code, required if the user enabled TOTP
+	TotpCode string `json:"totpCode"`
+}
+
+// SrpUnlockDto answers a verified SRP proof. The client unwraps the user key
+// with its passcode key and sends it back encrypted with the SRP session key
+// to create a key session.
+type SrpUnlockDto struct {
+	// ServerProof proves to the client that keyservice holds the verifier
+	ServerProof []byte `json:"serverProof"`
+	// WrappedKeyCipher is the user key wrapped by the passcode key, encrypted
+	// with the SRP session key. It is empty for legacy generators where the
+	// passcode key is the user key itself.
+	WrappedKeyCipher []byte `json:"wrappedKeyCipher"`
+}
+
+// SrpKeySessionCreateDto is the last round of an SRP unlock
+type SrpKeySessionCreateDto struct {
+	// ChallengeId is the challenge whose proof was verified
+	ChallengeId string `json:"challengeId" binding:"required"`
+	// KeyCipher is the user key encrypted with the SRP session key
+	KeyCipher []byte `json:"keyCipher" binding:"required"`
+}
+
+// SessionElevationDto is returned when a key session is elevated
+type SessionElevationDto struct {
+	// ExpiresAt is when the elevation expires in unix milliseconds
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// VaultDto describes one of a user's vaults, each with its own user key and
+// passcode
+type VaultDto struct {
+	VaultId string `json:"vaultId"`
+	Name    string `json:"name"`
+}
+
+// VaultExportDto is the non-secret metadata of a vault in a user's data export
+type VaultExportDto struct {
+	VaultDto
+	KeyVersion  int64 `json:"keyVersion"`
+	SrpEnabled  bool  `json:"srpEnabled"`
+	TotpEnabled bool  `json:"totpEnabled"`
+	// SessionsRevokedAt is when key sessions of the vault were last revoked in
+	// unix milliseconds
+	SessionsRevokedAt int64 `json:"sessionsRevokedAt"`
+	embedded.BaseTimestamp
+}
+
+// UserKeyDataExportDto is the key service's part of a user's data export. Key
+// sessions are short-lived and stored encrypted by their ID, so they are not
+// exported.
+type UserKeyDataExportDto struct {
+	Vaults []VaultExportDto `json:"vaults"`
+}
@@ -0,0 +1,45 @@
+package keydtos_test
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestUserKeyPayloadEncryption(t *testing.T) {
+	cv.Convey("When a user key has a current and a previous version", t, func() {
+		key, err := cipherutils.GenerateRandomKeyAES()
+		cv.So(err, cv.ShouldBeNil)
+		previousKey, err := cipherutils.GenerateRandomKeyAES()
+		cv.So(err, cv.ShouldBeNil)
+		keyDto := keydtos.NewUserKeyDto(key, 1)
+		keyDto.PreviousKeys = []keydtos.PreviousUserKeyDto{keydtos.NewPreviousUserKeyDto(previousKey, 0)}
+		message := []byte("Hello world")
+
+		cv.Convey("Expect encrypted payloads have the current key version and decrypt", func() {
+			cipherPayloads, err := keyDto.EncryptPayloads([]keydtos.CipherPayloadDto{{Data: message, KeyVersion: 0}})
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(cipherPayloads[0].KeyVersion, cv.ShouldEqual, 1)
+
+			plainPayloads, err := keyDto.DecryptPayloads(cipherPayloads)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(plainPayloads[0].Data, cv.ShouldResemble, message)
+		})
+		cv.Convey("Expect payloads of the previous version decrypt with the previous key", func() {
+			cipherData, err := cipherutils.EncryptAES(previousKey, message)
+			cv.So(err, cv.ShouldBeNil)
+
+			plainPayloads, err := keyDto.DecryptPayloads([]keydtos.CipherPayloadDto{{Data: cipherData, KeyVersion: 0}})
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(plainPayloads[0].Data, cv.ShouldResemble, message)
+		})
+		cv.Convey("Expect payloads of an unknown version fail to decrypt", func() {
+			cipherData, err := cipherutils.EncryptAES(key, message)
+			cv.So(err, cv.ShouldBeNil)
+
+			_, err = keyDto.DecryptPayloads([]keydtos.CipherPayloadDto{{Data: cipherData, KeyVersion: 5}})
+			cv.So(err, cv.ShouldEqual, keydtos.ErrKeyVersionNotFound)
+		})
+	})
+}
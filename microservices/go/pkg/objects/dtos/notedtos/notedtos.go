@@ -45,3 +45,16 @@ type NoteReadDto struct {
 type NoteIdDto struct {
 	embedded.BaseRequiredId
 }
+
+// NoteExportDto is the non-secret metadata of a note in a user's data export.
+// The title and text are end-to-end encrypted so they are not exported.
+type NoteExportDto struct {
+	embedded.BaseCRUDObject
+	VaultId    string `json:"vaultId"`
+	KeyVersion int64  `json:"keyVersion"`
+}
+
+// NoteDataExportDto is the note service's part of a user's data export
+type NoteDataExportDto struct {
+	Notes []NoteExportDto `json:"notes"`
+}
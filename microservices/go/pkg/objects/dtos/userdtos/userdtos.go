@@ -1,8 +1,11 @@
 package userdtos
 
 import (
+	"encoding/json"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded/embeddeduser"
+	"mime/multipart"
 )
 
 type UserIdentityDto struct {
@@ -33,6 +36,149 @@ func (u UserChangeEventDto) MessageKey() ([]byte, error) {
 	return []byte(u.Id), nil
 }
 
+// UserChangeFeedDto is a published user change along with the cursor to resume
+// watching changes after it
+type UserChangeFeedDto struct {
+	UserChangeEventDto
+	Sequence int64  `json:"sequence"`
+	Cursor   string `json:"cursor"`
+}
+
+// Services that delete the data of a deleted user and acknowledge the deletion
+const (
+	UserDeleteParticipantKeyService  = "keyservice"
+	UserDeleteParticipantNoteService = "noteservice"
+)
+
+// UserDeleteParticipants are the services a user deletion waits for
+var UserDeleteParticipants = []string{UserDeleteParticipantKeyService, UserDeleteParticipantNoteService}
+
+// UserDeleteAckDto is the reply of a service that handled a user deletion
+type UserDeleteAckDto struct {
+	UserId      string `json:"userId"`
+	Participant string `json:"participant"`
+	Succeeded   bool   `json:"succeeded"`
+	// Error describes why the service failed to delete the user's data
+	Error string `json:"error"`
+}
+
+func (u UserDeleteAckDto) MessageKey() ([]byte, error) {
+	return []byte(u.UserId), nil
+}
+
+// Statuses of a user deletion and of each of its participants
+const (
+	UserDeletionPending   = "pending"
+	UserDeletionFailed    = "failed"
+	UserDeletionCompleted = "completed"
+)
+
+type UserDeletionParticipantDto struct {
+	Participant string `json:"participant"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	UpdatedAt   int64  `json:"updatedAt"` // In unix timestamp in milliseconds
+}
+
+type UserDeletionDto struct {
+	UserId       string                       `json:"userId"`
+	UserName     string                       `json:"userName"`
+	Status       string                       `json:"status"`
+	Participants []UserDeletionParticipantDto `json:"participants"`
+	// IdentityProviderDeleted is true once the user is deleted from the auth
+	// server after every participant completed
+	IdentityProviderDeleted bool `json:"identityProviderDeleted"`
+	embedded.BaseTimestamp
+}
+
+// Services that contribute the data they hold about a user to a data export
+const (
+	UserDataExportParticipantKeyService  = "keyservice"
+	UserDataExportParticipantNoteService = "noteservice"
+	UserDataExportParticipantUIService   = "uiservice"
+)
+
+// UserDataExportParticipants are the services a data export waits for
+var UserDataExportParticipants = []string{
+	UserDataExportParticipantKeyService,
+	UserDataExportParticipantNoteService,
+	UserDataExportParticipantUIService,
+}
+
+// UserDataExportRequestDto asks every participant for the data it holds about
+// a user
+type UserDataExportRequestDto struct {
+	ExportId string `json:"exportId"`
+	UserId   string `json:"userId"`
+	// AuthId is the identity provider ID of the user, which services that key
+	// data by it such as the web sessions of the UI service look up
+	AuthId string `json:"authId"`
+}
+
+func (u UserDataExportRequestDto) MessageKey() ([]byte, error) {
+	return []byte(u.UserId), nil
+}
+
+// WebSessionExportDto is a web session of a user in the user's data export.
+// Session IDs and access tokens are secrets, so only when the session expires
+// is exported.
+type WebSessionExportDto struct {
+	ExpiresAt int64 `json:"expiresAt"` // In unix timestamp in milliseconds
+}
+
+// WebSessionDataExportDto is the UI service's part of a user's data export
+type WebSessionDataExportDto struct {
+	Sessions []WebSessionExportDto `json:"sessions"`
+}
+
+// UserDataExportPartDto is the reply of a participant to a data export request
+type UserDataExportPartDto struct {
+	ExportId    string `json:"exportId"`
+	UserId      string `json:"userId"`
+	Participant string `json:"participant"`
+	Succeeded   bool   `json:"succeeded"`
+	// Error describes why the service failed to export the user's data
+	Error string `json:"error"`
+	// Data is the JSON document of the non-secret data the service holds about
+	// the user
+	Data json.RawMessage `json:"data"`
+}
+
+func (u UserDataExportPartDto) MessageKey() ([]byte, error) {
+	return []byte(u.UserId), nil
+}
+
+// Statuses of a data export and of each of its participants
+const (
+	UserDataExportPending   = "pending"
+	UserDataExportFailed    = "failed"
+	UserDataExportCompleted = "completed"
+)
+
+type UserDataExportParticipantDto struct {
+	Participant string `json:"participant"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	UpdatedAt   int64  `json:"updatedAt"` // In unix timestamp in milliseconds
+}
+
+type UserDataExportDto struct {
+	Id           string                         `json:"id"`
+	Status       string                         `json:"status"`
+	Participants []UserDataExportParticipantDto `json:"participants"`
+	ExpiresAt    int64                          `json:"expiresAt"` // In unix timestamp in milliseconds
+	embedded.BaseTimestamp
+}
+
+// UserDataExportBundleDto is the downloadable copy of the data held about a
+// user, with the data of each participant keyed by the participant
+type UserDataExportBundleDto struct {
+	ExportId   string                     `json:"exportId"`
+	ExportedAt int64                      `json:"exportedAt"` // In unix timestamp in milliseconds
+	User       UserReadDto                `json:"user"`
+	Services   map[string]json.RawMessage `json:"services"`
+}
+
 type UserChangeEventResponseDto struct {
 	Discarded bool
 }
@@ -40,3 +186,53 @@ type UserChangeEventResponseDto struct {
 type UserSaveDto struct {
 	embeddeduser.BaseUserCommon
 }
+
+type UserProfileSaveDto struct {
+	embeddeduser.BaseUserProfile
+}
+
+type UserPreferencesSaveDto struct {
+	embeddeduser.UserPreferences
+}
+
+// UserAvatarSaveDto is a multipart form with the avatar image
+type UserAvatarSaveDto struct {
+	Avatar *multipart.FileHeader `form:"avatar" binding:"required"`
+}
+
+// UserAvatarDto is an avatar image, which is served as is rather than as JSON
+type UserAvatarDto struct {
+	ContentType string
+	Data        []byte
+}
+
+// UserAdminDto is a user as operators see it
+type UserAdminDto struct {
+	embeddeduser.BaseUserPublicDto
+	embeddeduser.BaseUserAuthId
+	commondtos.ExistsDto
+	ChangeSequence int64 `json:"changeSequence"`
+}
+
+// UserDistributionDto describes how far the change events of a user have been
+// published to other services. Sent events are only kept for a while, so
+// SentSequence and LastSentAt are 0 once they expire.
+type UserDistributionDto struct {
+	LatestSequence int64 `json:"latestSequence"`
+	SentSequence   int64 `json:"sentSequence"`
+	PendingEvents  int64 `json:"pendingEvents"`
+	LastSentAt     int64 `json:"lastSentAt"` // In unix timestamp in milliseconds
+}
+
+type UserDeletionStateDto struct {
+	commondtos.ExistsDto
+	UserDeletionDto
+}
+
+// UserAdminDetailsDto is what operators know about a user, which may already
+// be deleted
+type UserAdminDetailsDto struct {
+	User         UserAdminDto         `json:"user"`
+	Distribution UserDistributionDto  `json:"distribution"`
+	Deletion     UserDeletionStateDto `json:"deletion"`
+}
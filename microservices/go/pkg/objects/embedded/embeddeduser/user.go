@@ -1,17 +1,70 @@
 package embeddeduser
 
-import "github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded"
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded"
+)
 
 type BaseUserCommon struct {
 	UserName    string `json:"userName" binding:"required,alphanumunicode,min=4,max=255"`
 	DisplayName string `json:"displayName" binding:"required,min=4,max=255"`
 }
 
+// BaseUserProfile is optional information users share about themselves
+type BaseUserProfile struct {
+	// Timezone is an IANA time zone name such as America/New_York
+	Timezone string `json:"timezone" binding:"max=64"`
+	// Locale is a BCP 47 language tag such as en-US
+	Locale string `json:"locale" binding:"max=35"`
+	Bio    string `json:"bio" binding:"max=1000"`
+}
+
+// Themes a user can choose for the UI
+const (
+	ThemeSystem = "system"
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+)
+
+// UserPreferences customize how the apps behave for a user. Unset
+// preferences fall back to the app defaults.
+type UserPreferences struct {
+	// DefaultNoteSort sorts pages of notes requested without a sort
+	DefaultNoteSort NoteSortPreference `json:"defaultNoteSort"`
+	// KeySessionMinutes shortens key sessions below the configured duration
+	KeySessionMinutes int64  `json:"keySessionMinutes" binding:"min=0,max=1440"`
+	Theme             string `json:"theme" binding:"omitempty,oneof=system light dark"`
+}
+
+type NoteSortPreference struct {
+	Field     string               `json:"field" binding:"omitempty,oneof=createdAt updatedAt"`
+	Direction pagination.Direction `json:"direction" binding:"omitempty,oneof=asc desc"`
+}
+
+func (n NoteSortPreference) IsSet() bool {
+	return n.Field != ""
+}
+
+func (n NoteSortPreference) ToSortField() pagination.SortField {
+	direction := n.Direction
+	if direction == "" {
+		direction = pagination.Descending
+	}
+	return pagination.NewSortField(n.Field, direction)
+}
+
 type BaseUserAuthId struct {
 	AuthId string `json:"authId"`
 }
 
 type BaseUserPublicDto struct {
 	BaseUserCommon
+	BaseUserProfile
+	Preferences UserPreferences `json:"preferences"`
+	// AvatarId identifies the user's avatar image and is empty if the user has
+	// no avatar. A new avatar gets a new ID.
+	AvatarId string `json:"avatarId"`
+	// Suspended users are rejected by every service until they are reinstated
+	Suspended bool `json:"suspended"`
 	embedded.BaseCRUDObject
 }
@@ -0,0 +1,44 @@
+package embeddeduser_test
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded/embeddeduser"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestNoteSortPreference(t *testing.T) {
+	cv.Convey("When the default note sort is not set", t, func() {
+		preference := embeddeduser.NoteSortPreference{}
+
+		cv.Convey("Expect it is reported as unset", func() {
+			cv.So(preference.IsSet(), cv.ShouldBeFalse)
+		})
+	})
+	cv.Convey("When the default note sort has a field without a direction", t, func() {
+		preference := embeddeduser.NoteSortPreference{Field: pagination.SortFieldUpdatedAt}
+
+		cv.Convey("Expect notes are sorted by the field in descending order", func() {
+			cv.So(preference.IsSet(), cv.ShouldBeTrue)
+			cv.So(
+				preference.ToSortField(),
+				cv.ShouldResemble,
+				pagination.NewSortField(pagination.SortFieldUpdatedAt, pagination.Descending),
+			)
+		})
+	})
+	cv.Convey("When the default note sort has a field and a direction", t, func() {
+		preference := embeddeduser.NoteSortPreference{
+			Field:     pagination.SortFieldCreatedAt,
+			Direction: pagination.Ascending,
+		}
+
+		cv.Convey("Expect notes are sorted by the field in the direction", func() {
+			cv.So(
+				preference.ToSortField(),
+				cv.ShouldResemble,
+				pagination.NewSortField(pagination.SortFieldCreatedAt, pagination.Ascending),
+			)
+		})
+	})
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/go-redis/redis/v9"
+	"github.com/google/uuid"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/dshandlers"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/kvstoreutils"
+	"os"
+	"time"
+)
+
+// Lease is a lock on a job that is held by one replica at a time and expires
+// unless it is renewed
+type Lease interface {
+	// Acquire takes the lease if no replica holds it, returning true if this
+	// replica now holds the lease
+	Acquire(ctx context.Context) (bool, error)
+	// Renew extends the lease, returning false if the lease was lost
+	Renew(ctx context.Context) (bool, error)
+	// Release gives up the lease so another replica can take it right away
+	Release(ctx context.Context) error
+}
+
+// LeaseManager creates the leases of cluster singleton jobs
+type LeaseManager interface {
+	// NewLease creates a lease for the job with the given name, held by this
+	// replica once acquired
+	NewLease(name string) Lease
+	// GetLeaseTtl returns how long a lease is held without being renewed
+	GetLeaseTtl() time.Duration
+}
+
+// renewLeaseScript extends a lease only if the token still holds it
+var renewLeaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaseScript deletes a lease only if the token still holds it
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+type redisLease struct {
+	redisClient *redis.Client
+	key         string
+	token       string
+	ttl         time.Duration
+}
+
+func (r redisLease) Acquire(ctx context.Context) (bool, error) {
+	return r.redisClient.SetNX(ctx, r.key, r.token, r.ttl).Result()
+}
+
+func (r redisLease) Renew(ctx context.Context) (bool, error) {
+	res, err := renewLeaseScript.Run(ctx, r.redisClient, []string{r.key}, r.token, r.ttl.Milliseconds()).Int()
+	return res == 1, err
+}
+
+func (r redisLease) Release(ctx context.Context) error {
+	return releaseLeaseScript.Run(ctx, r.redisClient, []string{r.key}, r.token).Err()
+}
+
+// RedisLeaseManagerImpl keeps leases in Redis. Each lease is a key holding a
+// token unique to the replica, so only the replica holding the lease can renew
+// or release it.
+type RedisLeaseManagerImpl struct {
+	redisDBHandler *dshandlers.RedisDBHandler
+	schedulerConf  conf.SchedulerConf
+}
+
+func (r RedisLeaseManagerImpl) NewLease(name string) Lease {
+	hostname, _ := os.Hostname()
+	return &redisLease{
+		redisClient: r.redisDBHandler.GetRedisClient(),
+		key:         kvstoreutils.CombineKeySections("scheduler", "lease", name),
+		token:       kvstoreutils.CombineKeySections(hostname, uuid.New().String()),
+		ttl:         r.schedulerConf.GetLeaseTtl(),
+	}
+}
+
+func (r RedisLeaseManagerImpl) GetLeaseTtl() time.Duration {
+	return r.schedulerConf.GetLeaseTtl()
+}
+
+func NewRedisLeaseManagerImpl(
+	redisDBHandler *dshandlers.RedisDBHandler,
+	schedulerConf conf.SchedulerConf,
+) *RedisLeaseManagerImpl {
+	return &RedisLeaseManagerImpl{redisDBHandler: redisDBHandler, schedulerConf: schedulerConf}
+}
@@ -0,0 +1,175 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"sync"
+	"time"
+)
+
+// Job is a task run by a Scheduler. The context is cancelled when the job has
+// to stop, such as when the scheduler closes or when a cluster singleton job
+// loses its lease or fails to renew it.
+type Job func(ctx context.Context)
+
+type JobSettings struct {
+	// Name identifies the job across replicas
+	Name string
+	// Interval is the time between runs, a job never overlaps with itself in
+	// one replica
+	Interval time.Duration
+	// ClusterSingleton jobs run on only one replica at a time, which is the
+	// replica holding the job's lease
+	ClusterSingleton bool
+}
+
+// Scheduler runs jobs at fixed intervals
+type Scheduler interface {
+	lifecycle.Closable
+	// Schedule adds a job to be run once the scheduler starts
+	Schedule(settings JobSettings, job Job)
+	// StartBlocking runs the scheduled jobs until the scheduler is closed
+	StartBlocking()
+}
+
+type scheduledJob struct {
+	settings JobSettings
+	job      Job
+}
+
+type SchedulerImpl struct {
+	leaseManager LeaseManager
+	ctx          context.Context
+	cancel       context.CancelFunc
+	jobs         []scheduledJob
+	wg           sync.WaitGroup
+}
+
+func (s *SchedulerImpl) Schedule(settings JobSettings, job Job) {
+	s.jobs = append(s.jobs, scheduledJob{settings: settings, job: job})
+}
+
+func (s *SchedulerImpl) StartBlocking() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j scheduledJob) {
+			defer s.wg.Done()
+			if j.settings.ClusterSingleton {
+				s.runAsLeader(s.ctx, j)
+			} else {
+				runEvery(s.ctx, j)
+			}
+		}(j)
+	}
+	s.wg.Wait()
+}
+
+// Close stops the jobs and waits for them to finish, releasing the leases of
+// cluster singleton jobs so another replica takes over right away
+func (s *SchedulerImpl) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// runAsLeader runs the job while this replica holds the job's lease, trying to
+// acquire the lease again whenever it is not held
+func (s *SchedulerImpl) runAsLeader(ctx context.Context, j scheduledJob) {
+	log := logger.Log.WithField("Job", j.settings.Name)
+	lease := s.leaseManager.NewLease(j.settings.Name)
+	leaseTtl := s.leaseManager.GetLeaseTtl()
+	acquireTicker := time.NewTicker(leaseTtl / 3)
+	defer acquireTicker.Stop()
+	for {
+		if acquired, err := lease.Acquire(ctx); err != nil {
+			log.WithError(err).Warn("Failed to acquire job lease")
+		} else if acquired {
+			log.Info("Acquired job lease, running job")
+			s.lead(ctx, lease, leaseTtl, j)
+			releaseCtx, cancelRelease := context.WithTimeout(context.Background(), leaseTtl/3)
+			if err := lease.Release(releaseCtx); err != nil {
+				log.WithError(err).Warn("Failed to release job lease")
+			}
+			cancelRelease()
+			log.Info("Handed over job lease")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-acquireTicker.C:
+		}
+	}
+}
+
+// lead runs the job until the lease is lost or ctx is done
+func (s *SchedulerImpl) lead(ctx context.Context, lease Lease, leaseTtl time.Duration, j scheduledJob) {
+	leaderCtx, cancelLeader := context.WithCancel(ctx)
+	defer cancelLeader()
+	renewDone := make(chan bool)
+	go func() {
+		defer close(renewDone)
+		renewLease(leaderCtx, cancelLeader, lease, leaseTtl, j.settings.Name)
+	}()
+	runEvery(leaderCtx, j)
+	<-renewDone
+}
+
+// renewLease renews the lease until leaderCtx is done, cancelling leaderCtx
+// once renewing the lease fails, as the lease may then expire and be acquired
+// by another replica while the job still runs. Each renewal is given until
+// the next one to finish.
+func renewLease(
+	leaderCtx context.Context,
+	cancelLeader context.CancelFunc,
+	lease Lease,
+	leaseTtl time.Duration,
+	name string,
+) {
+	defer cancelLeader()
+	log := logger.Log.WithField("Job", name)
+	renewInterval := leaseTtl / 3
+	renewTicker := time.NewTicker(renewInterval)
+	defer renewTicker.Stop()
+	for {
+		select {
+		case <-leaderCtx.Done():
+			return
+		case <-renewTicker.C:
+		}
+		renewCtx, cancelRenew := context.WithTimeout(leaderCtx, renewInterval)
+		renewed, err := lease.Renew(renewCtx)
+		cancelRenew()
+		if leaderCtx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.WithError(err).Error("Failed to renew job lease, stopping job")
+			return
+		}
+		if !renewed {
+			log.Warn("Lost job lease, stopping job")
+			return
+		}
+	}
+}
+
+// runEvery runs the job right away and then at every interval until ctx is done
+func runEvery(ctx context.Context, j scheduledJob) {
+	ticker := time.NewTicker(j.settings.Interval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		j.job(ctx)
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+func NewSchedulerImpl(leaseManager LeaseManager) *SchedulerImpl {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &SchedulerImpl{leaseManager: leaseManager, ctx: ctx, cancel: cancel}
+	lifecycle.RegisterClosable(s)
+	return s
+}
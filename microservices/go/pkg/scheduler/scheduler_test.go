@@ -0,0 +1,139 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/scheduler"
+	cv "github.com/smartystreets/goconvey/convey"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testLeaseTtl = 60 * time.Millisecond
+const testJobName = "test-job"
+
+// memLeaseManager keeps leases in memory, shared by every scheduler using it,
+// imitating replicas sharing a lease store. Leases do not expire.
+type memLeaseManager struct {
+	mu       sync.Mutex
+	holders  map[string]*memLease
+	renewErr error
+}
+
+func (m *memLeaseManager) NewLease(name string) scheduler.Lease {
+	return &memLease{manager: m, name: name}
+}
+
+func (m *memLeaseManager) GetLeaseTtl() time.Duration { return testLeaseTtl }
+
+// steal takes the lease from its holder, as if it expired and another replica
+// acquired it
+func (m *memLeaseManager) steal(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.holders[name] = &memLease{manager: m, name: name}
+}
+
+// failRenewals makes renewing leases fail, as if the lease store could not be
+// reached
+func (m *memLeaseManager) failRenewals() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.renewErr = errors.New("lease store unreachable")
+}
+
+type memLease struct {
+	manager *memLeaseManager
+	name    string
+}
+
+func (l *memLease) Acquire(context.Context) (bool, error) {
+	l.manager.mu.Lock()
+	defer l.manager.mu.Unlock()
+	if _, ok := l.manager.holders[l.name]; ok {
+		return false, nil
+	}
+	l.manager.holders[l.name] = l
+	return true, nil
+}
+
+func (l *memLease) Renew(context.Context) (bool, error) {
+	l.manager.mu.Lock()
+	defer l.manager.mu.Unlock()
+	if l.manager.renewErr != nil {
+		return false, l.manager.renewErr
+	}
+	return l.manager.holders[l.name] == l, nil
+}
+
+func (l *memLease) Release(context.Context) error {
+	l.manager.mu.Lock()
+	defer l.manager.mu.Unlock()
+	if l.manager.holders[l.name] == l {
+		delete(l.manager.holders, l.name)
+	}
+	return nil
+}
+
+// replica is a scheduler running a cluster singleton job that counts its runs
+type replica struct {
+	scheduler *scheduler.SchedulerImpl
+	runs      int64
+	jobCtx    atomic.Value
+}
+
+func startReplica(leaseManager scheduler.LeaseManager) *replica {
+	r := &replica{scheduler: scheduler.NewSchedulerImpl(leaseManager)}
+	settings := scheduler.JobSettings{Name: testJobName, Interval: 5 * time.Millisecond, ClusterSingleton: true}
+	r.scheduler.Schedule(settings, func(ctx context.Context) {
+		r.jobCtx.Store(ctx)
+		atomic.AddInt64(&r.runs, 1)
+	})
+	go r.scheduler.StartBlocking()
+	return r
+}
+
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestClusterSingletonJob(t *testing.T) {
+	cv.Convey("When two replicas schedule the same cluster singleton job", t, func() {
+		leaseManager := &memLeaseManager{holders: map[string]*memLease{}}
+		first := startReplica(leaseManager)
+		cv.So(eventually(func() bool { return atomic.LoadInt64(&first.runs) > 0 }), cv.ShouldBeTrue)
+		second := startReplica(leaseManager)
+		defer func() {
+			_ = first.scheduler.Close()
+			_ = second.scheduler.Close()
+		}()
+
+		cv.Convey("Expect only the replica holding the lease runs the job", func() {
+			time.Sleep(2 * testLeaseTtl)
+			cv.So(atomic.LoadInt64(&second.runs), cv.ShouldEqual, 0)
+		})
+		cv.Convey("Expect the other replica takes over when the leader closes", func() {
+			cv.So(first.scheduler.Close(), cv.ShouldBeNil)
+			cv.So(eventually(func() bool { return atomic.LoadInt64(&second.runs) > 0 }), cv.ShouldBeTrue)
+		})
+		cv.Convey("Expect losing the lease cancels the job's context", func() {
+			jobCtx := first.jobCtx.Load().(context.Context)
+			leaseManager.steal(testJobName)
+			cv.So(eventually(func() bool { return jobCtx.Err() != nil }), cv.ShouldBeTrue)
+		})
+		cv.Convey("Expect failing to renew the lease cancels the job's context", func() {
+			jobCtx := first.jobCtx.Load().(context.Context)
+			leaseManager.failRenewals()
+			cv.So(eventually(func() bool { return jobCtx.Err() != nil }), cv.ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,9 @@
+package security
+
+// AuthorityAdminKeys allows an identity to administer keys and secrets managed
+// by the key service
+const AuthorityAdminKeys = "admin:keys"
+
+// AuthorityAdminUsers allows an identity to administer users managed by the
+// user service
+const AuthorityAdminUsers = "admin:users"
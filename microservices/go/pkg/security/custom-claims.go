@@ -1,20 +1,83 @@
 package security
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
 
-// Auth0CustomClaims contains custom claims from an Auth0 token
-type Auth0CustomClaims struct {
-	Scope       string   `json:"scope"`
-	Permissions []string `json:"permissions"`
+// Claims are the claims of a token by name
+type Claims map[string]any
+
+// Get returns the claim at a dot separated path, such as realm_access.roles for
+// the roles nested in the realm_access claim
+func (c Claims) Get(path string) (any, bool) {
+	var claim any = map[string]any(c)
+	for _, name := range strings.Split(path, ".") {
+		nestedClaims, ok := claim.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if claim, ok = nestedClaims[name]; !ok {
+			return nil, false
+		}
+	}
+	return claim, true
+}
+
+// GetStrings returns the strings of the claim at a dot separated path. A list
+// claim returns its strings and a string claim returns its space separated
+// values, like the scope claim.
+func (c Claims) GetStrings(path string) []string {
+	claim, ok := c.Get(path)
+	if !ok {
+		return []string{}
+	}
+	switch claimValue := claim.(type) {
+	case string:
+		return strings.Fields(claimValue)
+	case []any:
+		values := make([]string, 0, len(claimValue))
+		for _, item := range claimValue {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+		return values
+	default:
+		return []string{}
+	}
+}
+
+// ClaimsMapper maps the claims of a token to the identity the token was
+// issued to, as each identity provider has its own conventions
+type ClaimsMapper interface {
+	// IsSystemClient checks if the token with the subject and claims was issued
+	// to a system client rather than a user
+	IsSystemClient(subject string, claims Claims) bool
+
+	// GetAuthorities returns the authorities the claims grant
+	GetAuthorities(claims Claims) []string
+}
+
+// CustomClaims contains the claims of a token to be mapped by a ClaimsMapper
+type CustomClaims struct {
+	Claims       Claims
+	claimsMapper ClaimsMapper
+}
+
+func (c *CustomClaims) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &c.Claims)
 }
 
-// Validate does nothing for this example, but we need
-// it to satisfy validator.CustomClaims interface.
-func (c Auth0CustomClaims) Validate(_ context.Context) error {
+// Validate does nothing as the registered claims are already validated, but we
+// need it to satisfy validator.CustomClaims interface.
+func (c *CustomClaims) Validate(_ context.Context) error {
 	return nil
 }
 
-// defaultAuth0CustomClaims are default claims for the custom claims of an auth0 token
-func defaultAuth0CustomClaims() *Auth0CustomClaims {
-	return &Auth0CustomClaims{Scope: "", Permissions: []string{}}
+// NewCustomClaims creates the CustomClaims of a token to be mapped by the
+// ClaimsMapper
+func NewCustomClaims(claimsMapper ClaimsMapper) *CustomClaims {
+	return &CustomClaims{Claims: Claims{}, claimsMapper: claimsMapper}
 }
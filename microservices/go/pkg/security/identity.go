@@ -6,7 +6,6 @@ import (
 	"github.com/auth0/go-jwt-middleware/v2/validator"
 	"github.com/gin-gonic/gin"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
-	"strings"
 )
 
 // Identity contains information regarding who is accessing a resource, usually
@@ -46,38 +45,36 @@ type Identity interface {
 	ContainsAllAuthorities(requiredAuthorities []string) bool
 }
 
-type identityAuth0Impl struct {
-	isAnonymous     bool
-	validatedClaims *validator.ValidatedClaims
-	customClaims    *Auth0CustomClaims
+type identityImpl struct {
+	isAnonymous    bool
+	isSystemClient bool
+	authId         string
+	authorities    []string
 }
 
-func (i identityAuth0Impl) IsUser() bool {
-	return strings.Contains(i.GetAuthId(), "|")
+func (i identityImpl) IsUser() bool {
+	return !i.isAnonymous && !i.isSystemClient
 }
 
-func (i identityAuth0Impl) IsSystemClient() bool {
-	return !i.isAnonymous && !i.IsUser()
+func (i identityImpl) IsSystemClient() bool {
+	return !i.isAnonymous && i.isSystemClient
 }
 
-func (i identityAuth0Impl) IsAnonymous() bool {
+func (i identityImpl) IsAnonymous() bool {
 	return i.isAnonymous
 }
 
-func (i identityAuth0Impl) GetAuthorities() []string {
-	if i.customClaims == nil {
-		return []string{}
-	}
-	return i.customClaims.Permissions
+func (i identityImpl) GetAuthorities() []string {
+	return i.authorities
 }
 
-func (i identityAuth0Impl) GetAuthId() string {
-	return i.validatedClaims.RegisteredClaims.Subject
+func (i identityImpl) GetAuthId() string {
+	return i.authId
 }
 
 type authoritySet map[string]bool
 
-func (i identityAuth0Impl) ContainsAnyAuthorities(authoritiesToCheck []string) bool {
+func (i identityImpl) ContainsAnyAuthorities(authoritiesToCheck []string) bool {
 	if len(authoritiesToCheck) == 0 {
 		return true
 	}
@@ -91,7 +88,7 @@ func (i identityAuth0Impl) ContainsAnyAuthorities(authoritiesToCheck []string) b
 	return false
 }
 
-func (i identityAuth0Impl) ContainsAllAuthorities(requiredAuthorities []string) bool {
+func (i identityImpl) ContainsAllAuthorities(requiredAuthorities []string) bool {
 	if len(requiredAuthorities) == 0 {
 		return true
 	}
@@ -106,24 +103,24 @@ func (i identityAuth0Impl) ContainsAllAuthorities(requiredAuthorities []string)
 }
 
 // GetIdentityFromGinContext retrieves the Identity of whoever is accessing an
-// HTTP request implemented with Gin.
+// HTTP request implemented with Gin. The claims of the token are mapped by the
+// ClaimsMapper of the identity provider that issued it.
 func GetIdentityFromGinContext(c *gin.Context) Identity {
 	contextValue := c.Request.Context().Value(jwtmiddleware.ContextKey{})
-	isAnonymous := utils.StringIsBlank(c.GetHeader("Authorization"))
-	validatedClaims, ok := contextValue.(*validator.ValidatedClaims)
-	if !ok {
-		validatedClaims = &validator.ValidatedClaims{
-			RegisteredClaims: validator.RegisteredClaims{},
-			CustomClaims:     defaultAuth0CustomClaims(),
-		}
+	identity := &identityImpl{
+		isAnonymous: utils.StringIsBlank(c.GetHeader("Authorization")),
+		authorities: []string{},
 	}
-	customClaims, ok := validatedClaims.CustomClaims.(*Auth0CustomClaims)
+	validatedClaims, ok := contextValue.(*validator.ValidatedClaims)
 	if !ok {
-		customClaims = defaultAuth0CustomClaims()
+		return identity
 	}
-	return &identityAuth0Impl{
-		isAnonymous:     isAnonymous,
-		validatedClaims: validatedClaims,
-		customClaims:    customClaims,
+	identity.authId = validatedClaims.RegisteredClaims.Subject
+	customClaims, ok := validatedClaims.CustomClaims.(*CustomClaims)
+	if !ok || customClaims.claimsMapper == nil {
+		return identity
 	}
+	identity.isSystemClient = customClaims.claimsMapper.IsSystemClient(identity.authId, customClaims.Claims)
+	identity.authorities = customClaims.claimsMapper.GetAuthorities(customClaims.Claims)
+	return identity
 }
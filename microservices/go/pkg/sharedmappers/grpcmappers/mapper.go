@@ -1,11 +1,13 @@
 package grpcmappers
 
 import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userkeypb"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userpb"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded/embeddeduser"
 )
 
 func UserReadDtoToUserReply(dto *userdtos.UserReadDto, reply *userpb.UserReply) {
@@ -15,6 +17,13 @@ func UserReadDtoToUserReply(dto *userdtos.UserReadDto, reply *userpb.UserReply)
 	reply.DisplayName = dto.DisplayName
 	reply.CreatedAt = dto.CreatedAt
 	reply.UpdatedAt = dto.UpdatedAt
+	reply.Timezone = dto.Timezone
+	reply.Locale = dto.Locale
+	reply.Bio = dto.Bio
+	reply.AvatarId = dto.AvatarId
+	reply.Suspended = dto.Suspended
+	reply.Preferences = &userpb.UserPreferences{}
+	UserPreferencesDtoToUserPreferences(&dto.Preferences, reply.Preferences)
 }
 
 func UserReplyToUserReadDto(reply *userpb.UserReply, dto *userdtos.UserReadDto) {
@@ -24,32 +33,186 @@ func UserReplyToUserReadDto(reply *userpb.UserReply, dto *userdtos.UserReadDto)
 	dto.DisplayName = reply.GetDisplayName()
 	dto.CreatedAt = reply.GetCreatedAt()
 	dto.UpdatedAt = reply.GetUpdatedAt()
+	dto.Timezone = reply.GetTimezone()
+	dto.Locale = reply.GetLocale()
+	dto.Bio = reply.GetBio()
+	dto.AvatarId = reply.GetAvatarId()
+	dto.Suspended = reply.GetSuspended()
+	UserPreferencesToUserPreferencesDto(reply.GetPreferences(), &dto.Preferences)
+}
+
+func UserReadDtosToUserReplies(source []userdtos.UserReadDto) []*userpb.UserReply {
+	dest := make([]*userpb.UserReply, 0, len(source))
+	for i := range source {
+		reply := &userpb.UserReply{}
+		UserReadDtoToUserReply(&source[i], reply)
+		dest = append(dest, reply)
+	}
+	return dest
+}
+
+func UserRepliesToUserReadDtos(source []*userpb.UserReply) []userdtos.UserReadDto {
+	dest := make([]userdtos.UserReadDto, 0, len(source))
+	for _, reply := range source {
+		dto := userdtos.UserReadDto{}
+		UserReplyToUserReadDto(reply, &dto)
+		dest = append(dest, dto)
+	}
+	return dest
+}
+
+func UserChangeFeedDtoToUserChangeReply(source *userdtos.UserChangeFeedDto, dest *userpb.UserChangeReply) {
+	userDto := userdtos.UserReadDto{BaseUserPublicDto: source.BaseUserPublicDto}
+	userDto.Exists = source.Action == userdtos.UserSave
+	dest.User = &userpb.UserReply{}
+	UserReadDtoToUserReply(&userDto, dest.User)
+	dest.AuthId = source.AuthId
+	dest.Action = userpb.UserChangeAction(source.Action)
+	dest.Sequence = source.Sequence
+	dest.Cursor = source.Cursor
+}
+
+func UserChangeReplyToUserChangeFeedDto(source *userpb.UserChangeReply, dest *userdtos.UserChangeFeedDto) {
+	userDto := userdtos.UserReadDto{}
+	UserReplyToUserReadDto(source.GetUser(), &userDto)
+	dest.BaseUserPublicDto = userDto.BaseUserPublicDto
+	dest.AuthId = source.GetAuthId()
+	dest.Action = userdtos.UserChangeAction(source.GetAction())
+	dest.Sequence = source.GetSequence()
+	dest.Cursor = source.GetCursor()
+}
+
+func UserPreferencesDtoToUserPreferences(source *embeddeduser.UserPreferences, dest *userpb.UserPreferences) {
+	dest.DefaultNoteSortField = source.DefaultNoteSort.Field
+	dest.DefaultNoteSortDirection = string(source.DefaultNoteSort.Direction)
+	dest.KeySessionMinutes = source.KeySessionMinutes
+	dest.Theme = source.Theme
+}
+
+func UserPreferencesToUserPreferencesDto(source *userpb.UserPreferences, dest *embeddeduser.UserPreferences) {
+	dest.DefaultNoteSort.Field = source.GetDefaultNoteSortField()
+	dest.DefaultNoteSort.Direction = pagination.Direction(source.GetDefaultNoteSortDirection())
+	dest.KeySessionMinutes = source.GetKeySessionMinutes()
+	dest.Theme = source.GetTheme()
 }
 
 func UserKeySessionDtoToUserKeySession(source *commondtos.UKeySessionDto, dest *userkeypb.UserKeySession) {
 	dest.ProxyKid = source.ProxyKid
 	dest.Token = source.Token
 	dest.UserId = source.UserId
+	dest.VaultId = source.VaultId
 	dest.KeyVersion = source.KeyVersion
 	dest.StartTime = source.StartTime
 	dest.DurationMilli = source.DurationMilli
+	dest.Binding = &userkeypb.ClientBinding{}
+	ClientBindingDtoToClientBinding(&source.Binding, dest.Binding)
 }
 
 func UserKeySessionToUserKeySessionDto(source *userkeypb.UserKeySession, dest *commondtos.UKeySessionDto) {
 	dest.ProxyKid = source.GetProxyKid()
 	dest.Token = source.GetToken()
 	dest.UserId = source.GetUserId()
+	dest.VaultId = source.GetVaultId()
 	dest.KeyVersion = source.GetKeyVersion()
 	dest.StartTime = source.GetStartTime()
 	dest.DurationMilli = source.GetDurationMilli()
+	ClientBindingToClientBindingDto(source.GetBinding(), &dest.Binding)
+}
+
+func ClientBindingDtoToClientBinding(source *commondtos.ClientBindingDto, dest *userkeypb.ClientBinding) {
+	dest.DeviceId = source.DeviceId
+	dest.ClientIp = source.ClientIp
+}
+
+func ClientBindingToClientBindingDto(source *userkeypb.ClientBinding, dest *commondtos.ClientBindingDto) {
+	dest.DeviceId = source.GetDeviceId()
+	dest.ClientIp = source.GetClientIp()
 }
 
 func UserKeyDtoToUserKey(source *keydtos.UserKeyDto, dest *userkeypb.UserKey) {
 	dest.KeyBase64 = source.KeyBase64
 	dest.KeyVersion = source.KeyVersion
+	dest.Elevated = source.Elevated
+	dest.PreviousKeys = make([]*userkeypb.PreviousUserKey, 0, len(source.PreviousKeys))
+	for _, previousKeyDto := range source.PreviousKeys {
+		previousKey := &userkeypb.PreviousUserKey{}
+		PreviousUserKeyDtoToPreviousUserKey(&previousKeyDto, previousKey)
+		dest.PreviousKeys = append(dest.PreviousKeys, previousKey)
+	}
 }
 
 func UserKeyToUserKeyDto(source *userkeypb.UserKey, dest *keydtos.UserKeyDto) {
 	dest.KeyBase64 = source.GetKeyBase64()
 	dest.KeyVersion = source.GetKeyVersion()
+	dest.Elevated = source.GetElevated()
+	dest.PreviousKeys = make([]keydtos.PreviousUserKeyDto, 0, len(source.GetPreviousKeys()))
+	for _, previousKey := range source.GetPreviousKeys() {
+		previousKeyDto := keydtos.PreviousUserKeyDto{}
+		PreviousUserKeyToPreviousUserKeyDto(previousKey, &previousKeyDto)
+		dest.PreviousKeys = append(dest.PreviousKeys, previousKeyDto)
+	}
+}
+
+func PreviousUserKeyDtoToPreviousUserKey(source *keydtos.PreviousUserKeyDto, dest *userkeypb.PreviousUserKey) {
+	dest.KeyBase64 = source.KeyBase64
+	dest.KeyVersion = source.KeyVersion
+}
+
+func PreviousUserKeyToPreviousUserKeyDto(source *userkeypb.PreviousUserKey, dest *keydtos.PreviousUserKeyDto) {
+	dest.KeyBase64 = source.GetKeyBase64()
+	dest.KeyVersion = source.GetKeyVersion()
+}
+
+func CipherPayloadDtoToCipherPayload(source *keydtos.CipherPayloadDto, dest *userkeypb.CipherPayload) {
+	dest.Data = source.Data
+	dest.KeyVersion = source.KeyVersion
+}
+
+func CipherPayloadToCipherPayloadDto(source *userkeypb.CipherPayload, dest *keydtos.CipherPayloadDto) {
+	dest.Data = source.GetData()
+	dest.KeyVersion = source.GetKeyVersion()
+}
+
+func KeySessionInfoDtoToKeySessionInfo(source *keydtos.KeySessionInfoDto, dest *userkeypb.KeySessionInfo) {
+	dest.KeyVersion = source.KeyVersion
+	dest.PreviousKeyVersions = source.PreviousKeyVersions
+	dest.Elevated = source.Elevated
+}
+
+func KeySessionInfoToKeySessionInfoDto(source *userkeypb.KeySessionInfo, dest *keydtos.KeySessionInfoDto) {
+	dest.KeyVersion = source.GetKeyVersion()
+	dest.PreviousKeyVersions = source.GetPreviousKeyVersions()
+	dest.Elevated = source.GetElevated()
+}
+
+func RewrapGrantDtoToRewrapGrant(source *keydtos.RewrapGrantDto, dest *userkeypb.RewrapGrant) {
+	dest.GrantId = source.GrantId
+	dest.Token = source.Token
+	dest.Consumer = source.Consumer
+}
+
+func RewrapGrantToRewrapGrantDto(source *userkeypb.RewrapGrant, dest *keydtos.RewrapGrantDto) {
+	dest.GrantId = source.GetGrantId()
+	dest.Token = source.GetToken()
+	dest.Consumer = source.GetConsumer()
+}
+
+func CipherPayloadDtosToCipherPayloads(source []keydtos.CipherPayloadDto) []*userkeypb.CipherPayload {
+	dest := make([]*userkeypb.CipherPayload, 0, len(source))
+	for _, payloadDto := range source {
+		payload := &userkeypb.CipherPayload{}
+		CipherPayloadDtoToCipherPayload(&payloadDto, payload)
+		dest = append(dest, payload)
+	}
+	return dest
+}
+
+func CipherPayloadsToCipherPayloadDtos(source []*userkeypb.CipherPayload) []keydtos.CipherPayloadDto {
+	dest := make([]keydtos.CipherPayloadDto, 0, len(source))
+	for _, payload := range source {
+		payloadDto := keydtos.CipherPayloadDto{}
+		CipherPayloadToCipherPayloadDto(payload, &payloadDto)
+		dest = append(dest, payloadDto)
+	}
+	return dest
 }
@@ -0,0 +1,61 @@
+package grpcmappers_test
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userpb"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmappers/grpcmappers"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestUserChangeMapping(t *testing.T) {
+	cv.Convey("When a saved user change is sent over gRPC", t, func() {
+		changeDto := userdtos.UserChangeFeedDto{Sequence: 3, Cursor: "1700000000000-"}
+		changeDto.Id = "64b7f0c2a1e4c3d2b1a09f8e"
+		changeDto.UserName = "changed"
+		changeDto.Suspended = true
+		changeDto.AuthId = "auth0|123"
+		changeDto.Action = userdtos.UserSave
+
+		changeReply := &userpb.UserChangeReply{}
+		grpcmappers.UserChangeFeedDtoToUserChangeReply(&changeDto, changeReply)
+
+		cv.Convey("Expect the user exists in the reply", func() {
+			cv.So(changeReply.GetUser().GetExists(), cv.ShouldBeTrue)
+			cv.So(changeReply.GetAction(), cv.ShouldEqual, userpb.UserChangeAction_USER_SAVE)
+		})
+		cv.Convey("Expect the change is received as it was sent", func() {
+			receivedDto := userdtos.UserChangeFeedDto{}
+			grpcmappers.UserChangeReplyToUserChangeFeedDto(changeReply, &receivedDto)
+			cv.So(receivedDto, cv.ShouldResemble, changeDto)
+		})
+	})
+	cv.Convey("When a deleted user change is sent over gRPC", t, func() {
+		changeDto := userdtos.UserChangeFeedDto{}
+		changeDto.Id = "64b7f0c2a1e4c3d2b1a09f8e"
+		changeDto.Action = userdtos.UserDelete
+
+		changeReply := &userpb.UserChangeReply{}
+		grpcmappers.UserChangeFeedDtoToUserChangeReply(&changeDto, changeReply)
+
+		cv.Convey("Expect the user does not exist in the reply", func() {
+			cv.So(changeReply.GetUser().GetExists(), cv.ShouldBeFalse)
+			cv.So(changeReply.GetAction(), cv.ShouldEqual, userpb.UserChangeAction_USER_DELETE)
+		})
+	})
+}
+
+func TestUserRepliesMapping(t *testing.T) {
+	cv.Convey("When users are sent over gRPC", t, func() {
+		userDtos := []userdtos.UserReadDto{{}, {}}
+		userDtos[0].Id = "first"
+		userDtos[0].Exists = true
+		userDtos[1].Id = "second"
+
+		replies := grpcmappers.UserReadDtosToUserReplies(userDtos)
+
+		cv.Convey("Expect the users are received in the same order", func() {
+			cv.So(grpcmappers.UserRepliesToUserReadDtos(replies), cv.ShouldResemble, userDtos)
+		})
+	})
+}
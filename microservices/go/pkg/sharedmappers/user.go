@@ -1,6 +1,7 @@
 package sharedmappers
 
 import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/businessobjects/userbos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/embedded/embeddeduser"
@@ -17,6 +18,12 @@ func AuthIdAndUserPublicDtoToUserModel(authId string, userDto embeddeduser.BaseU
 	user.UserId = userDto.Id
 	user.UserName = userDto.UserName
 	user.DisplayName = userDto.DisplayName
+	user.Timezone = userDto.Timezone
+	user.Locale = userDto.Locale
+	user.Bio = userDto.Bio
+	user.AvatarId = userDto.AvatarId
+	user.Suspended = userDto.Suspended
+	UserPreferencesToUserPreferencesModel(userDto.Preferences, &user.Preferences)
 	user.UserCreatedAt = userDto.CreatedAt
 	user.UserUpdatedAt = userDto.UpdatedAt
 }
@@ -25,10 +32,36 @@ func UserModelToUserBo(user sharedmodels.User, userBo *userbos.UserBo) {
 	userBo.Id = user.UserId
 	userBo.UserName = user.UserName
 	userBo.DisplayName = user.DisplayName
+	userBo.Timezone = user.Timezone
+	userBo.Locale = user.Locale
+	userBo.Bio = user.Bio
+	userBo.AvatarId = user.AvatarId
+	userBo.Suspended = user.Suspended
+	UserPreferencesModelToUserPreferences(user.Preferences, &userBo.Preferences)
 	userBo.CreatedAt = user.UserCreatedAt
 	userBo.UpdatedAt = user.UserUpdatedAt
 }
 
+func UserPreferencesToUserPreferencesModel(
+	preferences embeddeduser.UserPreferences,
+	dest *sharedmodels.UserPreferences,
+) {
+	dest.DefaultNoteSortField = preferences.DefaultNoteSort.Field
+	dest.DefaultNoteSortDirection = string(preferences.DefaultNoteSort.Direction)
+	dest.KeySessionMinutes = preferences.KeySessionMinutes
+	dest.Theme = preferences.Theme
+}
+
+func UserPreferencesModelToUserPreferences(
+	preferences sharedmodels.UserPreferences,
+	dest *embeddeduser.UserPreferences,
+) {
+	dest.DefaultNoteSort.Field = preferences.DefaultNoteSortField
+	dest.DefaultNoteSort.Direction = pagination.Direction(preferences.DefaultNoteSortDirection)
+	dest.KeySessionMinutes = preferences.KeySessionMinutes
+	dest.Theme = preferences.Theme
+}
+
 func UserReadDtoAndIdentityToUserIdentityDto(
 	userDto userdtos.UserReadDto,
 	identity security.Identity,
@@ -8,12 +8,25 @@ import (
 type User struct {
 	// DefaultModel adds _id, created_at and updated_at fields to the Model
 	mgm.DefaultModel `bson:",inline"`
-	AuthId           string `json:"authId" bson:"authId"`
-	UserId           string `json:"userId" bson:"userId"`
-	UserName         string `json:"userName" bson:"userName"`
-	DisplayName      string `json:"displayName" bson:"displayName"`
-	UserCreatedAt    int64  `json:"userCreatedAt" bson:"userCreatedAt"`
-	UserUpdatedAt    int64  `json:"userUpdatedAt" bson:"userUpdatedAt"`
+	AuthId           string          `json:"authId" bson:"authId"`
+	UserId           string          `json:"userId" bson:"userId"`
+	UserName         string          `json:"userName" bson:"userName"`
+	DisplayName      string          `json:"displayName" bson:"displayName"`
+	Timezone         string          `json:"timezone" bson:"timezone"`
+	Locale           string          `json:"locale" bson:"locale"`
+	Bio              string          `json:"bio" bson:"bio"`
+	AvatarId         string          `json:"avatarId" bson:"avatarId"`
+	Preferences      UserPreferences `json:"preferences" bson:"preferences"`
+	Suspended        bool            `json:"suspended" bson:"suspended"`
+	UserCreatedAt    int64           `json:"userCreatedAt" bson:"userCreatedAt"`
+	UserUpdatedAt    int64           `json:"userUpdatedAt" bson:"userUpdatedAt"`
+}
+
+type UserPreferences struct {
+	DefaultNoteSortField     string `json:"defaultNoteSortField" bson:"defaultNoteSortField"`
+	DefaultNoteSortDirection string `json:"defaultNoteSortDirection" bson:"defaultNoteSortDirection"`
+	KeySessionMinutes        int64  `json:"keySessionMinutes" bson:"keySessionMinutes"`
+	Theme                    string `json:"theme" bson:"theme"`
 }
 
 func (u User) GetIdStr() string {
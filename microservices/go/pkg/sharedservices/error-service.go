@@ -29,20 +29,46 @@ func (e ErrorServiceImpl) RuleErrorFromCode(code string, args ...any) apperrors.
 
 func NewErrorServiceImpl() *ErrorServiceImpl {
 	errorCodeToMsgMap := map[string]string{
-		apperrors.ErrCodeReqResourcesNotFound:   "Requested resources not found",
-		apperrors.ErrCodeCannotBindJson:         "Unable to bind json",
-		apperrors.ErrCodeResourceAlreadyCreated: "Resource already created",
-		apperrors.ErrCodeUsernameTaken:          "Username is taken",
-		apperrors.ErrCodeUserRequireFail:        "User is not found or incomplete",
-		apperrors.ErrCodeIncorrectPasscode:      "Incorrect passcode",
-		apperrors.ErrCodeInvalidSession:         "Invalid session",
-		apperrors.ErrCodeDataRace:               "Waiting for relevant updates to complete",
-		apperrors.ErrCodeReqQueryBoolParseFail:  "Failed to parse a boolean from the request query %v",
-		apperrors.ErrCodeReqQueryIntParseFail:   "Failed to parse an integer from the request query %v",
-		apperrors.ErrCodeReqQueryRequired:       "Query param %v is required",
-		apperrors.ErrCodeReqQuerySortParseFail:  "Could not parse sort query parameter",
-		apperrors.ErrCodeMustSortByOneOption:    "Must sort by one option",
-		apperrors.ErrCodeInvalidSortOptions:     "Invalid sort options",
+		apperrors.ErrCodeReqResourcesNotFound:          "Requested resources not found",
+		apperrors.ErrCodeCannotBindJson:                "Unable to bind json",
+		apperrors.ErrCodeResourceAlreadyCreated:        "Resource already created",
+		apperrors.ErrCodeUsernameTaken:                 "Username is taken",
+		apperrors.ErrCodeUserRequireFail:               "User is not found or incomplete",
+		apperrors.ErrCodeIncorrectPasscode:             "Incorrect passcode",
+		apperrors.ErrCodeInvalidSession:                "Invalid session",
+		apperrors.ErrCodeDataRace:                      "Waiting for relevant updates to complete",
+		apperrors.ErrCodeReqQueryBoolParseFail:         "Failed to parse a boolean from the request query %v",
+		apperrors.ErrCodeReqQueryIntParseFail:          "Failed to parse an integer from the request query %v",
+		apperrors.ErrCodeReqQueryRequired:              "Query param %v is required",
+		apperrors.ErrCodeReqQuerySortParseFail:         "Could not parse sort query parameter",
+		apperrors.ErrCodeMustSortByOneOption:           "Must sort by one option",
+		apperrors.ErrCodeInvalidSortOptions:            "Invalid sort options",
+		apperrors.ErrCodeKeyRotationInProgress:         "A key rotation is already in progress",
+		apperrors.ErrCodeInvalidRewrapGrant:            "The rewrap grant is invalid or has expired",
+		apperrors.ErrCodeSrpNotEnabled:                 "The user key cannot be unlocked with SRP",
+		apperrors.ErrCodeInvalidSrpChallenge:           "The SRP challenge is invalid or has expired",
+		apperrors.ErrCodeInvalidUserKey:                "The user key is not the 32 byte AES key of the vault",
+		apperrors.ErrCodeTotpRequired:                  "A TOTP code is required",
+		apperrors.ErrCodeTooManyUnlockAttempts:         "Too many incorrect passcodes or codes were entered, try again later",
+		apperrors.ErrCodeIncorrectTotpCode:             "Incorrect or already used TOTP code",
+		apperrors.ErrCodeTotpAlreadyEnabled:            "TOTP is already enabled",
+		apperrors.ErrCodeTotpNotEnrolled:               "TOTP has not been enrolled",
+		apperrors.ErrCodeInvalidVaultId:                "Vault IDs must be lowercase letters, digits and dashes",
+		apperrors.ErrCodeSessionNotElevated:            "Re-enter your passcode to perform this action",
+		apperrors.ErrCodeDuressPasscodeMatchesPasscode: "The duress passcode must differ from the passcode",
+		apperrors.ErrCodeUserDeletionInProgress:        "The account is still being deleted",
+		apperrors.ErrCodeDataExportInProgress:          "A data export is already in progress",
+		apperrors.ErrCodeInvalidTimezone:               "The timezone must be an IANA time zone name",
+		apperrors.ErrCodeInvalidLocale:                 "The locale must be a BCP 47 language tag",
+		apperrors.ErrCodeAvatarTooLarge:                "The avatar must be at most %v bytes",
+		apperrors.ErrCodeUnsupportedAvatarType:         "The avatar must be a PNG, JPEG, GIF or WebP image",
+		apperrors.ErrCodeDataExportNotReady:            "The data export has not completed",
+		apperrors.ErrCodeUserSuspended:                 "The account is suspended",
+		apperrors.ErrCodeTooManyItemsRequested:         "At most %v items can be requested at once",
+		apperrors.ErrCodeInvalidCursor:                 "The cursor is invalid",
+		apperrors.ErrCodeUnknownDeadLetterTopic:        "The dead letter topic is unknown",
+		apperrors.ErrCodeInvalidRetryStage:             "The retry stage must be 0 for the main topic or at most %v",
+		apperrors.ErrCodeNoDeadLettersSelected:         "Select dead letters by ID or select all of them",
 	}
 	return &ErrorServiceImpl{errorCodeToMsgMap: errorCodeToMsgMap}
 }
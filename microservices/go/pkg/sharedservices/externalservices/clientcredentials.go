@@ -8,6 +8,7 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/clientcredentialsdtos"
 	"golang.org/x/oauth2"
+	"time"
 )
 
 type SysAccessTokenClient interface {
@@ -47,9 +48,16 @@ func (a Auth0SysAccessTokenClient) getAccessToken(audience string) (oauth2.Token
 	if err != nil {
 		return token, err
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+	resultDto := clientcredentialsdtos.ClientCredentialsResultDto{}
+	if err := json.NewDecoder(resp.Body).Decode(&resultDto); err != nil {
 		return token, err
 	}
+	token.AccessToken = resultDto.AccessToken
+	token.TokenType = resultDto.TokenType
+	// The expiry lets callers reuse the token until it expires
+	if resultDto.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resultDto.ExpiresIn) * time.Second)
+	}
 	return token, err
 }
 
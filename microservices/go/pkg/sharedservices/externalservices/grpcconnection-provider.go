@@ -2,26 +2,50 @@ package externalservices
 
 import (
 	"context"
-	"github.com/obenkenobi/cypher-log/microservices/go/pkg/concurrent"
+	"github.com/akrennmair/slice"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/environment"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/gtools"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"golang.org/x/oauth2"
 	"google.golang.org/grpc"
+	"sync"
 )
 
 type CoreGrpcConnProvider interface {
-	CreateConnectionSingle(ctx context.Context, address string) (*grpc.ClientConn, error)
+	// GetConnection returns the connection to the address, which is dialed on
+	// first use and shared by every caller. Callers must not close it.
+	GetConnection(ctx context.Context, address string) (*grpc.ClientConn, error)
+	lifecycle.Closable
 }
 
 type CoreGrpcConnProviderImpl struct {
 	systemAccessTokenClient SysAccessTokenClient
 	tlsConf                 conf.TLSConf
+	// tokenSource reuses the system access token until it expires
+	tokenSource oauth2.TokenSource
+	connsMutex  *sync.Mutex
+	conns       map[string]*grpc.ClientConn
 }
 
-func (u CoreGrpcConnProviderImpl) CreateConnectionSingle(_ context.Context, address string) (*grpc.ClientConn, error) {
+func (u CoreGrpcConnProviderImpl) GetConnection(_ context.Context, address string) (*grpc.ClientConn, error) {
+	u.connsMutex.Lock()
+	defer u.connsMutex.Unlock()
+	if conn, ok := u.conns[address]; ok {
+		return conn, nil
+	}
+	conn, err := u.dial(address)
+	if err != nil {
+		return nil, err
+	}
+	u.conns[address] = conn
+	return conn, nil
+}
+
+func (u CoreGrpcConnProviderImpl) dial(address string) (*grpc.ClientConn, error) {
 	var dialOptions []grpc.DialOption
 	if environment.ActivateGRPCAuth() {
-		oathTokenFuture := concurrent.Async(u.systemAccessTokenClient.GetGRPCAccessToken)
 		if u.tlsConf.WillLoadCACert() {
 			tlsOpt, err := gtools.LoadTLSCredentialsOption(u.tlsConf.CACertPath(), environment.IsDevelopment())
 			if err != nil {
@@ -29,21 +53,50 @@ func (u CoreGrpcConnProviderImpl) CreateConnectionSingle(_ context.Context, addr
 			}
 			dialOptions = append(dialOptions, tlsOpt)
 		}
-		token, err := oathTokenFuture.Await()
-		if err != nil {
-			return nil, err
-		}
-		dialOptions = append(dialOptions, gtools.OathAccessOption(token))
+		// The token is fetched for each call, so a long-lived connection does
+		// not keep sending an expired token
+		dialOptions = append(dialOptions, gtools.OathTokenSourceOption(u.tokenSource))
 	}
 	return grpc.Dial(address, dialOptions...)
 }
 
+func (u CoreGrpcConnProviderImpl) Close() error {
+	u.connsMutex.Lock()
+	defer u.connsMutex.Unlock()
+	conns := make([]*grpc.ClientConn, 0, len(u.conns))
+	for address, conn := range u.conns {
+		conns = append(conns, conn)
+		delete(u.conns, address)
+	}
+	errs := slice.MapConcurrent(conns, (*grpc.ClientConn).Close)
+	return utils.ConcatErrors(errs...)
+}
+
+// sysAccessTokenSource gets gRPC access tokens from the system access token
+// client
+type sysAccessTokenSource struct {
+	systemAccessTokenClient SysAccessTokenClient
+}
+
+func (s sysAccessTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.systemAccessTokenClient.GetGRPCAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
 func NewCoreGrpcConnProviderImpl(
 	systemAccessTokenClient SysAccessTokenClient,
 	tlsConf conf.TLSConf,
 ) *CoreGrpcConnProviderImpl {
-	return &CoreGrpcConnProviderImpl{
+	u := &CoreGrpcConnProviderImpl{
 		systemAccessTokenClient: systemAccessTokenClient,
 		tlsConf:                 tlsConf,
+		tokenSource:             oauth2.ReuseTokenSource(nil, sysAccessTokenSource{systemAccessTokenClient}),
+		connsMutex:              &sync.Mutex{},
+		conns:                   map[string]*grpc.ClientConn{},
 	}
+	lifecycle.RegisterClosable(u)
+	return u
 }
@@ -5,15 +5,31 @@ import (
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/gtools"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userpb"
-	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmappers/grpcmappers"
-	"google.golang.org/grpc"
+	"io"
 )
 
 type ExtUserService interface {
 	GetByAuthId(ctx context.Context, authId string) (userdtos.UserReadDto, error)
 	GetById(ctx context.Context, id string) (userdtos.UserReadDto, error)
+
+	// GetByIds returns a user for each ID in the same order. Users that do not
+	// exist only have their ID set.
+	GetByIds(ctx context.Context, ids []string) ([]userdtos.UserReadDto, error)
+
+	// ListUsers passes every user ordered by ID to handlePage a page at a time
+	ListUsers(ctx context.Context, pageSize int64, handlePage func([]userdtos.UserReadDto) error) error
+
+	// WatchUserChanges passes the user changes to handleChange as they are
+	// published, resuming after the change with the cursor or starting with the
+	// changes published from now on if the cursor is blank. It returns once the
+	// context is done or watching fails.
+	WatchUserChanges(
+		ctx context.Context,
+		cursor string,
+		handleChange func(userdtos.UserChangeFeedDto) error,
+	) error
 }
 
 type ExtUserServiceImpl struct {
@@ -22,49 +38,106 @@ type ExtUserServiceImpl struct {
 }
 
 func (u ExtUserServiceImpl) GetById(ctx context.Context, id string) (userDto userdtos.UserReadDto, err error) {
-	conn, err := u.coreGrpcConnProvider.CreateConnectionSingle(ctx, u.grpcClientConf.UserServiceAddress())
-	if err != nil {
-		return userDto, err
-	}
-	defer func(conn *grpc.ClientConn) {
-		if conErr := conn.Close(); conErr != nil {
-			logger.Log.WithContext(ctx).WithError(conErr).Error()
+	err = u.withUserServiceClient(ctx, func(userService userpb.UserServiceClient) error {
+		reply, err := userService.GetUserById(ctx, &userpb.IdRequest{Id: id})
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
 		}
-	}(conn)
+		grpcmappers.UserReplyToUserReadDto(reply, &userDto)
+		return nil
+	})
+	return userDto, err
+}
 
-	userService := userpb.NewUserServiceClient(conn)
-	reply, err := userService.GetUserById(ctx, &userpb.IdRequest{Id: id})
-	if err != nil {
-		err = gtools.NewErrorResponseHandler(err).GetProcessedError()
-		return userDto, err
-	}
+func (u ExtUserServiceImpl) GetByAuthId(ctx context.Context, authId string) (userDto userdtos.UserReadDto, err error) {
+	err = u.withUserServiceClient(ctx, func(userService userpb.UserServiceClient) error {
+		reply, err := userService.GetUserByAuthId(ctx, &userpb.AuthIdRequest{AuthId: authId})
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
+		}
+		grpcmappers.UserReplyToUserReadDto(reply, &userDto)
+		return nil
+	})
+	return userDto, err
+}
 
-	dto := userdtos.UserReadDto{}
-	grpcmappers.UserReplyToUserReadDto(reply, &dto)
-	return dto, nil
+func (u ExtUserServiceImpl) GetByIds(ctx context.Context, ids []string) (userDtos []userdtos.UserReadDto, err error) {
+	err = u.withUserServiceClient(ctx, func(userService userpb.UserServiceClient) error {
+		reply, err := userService.GetUsersByIds(ctx, &userpb.IdsRequest{Ids: ids})
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
+		}
+		userDtos = grpcmappers.UserRepliesToUserReadDtos(reply.GetUsers())
+		return nil
+	})
+	return userDtos, err
 }
 
-func (u ExtUserServiceImpl) GetByAuthId(ctx context.Context, authId string) (userDto userdtos.UserReadDto, err error) {
-	conn, err := u.coreGrpcConnProvider.CreateConnectionSingle(ctx, u.grpcClientConf.UserServiceAddress())
-	if err != nil {
-		return userDto, err
-	}
-	defer func(conn *grpc.ClientConn) {
-		if conErr := conn.Close(); conErr != nil {
-			logger.Log.WithContext(ctx).WithError(conErr).Error()
+func (u ExtUserServiceImpl) ListUsers(
+	ctx context.Context,
+	pageSize int64,
+	handlePage func([]userdtos.UserReadDto) error,
+) error {
+	return u.withUserServiceClient(ctx, func(userService userpb.UserServiceClient) error {
+		stream, err := userService.ListUsers(ctx, &userpb.ListUsersRequest{PageSize: pageSize})
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
+		}
+		for {
+			reply, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return gtools.NewErrorResponseHandler(err).GetProcessedError()
+			}
+			if err := handlePage(grpcmappers.UserRepliesToUserReadDtos(reply.GetUsers())); err != nil {
+				return err
+			}
+			// Stop between pages once the caller gives up
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 		}
-	}(conn)
+	})
+}
 
-	userService := userpb.NewUserServiceClient(conn)
-	reply, err := userService.GetUserByAuthId(ctx, &userpb.AuthIdRequest{AuthId: authId})
+func (u ExtUserServiceImpl) WatchUserChanges(
+	ctx context.Context,
+	cursor string,
+	handleChange func(userdtos.UserChangeFeedDto) error,
+) error {
+	return u.withUserServiceClient(ctx, func(userService userpb.UserServiceClient) error {
+		stream, err := userService.WatchUserChanges(ctx, &userpb.WatchUserChangesRequest{Cursor: cursor})
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
+		}
+		for {
+			reply, err := stream.Recv()
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			} else if err != nil {
+				return gtools.NewErrorResponseHandler(err).GetProcessedError()
+			}
+			changeDto := userdtos.UserChangeFeedDto{}
+			grpcmappers.UserChangeReplyToUserChangeFeedDto(reply, &changeDto)
+			if err := handleChange(changeDto); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// withUserServiceClient passes useClient a client of the shared connection to
+// the user service
+func (u ExtUserServiceImpl) withUserServiceClient(
+	ctx context.Context,
+	useClient func(userService userpb.UserServiceClient) error,
+) error {
+	conn, err := u.coreGrpcConnProvider.GetConnection(ctx, u.grpcClientConf.UserServiceAddress())
 	if err != nil {
-		err = gtools.NewErrorResponseHandler(err).GetProcessedError()
-		return userDto, err
+		return err
 	}
-
-	dto := userdtos.UserReadDto{}
-	grpcmappers.UserReplyToUserReadDto(reply, &dto)
-	return dto, nil
+	return useClient(userpb.NewUserServiceClient(conn))
 }
 
 func NewExtUserServiceImpl(
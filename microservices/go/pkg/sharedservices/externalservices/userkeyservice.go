@@ -2,14 +2,14 @@ package externalservices
 
 import (
 	"context"
+	"errors"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/gtools"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/grpc/userkeypb"
-	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmappers/grpcmappers"
-	"google.golang.org/grpc"
 )
 
 type ExtUserKeyService interface {
@@ -17,6 +17,37 @@ type ExtUserKeyService interface {
 		ctx context.Context,
 		userKeySessionDto commondtos.UKeySessionDto,
 	) (keydtos.UserKeyDto, error)
+
+	// GetKeySessionInfo returns the key versions of a session and whether it is
+	// elevated. The keys never leave the key service.
+	GetKeySessionInfo(
+		ctx context.Context,
+		userKeySessionDto commondtos.UKeySessionDto,
+	) (keydtos.KeySessionInfoDto, error)
+
+	// EncryptWithSession encrypts the payloads with the session's user key,
+	// returning them with the key version they were encrypted with
+	EncryptWithSession(
+		ctx context.Context,
+		userKeySessionDto commondtos.UKeySessionDto,
+		payloads []keydtos.CipherPayloadDto,
+	) ([]keydtos.CipherPayloadDto, error)
+
+	// DecryptWithSession decrypts each payload with the current or previous
+	// user key matching the payload's key version
+	DecryptWithSession(
+		ctx context.Context,
+		userKeySessionDto commondtos.UKeySessionDto,
+		payloads []keydtos.CipherPayloadDto,
+	) ([]keydtos.CipherPayloadDto, error)
+
+	// RewrapWithGrant re-encrypts payloads of previous key versions with the
+	// current user key using a rewrap grant issued for a key rotation
+	RewrapWithGrant(
+		ctx context.Context,
+		grantDto keydtos.RewrapGrantDto,
+		payloads []keydtos.CipherPayloadDto,
+	) ([]keydtos.CipherPayloadDto, error)
 }
 
 type ExtUserKeyServiceImpl struct {
@@ -28,35 +59,143 @@ func (e ExtUserKeyServiceImpl) GetKeyFromSession(
 	ctx context.Context,
 	userKeySessionDto commondtos.UKeySessionDto,
 ) (keyDto keydtos.UserKeyDto, err error) {
-	conn, err := e.coreGrpcConnProvider.CreateConnectionSingle(ctx, e.grpcClientConf.KeyServiceAddress())
-	if err != nil {
-		return keyDto, err
+	err = e.withClient(ctx, func(client userkeypb.UserKeyServiceClient) error {
+		userKeySession := &userkeypb.UserKeySession{}
+		grpcmappers.UserKeySessionDtoToUserKeySession(&userKeySessionDto, userKeySession)
+
+		reply, err := client.GetKeyFromSession(ctx, userKeySession)
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
+		}
+		grpcmappers.UserKeyToUserKeyDto(reply, &keyDto)
+		return nil
+	})
+	return keyDto, err
+}
+
+func (e ExtUserKeyServiceImpl) GetKeySessionInfo(
+	ctx context.Context,
+	userKeySessionDto commondtos.UKeySessionDto,
+) (sessionInfoDto keydtos.KeySessionInfoDto, err error) {
+	err = e.withClient(ctx, func(client userkeypb.UserKeyServiceClient) error {
+		userKeySession := &userkeypb.UserKeySession{}
+		grpcmappers.UserKeySessionDtoToUserKeySession(&userKeySessionDto, userKeySession)
+
+		reply, err := client.GetKeySessionInfo(ctx, userKeySession)
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
+		}
+		grpcmappers.KeySessionInfoToKeySessionInfoDto(reply, &sessionInfoDto)
+		return nil
+	})
+	return sessionInfoDto, err
+}
+
+func (e ExtUserKeyServiceImpl) EncryptWithSession(
+	ctx context.Context,
+	userKeySessionDto commondtos.UKeySessionDto,
+	payloads []keydtos.CipherPayloadDto,
+) ([]keydtos.CipherPayloadDto, error) {
+	if e.grpcClientConf.UserKeyClientMode() == conf.UserKeyClientModeLocal {
+		keyDto, err := e.GetKeyFromSession(ctx, userKeySessionDto)
+		if err != nil {
+			return nil, err
+		}
+		return keyDto.EncryptPayloads(payloads)
 	}
-	defer func(conn *grpc.ClientConn) {
-		if conErr := conn.Close(); conErr != nil {
-			logger.Log.WithContext(ctx).WithError(conErr).Error()
+	var cipherPayloads []keydtos.CipherPayloadDto
+	err := e.withClient(ctx, func(client userkeypb.UserKeyServiceClient) error {
+		reply, err := client.EncryptBatchWithSession(ctx, e.createSessionPayloadBatch(userKeySessionDto, payloads))
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
 		}
-	}(conn)
+		cipherPayloads = grpcmappers.CipherPayloadsToCipherPayloadDtos(reply.GetPayloads())
+		return nil
+	})
+	return cipherPayloads, err
+}
 
-	client := userkeypb.NewUserKeyServiceClient(conn)
+func (e ExtUserKeyServiceImpl) DecryptWithSession(
+	ctx context.Context,
+	userKeySessionDto commondtos.UKeySessionDto,
+	payloads []keydtos.CipherPayloadDto,
+) ([]keydtos.CipherPayloadDto, error) {
+	if e.grpcClientConf.UserKeyClientMode() == conf.UserKeyClientModeLocal {
+		keyDto, err := e.GetKeyFromSession(ctx, userKeySessionDto)
+		if err != nil {
+			return nil, err
+		}
+		plainPayloads, err := keyDto.DecryptPayloads(payloads)
+		if errors.Is(err, keydtos.ErrKeyVersionNotFound) {
+			// Matches the rule error code the key service replies with in remote mode
+			ruleErr := apperrors.RuleError{Code: apperrors.ErrCodeDataRace, Message: err.Error()}
+			return nil, apperrors.NewBadReqErrorFromRuleError(ruleErr)
+		}
+		return plainPayloads, err
+	}
+	var plainPayloads []keydtos.CipherPayloadDto
+	err := e.withClient(ctx, func(client userkeypb.UserKeyServiceClient) error {
+		reply, err := client.DecryptBatchWithSession(ctx, e.createSessionPayloadBatch(userKeySessionDto, payloads))
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
+		}
+		plainPayloads = grpcmappers.CipherPayloadsToCipherPayloadDtos(reply.GetPayloads())
+		return nil
+	})
+	return plainPayloads, err
+}
 
+func (e ExtUserKeyServiceImpl) RewrapWithGrant(
+	ctx context.Context,
+	grantDto keydtos.RewrapGrantDto,
+	payloads []keydtos.CipherPayloadDto,
+) ([]keydtos.CipherPayloadDto, error) {
+	var cipherPayloads []keydtos.CipherPayloadDto
+	err := e.withClient(ctx, func(client userkeypb.UserKeyServiceClient) error {
+		grant := &userkeypb.RewrapGrant{}
+		grpcmappers.RewrapGrantDtoToRewrapGrant(&grantDto, grant)
+		reply, err := client.RewrapWithGrant(ctx, &userkeypb.RewrapGrantPayloadBatch{
+			Grant:    grant,
+			Payloads: grpcmappers.CipherPayloadDtosToCipherPayloads(payloads),
+		})
+		if err != nil {
+			return gtools.NewErrorResponseHandler(err).GetProcessedError()
+		}
+		cipherPayloads = grpcmappers.CipherPayloadsToCipherPayloadDtos(reply.GetPayloads())
+		return nil
+	})
+	return cipherPayloads, err
+}
+
+func (e ExtUserKeyServiceImpl) createSessionPayloadBatch(
+	userKeySessionDto commondtos.UKeySessionDto,
+	payloads []keydtos.CipherPayloadDto,
+) *userkeypb.SessionCipherPayloadBatch {
 	userKeySession := &userkeypb.UserKeySession{}
 	grpcmappers.UserKeySessionDtoToUserKeySession(&userKeySessionDto, userKeySession)
+	return &userkeypb.SessionCipherPayloadBatch{
+		Session:  userKeySession,
+		Payloads: grpcmappers.CipherPayloadDtosToCipherPayloads(payloads),
+	}
+}
 
-	reply, err := client.GetKeyFromSession(ctx, userKeySession)
+func (e ExtUserKeyServiceImpl) withClient(
+	ctx context.Context,
+	call func(client userkeypb.UserKeyServiceClient) error,
+) error {
+	conn, err := e.coreGrpcConnProvider.GetConnection(ctx, e.grpcClientConf.KeyServiceAddress())
 	if err != nil {
-		err = gtools.NewErrorResponseHandler(err).GetProcessedError()
-		return keyDto, err
+		return err
 	}
-
-	dto := keydtos.UserKeyDto{}
-	grpcmappers.UserKeyToUserKeyDto(reply, &dto)
-	return dto, nil
+	return call(userkeypb.NewUserKeyServiceClient(conn))
 }
 
 func NewExtUserKeyServiceImpl(
 	grpcClientConf conf.GrpcClientConf,
 	coreGrpcConnProvider CoreGrpcConnProvider,
 ) *ExtUserKeyServiceImpl {
-	return &ExtUserKeyServiceImpl{grpcClientConf: grpcClientConf, coreGrpcConnProvider: coreGrpcConnProvider}
+	return &ExtUserKeyServiceImpl{
+		grpcClientConf:       grpcClientConf,
+		coreGrpcConnProvider: coreGrpcConnProvider,
+	}
 }
@@ -6,9 +6,11 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/apperrors"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/logger"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/commondtos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/controller"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/queryreq"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/routing"
 	"net/http"
 )
 
@@ -85,6 +87,30 @@ func ReadValueFromBody[V any](ginCtxService GinCtxService, c *gin.Context) (V, e
 	return value, nil
 }
 
+// ReadSessionReqFromBody reads a request wrapped with a key session. The
+// session is bound to the client the gateway identified in the request headers,
+// as the client cannot be trusted to identify itself in the body.
+func ReadSessionReqFromBody[V any](
+	ginCtxService GinCtxService,
+	c *gin.Context,
+) (commondtos.UKeySessionReqDto[V], error) {
+	sessReqDto, err := ReadValueFromBody[commondtos.UKeySessionReqDto[V]](ginCtxService, c)
+	if err != nil {
+		return sessReqDto, err
+	}
+	sessReqDto.Session.Binding = ClientBindingFromHeaders(c)
+	return sessReqDto, nil
+}
+
+// ClientBindingFromHeaders reads the client binding the gateway set on the
+// request
+func ClientBindingFromHeaders(c *gin.Context) commondtos.ClientBindingDto {
+	return commondtos.ClientBindingDto{
+		DeviceId: c.GetHeader(routing.HeaderClientDeviceId),
+		ClientIp: c.GetHeader(routing.HeaderClientIp),
+	}
+}
+
 // BindBodyToReferenceObj reads the request type and writes it to a value that must be a reference type
 func BindBodyToReferenceObj[V any](ginCtxService GinCtxService, c *gin.Context, value V) (V, error) {
 	if err := c.ShouldBind(value); err != nil {
@@ -12,6 +12,7 @@ import (
 
 type AuthInterceptorCreator interface {
 	CreateUnaryInterceptor() grpc.ServerOption
+	CreateStreamInterceptor() grpc.ServerOption
 }
 
 type AuthInterceptorCreatorImpl struct {
@@ -28,29 +29,53 @@ func (a AuthInterceptorCreatorImpl) valid(ctx context.Context, authorization []s
 	return err == nil
 }
 
-func (a AuthInterceptorCreatorImpl) authenticate(
-	ctx context.Context,
-	req interface{},
-	info *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (interface{}, error) {
+// checkAuthorization returns an error unless the metadata has a valid token
+func (a AuthInterceptorCreatorImpl) checkAuthorization(ctx context.Context) error {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return nil, status.Errorf(codes.InvalidArgument, "missing metadata")
+		return status.Errorf(codes.InvalidArgument, "missing metadata")
 	}
 	// The keys within metadata.MD are normalized to lowercase.
 	// See: https://godoc.org/google.golang.org/grpc/metadata#New
 	if !a.valid(ctx, md["authorization"]) {
-		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+		return status.Errorf(codes.Unauthenticated, "invalid token")
+	}
+	return nil
+}
+
+func (a AuthInterceptorCreatorImpl) authenticate(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if err := a.checkAuthorization(ctx); err != nil {
+		return nil, err
 	}
 	// Continue execution of handler after ensuring a valid token.
 	return handler(ctx, req)
 }
 
+func (a AuthInterceptorCreatorImpl) authenticateStream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if err := a.checkAuthorization(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
 func (a AuthInterceptorCreatorImpl) CreateUnaryInterceptor() grpc.ServerOption {
 	return grpc.UnaryInterceptor(a.authenticate)
 }
 
+func (a AuthInterceptorCreatorImpl) CreateStreamInterceptor() grpc.ServerOption {
+	return grpc.StreamInterceptor(a.authenticateStream)
+}
+
 func NewAuthInterceptorCreatorImpl(
 	grpcAuth0JwtValidateService securityservices.JwtValidateGrpcService,
 ) *AuthInterceptorCreatorImpl {
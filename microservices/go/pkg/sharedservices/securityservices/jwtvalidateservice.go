@@ -4,6 +4,7 @@ import (
 	"github.com/auth0/go-jwt-middleware/v2/jwks"
 	"github.com/auth0/go-jwt-middleware/v2/validator"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf/authconf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/identityproviders"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/security"
 	"net/url"
 	"time"
@@ -22,13 +23,17 @@ func (o BaseOath2ValidateServiceImpl) GetJwtValidator() *validator.Validator {
 	return o.jwtValidator
 }
 
-func createOath2ValidateService(issuerURL *url.URL, audience string) *BaseOath2ValidateServiceImpl {
+func createOath2ValidateService(
+	issuerURL *url.URL,
+	audience string,
+	claimsMapper security.ClaimsMapper,
+) *BaseOath2ValidateServiceImpl {
 	provider := jwks.NewCachingProvider(issuerURL, 5*time.Minute)
 	jwtValidator, _ := validator.New(provider.KeyFunc,
 		validator.RS256,
 		issuerURL.String(),
 		[]string{audience},
-		validator.WithCustomClaims(func() validator.CustomClaims { return &security.Auth0CustomClaims{} }),
+		validator.WithCustomClaims(func() validator.CustomClaims { return security.NewCustomClaims(claimsMapper) }),
 		validator.WithAllowedClockSkew(time.Minute),
 	)
 	return &BaseOath2ValidateServiceImpl{
@@ -55,20 +60,26 @@ type JwtValidateGrpcServiceImpl struct {
 
 func NewJwtValidateWebAppServiceImpl(
 	auth0RouteSecurityConf authconf.Auth0SecurityConf,
+	identityProviderConf authconf.IdentityProviderConf,
+	identityProvider identityproviders.IdentityProvider,
 ) *JwtValidateWebAppServiceImpl {
 	baseService := createOath2ValidateService(
-		auth0RouteSecurityConf.GetIssuerUrl(),
+		identityProviderConf.GetIssuerUrl(),
 		auth0RouteSecurityConf.GetApiAudience(),
+		identityProvider,
 	)
 	return &JwtValidateWebAppServiceImpl{BaseJwtValidateService: baseService}
 }
 
 func NewJwtValidateGrpcServiceImpl(
 	auth0RouteSecurityConf authconf.Auth0SecurityConf,
+	identityProviderConf authconf.IdentityProviderConf,
+	identityProvider identityproviders.IdentityProvider,
 ) *JwtValidateGrpcServiceImpl {
 	baseService := createOath2ValidateService(
-		auth0RouteSecurityConf.GetIssuerUrl(),
+		identityProviderConf.GetIssuerUrl(),
 		auth0RouteSecurityConf.GetGrpcAudience(),
+		identityProvider,
 	)
 	return &JwtValidateGrpcServiceImpl{BaseJwtValidateService: baseService}
 }
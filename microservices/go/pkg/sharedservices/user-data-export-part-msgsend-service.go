@@ -0,0 +1,47 @@
+package sharedservices
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/segmentio/kafka-go"
+)
+
+// UserDataExportPartMsgSendService sends the user service a service's part of
+// a user's data export
+type UserDataExportPartMsgSendService interface {
+	SendUserDataExportPart(ctx context.Context, dto userdtos.UserDataExportPartDto) error
+	lifecycle.Closable
+}
+
+type UserDataExportPartMsgSendServiceImpl struct {
+	userDataExportPartSender *kfka.KafkaSender[userdtos.UserDataExportPartDto]
+}
+
+func (u *UserDataExportPartMsgSendServiceImpl) SendUserDataExportPart(
+	ctx context.Context,
+	dto userdtos.UserDataExportPartDto,
+) error {
+	return u.userDataExportPartSender.Send(ctx, dto)
+}
+
+func (u *UserDataExportPartMsgSendServiceImpl) Close() error {
+	return u.userDataExportPartSender.Close()
+}
+
+func NewUserDataExportPartMsgSendServiceImpl(kafkaConf conf.KafkaConf) *UserDataExportPartMsgSendServiceImpl {
+	userDataExportPartSender := kfka.NewKafkaSender(
+		&kafka.Writer{
+			Addr:     kafka.TCP(kafkaConf.GetBootstrapServers()...),
+			Topic:    topics.UserDataExportPartTopic,
+			Balancer: &kafka.Murmur2Balancer{},
+		},
+		userdtos.UserDataExportPartDto.MessageKey,
+	)
+	u := &UserDataExportPartMsgSendServiceImpl{userDataExportPartSender: userDataExportPartSender}
+	lifecycle.RegisterClosable(u)
+	return u
+}
@@ -0,0 +1,44 @@
+package sharedservices
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/userdtos"
+	"github.com/segmentio/kafka-go"
+)
+
+// UserDeleteAckMsgSendService replies to the user service once a service has
+// handled a user deletion
+type UserDeleteAckMsgSendService interface {
+	SendUserDeleteAck(ctx context.Context, dto userdtos.UserDeleteAckDto) error
+	lifecycle.Closable
+}
+
+type UserDeleteAckMsgSendServiceImpl struct {
+	userDeleteAckSender *kfka.KafkaSender[userdtos.UserDeleteAckDto]
+}
+
+func (u *UserDeleteAckMsgSendServiceImpl) SendUserDeleteAck(ctx context.Context, dto userdtos.UserDeleteAckDto) error {
+	return u.userDeleteAckSender.Send(ctx, dto)
+}
+
+func (u *UserDeleteAckMsgSendServiceImpl) Close() error {
+	return u.userDeleteAckSender.Close()
+}
+
+func NewUserDeleteAckMsgSendServiceImpl(kafkaConf conf.KafkaConf) *UserDeleteAckMsgSendServiceImpl {
+	userDeleteAckSender := kfka.NewKafkaSender(
+		&kafka.Writer{
+			Addr:     kafka.TCP(kafkaConf.GetBootstrapServers()...),
+			Topic:    topics.UserDeleteAckTopic,
+			Balancer: &kafka.Murmur2Balancer{},
+		},
+		userdtos.UserDeleteAckDto.MessageKey,
+	)
+	u := &UserDeleteAckMsgSendServiceImpl{userDeleteAckSender: userDeleteAckSender}
+	lifecycle.RegisterClosable(u)
+	return u
+}
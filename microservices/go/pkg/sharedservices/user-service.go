@@ -12,13 +12,16 @@ import (
 	cModels "github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedmodels"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedrepos"
 	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices/externalservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/wrappers/option"
 )
 
 type UserService interface {
+	// RequireUser returns the user of an identity, rejecting suspended users
 	RequireUser(ctx context.Context, identity security.Identity) (userbos.UserBo, error)
 	SaveUser(ctx context.Context, userEventDto userdtos.UserChangeEventDto) (userbos.UserBo, error)
 	DeleteUser(ctx context.Context, userEventDto userdtos.UserChangeEventDto) (userbos.UserBo, error)
 	UserExistsWithId(ctx context.Context, userId string) (bool, error)
+	GetUserWithId(ctx context.Context, userId string) (option.Maybe[userbos.UserBo], error)
 }
 
 type UserServiceImpl struct {
@@ -88,27 +91,44 @@ func (u UserServiceImpl) RequireUser(ctx context.Context, identity security.Iden
 		}
 	}
 
+	if user.Suspended {
+		suspendedRuleErr := u.errorService.RuleErrorFromCode(apperrors.ErrCodeUserSuspended)
+		return userbos.UserBo{}, apperrors.NewBadReqErrorFromRuleError(suspendedRuleErr)
+	}
+
 	userBo := userbos.UserBo{}
 	sharedmappers.UserModelToUserBo(user, &userBo)
 	return userBo, nil
 }
 
 func (u UserServiceImpl) UserExistsWithId(ctx context.Context, userId string) (bool, error) {
-	userMaybe, err := u.userRepository.FindByUserId(ctx, userId)
+	userFind, err := u.GetUserWithId(ctx, userId)
 	if err != nil {
 		return false, err
 	}
+	return userFind.IsPresent(), nil
+}
+
+func (u UserServiceImpl) GetUserWithId(ctx context.Context, userId string) (option.Maybe[userbos.UserBo], error) {
+	userMaybe, err := u.userRepository.FindByUserId(ctx, userId)
+	if err != nil {
+		return option.None[userbos.UserBo](), err
+	}
 
-	_, existsInRepo := userMaybe.Get()
-	if existsInRepo {
-		return true, nil
+	if user, existsInRepo := userMaybe.Get(); existsInRepo {
+		userBo := userbos.UserBo{}
+		sharedmappers.UserModelToUserBo(user, &userBo)
+		return option.Perhaps(userBo), nil
 	}
 
 	extUserDto, err := u.extUserService.GetById(ctx, userId)
 	if err != nil {
-		return false, err
+		return option.None[userbos.UserBo](), err
+	}
+	if !extUserDto.Exists {
+		return option.None[userbos.UserBo](), nil
 	}
-	return extUserDto.Exists, nil
+	return option.Perhaps(userbos.UserBo{BaseUserPublicDto: extUserDto.BaseUserPublicDto}), nil
 }
 
 func (u UserServiceImpl) saveUserDataAndGetModel(
@@ -0,0 +1,90 @@
+package sharedservices
+
+import (
+	"context"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/conf"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/lifecycle"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/messaging/kfka/topics"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/objects/dtos/keydtos"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils"
+	"github.com/segmentio/kafka-go"
+)
+
+// UserKeyMsgSendService sends user key rotations and decoy vault seeds from the
+// key service and the acknowledgements of rotation consumers
+type UserKeyMsgSendService interface {
+	SendUserKeyRotation(ctx context.Context, dto keydtos.UserKeyRotationEventDto) error
+	SendUserKeyRotationAck(ctx context.Context, dto keydtos.UserKeyRotationAckDto) error
+	SendDecoyVaultSeed(ctx context.Context, dto keydtos.DecoyVaultSeedEventDto) error
+	lifecycle.Closable
+}
+
+type UserKeyMsgSendServiceImpl struct {
+	userKeyRotationSender    *kfka.KafkaSender[keydtos.UserKeyRotationEventDto]
+	userKeyRotationAckSender *kfka.KafkaSender[keydtos.UserKeyRotationAckDto]
+	decoyVaultSeedSender     *kfka.KafkaSender[keydtos.DecoyVaultSeedEventDto]
+}
+
+func (u *UserKeyMsgSendServiceImpl) SendUserKeyRotation(
+	ctx context.Context,
+	dto keydtos.UserKeyRotationEventDto,
+) error {
+	return u.userKeyRotationSender.Send(ctx, dto)
+}
+
+func (u *UserKeyMsgSendServiceImpl) SendUserKeyRotationAck(
+	ctx context.Context,
+	dto keydtos.UserKeyRotationAckDto,
+) error {
+	return u.userKeyRotationAckSender.Send(ctx, dto)
+}
+
+func (u *UserKeyMsgSendServiceImpl) SendDecoyVaultSeed(
+	ctx context.Context,
+	dto keydtos.DecoyVaultSeedEventDto,
+) error {
+	return u.decoyVaultSeedSender.Send(ctx, dto)
+}
+
+func (u *UserKeyMsgSendServiceImpl) Close() error {
+	return utils.ConcatErrors(
+		u.userKeyRotationSender.Close(),
+		u.userKeyRotationAckSender.Close(),
+		u.decoyVaultSeedSender.Close(),
+	)
+}
+
+func NewUserKeyMsgSendServiceImpl(kafkaConf conf.KafkaConf) *UserKeyMsgSendServiceImpl {
+	userKeyRotationSender := kfka.NewKafkaSender(
+		&kafka.Writer{
+			Addr:     kafka.TCP(kafkaConf.GetBootstrapServers()...),
+			Topic:    topics.UserKeyRotationTopic,
+			Balancer: &kafka.Murmur2Balancer{},
+		},
+		keydtos.UserKeyRotationEventDto.MessageKey,
+	)
+	userKeyRotationAckSender := kfka.NewKafkaSender(
+		&kafka.Writer{
+			Addr:     kafka.TCP(kafkaConf.GetBootstrapServers()...),
+			Topic:    topics.UserKeyRotationAckTopic,
+			Balancer: &kafka.Murmur2Balancer{},
+		},
+		keydtos.UserKeyRotationAckDto.MessageKey,
+	)
+	decoyVaultSeedSender := kfka.NewKafkaSender(
+		&kafka.Writer{
+			Addr:     kafka.TCP(kafkaConf.GetBootstrapServers()...),
+			Topic:    topics.DecoyVaultSeedTopic,
+			Balancer: &kafka.Murmur2Balancer{},
+		},
+		keydtos.DecoyVaultSeedEventDto.MessageKey,
+	)
+	u := &UserKeyMsgSendServiceImpl{
+		userKeyRotationSender:    userKeyRotationSender,
+		userKeyRotationAckSender: userKeyRotationAckSender,
+		decoyVaultSeedSender:     decoyVaultSeedSender,
+	}
+	lifecycle.RegisterClosable(u)
+	return u
+}
@@ -6,7 +6,6 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"golang.org/x/crypto/bcrypt"
-	"golang.org/x/crypto/scrypt"
 	"golang.org/x/exp/slices"
 )
 
@@ -56,6 +55,12 @@ func DecryptAES(key, data []byte) ([]byte, error) {
 	return plaintext, nil
 }
 
+// IsKeyLengthAES returns true if the key is the length of keys generated by
+// GenerateRandomKeyAES
+func IsKeyLengthAES(key []byte) bool {
+	return len(key) == aesKeyLength
+}
+
 // GenerateRandomKeyAES generates a random 32 byte encryption key to be used with AES
 func GenerateRandomKeyAES() ([]byte, error) {
 	return generateRandomBytes(aesKeyLength)
@@ -74,19 +79,7 @@ func DeriveAESKeyFromPassword(password, salt []byte) (derivedKey []byte, passwor
 // key. If a salt already exists, just pass the existing one, and it will be used
 // to derive the new key.
 func DeriveAESKeyFromText(text, salt []byte) (derivedKey []byte, passwordSalt []byte, err error) {
-	if salt == nil {
-		salt, err = generateRandomBytes(32)
-		if err != nil {
-			return nil, nil, err
-		}
-	}
-
-	key, err := scrypt.Key(text, salt, 32768, 8, 1, aesKeyLength)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return key, salt, nil
+	return DeriveAESKeyWithKDF(text, salt, LegacyKDFParams())
 }
 
 // HashKeyBcrypt hashes an encryption key with bcrypts
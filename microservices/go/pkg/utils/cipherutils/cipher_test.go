@@ -61,6 +61,39 @@ func TestPasswordBasedEncryptionWithAES(t *testing.T) {
 
 }
 
+func TestKeyDerivationWithKDFParams(t *testing.T) {
+	startTimeMilli := time.Now().UnixMilli()
+	password := []byte("123242432sgdfdffhgvdfhgdfghdfghetyehgbewrtynbertyuuryt43")
+	argon2Params := cipherutils.DefaultArgon2idKDFParams()
+
+	cv.Convey("When deriving keys with configurable KDF parameters", t, func() {
+		cv.Convey("Expect the legacy parameters derive the same key as DeriveAESKeyFromText", func() {
+			legacyKey, salt, err := cipherutils.DeriveAESKeyFromText(password, nil)
+			cv.So(err, cv.ShouldBeNil)
+			key, _, err := cipherutils.DeriveAESKeyWithKDF(password, salt, cipherutils.LegacyKDFParams())
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(key, cv.ShouldResemble, legacyKey)
+		})
+		cv.Convey("Expect Argon2id derives a repeatable key that can encrypt and decrypt", func() {
+			key, salt, err := cipherutils.DeriveAESKeyWithKDF(password, nil, argon2Params)
+			logWithTimestamp("Derived argon2id key", startTimeMilli)
+			cv.So(err, cv.ShouldBeNil)
+			sameKey, _, err := cipherutils.DeriveAESKeyWithKDF(password, salt, argon2Params)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(sameKey, cv.ShouldResemble, key)
+			testAESKeyCanEncryptAndDecrypt(key, startTimeMilli)
+		})
+		cv.Convey("Expect different parameters are not equal", func() {
+			cv.So(argon2Params.Equals(cipherutils.LegacyKDFParams()), cv.ShouldBeFalse)
+			cv.So(argon2Params.Equals(cipherutils.DefaultArgon2idKDFParams()), cv.ShouldBeTrue)
+		})
+		cv.Convey("Expect an unknown algorithm to fail", func() {
+			_, _, err := cipherutils.DeriveAESKeyWithKDF(password, nil, cipherutils.KDFParams{Algorithm: "unknown"})
+			cv.So(err, cv.ShouldNotBeNil)
+		})
+	})
+}
+
 func TestRandomKeyEncryptionWithAES(t *testing.T) {
 	startTimeMilli := time.Now().UnixMilli()
 	cv.Convey("When given an randomly generated AES key", t, func() {
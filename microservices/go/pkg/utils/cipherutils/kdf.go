@@ -0,0 +1,120 @@
+package cipherutils
+
+import (
+	"errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+type KDFAlgorithm string
+
+const (
+	KDFAlgorithmScrypt   KDFAlgorithm = "scrypt"
+	KDFAlgorithmArgon2id KDFAlgorithm = "argon2id"
+)
+
+const kdfSaltLength = 32
+
+// ScryptParams are the cost parameters used by scrypt
+type ScryptParams struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// Argon2idParams are the cost parameters used by Argon2id
+type Argon2idParams struct {
+	Time      uint32 `json:"time"`
+	MemoryKiB uint32 `json:"memoryKiB"`
+	Threads   uint8  `json:"threads"`
+}
+
+// KDFParams describes the algorithm and parameters used to derive a key from
+// text. Only the parameters belonging to the chosen algorithm are used.
+type KDFParams struct {
+	Algorithm KDFAlgorithm   `json:"algorithm"`
+	Scrypt    ScryptParams   `json:"scrypt"`
+	Argon2id  Argon2idParams `json:"argon2id"`
+}
+
+// LegacyKDFParams returns the scrypt parameters used by DeriveAESKeyFromText,
+// which were used for all keys derived before KDF parameters became
+// configurable.
+func LegacyKDFParams() KDFParams {
+	return KDFParams{
+		Algorithm: KDFAlgorithmScrypt,
+		Scrypt:    ScryptParams{N: 32768, R: 8, P: 1},
+	}
+}
+
+// DefaultArgon2idKDFParams returns the recommended Argon2id parameters
+func DefaultArgon2idKDFParams() KDFParams {
+	return KDFParams{
+		Algorithm: KDFAlgorithmArgon2id,
+		Argon2id:  Argon2idParams{Time: 3, MemoryKiB: 64 * 1024, Threads: 4},
+	}
+}
+
+// IsEmpty returns true if no algorithm was set
+func (p KDFParams) IsEmpty() bool {
+	return p.Algorithm == ""
+}
+
+// Equals returns true if both parameter sets derive the same key with the
+// same text and salt
+func (p KDFParams) Equals(other KDFParams) bool {
+	if p.Algorithm != other.Algorithm {
+		return false
+	}
+	switch p.Algorithm {
+	case KDFAlgorithmScrypt:
+		return p.Scrypt == other.Scrypt
+	case KDFAlgorithmArgon2id:
+		return p.Argon2id == other.Argon2id
+	default:
+		return p == other
+	}
+}
+
+// GenerateKDFSalt generates a random salt of the length DeriveAESKeyWithKDF
+// generates when no salt is provided
+func GenerateKDFSalt() ([]byte, error) {
+	return generateRandomBytes(kdfSaltLength)
+}
+
+// DeriveAESKeyWithKDF generates an encryption key with text using the
+// algorithm and parameters provided. Salt can be passed as nil if no salt is
+// provided as a new salt will be returned alongside the new key. If a salt
+// already exists, just pass the existing one, and it will be used to derive the
+// new key.
+func DeriveAESKeyWithKDF(
+	text, salt []byte,
+	params KDFParams,
+) (derivedKey []byte, passwordSalt []byte, err error) {
+	if salt == nil {
+		salt, err = GenerateKDFSalt()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var key []byte
+	switch params.Algorithm {
+	case KDFAlgorithmScrypt:
+		sp := params.Scrypt
+		key, err = scrypt.Key(text, salt, sp.N, sp.R, sp.P, aesKeyLength)
+		if err != nil {
+			return nil, nil, err
+		}
+	case KDFAlgorithmArgon2id:
+		ap := params.Argon2id
+		if ap.Time < 1 || ap.Threads < 1 {
+			return nil, nil, errors.New("argon2id time and threads must be at least 1")
+		}
+		key = argon2.IDKey(text, salt, ap.Time, ap.MemoryKiB, ap.Threads, aesKeyLength)
+	default:
+		return nil, nil, errors.New("unsupported key derivation algorithm: " + string(params.Algorithm))
+	}
+
+	return key, salt, nil
+}
@@ -0,0 +1,208 @@
+package cipherutils
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+)
+
+// SRP-6a (RFC 5054) with the 2048-bit group and SHA-256. The password input x
+// is derived from the SRP salt and a key the client derives from its passcode,
+// so the passcode never has to leave the client.
+//
+// Values exchanged between the client and server are big-endian byte slices:
+//
+//	k  = H(N | PAD(g))
+//	x  = H(s | passcodeKey)
+//	v  = g^x
+//	A  = g^a, B = k*v + g^b
+//	u  = H(PAD(A) | PAD(B))
+//	S  = (B - k*g^x)^(a + u*x) = (A * v^u)^b
+//	K  = H(PAD(S))
+//	M1 = H(PAD(A) | PAD(B) | K)
+//	M2 = H(PAD(A) | M1 | K)
+
+const srpGroup2048Hex = "" +
+	"AC6BDB41324A9A9BF166DE5E1389582FAF72B6651987EE07FC3192943DB56050" +
+	"A37329CBB4A099ED8193E0757767A13DD52312AB4B03310DCD7F48A9DA04FD50" +
+	"E8083969EDB767B0CF6095179A163AB3661A05FBD5FAAAE82918A9962F0B93B8" +
+	"55F97993EC975EEAA80D740ADBF4FF747359D041D5C33EA71D281E446B14773B" +
+	"CA97B43A23FB801676BD207A436C6481F1D2B9078717461A5B9D32E688F87748" +
+	"544523B524B0D57D5EA77A2775D2ECFA032CFBDBF52FB3786160279004E57AE6" +
+	"AF874E7303CE53299CCC041C7BC308D82A5698F3A8D0C38271AE35F8E9DBFBB6" +
+	"94B5C803D89F7AE435DE236D525F54759B65E372FCD68EF20FA7111F9E4AFF73"
+
+const srpPrivateValueLength = 32
+
+var (
+	srpN, _ = new(big.Int).SetString(srpGroup2048Hex, 16)
+	srpG    = big.NewInt(2)
+	srpK    = srpHashInt(srpN.Bytes(), srpPad(srpG))
+)
+
+// ErrSRPInvalidPublicValue is returned when a public value is zero modulo N,
+// which would let the other party force a known session key
+var ErrSRPInvalidPublicValue = errors.New("invalid SRP public value")
+
+// ErrSRPProofMismatch is returned when a proof does not match the expected
+// value, meaning the other party does not know the passcode key or verifier
+var ErrSRPProofMismatch = errors.New("SRP proof mismatch")
+
+// SRPComputeVerifier returns the verifier for a salt and passcode key. The
+// server stores only the verifier and salt.
+func SRPComputeVerifier(salt, passcodeKey []byte) []byte {
+	x := srpComputeX(salt, passcodeKey)
+	return new(big.Int).Exp(srpG, x, srpN).Bytes()
+}
+
+// GenerateSRPSalt generates a random salt to compute a verifier with
+func GenerateSRPSalt() ([]byte, error) {
+	return generateRandomBytes(kdfSaltLength)
+}
+
+// SRPServer is the server side of an SRP-6a exchange
+type SRPServer struct {
+	verifier *big.Int
+	b        *big.Int
+	publicB  *big.Int
+}
+
+// NewSRPServer generates a private value for the verifier and returns a
+// server ready to be challenged
+func NewSRPServer(verifier []byte) (*SRPServer, error) {
+	b, err := generateRandomBytes(srpPrivateValueLength)
+	if err != nil {
+		return nil, err
+	}
+	return RestoreSRPServer(verifier, b)
+}
+
+// RestoreSRPServer rebuilds a server from the verifier and a private value
+// returned by PrivateValue, so the second round of an exchange can be handled
+// by a different request than the first.
+func RestoreSRPServer(verifier, privateValue []byte) (*SRPServer, error) {
+	v := new(big.Int).SetBytes(verifier)
+	if v.Sign() == 0 {
+		return nil, errors.New("SRP verifier is empty")
+	}
+	b := new(big.Int).SetBytes(privateValue)
+	kv := new(big.Int).Mul(srpK, v)
+	publicB := kv.Add(kv, new(big.Int).Exp(srpG, b, srpN))
+	publicB.Mod(publicB, srpN)
+	return &SRPServer{verifier: v, b: b, publicB: publicB}, nil
+}
+
+// PublicValue returns B, which is sent to the client
+func (s SRPServer) PublicValue() []byte {
+	return s.publicB.Bytes()
+}
+
+// PrivateValue returns b, which must be kept secret
+func (s SRPServer) PrivateValue() []byte {
+	return s.b.Bytes()
+}
+
+// VerifyClient checks the client proof M1 for the client public value A. If
+// it is valid, the shared session key K and the server proof M2 are returned.
+func (s SRPServer) VerifyClient(clientPublic, clientProof []byte) (sessionKey []byte, serverProof []byte, err error) {
+	publicA := new(big.Int).SetBytes(clientPublic)
+	if publicA.Mod(publicA, srpN).Sign() == 0 {
+		return nil, nil, ErrSRPInvalidPublicValue
+	}
+	u := srpHashInt(srpPad(publicA), srpPad(s.publicB))
+	if u.Sign() == 0 {
+		return nil, nil, ErrSRPInvalidPublicValue
+	}
+	base := new(big.Int).Exp(s.verifier, u, srpN)
+	base.Mul(base, publicA)
+	base.Mod(base, srpN)
+	premasterSecret := new(big.Int).Exp(base, s.b, srpN)
+	sessionKey = srpHash(srpPad(premasterSecret))
+
+	expectedProof := srpHash(srpPad(publicA), srpPad(s.publicB), sessionKey)
+	if subtle.ConstantTimeCompare(expectedProof, clientProof) != 1 {
+		return nil, nil, ErrSRPProofMismatch
+	}
+	return sessionKey, srpHash(srpPad(publicA), clientProof, sessionKey), nil
+}
+
+// SRPClient is the client side of an SRP-6a exchange
+type SRPClient struct {
+	a       *big.Int
+	publicA *big.Int
+}
+
+// NewSRPClient generates a private value and returns a client ready to answer
+// a server challenge
+func NewSRPClient() (*SRPClient, error) {
+	aBytes, err := generateRandomBytes(srpPrivateValueLength)
+	if err != nil {
+		return nil, err
+	}
+	a := new(big.Int).SetBytes(aBytes)
+	return &SRPClient{a: a, publicA: new(big.Int).Exp(srpG, a, srpN)}, nil
+}
+
+// PublicValue returns A, which is sent to the server
+func (c SRPClient) PublicValue() []byte {
+	return c.publicA.Bytes()
+}
+
+// ComputeProof answers a server challenge of the salt and server public value
+// B, returning the client proof M1, the expected server proof M2 and the
+// shared session key K.
+func (c SRPClient) ComputeProof(
+	salt, passcodeKey, serverPublic []byte,
+) (clientProof []byte, expectedServerProof []byte, sessionKey []byte, err error) {
+	publicB := new(big.Int).SetBytes(serverPublic)
+	if publicB.Mod(publicB, srpN).Sign() == 0 {
+		return nil, nil, nil, ErrSRPInvalidPublicValue
+	}
+	u := srpHashInt(srpPad(c.publicA), srpPad(publicB))
+	if u.Sign() == 0 {
+		return nil, nil, nil, ErrSRPInvalidPublicValue
+	}
+	x := srpComputeX(salt, passcodeKey)
+	kgx := new(big.Int).Exp(srpG, x, srpN)
+	kgx.Mul(kgx, srpK)
+	base := new(big.Int).Sub(publicB, kgx)
+	base.Mod(base, srpN)
+	exponent := new(big.Int).Mul(u, x)
+	exponent.Add(exponent, c.a)
+	premasterSecret := new(big.Int).Exp(base, exponent, srpN)
+	sessionKey = srpHash(srpPad(premasterSecret))
+
+	clientProof = srpHash(srpPad(c.publicA), srpPad(publicB), sessionKey)
+	expectedServerProof = srpHash(srpPad(c.publicA), clientProof, sessionKey)
+	return clientProof, expectedServerProof, sessionKey, nil
+}
+
+// VerifySRPServerProof checks the proof returned by the server against the
+// expected proof from SRPClient.ComputeProof
+func VerifySRPServerProof(expectedServerProof, serverProof []byte) error {
+	if subtle.ConstantTimeCompare(expectedServerProof, serverProof) != 1 {
+		return ErrSRPProofMismatch
+	}
+	return nil
+}
+
+func srpComputeX(salt, passcodeKey []byte) *big.Int {
+	return srpHashInt(salt, passcodeKey)
+}
+
+func srpPad(value *big.Int) []byte {
+	return value.FillBytes(make([]byte, len(srpN.Bytes())))
+}
+
+func srpHash(values ...[]byte) []byte {
+	hash := sha256.New()
+	for _, value := range values {
+		hash.Write(value)
+	}
+	return hash.Sum(nil)
+}
+
+func srpHashInt(values ...[]byte) *big.Int {
+	return new(big.Int).SetBytes(srpHash(values...))
+}
@@ -0,0 +1,62 @@
+package cipherutils_test
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSRPExchange(t *testing.T) {
+	cv.Convey("When a verifier is computed from a passcode key", t, func() {
+		passcodeKey, err := cipherutils.GenerateRandomKeyAES()
+		cv.So(err, cv.ShouldBeNil)
+		salt, err := cipherutils.GenerateSRPSalt()
+		cv.So(err, cv.ShouldBeNil)
+		verifier := cipherutils.SRPComputeVerifier(salt, passcodeKey)
+
+		server, err := cipherutils.NewSRPServer(verifier)
+		cv.So(err, cv.ShouldBeNil)
+		client, err := cipherutils.NewSRPClient()
+		cv.So(err, cv.ShouldBeNil)
+
+		cv.Convey("Expect a client with the passcode key to agree on a session key with the server", func() {
+			clientProof, expectedServerProof, clientSessionKey, err := client.ComputeProof(
+				salt,
+				passcodeKey,
+				server.PublicValue(),
+			)
+			cv.So(err, cv.ShouldBeNil)
+
+			serverSessionKey, serverProof, err := server.VerifyClient(client.PublicValue(), clientProof)
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(serverSessionKey, cv.ShouldResemble, clientSessionKey)
+			cv.So(cipherutils.VerifySRPServerProof(expectedServerProof, serverProof), cv.ShouldBeNil)
+		})
+
+		cv.Convey("Expect a server restored from its private value to verify the client", func() {
+			restored, err := cipherutils.RestoreSRPServer(verifier, server.PrivateValue())
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(restored.PublicValue(), cv.ShouldResemble, server.PublicValue())
+
+			clientProof, _, _, err := client.ComputeProof(salt, passcodeKey, restored.PublicValue())
+			cv.So(err, cv.ShouldBeNil)
+			_, _, err = restored.VerifyClient(client.PublicValue(), clientProof)
+			cv.So(err, cv.ShouldBeNil)
+		})
+
+		cv.Convey("Expect a client with the wrong passcode key to be rejected", func() {
+			wrongKey, err := cipherutils.GenerateRandomKeyAES()
+			cv.So(err, cv.ShouldBeNil)
+			clientProof, _, _, err := client.ComputeProof(salt, wrongKey, server.PublicValue())
+			cv.So(err, cv.ShouldBeNil)
+
+			_, _, err = server.VerifyClient(client.PublicValue(), clientProof)
+			cv.So(err, cv.ShouldEqual, cipherutils.ErrSRPProofMismatch)
+		})
+
+		cv.Convey("Expect a client public value of zero to be rejected", func() {
+			_, _, err := server.VerifyClient([]byte{0}, []byte("proof"))
+			cv.So(err, cv.ShouldEqual, cipherutils.ErrSRPInvalidPublicValue)
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package cipherutils
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP (RFC 6238) with HMAC-SHA1, 30 second steps and 6 digit codes, which are
+// the defaults of every common authenticator app.
+
+const (
+	totpSecretLength   = 20
+	totpStepSeconds    = 30
+	totpDigits         = 6
+	totpDigitsModulus  = 1000000
+	backupCodeLength   = 10
+	backupCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+)
+
+var totpSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret generates a random TOTP secret
+func GenerateTOTPSecret() ([]byte, error) {
+	return generateRandomBytes(totpSecretLength)
+}
+
+// EncodeTOTPSecret returns the secret in the base32 form authenticator apps
+// accept for manual entry
+func EncodeTOTPSecret(secret []byte) string {
+	return totpSecretEncoding.EncodeToString(secret)
+}
+
+// TOTPKeyURI returns the otpauth URI authenticator apps read from QR codes
+func TOTPKeyURI(issuer, accountName string, secret []byte) string {
+	query := url.Values{}
+	query.Set("secret", EncodeTOTPSecret(secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpStepSeconds))
+	label := url.PathEscape(issuer + ":" + accountName)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TOTPStep returns the time step of a time
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / totpStepSeconds
+}
+
+// TOTPCode returns the code of a secret for a time step
+func TOTPCode(secret []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, truncated%totpDigitsModulus)
+}
+
+// VerifyTOTPCode checks a code against the time steps within skew steps of
+// the time provided. The matching step is returned so callers can reject a
+// code that was already used.
+func VerifyTOTPCode(secret []byte, code string, t time.Time, skew int64) (step int64, valid bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	currentStep := TOTPStep(t)
+	for s := currentStep - skew; s <= currentStep+skew; s++ {
+		if subtle.ConstantTimeCompare([]byte(TOTPCode(secret, s)), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateBackupCodes generates random single use codes that can be used in
+// place of a TOTP code
+func GenerateBackupCodes(count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		randomBytes, err := generateRandomBytes(backupCodeLength)
+		if err != nil {
+			return nil, err
+		}
+		var code strings.Builder
+		for _, b := range randomBytes {
+			// The alphabet length divides 256, so the modulus is unbiased
+			code.WriteByte(backupCodeAlphabet[int(b)%len(backupCodeAlphabet)])
+		}
+		codes = append(codes, code.String())
+	}
+	return codes, nil
+}
+
+// NormalizeBackupCode lowercases a backup code and removes the separators
+// users may type between groups of characters
+func NormalizeBackupCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
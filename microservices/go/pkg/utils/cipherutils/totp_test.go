@@ -0,0 +1,51 @@
+package cipherutils_test
+
+import (
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/utils/cipherutils"
+	cv "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func TestTOTP(t *testing.T) {
+	// The SHA1 secret of the RFC 6238 test vectors
+	secret := []byte("12345678901234567890")
+
+	cv.Convey("When generating TOTP codes", t, func() {
+		cv.Convey("Expect the codes to match the RFC 6238 test vectors", func() {
+			cv.So(cipherutils.TOTPCode(secret, cipherutils.TOTPStep(time.Unix(59, 0))), cv.ShouldEqual, "287082")
+			cv.So(cipherutils.TOTPCode(secret, cipherutils.TOTPStep(time.Unix(1111111109, 0))), cv.ShouldEqual, "081804")
+			cv.So(cipherutils.TOTPCode(secret, cipherutils.TOTPStep(time.Unix(2000000000, 0))), cv.ShouldEqual, "279037")
+		})
+
+		cv.Convey("Expect a code from the previous step to verify within the skew", func() {
+			now := time.Unix(1111111109, 0)
+			previousStep := cipherutils.TOTPStep(now) - 1
+			step, valid := cipherutils.VerifyTOTPCode(secret, cipherutils.TOTPCode(secret, previousStep), now, 1)
+			cv.So(valid, cv.ShouldBeTrue)
+			cv.So(step, cv.ShouldEqual, previousStep)
+		})
+
+		cv.Convey("Expect a code outside the skew to be rejected", func() {
+			now := time.Unix(1111111109, 0)
+			oldCode := cipherutils.TOTPCode(secret, cipherutils.TOTPStep(now)-2)
+			_, valid := cipherutils.VerifyTOTPCode(secret, oldCode, now, 1)
+			cv.So(valid, cv.ShouldBeFalse)
+		})
+	})
+
+	cv.Convey("When generating backup codes", t, func() {
+		codes, err := cipherutils.GenerateBackupCodes(10)
+		cv.So(err, cv.ShouldBeNil)
+
+		cv.Convey("Expect the requested number of distinct codes", func() {
+			cv.So(codes, cv.ShouldHaveLength, 10)
+			cv.So(codes[0], cv.ShouldNotEqual, codes[1])
+		})
+
+		cv.Convey("Expect a code typed with separators to normalize to the original", func() {
+			typed := codes[0][:5] + "-" + codes[0][5:]
+			cv.So(cipherutils.NormalizeBackupCode(typed), cv.ShouldEqual, codes[0])
+		})
+	})
+}
@@ -2,6 +2,14 @@ package kvstoreutils
 
 import "strings"
 
+var patternEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
 func CombineKeySections(keyStrings ...string) string {
 	return strings.Join(keyStrings, "/")
 }
+
+// EscapePattern escapes a key section so it is matched literally in a key
+// pattern
+func EscapePattern(keySection string) string {
+	return patternEscaper.Replace(keySection)
+}
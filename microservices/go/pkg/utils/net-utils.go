@@ -0,0 +1,19 @@
+package utils
+
+import "net"
+
+// IPNetwork returns the network of an IP address in CIDR notation, masking
+// IPv4 addresses to the IPv4 prefix length and IPv6 addresses to the IPv6
+// prefix length. False is returned if the address cannot be parsed.
+func IPNetwork(ip string, ipv4PrefixLength, ipv6PrefixLength int) (string, bool) {
+	parsedIp := net.ParseIP(ip)
+	if parsedIp == nil {
+		return "", false
+	}
+	if ipv4 := parsedIp.To4(); ipv4 != nil {
+		network := net.IPNet{IP: ipv4.Mask(net.CIDRMask(ipv4PrefixLength, 32)), Mask: net.CIDRMask(ipv4PrefixLength, 32)}
+		return network.String(), true
+	}
+	network := net.IPNet{IP: parsedIp.Mask(net.CIDRMask(ipv6PrefixLength, 128)), Mask: net.CIDRMask(ipv6PrefixLength, 128)}
+	return network.String(), true
+}
@@ -221,7 +221,7 @@ func (q GinCtxReqQueryReaderImpl) parseSortQueryStrings(values []string) []pagin
 		return pagination.NewSortField(field, direction)
 	})
 	return slice.Filter(mappedSortFields, func(sf pagination.SortField) bool {
-		return utils.StringIsBlank(sf.Field)
+		return !utils.StringIsBlank(sf.Field)
 	})
 }
 
@@ -0,0 +1,45 @@
+package queryreq_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/datasource/pagination"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/sharedservices"
+	"github.com/obenkenobi/cypher-log/microservices/go/pkg/web/queryreq"
+	cv "github.com/smartystreets/goconvey/convey"
+	"net/http/httptest"
+	"testing"
+)
+
+func newQueryReader(target string) queryreq.ReqQueryReader {
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest("GET", target, nil)
+	return queryreq.NewGinCtxReqQueryReaderImpl(ginCtx, sharedservices.NewErrorServiceImpl())
+}
+
+func TestReadSort(t *testing.T) {
+	cv.Convey("When reading sort fields from the query", t, func() {
+		var sortFields []pagination.SortField
+		err := newQueryReader("/?sort=userName,desc&sort=&sort=%20,desc&sort=createdAt").
+			ReadSort("sort", &sortFields).
+			Complete()
+
+		cv.Convey("Expect blank fields are dropped and named fields are kept in order", func() {
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(sortFields, cv.ShouldResemble, []pagination.SortField{
+				pagination.NewSortField("userName", pagination.Descending),
+				pagination.NewSortField("createdAt", pagination.Ascending),
+			})
+		})
+	})
+	cv.Convey("When the sort query is missing", t, func() {
+		var sortFields []pagination.SortField
+		defaultSort := []pagination.SortField{pagination.NewSortField("_id", pagination.Ascending)}
+		err := newQueryReader("/").ReadSortOrDefault("sort", &sortFields, defaultSort).Complete()
+
+		cv.Convey("Expect the default sort fields", func() {
+			cv.So(err, cv.ShouldBeNil)
+			cv.So(sortFields, cv.ShouldResemble, defaultSort)
+		})
+	})
+}
@@ -0,0 +1,8 @@
+package routing
+
+// Headers the uiservice gateway sets on proxied requests to identify the
+// client. Values sent by the client itself are overwritten.
+const (
+	HeaderClientDeviceId = "X-Client-Device-Id"
+	HeaderClientIp       = "X-Client-Ip"
+)
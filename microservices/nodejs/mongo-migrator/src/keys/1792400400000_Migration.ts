@@ -0,0 +1,18 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792400400000 implements MigrationInterface {// userKeys vaults
+  public async up(db: Db): Promise<any> {
+    await db.collection('userKeys').updateMany({ vaultId: { $exists: false } },
+        { $set: { vaultId: "default", vaultName: "" } })
+    await db.collection('userKeys').dropIndex( "idx-userKeys-userId-unique" )
+    await db.collection('userKeys').createIndex({ userId: 1, vaultId: 1 },
+        { unique: true, name: "idx-userKeys-userId-vaultId-unique" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userKeys').dropIndex( "idx-userKeys-userId-vaultId-unique" )
+    await db.collection('userKeys').createIndex({ userId: 1 },
+        { unique: true, name: "idx-userKeys-userId-unique" })
+  }
+}
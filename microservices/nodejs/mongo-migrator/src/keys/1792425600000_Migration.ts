@@ -0,0 +1,24 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+const sentEventRetentionSeconds = 7 * 24 * 60 * 60
+
+export class Migration1792425600000 implements MigrationInterface {// userKeyRotationOutbox
+  public async up(db: Db): Promise<any> {
+    await db.collection('userKeyRotationOutbox').createIndex({ sent: 1, _id: 1 },
+        { name: "idx-userKeyRotationOutbox-sent-id" })
+    await db.collection('userKeyRotationOutbox').createIndex({ sentAt: 1 },
+        { expireAfterSeconds: sentEventRetentionSeconds, partialFilterExpression: { sent: true },
+          name: "idx-userKeyRotationOutbox-sentAt-ttl" })
+
+    // Rotations sent with a rotation session can no longer be finished, so
+    // they are left to be superseded
+    await db.collection('userKeys').updateMany({ rotationSessionKid: { $exists: true } },
+        { $set: { rotationExpiresAt: 0 }, $unset: { rotationSessionKid: "" } })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userKeys').updateMany({}, { $unset: { rotationExpiresAt: "" } })
+    await db.collection('userKeyRotationOutbox').drop()
+  }
+}
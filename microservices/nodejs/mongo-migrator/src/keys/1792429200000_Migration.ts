@@ -0,0 +1,18 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+const sentEventRetentionSeconds = 7 * 24 * 60 * 60
+
+export class Migration1792429200000 implements MigrationInterface {// decoyVaultSeedOutbox
+  public async up(db: Db): Promise<any> {
+    await db.collection('decoyVaultSeedOutbox').createIndex({ sent: 1, _id: 1 },
+        { name: "idx-decoyVaultSeedOutbox-sent-id" })
+    await db.collection('decoyVaultSeedOutbox').createIndex({ sentAt: 1 },
+        { expireAfterSeconds: sentEventRetentionSeconds, partialFilterExpression: { sent: true },
+          name: "idx-decoyVaultSeedOutbox-sentAt-ttl" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('decoyVaultSeedOutbox').drop()
+  }
+}
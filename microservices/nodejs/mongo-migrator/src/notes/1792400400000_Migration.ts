@@ -0,0 +1,15 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792400400000 implements MigrationInterface {// note vaults
+  public async up(db: Db): Promise<any> {
+    await db.collection('note').updateMany({ vaultId: { $exists: false } },
+        { $set: { vaultId: "default" } })
+    await db.collection('note').createIndex({ userId: 1, vaultId: 1 },
+        { name: "idx-note-userId-vaultId" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('note').dropIndex( "idx-note-userId-vaultId" )
+  }
+}
@@ -0,0 +1,48 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+const sentEventRetentionSeconds = 7 * 24 * 60 * 60
+
+export class Migration1792400400000 implements MigrationInterface {// userChangeOutbox
+  public async up(db: Db): Promise<any> {
+    await db.collection('userChangeOutbox').createIndex({ userId: 1, sequence: 1 },
+        { unique: true, name: "idx-userChangeOutbox-userId-sequence-unique" })
+    await db.collection('userChangeOutbox').createIndex({ sent: 1, userId: 1, sequence: 1 },
+        { name: "idx-userChangeOutbox-sent-userId-sequence" })
+    await db.collection('userChangeOutbox').createIndex({ sentAt: 1 },
+        { expireAfterSeconds: sentEventRetentionSeconds, partialFilterExpression: { sent: true },
+          name: "idx-userChangeOutbox-sentAt-ttl" })
+
+    // Move the changes the polling task had not distributed yet to the outbox
+    const now = new Date()
+    const pendingUsers = await db.collection('users').find({ distributed: { $ne: true } }).toArray()
+    for (const user of pendingUsers) {
+      await db.collection('userChangeOutbox').insertOne({
+        created_at: now,
+        updated_at: now,
+        userId: user._id.toHexString(),
+        sequence: 1,
+        payload: JSON.stringify({
+          userName: user.userName,
+          displayName: user.displayName,
+          id: user._id.toHexString(),
+          createdAt: user.created_at.getTime(),
+          updatedAt: user.updated_at.getTime(),
+          authId: user.authId,
+          action: user.toBeDeleted === true ? 1 : 0,
+        }),
+        sent: false,
+        sentAt: new Date(0),
+      })
+    }
+    await db.collection('users').deleteMany({ toBeDeleted: true })
+    await db.collection('users').updateMany({},
+        { $set: { changeSequence: 1 }, $unset: { distributed: "", toBeDeleted: "" } })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('users').updateMany({},
+        { $set: { distributed: false, toBeDeleted: false }, $unset: { changeSequence: "" } })
+    await db.collection('userChangeOutbox').drop()
+  }
+}
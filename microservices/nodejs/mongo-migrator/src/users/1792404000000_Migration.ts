@@ -0,0 +1,42 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+const userDeleteAction = 1
+const userDeleteParticipants = ["keyservice", "noteservice"]
+
+export class Migration1792404000000 implements MigrationInterface {// userDeletions
+  public async up(db: Db): Promise<any> {
+    await db.collection('userDeletions').createIndex({ userId: 1 },
+        { unique: true, name: "idx-userDeletions-userId-unique" })
+    await db.collection('userDeletions').createIndex({ authId: 1, status: 1 },
+        { name: "idx-userDeletions-authId-status" })
+    await db.collection('userDeletions').createIndex({ status: 1, created_at: 1 },
+        { name: "idx-userDeletions-status-created_at" })
+
+    // Deletions that were not published yet now wait for every service before
+    // the user is deleted from the auth server
+    const now = new Date()
+    const unsentEvents = await db.collection('userChangeOutbox').find({ sent: false }).toArray()
+    for (const outboxEvent of unsentEvents) {
+      const event = JSON.parse(outboxEvent.payload)
+      if (event.action !== userDeleteAction) {
+        continue
+      }
+      await db.collection('userDeletions').insertOne({
+        created_at: now,
+        updated_at: now,
+        userId: event.id,
+        authId: event.authId,
+        userName: event.userName,
+        status: "pending",
+        participants: userDeleteParticipants.map(participant => ({
+          participant, status: "pending", error: "", updatedAt: now,
+        })),
+      })
+    }
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userDeletions').drop()
+  }
+}
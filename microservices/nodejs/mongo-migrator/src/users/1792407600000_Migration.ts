@@ -0,0 +1,17 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+const exportRetentionSeconds = 7 * 24 * 60 * 60
+
+export class Migration1792407600000 implements MigrationInterface {// userDataExports
+  public async up(db: Db): Promise<any> {
+    await db.collection('userDataExports').createIndex({ userId: 1, created_at: -1 },
+        { name: "idx-userDataExports-userId-created_at" })
+    await db.collection('userDataExports').createIndex({ created_at: 1 },
+        { expireAfterSeconds: exportRetentionSeconds, name: "idx-userDataExports-created_at-ttl" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userDataExports').drop()
+  }
+}
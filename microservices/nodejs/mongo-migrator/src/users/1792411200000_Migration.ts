@@ -0,0 +1,13 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792411200000 implements MigrationInterface {// userAvatars
+  public async up(db: Db): Promise<any> {
+    await db.collection('userAvatars.files').createIndex({ "metadata.userId": 1 },
+        { name: "idx-userAvatars-metadata.userId" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userAvatars.files').dropIndex("idx-userAvatars-metadata.userId")
+  }
+}
@@ -0,0 +1,13 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792414800000 implements MigrationInterface {// users listed newest first
+  public async up(db: Db): Promise<any> {
+    await db.collection('users').createIndex({ created_at: -1 },
+        { name: "idx-users-created_at" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('users').dropIndex("idx-users-created_at")
+  }
+}
@@ -0,0 +1,13 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792418400000 implements MigrationInterface {// sent user changes in the order they were sent
+  public async up(db: Db): Promise<any> {
+    await db.collection('userChangeOutbox').createIndex({ sentAt: 1, _id: 1 },
+        { partialFilterExpression: { sent: true }, name: "idx-userChangeOutbox-sentAt-id" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userChangeOutbox').dropIndex("idx-userChangeOutbox-sentAt-id")
+  }
+}
@@ -0,0 +1,19 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792422000000 implements MigrationInterface {// dead letters and their audits
+  public async up(db: Db): Promise<any> {
+    await db.collection('deadLetters').createIndex({ topic: 1, partition: 1, offset: 1 },
+        { unique: true, name: "idx-deadLetters-topic-partition-offset" })
+    await db.collection('deadLetters').createIndex({ topic: 1, _id: 1 },
+        { name: "idx-deadLetters-topic-id" })
+    await db.collection('deadLetterAudits').createIndex({ created_at: -1 },
+        { name: "idx-deadLetterAudits-created_at" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('deadLetters').dropIndex("idx-deadLetters-topic-partition-offset")
+    await db.collection('deadLetters').dropIndex("idx-deadLetters-topic-id")
+    await db.collection('deadLetterAudits').dropIndex("idx-deadLetterAudits-created_at")
+  }
+}
@@ -0,0 +1,25 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792432800000 implements MigrationInterface {// users deleted from the identity provider after commit
+  public async up(db: Db): Promise<any> {
+    // Completed deletions already deleted their user from the identity provider
+    await db.collection('userDeletions').updateMany({ status: "completed" },
+        { $set: { identityProviderDeleted: true } })
+    await db.collection('userDeletions').updateMany({ status: { $ne: "completed" } },
+        { $set: { identityProviderDeleted: false } })
+    await db.collection('userDeletions').dropIndex("idx-userDeletions-authId-status")
+    await db.collection('userDeletions').createIndex({ authId: 1, identityProviderDeleted: 1 },
+        { name: "idx-userDeletions-authId-identityProviderDeleted" })
+    await db.collection('userDeletions').createIndex({ status: 1, identityProviderDeleted: 1, _id: 1 },
+        { name: "idx-userDeletions-status-identityProviderDeleted-id" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userDeletions').dropIndex("idx-userDeletions-status-identityProviderDeleted-id")
+    await db.collection('userDeletions').dropIndex("idx-userDeletions-authId-identityProviderDeleted")
+    await db.collection('userDeletions').createIndex({ authId: 1, status: 1 },
+        { name: "idx-userDeletions-authId-status" })
+    await db.collection('userDeletions').updateMany({}, { $unset: { identityProviderDeleted: "" } })
+  }
+}
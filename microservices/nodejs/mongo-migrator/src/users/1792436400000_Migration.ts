@@ -0,0 +1,24 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792436400000 implements MigrationInterface {// userDataExports as the outbox of their requests
+  public async up(db: Db): Promise<any> {
+    // Requests of existing exports were sent when the export was created
+    await db.collection('userDataExports').updateMany({ requestSent: { $exists: false } },
+        { $set: { requestSent: true } })
+    await db.collection('userDataExports').createIndex({ requestSent: 1, _id: 1 },
+        { name: "idx-userDataExports-requestSent-id" })
+    // A user has one pending export at a time. Users may have several from
+    // before the index, so pending exports are failed to be requested again.
+    await db.collection('userDataExports').updateMany({ status: "pending" },
+        { $set: { status: "failed" } })
+    await db.collection('userDataExports').createIndex({ userId: 1 },
+        { unique: true, partialFilterExpression: { status: "pending" }, name: "idx-userDataExports-userId-pending" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userDataExports').dropIndex("idx-userDataExports-userId-pending")
+    await db.collection('userDataExports').dropIndex("idx-userDataExports-requestSent-id")
+    await db.collection('userDataExports').updateMany({}, { $unset: { requestSent: "", requestSentAt: "" } })
+  }
+}
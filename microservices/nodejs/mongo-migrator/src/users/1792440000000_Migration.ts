@@ -0,0 +1,16 @@
+import { Db } from 'mongodb'
+import { MigrationInterface } from 'mongo-migrate-ts';
+
+export class Migration1792440000000 implements MigrationInterface {// sent user changes in change feed order
+  public async up(db: Db): Promise<any> {
+    await db.collection('userChangeOutbox').dropIndex("idx-userChangeOutbox-sentAt-id")
+    await db.collection('userChangeOutbox').createIndex({ feedPosition: 1 },
+        { partialFilterExpression: { sent: true }, name: "idx-userChangeOutbox-feedPosition" })
+  }
+
+  public async down(db: Db): Promise<any> {
+    await db.collection('userChangeOutbox').dropIndex("idx-userChangeOutbox-feedPosition")
+    await db.collection('userChangeOutbox').createIndex({ sentAt: 1, _id: 1 },
+        { partialFilterExpression: { sent: true }, name: "idx-userChangeOutbox-sentAt-id" })
+  }
+}